@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/config"
 	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/handler"
 	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/memory"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/sqlite"
 	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,13 +20,68 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
+// shutdownTimeout is how long in-flight requests get to finish on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// repository is implemented by every storage backend.
+type repository interface {
+	service.BetRepository
+	service.UserRepository
+	service.EventRepository
+	service.IdempotencyRepository
+	service.WalletRepository
+	service.FreeBetRepository
+	service.BoostRepository
+	service.LimitRepository
+	service.SettlementJobRepository
+}
+
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// --- Dependency Injection ---
-	// Create the in-memory repository
-	betRepo := memory.NewInMemoryBetRepository()
+	// Create the repository selected by configuration; closeRepo releases it on shutdown
+	var betRepo repository
+	closeRepo := func() error { return nil }
+	switch cfg.StorageBackend {
+	case config.BackendSQLite:
+		sqliteRepo, err := sqlite.NewSQLiteRepository(cfg.SQLitePath)
+		if err != nil {
+			log.Fatalf("Failed to open SQLite database %s: %v", cfg.SQLitePath, err)
+		}
+		betRepo = sqliteRepo
+		closeRepo = sqliteRepo.Close
+		log.Printf("Using SQLite storage at %s", cfg.SQLitePath)
+	default:
+		betRepo = memory.NewInMemoryBetRepository()
+		log.Print("Using in-memory storage")
+	}
 
 	// Create the service layer
-	betService := service.NewBetService(betRepo)
+	betService := service.NewBetService(betRepo, betRepo, betRepo, betRepo, betRepo, betRepo, betRepo, betRepo, betRepo,
+		service.WithCashOutMargin(cfg.CashOutMarginBps),
+		service.WithIdempotencyTTL(cfg.IdempotencyTTL),
+		service.WithIdempotencyLease(cfg.IdempotencyLease),
+		service.WithFXRates(cfg.FXRates),
+		service.WithReportingCurrency(cfg.ReportingCurrency),
+		service.WithSettlementWorkers(cfg.SettlementWorkers),
+		service.WithSettlementBatchSize(cfg.SettlementBatchSize))
+
+	// Start the settlement job workers, resuming jobs interrupted by the last shutdown.
+	// They run until the server shuts down.
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	if err := betService.StartSettlementWorkers(workersCtx); err != nil {
+		closeRepo()
+		log.Fatalf("Failed to start settlement workers: %v", err)
+	}
 
 	// Create the application handler (which now includes user and bet handlers)
 	appHandler := handler.NewAppHandler(betService)
@@ -76,11 +138,36 @@ func main() {
 
 
 	// --- Start Server ---
-	port := "8080"
+	port := cfg.Port
 	log.Printf("Starting Bet Settlement API server on port %s...", port)
 
-	err := app.Listen(":" + port)
-	if err != nil {
-		log.Fatalf("Failed to start server on port %s: %v", port, err)
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":" + port)
+	}()
+
+	// --- Graceful Shutdown ---
+	// Let in-flight requests and settlement batches finish, then close the repository so nothing is left half-written
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Print("Shutting down...")
+		if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			log.Printf("Error shutting down the server: %v", err)
+			exitCode = 1
+		}
+	case err := <-listenErr:
+		log.Printf("Failed to start server on port %s: %v", port, err)
+		exitCode = 1
+	}
+	stopWorkers()
+	betService.WaitSettlementWorkers()
+	if err := closeRepo(); err != nil {
+		log.Printf("Error closing the repository: %v", err)
+		exitCode = 1
+	}
+	log.Print("Server stopped")
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
-}
\ No newline at end of file
+}
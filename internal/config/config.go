@@ -0,0 +1,117 @@
+// Package config reads the server's settings from environment variables.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+// Storage backends selectable with STORAGE_BACKEND.
+const (
+	BackendMemory = "memory"
+	BackendSQLite = "sqlite"
+)
+
+// Config holds the settings the server needs at startup.
+type Config struct {
+	// Port is the HTTP listen port (PORT, default 8080).
+	Port string
+	// StorageBackend selects the repository implementation (STORAGE_BACKEND, default memory).
+	StorageBackend string
+	// SQLitePath is the database file used by the sqlite backend (SQLITE_PATH, default bets.db).
+	SQLitePath string
+	// CashOutMarginBps is the share of a bet's fair cash-out value kept by
+	// the house, in basis points (CASHOUT_MARGIN_BPS, default 500 = 5%).
+	CashOutMarginBps int64
+	// IdempotencyTTL is how long Idempotency-Keys are remembered
+	// (IDEMPOTENCY_TTL, a Go duration, default 24h).
+	IdempotencyTTL time.Duration
+	// IdempotencyLease is how long a request may hold its Idempotency-Key
+	// before its response is stored; a key left held by a crash is freed
+	// after it (IDEMPOTENCY_LEASE, a Go duration, default 1m).
+	IdempotencyLease time.Duration
+	// FXRates converts between currencies and lists the ones bets may be
+	// placed in (FX_RATES, CODE=RATE pairs, default EUR=1,GBP=1.17,USD=0.92).
+	FXRates *money.FXTable
+	// ReportingCurrency is what totals and reports are converted to
+	// (REPORTING_CURRENCY, default EUR).
+	ReportingCurrency money.Currency
+	// SettlementWorkers is how many settlement jobs run at once
+	// (SETTLEMENT_WORKERS, default 2).
+	SettlementWorkers int
+	// SettlementBatchSize is how many bets a settlement job settles in one
+	// step (SETTLEMENT_BATCH_SIZE, default 500).
+	SettlementBatchSize int
+}
+
+// Load reads the configuration from the environment, applying defaults.
+func Load() (*Config, error) {
+	cfg := &Config{
+		Port:           getEnv("PORT", "8080"),
+		StorageBackend: getEnv("STORAGE_BACKEND", BackendMemory),
+		SQLitePath:     getEnv("SQLITE_PATH", "bets.db"),
+	}
+
+	switch cfg.StorageBackend {
+	case BackendMemory, BackendSQLite:
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q (want %q or %q)", cfg.StorageBackend, BackendMemory, BackendSQLite)
+	}
+
+	margin, err := strconv.ParseInt(getEnv("CASHOUT_MARGIN_BPS", "500"), 10, 64)
+	if err != nil || margin < 0 || margin >= 10000 {
+		return nil, fmt.Errorf("invalid CASHOUT_MARGIN_BPS %q (want basis points from 0 to 9999)", os.Getenv("CASHOUT_MARGIN_BPS"))
+	}
+	cfg.CashOutMarginBps = margin
+
+	ttl, err := time.ParseDuration(getEnv("IDEMPOTENCY_TTL", "24h"))
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_TTL %q (want a positive duration such as 24h)", os.Getenv("IDEMPOTENCY_TTL"))
+	}
+	cfg.IdempotencyTTL = ttl
+	lease, err := time.ParseDuration(getEnv("IDEMPOTENCY_LEASE", "1m"))
+	if err != nil || lease <= 0 || lease > ttl {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_LEASE %q (want a positive duration no longer than IDEMPOTENCY_TTL)", os.Getenv("IDEMPOTENCY_LEASE"))
+	}
+	cfg.IdempotencyLease = lease
+
+	fx, err := money.ParseFXTable(getEnv("FX_RATES", "EUR=1,GBP=1.17,USD=0.92"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FX_RATES: %w", err)
+	}
+	cfg.FXRates = fx
+	reporting, err := money.ParseCurrency(getEnv("REPORTING_CURRENCY", string(money.DefaultCurrency)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPORTING_CURRENCY: %w", err)
+	}
+	cfg.ReportingCurrency = reporting
+	// Balances stored before currencies existed are in the default currency.
+	for _, c := range []money.Currency{reporting, money.DefaultCurrency} {
+		if !fx.Supports(c) {
+			return nil, fmt.Errorf("FX_RATES has no rate for %s", c)
+		}
+	}
+
+	workers, err := strconv.Atoi(getEnv("SETTLEMENT_WORKERS", "2"))
+	if err != nil || workers <= 0 {
+		return nil, fmt.Errorf("invalid SETTLEMENT_WORKERS %q (want a positive number)", os.Getenv("SETTLEMENT_WORKERS"))
+	}
+	cfg.SettlementWorkers = workers
+	batch, err := strconv.Atoi(getEnv("SETTLEMENT_BATCH_SIZE", "500"))
+	if err != nil || batch <= 0 {
+		return nil, fmt.Errorf("invalid SETTLEMENT_BATCH_SIZE %q (want a positive number)", os.Getenv("SETTLEMENT_BATCH_SIZE"))
+	}
+	cfg.SettlementBatchSize = batch
+	return cfg, nil
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
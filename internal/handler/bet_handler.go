@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -28,17 +29,93 @@ func (h *AppHandler) RegisterRoutes(app *fiber.App) {
 	// Bet Routes
 	bets := api.Group("/bets")
 	{
-		bets.Post("/", h.PlaceBet)               
-		bets.Post("/settle/:eventId", h.SettleBet) 
+		bets.Post("/", h.idempotent, h.PlaceBet)               
+		bets.Get("/", h.ListBets)
+		bets.Post("/accumulator", h.idempotent, h.PlaceAccumulator)
+		bets.Post("/system", h.idempotent, h.PlaceSystem)
+		bets.Post("/settle/:eventId", h.idempotent, h.SettleBet) 
+		bets.Post("/resettle/:eventId", h.idempotent, h.ResettleBets)
+		bets.Post("/unsettle/:eventId", h.idempotent, h.UnsettleBets)
+		bets.Get("/:betId", h.GetBet)
+		bets.Get("/:betId/cashout", h.QuoteCashOut)
+		bets.Post("/:betId/cashout", h.idempotent, h.CashOut)
 	}
 
+	// Event Routes
+	events := api.Group("/events")
+	{
+		events.Post("/", h.CreateEvent)
+		events.Get("/", h.ListEvents)
+		events.Get("/:eventId", h.GetEvent)
+		events.Get("/:eventId/exposure", h.GetEventExposure)
+		events.Get("/:eventId/corrections", h.ListSettlementCorrections)
+		events.Post("/:eventId/suspend", h.SuspendEvent)
+		events.Post("/:eventId/resume", h.ResumeEvent)
+		events.Post("/:eventId/close", h.CloseEvent)
+		events.Post("/:eventId/cancel", h.CancelEvent)
+		events.Post("/:eventId/markets", h.AddMarket)
+		events.Put("/:eventId/selections/:selectionId/odds", h.UpdateSelectionOdds)
+	}
+
+	// Boost Routes
+	boosts := api.Group("/boosts")
+	{
+		boosts.Post("/", h.CreateBoost)
+		boosts.Get("/", h.ListBoosts)
+		boosts.Get("/:boostId", h.GetBoost)
+		boosts.Post("/:boostId/cancel", h.CancelBoost)
+		boosts.Get("/:boostId/report", h.GetBoostReport)
+	}
+
+	// Limit Routes
+	limits := api.Group("/limits")
+	{
+		limits.Get("/", h.ListLimits)
+		limits.Get("/global", h.GetLimits)
+		limits.Put("/global", h.SetLimits)
+		limits.Delete("/global", h.DeleteLimits)
+		limits.Get("/events/:eventId", h.GetLimits)
+		limits.Put("/events/:eventId", h.SetLimits)
+		limits.Delete("/events/:eventId", h.DeleteLimits)
+		limits.Get("/users/:userId", h.GetLimits)
+		limits.Put("/users/:userId", h.SetLimits)
+		limits.Delete("/users/:userId", h.DeleteLimits)
+	}
+
+	// Settlement Job Routes
+	jobs := api.Group("/settlement-jobs")
+	{
+		jobs.Post("/", h.idempotent, h.SubmitSettlementJob)
+		jobs.Get("/", h.ListSettlementJobs)
+		jobs.Get("/:jobId", h.GetSettlementJob)
+		jobs.Post("/:jobId/cancel", h.CancelSettlementJob)
+	}
+
+	// Exposure Routes
+	api.Get("/exposure", h.ListExposure)
+
+	// Free Bet Routes
+	api.Post("/free-bets/expire", h.ExpireFreeBets)
+
 	// User Routes
 	users := api.Group("/users")
 	{
-		users.Post("/", h.CreateUser)             
+		users.Post("/", h.idempotent, h.CreateUser)             
 		users.Get("/", h.ListUsers)               
 		users.Get("/:userId", h.GetUser)          
 		users.Get("/:userId/balance", h.GetUserBalance) 
+		users.Get("/:userId/transactions", h.ListUserTransactions)
+		users.Get("/:userId/bets", h.ListUserBets)
+		users.Post("/:userId/adjustments", h.AdjustUserBalance)
+		users.Post("/:userId/deposits", h.idempotent, h.Deposit)
+		users.Get("/:userId/deposits", h.ListDeposits)
+		users.Post("/:userId/withdrawals", h.idempotent, h.RequestWithdrawal)
+		users.Get("/:userId/withdrawals", h.ListWithdrawals)
+		users.Post("/:userId/withdrawals/:withdrawalId/approve", h.ApproveWithdrawal)
+		users.Post("/:userId/withdrawals/:withdrawalId/reject", h.RejectWithdrawal)
+		users.Post("/:userId/free-bets", h.idempotent, h.GrantFreeBet)
+		users.Get("/:userId/free-bets", h.ListFreeBets)
+		users.Post("/:userId/free-bets/:freeBetId/revoke", h.RevokeFreeBet)
 		users.Put("/:userId", h.UpdateUser)      
 		users.Delete("/:userId", h.DeleteUser) 
 	}
@@ -48,14 +125,17 @@ func (h *AppHandler) RegisterRoutes(app *fiber.App) {
 
 // PlaceBet handles the request to place a new bet.
 // @Summary Place a new bet
-// @Description Places a bet for a user on a specific event.
+// @Description Places a bet for a user on a selection (at its current odds) or directly on an event. The event must be OPEN. The stake is debited from the user's wallet in the bet's currency, their own by default; stakes are never converted between currencies. With free_bet_id the stake is a free-bet token's value instead, and only the winnings are paid if it wins. With boost_id the bet is priced at the boost's odds; odds, if sent, are the unboosted price.
 // @Tags Bets
 // @Accept json
 // @Produce json
 // @Param bet body model.PlaceBetRequest true "Bet details"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 201 {object} model.Bet "Bet placed successfully"
-// @Failure 400 {object} map[string]string "Bad Request (validation error, insufficient balance)"
-// @Failure 404 {object} map[string]string "Not Found (user creation failed)"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, unsupported currency, no wallet in the currency, insufficient balance, free bet not eligible, boost not running, not covering the bet or stake over its maximum)"
+// @Failure 404 {object} map[string]string "Not Found (user creation failed, unknown event or selection, unknown free bet or boost)"
+// @Failure 409 {object} map[string]string "Conflict (event not OPEN, selection odds have changed, free bet already used, revoked or expired, boost cancelled or used up, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]interface{} "Unprocessable (stake, payout or liability limit exceeded, with its code and the max_stake allowed; Idempotency-Key reused with a different request)"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /bets [post]
 func (h *AppHandler) PlaceBet(c *fiber.Ctx) error {
@@ -79,25 +159,194 @@ func (h *AppHandler) PlaceBet(c *fiber.Ctx) error {
 		if e, ok := err.(*errors.ErrorNotFound); ok { 
 			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
 		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorLimitExceeded); ok {
+			return limitExceeded(c, e)
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to place bet"})
+	}
+
+	setETag(c, bet.Version)
+	return c.Status(http.StatusCreated).JSON(bet)
+}
+
+// PlaceAccumulator handles the request to place an accumulator bet.
+// @Summary Place an accumulator bet
+// @Description Places one stake on 2 to 20 legs, each on a different event. The bet pays at the product of the legs' odds if every leg wins; void legs drop out of the product.
+// @Tags Bets
+// @Accept json
+// @Produce json
+// @Param bet body model.PlaceAccumulatorRequest true "Accumulator details"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success 201 {object} model.Bet "Accumulator placed successfully"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, two legs on one event, no wallet in the currency, insufficient balance)"
+// @Failure 404 {object} map[string]string "Not Found (unknown event or selection)"
+// @Failure 409 {object} map[string]string "Conflict (an event not OPEN, selection odds have changed, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]interface{} "Unprocessable (stake, payout or liability limit exceeded, with its code and the max_stake allowed; Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /bets/accumulator [post]
+func (h *AppHandler) PlaceAccumulator(c *fiber.Ctx) error {
+	var req model.PlaceAccumulatorRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for PlaceAccumulator: %v", err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	bet, err := h.service.PlaceAccumulator(&req)
+	if err != nil {
+		log.Printf("Service error in PlaceAccumulator: %v", err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorLimitExceeded); ok {
+			return limitExceeded(c, e)
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to place bet"})
+	}
+
+	setETag(c, bet.Version)
+	return c.Status(http.StatusCreated).JSON(bet)
+}
+
+// PlaceSystem handles the request to place a system bet.
+// @Summary Place a system bet
+// @Description Splits one stake evenly across every combination of the legs named by a standard system (trixie, yankee, lucky15) or by a fold size (k-from-n). Each line settles like an accumulator of its legs; the bet pays the total its lines return.
+// @Tags Bets
+// @Accept json
+// @Produce json
+// @Param bet body model.PlaceSystemRequest true "System bet details"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success 201 {object} model.Bet "System bet placed successfully"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, wrong number of legs, stake does not split evenly, no wallet in the currency, insufficient balance)"
+// @Failure 404 {object} map[string]string "Not Found (unknown event or selection)"
+// @Failure 409 {object} map[string]string "Conflict (an event not OPEN, selection odds have changed, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]interface{} "Unprocessable (stake, payout or liability limit exceeded, with its code and the max_stake allowed; Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /bets/system [post]
+func (h *AppHandler) PlaceSystem(c *fiber.Ctx) error {
+	var req model.PlaceSystemRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for PlaceSystem: %v", err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	bet, err := h.service.PlaceSystem(&req)
+	if err != nil {
+		log.Printf("Service error in PlaceSystem: %v", err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorLimitExceeded); ok {
+			return limitExceeded(c, e)
+		}
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to place bet"})
 	}
 
+	setETag(c, bet.Version)
 	return c.Status(http.StatusCreated).JSON(bet)
 }
 
+// GetBet handles the request to retrieve a bet by ID.
+// @Summary Get bet by ID
+// @Description Retrieves a bet with its legs, lines and cash-out history.
+// @Tags Bets
+// @Produce json
+// @Param betId path string true "Bet ID"
+// @Success 200 {object} model.Bet "Bet details"
+// @Header 200 {string} ETag "Version of the bet"
+// @Failure 404 {object} map[string]string "Not Found (bet does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /bets/{betId} [get]
+func (h *AppHandler) GetBet(c *fiber.Ctx) error {
+	betID := c.Params("betId")
+
+	bet, err := h.service.GetBet(betID)
+	if err != nil {
+		log.Printf("Service error in GetBet (bet: %s): %v", betID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve bet"})
+	}
+
+	setETag(c, bet.Version)
+	return c.Status(http.StatusOK).JSON(bet)
+}
+
+// ListBets handles the request to search bets.
+// @Summary List bets
+// @Description Lists bets matching optional filters, one page at a time. Pass the returned next_cursor to read the following page with the same filters and sort.
+// @Tags Bets
+// @Produce json
+// @Param user_id query string false "User ID"
+// @Param event_id query string false "Event ID (including accumulator and system legs)"
+// @Param status query string false "Comma-separated bet statuses (PLACED, WON, LOST, VOID, CASHED_OUT)"
+// @Param created_from query string false "Placed at or after (RFC 3339)"
+// @Param created_to query string false "Placed at or before (RFC 3339)"
+// @Param settled_from query string false "Settled at or after (RFC 3339)"
+// @Param settled_to query string false "Settled at or before (RFC 3339)"
+// @Param min_stake query string false "Minimum stake"
+// @Param max_stake query string false "Maximum stake"
+// @Param sort query string false "Sort field: created_at (default), settled_at, amount"
+// @Param order query string false "asc (default) or desc"
+// @Param limit query int false "Page size, 1 to 200 (default 50)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} model.BetPage "Page of bets"
+// @Failure 400 {object} map[string]string "Bad Request (invalid filter or cursor)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /bets [get]
+func (h *AppHandler) ListBets(c *fiber.Ctx) error {
+	var req model.ListBetsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Printf("Error parsing query for ListBets: %v", err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse query parameters"})
+	}
+
+	page, err := h.service.ListBets(&req)
+	if err != nil {
+		log.Printf("Service error in ListBets: %v", err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve bets"})
+	}
+
+	return c.Status(http.StatusOK).JSON(page)
+}
+
 // SettleBet handles the request to settle bets for an event.
 // @Summary Settle bets for an event
-// @Description Settles all 'placed' bets for a given event ID based on the result (win/lose).
+// @Description Settles the 'placed' bets for a given event ID, either with one result (win/lose/void) for every bet or with the winning selections of each market. Void refunds the stake. Settlement is all-or-nothing. The event must be CLOSED; it becomes SETTLED once no bet waits for its result, so markets can be settled in several calls. With dry_run=true the settlement is worked out the same way but nothing is changed; the response previews the bets it would change, each user's balance change and the house's profit.
 // @Tags Bets
 // @Accept json
 // @Produce json
 // @Param eventId path string true "Event ID"
 // @Param result body model.SettleBetRequest true "Settlement result"
-// @Success 200 {object} map[string]string "Bets settled successfully"
-// @Failure 400 {object} map[string]string "Bad Request (invalid event ID or result)"
-// @Failure 404 {object} map[string]string "Not Found (no placed bets for the event)"
-// @Failure 409 {object} map[string]string "Conflict (e.g., bet already settled)"
-// @Failure 500 {object} map[string]string "Internal Server Error (partial settlement possible)"
+// @Param dry_run query bool false "Preview the settlement without changing anything"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success 200 {object} map[string]interface{} "Bets settled successfully, with a settlement summary, or the settlement preview of a dry run"
+// @Failure 400 {object} map[string]string "Bad Request (invalid event ID, result or dry_run)"
+// @Failure 404 {object} map[string]string "Not Found (unknown event)"
+// @Failure 409 {object} map[string]string "Conflict (event not CLOSED, event has an unfinished settlement job, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error (no bets were settled)"
 // @Router /bets/settle/{eventId} [post]
 func (h *AppHandler) SettleBet(c *fiber.Ctx) error {
 	eventID := c.Params("eventId")
@@ -117,8 +366,17 @@ func (h *AppHandler) SettleBet(c *fiber.Ctx) error {
 		log.Printf("Validation failed for SettleBet request (event: %s): %v", eventID, err)
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Validation failed: %s", err.Error())})
 	}
+	if raw := c.Query("dry_run"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Invalid dry_run '%s', must be true or false", raw)})
+		}
+		if dryRun {
+			return h.previewSettlement(c, eventID, &req)
+		}
+	}
 
-	err := h.service.SettleBetsForEvent(eventID, req.Result)
+	summary, err := h.service.SettleBetsForEvent(eventID, &req)
 	if err != nil {
 		log.Printf("Service error in SettleBet (event: %s): %v", eventID, err)
 		if e, ok := err.(*errors.ErrorNotFound); ok {
@@ -128,12 +386,44 @@ func (h *AppHandler) SettleBet(c *fiber.Ctx) error {
 			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
 		}
 		if e, ok := err.(*errors.ErrorConflict); ok { 
-			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error(), "message": "Some bets might have been settled previously or failed."})
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error(), "message": "No bets were settled."})
 		}
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": fmt.Sprintf("Failed to settle all bets for event %s, potential partial success: %s", eventID, err.Error())})
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": fmt.Sprintf("Failed to settle bets for event %s, no bets were settled: %s", eventID, err.Error())})
 	}
 
-	return c.Status(http.StatusOK).JSON(fiber.Map{"message": fmt.Sprintf("Bets for event %s settled successfully with result '%s'", eventID, req.Result)})
+	message := fmt.Sprintf("Bets for event %s settled successfully with result '%s'", eventID, req.Result)
+	if req.Result == "" {
+		message = fmt.Sprintf("Bets for event %s settled successfully by market results", eventID)
+	}
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"summary": summary,
+	})
+}
+
+// previewSettlement writes the preview of a settlement for a dry run of
+// SettleBet.
+func (h *AppHandler) previewSettlement(c *fiber.Ctx, eventID string, req *model.SettleBetRequest) error {
+	preview, err := h.service.PreviewSettlement(eventID, req)
+	if err != nil {
+		log.Printf("Service error in SettleBet dry run (event: %s): %v", eventID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": fmt.Sprintf("Failed to preview settlement of event %s: %s", eventID, err.Error())})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": fmt.Sprintf("Dry run: nothing was changed for event %s", eventID),
+		"dry_run": true,
+		"preview": preview,
+	})
 }
 
 // --- User CRUD Handlers ---
@@ -145,9 +435,11 @@ func (h *AppHandler) SettleBet(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param user body model.CreateUserRequest true "User details"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success 201 {object} model.User "User created successfully"
-// @Failure 400 {object} map[string]string "Bad Request (validation error)"
-// @Failure 409 {object} map[string]string "Conflict (user already exists)"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, unsupported currency)"
+// @Failure 409 {object} map[string]string "Conflict (user already exists, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /users [post]
 func (h *AppHandler) CreateUser(c *fiber.Ctx) error {
@@ -170,6 +462,7 @@ func (h *AppHandler) CreateUser(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create user"})
 	}
 
+	setETag(c, user.Version)
 	return c.Status(http.StatusCreated).JSON(user)
 }
 
@@ -180,6 +473,7 @@ func (h *AppHandler) CreateUser(c *fiber.Ctx) error {
 // @Produce json
 // @Param userId path string true "User ID"
 // @Success 200 {object} model.User "User details"
+// @Header 200 {string} ETag "Version of the user"
 // @Failure 400 {object} map[string]string "Bad Request (invalid user ID)"
 // @Failure 404 {object} map[string]string "Not Found (user does not exist)"
 // @Failure 500 {object} map[string]string "Internal Server Error"
@@ -199,6 +493,7 @@ func (h *AppHandler) GetUser(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user"})
 	}
 
+	setETag(c, user.Version)
 	return c.Status(http.StatusOK).JSON(user)
 }
 
@@ -226,14 +521,21 @@ func (h *AppHandler) ListUsers(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param userId path string true "User ID"
+// @Param If-Match header string false "ETag of the version being updated"
 // @Param user body model.UpdateUserRequest true "User details to update"
 // @Success 200 {object} model.User "User updated successfully"
-// @Failure 400 {object} map[string]string "Bad Request (invalid user ID or validation error)"
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400 {object} map[string]string "Bad Request (invalid user ID, If-Match or validation error)"
 // @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 412 {object} map[string]string "Precondition Failed (user changed since the If-Match version)"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /users/{userId} [put]
 func (h *AppHandler) UpdateUser(c *fiber.Ctx) error {
 	userID := c.Params("userId")
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	var req model.UpdateUserRequest
 	if err := c.BodyParser(&req); err != nil {
 		log.Printf("Error parsing request body for UpdateUser (user: %s): %v", userID, err)
@@ -241,7 +543,7 @@ func (h *AppHandler) UpdateUser(c *fiber.Ctx) error {
 	}
 
 	// Service layer handles validation and finding the user
-	user, err := h.service.UpdateUser(userID, &req)
+	user, err := h.service.UpdateUser(userID, version, &req)
 	if err != nil {
 		log.Printf("Service error in UpdateUser (user: %s): %v", userID, err)
 		if e, ok := err.(*errors.ErrorNotFound); ok {
@@ -250,9 +552,13 @@ func (h *AppHandler) UpdateUser(c *fiber.Ctx) error {
 		if e, ok := err.(*errors.ErrorBadRequest); ok {
 			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
 		}
+		if e, ok := err.(*errors.ErrorPreconditionFailed); ok {
+			return c.Status(http.StatusPreconditionFailed).JSON(fiber.Map{"error": e.Error()})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update user"})
 	}
 
+	setETag(c, user.Version)
 	return c.Status(http.StatusOK).JSON(user)
 }
 
@@ -262,16 +568,22 @@ func (h *AppHandler) UpdateUser(c *fiber.Ctx) error {
 // @Tags Users
 // @Produce json
 // @Param userId path string true "User ID"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 200 {object} map[string]string "User deleted successfully"
-// @Failure 400 {object} map[string]string "Bad Request (invalid user ID)"
+// @Failure 400 {object} map[string]string "Bad Request (invalid user ID or If-Match)"
 // @Failure 404 {object} map[string]string "Not Found (user does not exist)"
-// @Failure 409 {object} map[string]string "Conflict (e.g., user has active bets, if check implemented)"
+// @Failure 409 {object} map[string]string "Conflict (user has pending withdrawals or open bets)"
+// @Failure 412 {object} map[string]string "Precondition Failed (user changed since the If-Match version)"
 // @Failure 500 {object} map[string]string "Internal Server Error"
 // @Router /users/{userId} [delete]
 func (h *AppHandler) DeleteUser(c *fiber.Ctx) error {
 	userID := c.Params("userId")
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 
-	err := h.service.DeleteUser(userID)
+	err = h.service.DeleteUser(userID, version)
 	if err != nil {
 		log.Printf("Service error in DeleteUser (user: %s): %v", userID, err)
 		if e, ok := err.(*errors.ErrorNotFound); ok {
@@ -283,6 +595,9 @@ func (h *AppHandler) DeleteUser(c *fiber.Ctx) error {
 		if e, ok := err.(*errors.ErrorConflict); ok { 
 			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
 		}
+		if e, ok := err.(*errors.ErrorPreconditionFailed); ok {
+			return c.Status(http.StatusPreconditionFailed).JSON(fiber.Map{"error": e.Error()})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete user"})
 	}
 
@@ -292,11 +607,11 @@ func (h *AppHandler) DeleteUser(c *fiber.Ctx) error {
 
 // GetUserBalance handles the request to get a user's balance.
 // @Summary Get user balance
-// @Description Retrieves the current balance for a specific user ID.
+// @Description Retrieves the balance of a user in their own currency, the balance of each of their wallets, and the total of all of them converted to the reporting currency.
 // @Tags Users
 // @Produce json
 // @Param userId path string true "User ID"
-// @Success 200 {object} map[string]float64 "User balance"
+// @Success 200 {object} model.UserBalance "User balances"
 // @Failure 400 {object} map[string]string "Bad Request (invalid user ID)"
 // @Failure 404 {object} map[string]string "Not Found (user does not exist)"
 // @Failure 500 {object} map[string]string "Internal Server Error"
@@ -322,5 +637,110 @@ func (h *AppHandler) GetUserBalance(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user balance"})
 	}
 
-	return c.Status(http.StatusOK).JSON(fiber.Map{"user_id": userID, "balance": balance})
-}
\ No newline at end of file
+	return c.Status(http.StatusOK).JSON(balance)
+}
+
+// ListUserTransactions handles the request to list a user's ledger history.
+// @Summary List user transactions
+// @Description Retrieves every ledger entry that moved the user's balance, oldest first, with the running balance.
+// @Tags Users
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {array} model.Transaction "User transactions"
+// @Failure 400 {object} map[string]string "Bad Request (invalid user ID)"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/transactions [get]
+func (h *AppHandler) ListUserTransactions(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	txs, err := h.service.ListUserTransactions(userID)
+	if err != nil {
+		log.Printf("Service error in ListUserTransactions (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user transactions"})
+	}
+
+	return c.Status(http.StatusOK).JSON(txs)
+}
+
+// ListUserBets handles the request to list a user's bets.
+// @Summary List user bets
+// @Description Lists a user's bets one page at a time, with the filters, sort and cursor of GET /bets (except user_id). The summary covers every bet the user has placed, whatever the filters.
+// @Tags Users
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param status query string false "Comma-separated bet statuses (PLACED, WON, LOST, VOID, CASHED_OUT)"
+// @Param sort query string false "Sort field: created_at (default), settled_at, amount"
+// @Param order query string false "asc (default) or desc"
+// @Param limit query int false "Page size, 1 to 200 (default 50)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} model.UserBetHistory "Page of bets with the user's summary"
+// @Failure 400 {object} map[string]string "Bad Request (invalid filter or cursor)"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/bets [get]
+func (h *AppHandler) ListUserBets(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	var req model.ListBetsRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Printf("Error parsing query for ListUserBets (user: %s): %v", userID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse query parameters"})
+	}
+
+	history, err := h.service.ListUserBets(userID, &req)
+	if err != nil {
+		log.Printf("Service error in ListUserBets (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve user bets"})
+	}
+
+	return c.Status(http.StatusOK).JSON(history)
+}
+
+// AdjustUserBalance handles the request to manually adjust a user's balance.
+// @Summary Adjust user balance
+// @Description Credits (positive amount) or debits (negative amount) a user's wallet in the given currency, their own by default, with a recorded reason.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param adjustment body model.AdjustBalanceRequest true "Adjustment details"
+// @Success 200 {object} model.User "Balance adjusted"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, unsupported currency, no wallet in the currency, insufficient balance)"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/adjustments [post]
+func (h *AppHandler) AdjustUserBalance(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	var req model.AdjustBalanceRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for AdjustUserBalance (user: %s): %v", userID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	user, err := h.service.AdjustUserBalance(userID, &req)
+	if err != nil {
+		log.Printf("Service error in AdjustUserBalance (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to adjust user balance"})
+	}
+
+	setETag(c, user.Version)
+	return c.Status(http.StatusOK).JSON(user)
+}
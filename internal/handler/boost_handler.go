@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- Boost Handlers ---
+
+// CreateBoost handles the request to create an odds boost.
+// @Summary Create an odds boost
+// @Description Creates a promotion that boosts the winnings part of the odds (odds − 1) of single bets on an event, or one of its selections, by increase_bps basis points while it runs. Bets opt in with boost_id, up to max_stake and max_uses_per_user times per user.
+// @Tags Boosts
+// @Accept json
+// @Produce json
+// @Param boost body model.CreateBoostRequest true "Boost details"
+// @Success 201 {object} model.Boost "Boost created successfully"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, window in the past, selection not on the event, unsupported currency)"
+// @Failure 404 {object} map[string]string "Not Found (unknown selection)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /boosts [post]
+func (h *AppHandler) CreateBoost(c *fiber.Ctx) error {
+	var req model.CreateBoostRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for CreateBoost: %v", err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	boost, err := h.service.CreateBoost(&req)
+	if err != nil {
+		log.Printf("Service error in CreateBoost: %v", err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create boost"})
+	}
+	return c.Status(http.StatusCreated).JSON(boost)
+}
+
+// ListBoosts handles the request to list odds boosts.
+// @Summary List odds boosts
+// @Description Retrieves every boost, or those on one event, oldest first.
+// @Tags Boosts
+// @Produce json
+// @Param event_id query string false "Only boosts on this event"
+// @Success 200 {array} model.Boost "List of boosts"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /boosts [get]
+func (h *AppHandler) ListBoosts(c *fiber.Ctx) error {
+	boosts, err := h.service.ListBoosts(c.Query("event_id"))
+	if err != nil {
+		log.Printf("Service error in ListBoosts: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve boosts"})
+	}
+	return c.Status(http.StatusOK).JSON(boosts)
+}
+
+// GetBoost handles the request to retrieve an odds boost by ID.
+// @Summary Get boost by ID
+// @Description Retrieves a boost.
+// @Tags Boosts
+// @Produce json
+// @Param boostId path string true "Boost ID"
+// @Success 200 {object} model.Boost "Boost details"
+// @Failure 404 {object} map[string]string "Not Found (boost does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /boosts/{boostId} [get]
+func (h *AppHandler) GetBoost(c *fiber.Ctx) error {
+	boostID := c.Params("boostId")
+
+	boost, err := h.service.GetBoost(boostID)
+	if err != nil {
+		log.Printf("Service error in GetBoost (boost: %s): %v", boostID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve boost"})
+	}
+	return c.Status(http.StatusOK).JSON(boost)
+}
+
+// CancelBoost handles the request to cancel an odds boost.
+// @Summary Cancel a boost
+// @Description Stops an ACTIVE boost from pricing new bets. Bets already placed with it keep their boosted odds.
+// @Tags Boosts
+// @Produce json
+// @Param boostId path string true "Boost ID"
+// @Success 200 {object} model.Boost "Boost cancelled"
+// @Failure 404 {object} map[string]string "Not Found (boost does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (boost already cancelled)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /boosts/{boostId}/cancel [post]
+func (h *AppHandler) CancelBoost(c *fiber.Ctx) error {
+	boostID := c.Params("boostId")
+
+	boost, err := h.service.CancelBoost(boostID)
+	if err != nil {
+		log.Printf("Service error in CancelBoost (boost: %s): %v", boostID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to cancel boost"})
+	}
+	return c.Status(http.StatusOK).JSON(boost)
+}
+
+// GetBoostReport handles the request to report on an odds boost.
+// @Summary Report on a boost
+// @Description Summarises the bets placed with a boost: how many, by how many users, what they staked and returned, and the promotion's cost, the winnings paid over what the original odds would have paid. The cost is totalled in the reporting currency.
+// @Tags Boosts
+// @Produce json
+// @Param boostId path string true "Boost ID"
+// @Success 200 {object} model.BoostReport "Boost report"
+// @Failure 404 {object} map[string]string "Not Found (boost does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /boosts/{boostId}/report [get]
+func (h *AppHandler) GetBoostReport(c *fiber.Ctx) error {
+	boostID := c.Params("boostId")
+
+	report, err := h.service.BoostReport(boostID)
+	if err != nil {
+		log.Printf("Service error in GetBoostReport (boost: %s): %v", boostID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to report on boost"})
+	}
+	return c.Status(http.StatusOK).JSON(report)
+}
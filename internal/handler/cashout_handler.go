@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- Cash-out Handlers ---
+
+// QuoteCashOut handles the request for a bet's current cash-out value.
+// @Summary Quote a cash-out
+// @Description Prices some or all of a PLACED bet's open stake for cash-out at the current odds of its open selections, less the configured margin. Every event the bet still waits for must be OPEN. The quote holds only while those odds are unchanged and the events stay OPEN.
+// @Tags Bets
+// @Produce json
+// @Param betId path string true "Bet ID"
+// @Param stake query string false "Part of the open stake to quote (default: all of it)"
+// @Success 200 {object} model.CashOutQuote "Cash-out quote"
+// @Failure 400 {object} map[string]string "Bad Request (invalid stake)"
+// @Failure 404 {object} map[string]string "Not Found (bet or selection does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (bet is not PLACED, cannot be priced or waits for an event that is not OPEN)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /bets/{betId}/cashout [get]
+func (h *AppHandler) QuoteCashOut(c *fiber.Ctx) error {
+	betID := c.Params("betId")
+	var stake money.Money
+	if raw := c.Query("stake"); raw != "" {
+		parsed, err := money.Parse(raw)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Invalid stake: %s", err.Error())})
+		}
+		stake = parsed
+	}
+
+	quote, err := h.service.QuoteCashOut(betID, stake)
+	if err != nil {
+		log.Printf("Service error in QuoteCashOut (bet: %s): %v", betID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to quote cash-out"})
+	}
+
+	return c.Status(http.StatusOK).JSON(quote)
+}
+
+// CashOut handles the request to cash out a bet.
+// @Summary Cash out a bet
+// @Description Closes some or all of a PLACED bet's open stake for the quoted value, which is credited to the user's balance. The value sent must match a fresh quote; a stale quote is rejected and nothing changes. A partial cash-out leaves the rest of the stake PLACED.
+// @Tags Bets
+// @Accept json
+// @Produce json
+// @Param betId path string true "Bet ID"
+// @Param cashout body model.CashOutRequest true "Accepted cash-out value"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success 200 {object} model.Bet "Bet cashed out successfully, with its cash-out history"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, stake larger than the open stake)"
+// @Failure 404 {object} map[string]string "Not Found (bet does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (stale quote, bet already settled, event no longer OPEN, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /bets/{betId}/cashout [post]
+func (h *AppHandler) CashOut(c *fiber.Ctx) error {
+	betID := c.Params("betId")
+	var req model.CashOutRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for CashOut (bet: %s): %v", betID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	bet, err := h.service.CashOut(betID, &req)
+	if err != nil {
+		log.Printf("Service error in CashOut (bet: %s): %v", betID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to cash out bet"})
+	}
+
+	setETag(c, bet.Version)
+	return c.Status(http.StatusOK).JSON(bet)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// --- Settlement Correction Handlers ---
+
+// ResettleBets handles the request to correct the result of a settled event.
+// @Summary Resettle an event
+// @Description Corrects the result of a CLOSED or SETTLED event. Every bet the old result decided is reopened and what its settlement credited is clawed back, even into a negative balance. The corrected result, given as for a settlement, is then applied to every open bet on the event. Everything happens in one step, recorded with the operator and reason; if anything fails nothing changes.
+// @Tags Bets
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param correction body model.ResettleRequest true "Corrected result, operator and reason"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success 200 {object} model.SettlementCorrection "Event resettled"
+// @Failure 400 {object} map[string]string "Bad Request (invalid result, missing operator or reason)"
+// @Failure 404 {object} map[string]string "Not Found (unknown event)"
+// @Failure 409 {object} map[string]string "Conflict (event not CLOSED or SETTLED, event has an unfinished settlement job, a reopened bet would wait for another SETTLED event, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error (nothing was changed)"
+// @Router /bets/resettle/{eventId} [post]
+func (h *AppHandler) ResettleBets(c *fiber.Ctx) error {
+	// Copied, since the correction is stored and fiber reuses the request's memory.
+	eventID := utils.CopyString(c.Params("eventId"))
+
+	var req model.ResettleRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for ResettleBets (event: %s): %v", eventID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	correction, err := h.service.ResettleEvent(eventID, &req)
+	if err != nil {
+		return correctionError(c, "ResettleBets", eventID, err)
+	}
+	return c.Status(http.StatusOK).JSON(correction)
+}
+
+// UnsettleBets handles the request to take back the result of a settled
+// event.
+// @Summary Unsettle an event
+// @Description Takes back the result of a CLOSED or SETTLED event: the bets it decided return to PLACED, what their settlement credited is clawed back, even into a negative balance, and the event is CLOSED again so it can be settled. Accumulators and system bets have their legs on the event reopened. Cashed-out bets are left alone. Everything happens in one step, recorded with the operator and reason.
+// @Tags Bets
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param correction body model.CorrectionRequest true "Operator and reason"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success 200 {object} model.SettlementCorrection "Event unsettled"
+// @Failure 400 {object} map[string]string "Bad Request (missing operator or reason)"
+// @Failure 404 {object} map[string]string "Not Found (unknown event)"
+// @Failure 409 {object} map[string]string "Conflict (event not CLOSED or SETTLED, event has an unfinished settlement job, a reopened bet would wait for another SETTLED event, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error (nothing was changed)"
+// @Router /bets/unsettle/{eventId} [post]
+func (h *AppHandler) UnsettleBets(c *fiber.Ctx) error {
+	eventID := utils.CopyString(c.Params("eventId"))
+
+	var req model.CorrectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for UnsettleBets (event: %s): %v", eventID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	correction, err := h.service.UnsettleEvent(eventID, &req)
+	if err != nil {
+		return correctionError(c, "UnsettleBets", eventID, err)
+	}
+	return c.Status(http.StatusOK).JSON(correction)
+}
+
+// ListSettlementCorrections handles the request for an event's settlement
+// corrections.
+// @Summary List settlement corrections
+// @Description Lists the resettlements and unsettlements of an event, oldest first, with who made each one, why, and what it changed. Totals are in the reporting currency.
+// @Tags Events
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Success 200 {array} model.SettlementCorrection "Settlement corrections"
+// @Failure 404 {object} map[string]string "Not Found (event does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId}/corrections [get]
+func (h *AppHandler) ListSettlementCorrections(c *fiber.Ctx) error {
+	eventID := c.Params("eventId")
+
+	corrections, err := h.service.ListSettlementCorrections(eventID)
+	if err != nil {
+		log.Printf("Service error in ListSettlementCorrections (event: %s): %v", eventID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to list settlement corrections"})
+	}
+	return c.Status(http.StatusOK).JSON(corrections)
+}
+
+// correctionError writes the response for a failed settlement correction.
+func correctionError(c *fiber.Ctx, op, eventID string, err error) error {
+	log.Printf("Service error in %s (event: %s): %v", op, eventID, err)
+	if e, ok := err.(*errors.ErrorBadRequest); ok {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+	}
+	if e, ok := err.(*errors.ErrorNotFound); ok {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+	}
+	if e, ok := err.(*errors.ErrorConflict); ok {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error(), "message": "Nothing was changed."})
+	}
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": fmt.Sprintf("Failed to correct settlement of event %s, nothing was changed: %s", eventID, err.Error())})
+}
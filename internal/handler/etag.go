@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// setETag tags a response with the version of the user or bet it carries.
+func setETag(c *fiber.Ctx, version int64) {
+	c.Set(fiber.HeaderETag, fmt.Sprintf(`"%d"`, version))
+}
+
+// ifMatchVersion returns the version named by the request's If-Match
+// header, or 0 when the header is absent or "*". Weak tags are accepted
+// since versions are the only thing tags are compared by.
+func ifMatchVersion(c *fiber.Ctx) (int64, error) {
+	tag := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if tag == "" || tag == "*" {
+		return 0, nil
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err == nil {
+		var version int64
+		version, err = strconv.ParseInt(unquoted, 10, 64)
+		if err == nil && version > 0 {
+			return version, nil
+		}
+	}
+	return 0, &errors.ErrorBadRequest{Message: fmt.Sprintf("invalid If-Match header %q (want a single ETag such as \"3\")", c.Get(fiber.HeaderIfMatch))}
+}
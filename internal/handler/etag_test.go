@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/memory"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestETagOnReads(t *testing.T) {
+	app, _ := newTestApp(memory.NewInMemoryBetRepository())
+	resp, _ := send(t, app, http.MethodPost, "/api/v1/users", createAlice)
+	if got := resp.Header.Get(fiber.HeaderETag); got != `"1"` {
+		t.Fatalf("created user ETag = %q, want \"1\"", got)
+	}
+	resp, _ = send(t, app, http.MethodGet, "/api/v1/users/alice", "")
+	if got := resp.Header.Get(fiber.HeaderETag); got != `"1"` {
+		t.Fatalf("user ETag = %q, want \"1\"", got)
+	}
+
+	createEvent(t, app, "match-1")
+	bet := placeBet(t, app, "alice", "match-1-home", "10")
+	resp, _ = send(t, app, http.MethodGet, "/api/v1/bets/"+bet.ID, "")
+	if got := resp.Header.Get(fiber.HeaderETag); got != `"1"` {
+		t.Fatalf("bet ETag = %q, want \"1\"", got)
+	}
+
+	// The stake moved the balance, so the user's version moved on.
+	resp, _ = send(t, app, http.MethodGet, "/api/v1/users/alice", "")
+	if got := resp.Header.Get(fiber.HeaderETag); got != `"2"` {
+		t.Fatalf("user ETag after a bet = %q, want \"2\"", got)
+	}
+}
+
+func TestIfMatchStale(t *testing.T) {
+	app, _ := newTestApp(memory.NewInMemoryBetRepository())
+	mustSend(t, app, http.StatusCreated, http.MethodPost, "/api/v1/users", createAlice)
+	createEvent(t, app, "match-1")
+	placeBet(t, app, "alice", "match-1-home", "10")
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		body := ""
+		if method == http.MethodPut {
+			body = `{}`
+		}
+		resp, data := send(t, app, method, "/api/v1/users/alice", body, fiber.HeaderIfMatch, `"1"`)
+		if resp.StatusCode != http.StatusPreconditionFailed {
+			t.Errorf("%s with a stale If-Match = %d %s, want 412", method, resp.StatusCode, data)
+		}
+	}
+	mustSend(t, app, http.StatusOK, http.MethodGet, "/api/v1/users/alice", "")
+
+	resp, data := send(t, app, http.MethodPut, "/api/v1/users/alice", `{}`, fiber.HeaderIfMatch, `"2"`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT with the current If-Match = %d %s, want 200", resp.StatusCode, data)
+	}
+	if got := resp.Header.Get(fiber.HeaderETag); got == "" || got == `"2"` {
+		t.Fatalf("ETag after update = %q, want a new version", got)
+	}
+}
+
+func TestIfMatchHeader(t *testing.T) {
+	cases := []struct {
+		name    string
+		ifMatch []string
+		want    int
+	}{
+		{"missing", nil, http.StatusOK},
+		{"any", []string{fiber.HeaderIfMatch, "*"}, http.StatusOK},
+		{"current", []string{fiber.HeaderIfMatch, `"1"`}, http.StatusOK},
+		{"weak", []string{fiber.HeaderIfMatch, `W/"1"`}, http.StatusOK},
+		{"padded", []string{fiber.HeaderIfMatch, ` "1" `}, http.StatusOK},
+		{"unquoted", []string{fiber.HeaderIfMatch, "1"}, http.StatusBadRequest},
+		{"not a number", []string{fiber.HeaderIfMatch, `"abc"`}, http.StatusBadRequest},
+		{"zero", []string{fiber.HeaderIfMatch, `"0"`}, http.StatusBadRequest},
+		{"negative", []string{fiber.HeaderIfMatch, `"-1"`}, http.StatusBadRequest},
+		{"list", []string{fiber.HeaderIfMatch, `"1", "2"`}, http.StatusBadRequest},
+		{"unterminated", []string{fiber.HeaderIfMatch, `"1`}, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		for _, method := range []string{http.MethodPut, http.MethodDelete} {
+			app, _ := newTestApp(memory.NewInMemoryBetRepository())
+			mustSend(t, app, http.StatusCreated, http.MethodPost, "/api/v1/users", createAlice)
+			body := ""
+			if method == http.MethodPut {
+				body = `{}`
+			}
+			resp, data := send(t, app, method, "/api/v1/users/alice", body, tc.ifMatch...)
+			if resp.StatusCode != tc.want {
+				t.Errorf("%s: %s = %d %s, want %d", tc.name, method, resp.StatusCode, data, tc.want)
+			}
+		}
+	}
+}
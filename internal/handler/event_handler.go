@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- Event Handlers ---
+
+// CreateEvent handles the request to create an event with its markets.
+// @Summary Create an event
+// @Description Creates an event with its markets and selections. Market and selection IDs are generated when omitted.
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param event body model.CreateEventRequest true "Event details"
+// @Success 201 {object} model.Event "Event created successfully"
+// @Failure 400 {object} map[string]string "Bad Request (validation error)"
+// @Failure 409 {object} map[string]string "Conflict (event, market or selection ID already exists)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events [post]
+func (h *AppHandler) CreateEvent(c *fiber.Ctx) error {
+	var req model.CreateEventRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for CreateEvent: %v", err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	event, err := h.service.CreateEvent(&req)
+	if err != nil {
+		log.Printf("Service error in CreateEvent: %v", err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create event"})
+	}
+
+	return c.Status(http.StatusCreated).JSON(event)
+}
+
+// ListEvents handles the request to retrieve all events.
+// @Summary List all events
+// @Description Retrieves all events with their markets and selections.
+// @Tags Events
+// @Produce json
+// @Success 200 {array} model.Event "List of events"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events [get]
+func (h *AppHandler) ListEvents(c *fiber.Ctx) error {
+	events, err := h.service.ListEvents()
+	if err != nil {
+		log.Printf("Service error in ListEvents: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve events"})
+	}
+	return c.Status(http.StatusOK).JSON(events)
+}
+
+// GetEvent handles the request to retrieve an event by ID.
+// @Summary Get event by ID
+// @Description Retrieves an event with its markets and selections.
+// @Tags Events
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Success 200 {object} model.Event "Event details"
+// @Failure 404 {object} map[string]string "Not Found (event does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId} [get]
+func (h *AppHandler) GetEvent(c *fiber.Ctx) error {
+	eventID := c.Params("eventId")
+
+	event, err := h.service.GetEvent(eventID)
+	if err != nil {
+		log.Printf("Service error in GetEvent (event: %s): %v", eventID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve event"})
+	}
+
+	return c.Status(http.StatusOK).JSON(event)
+}
+
+// AddMarket handles the request to add a market to an event.
+// @Summary Add a market to an event
+// @Description Adds a market with its selections to an existing event.
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param market body model.CreateMarketRequest true "Market details"
+// @Success 201 {object} model.Market "Market added successfully"
+// @Failure 400 {object} map[string]string "Bad Request (validation error)"
+// @Failure 404 {object} map[string]string "Not Found (event does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (market or selection ID already exists)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId}/markets [post]
+func (h *AppHandler) AddMarket(c *fiber.Ctx) error {
+	eventID := c.Params("eventId")
+	var req model.CreateMarketRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for AddMarket (event: %s): %v", eventID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	market, err := h.service.AddMarket(eventID, &req)
+	if err != nil {
+		log.Printf("Service error in AddMarket (event: %s): %v", eventID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to add market"})
+	}
+
+	return c.Status(http.StatusCreated).JSON(market)
+}
+
+// UpdateSelectionOdds handles the request to reprice a selection.
+// @Summary Update a selection's odds
+// @Description Sets the current price of a selection. Bets already placed keep their odds; open cash-out quotes on the selection become stale.
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Param selectionId path string true "Selection ID"
+// @Param odds body model.UpdateOddsRequest true "New odds"
+// @Success 200 {object} model.Selection "Selection repriced successfully"
+// @Failure 400 {object} map[string]string "Bad Request (validation error)"
+// @Failure 404 {object} map[string]string "Not Found (selection does not exist on the event)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId}/selections/{selectionId}/odds [put]
+func (h *AppHandler) UpdateSelectionOdds(c *fiber.Ctx) error {
+	eventID := c.Params("eventId")
+	selectionID := c.Params("selectionId")
+	var req model.UpdateOddsRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for UpdateSelectionOdds (selection: %s): %v", selectionID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	sel, err := h.service.UpdateSelectionOdds(eventID, selectionID, &req)
+	if err != nil {
+		log.Printf("Service error in UpdateSelectionOdds (selection: %s): %v", selectionID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update odds"})
+	}
+
+	return c.Status(http.StatusOK).JSON(sel)
+}
+
+// SuspendEvent handles the request to suspend trading on an event.
+// @Summary Suspend an event
+// @Description Stops an OPEN event from taking bets until it is resumed, e.g. while a goal is reviewed. Open bets are unaffected.
+// @Tags Events
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Success 200 {object} model.Event "Event suspended"
+// @Failure 404 {object} map[string]string "Not Found (event does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (event is not OPEN)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId}/suspend [post]
+func (h *AppHandler) SuspendEvent(c *fiber.Ctx) error {
+	return h.setEventStatus(c, model.EventSuspended)
+}
+
+// ResumeEvent handles the request to resume trading on an event.
+// @Summary Resume an event
+// @Description Reopens a SUSPENDED event to bets.
+// @Tags Events
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Success 200 {object} model.Event "Event open"
+// @Failure 404 {object} map[string]string "Not Found (event does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (event is not SUSPENDED)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId}/resume [post]
+func (h *AppHandler) ResumeEvent(c *fiber.Ctx) error {
+	return h.setEventStatus(c, model.EventOpen)
+}
+
+// CloseEvent handles the request to close an event that has finished.
+// @Summary Close an event
+// @Description Stops an OPEN or SUSPENDED event from taking bets for good, so that it can be settled.
+// @Tags Events
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Success 200 {object} model.Event "Event closed"
+// @Failure 404 {object} map[string]string "Not Found (event does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (event already closed, settled or cancelled)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId}/close [post]
+func (h *AppHandler) CloseEvent(c *fiber.Ctx) error {
+	return h.setEventStatus(c, model.EventClosed)
+}
+
+// setEventStatus moves the event named in the route to status.
+func (h *AppHandler) setEventStatus(c *fiber.Ctx, status model.EventStatus) error {
+	eventID := c.Params("eventId")
+
+	event, err := h.service.SetEventStatus(eventID, status)
+	if err != nil {
+		log.Printf("Service error setting event %s to %s: %v", eventID, status, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update event"})
+	}
+
+	return c.Status(http.StatusOK).JSON(event)
+}
+
+// CancelEvent handles the request to cancel an event.
+// @Summary Cancel an event
+// @Description Cancels an event that will not finish: it stops taking bets and its open bets are voided, refunding their stakes. Accumulators and system bets have their leg on it voided. Cancelling a CANCELLED event again voids any bets left open.
+// @Tags Events
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Success 200 {object} map[string]interface{} "Event cancelled, with a settlement summary of the voided bets"
+// @Failure 404 {object} map[string]string "Not Found (event does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (event already settled, or a settlement job for it is queued or running)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId}/cancel [post]
+func (h *AppHandler) CancelEvent(c *fiber.Ctx) error {
+	eventID := c.Params("eventId")
+
+	summary, err := h.service.CancelEvent(eventID)
+	if err != nil {
+		log.Printf("Service error in CancelEvent (event: %s): %v", eventID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to cancel event"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": fmt.Sprintf("Event %s cancelled", eventID),
+		"summary": summary,
+	})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- Exposure Handlers ---
+
+// GetEventExposure handles the request for an event's exposure.
+// @Summary Get event exposure
+// @Description Reports what the house stands to lose on an event from its open bets, in the reporting currency: the stake and potential payout on each market and outcome, the liability of each outcome (its payout less the stake on its market) and the worst case per market and for the event. Figures are kept up to date as bets are placed, cashed out and settled.
+// @Tags Events
+// @Produce json
+// @Param eventId path string true "Event ID"
+// @Success 200 {object} model.EventExposure "Event exposure"
+// @Failure 404 {object} map[string]string "Not Found (event does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /events/{eventId}/exposure [get]
+func (h *AppHandler) GetEventExposure(c *fiber.Ctx) error {
+	eventID := c.Params("eventId")
+
+	exposure, err := h.service.EventExposure(eventID)
+	if err != nil {
+		log.Printf("Service error in GetEventExposure (event: %s): %v", eventID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve exposure"})
+	}
+
+	return c.Status(http.StatusOK).JSON(exposure)
+}
+
+// ListExposure handles the request for the riskiest events.
+// @Summary List the riskiest events
+// @Description Lists the events with open bets by worst-case liability in the reporting currency, highest first, with the exposure of each.
+// @Tags Events
+// @Produce json
+// @Param limit query int false "Number of events (default 10, max 100)"
+// @Success 200 {object} model.ExposureRanking "Riskiest events"
+// @Failure 400 {object} map[string]string "Bad Request (invalid limit)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /exposure [get]
+func (h *AppHandler) ListExposure(c *fiber.Ctx) error {
+	var req model.TopExposureRequest
+	if err := c.QueryParser(&req); err != nil {
+		log.Printf("Error parsing query for ListExposure: %v", err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse query parameters"})
+	}
+
+	ranking, err := h.service.TopExposure(&req)
+	if err != nil {
+		log.Printf("Service error in ListExposure: %v", err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve exposure"})
+	}
+
+	return c.Status(http.StatusOK).JSON(ranking)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- Free Bet Handlers ---
+
+// GrantFreeBet handles the request to grant a free-bet token to a user.
+// @Summary Grant a free bet
+// @Description Grants a user a token that stakes one single bet of its value without debiting their wallet. A winning free bet pays only its winnings, stake × (odds − 1). The token may be limited to some events and to a minimum price.
+// @Tags Free Bets
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param freeBet body model.GrantFreeBetRequest true "Token value, expiry and eligibility"
+// @Success 201 {object} model.FreeBet "Free bet granted"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, expiry in the past, unsupported currency)"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/free-bets [post]
+func (h *AppHandler) GrantFreeBet(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	var req model.GrantFreeBetRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for GrantFreeBet (user: %s): %v", userID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	freeBet, err := h.service.GrantFreeBet(userID, &req)
+	if err != nil {
+		log.Printf("Service error in GrantFreeBet (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to grant free bet"})
+	}
+	return c.Status(http.StatusCreated).JSON(freeBet)
+}
+
+// ListFreeBets handles the request to list a user's free-bet tokens.
+// @Summary List free bets
+// @Description Retrieves a user's free-bet tokens, oldest first. Tokens past their expiry show as EXPIRED.
+// @Tags Free Bets
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {array} model.FreeBet "User free bets"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/free-bets [get]
+func (h *AppHandler) ListFreeBets(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	freeBets, err := h.service.ListFreeBets(userID)
+	if err != nil {
+		log.Printf("Service error in ListFreeBets (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve free bets"})
+	}
+	return c.Status(http.StatusOK).JSON(freeBets)
+}
+
+// RevokeFreeBet handles the request to revoke a user's free-bet token.
+// @Summary Revoke a free bet
+// @Description Revokes an ACTIVE token so it can no longer be used.
+// @Tags Free Bets
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param freeBetId path string true "Free bet ID"
+// @Param revocation body model.RevokeFreeBetRequest false "Reason for the revocation"
+// @Success 200 {object} model.FreeBet "Free bet revoked"
+// @Failure 400 {object} map[string]string "Bad Request (validation error)"
+// @Failure 404 {object} map[string]string "Not Found (free bet does not exist for this user)"
+// @Failure 409 {object} map[string]string "Conflict (free bet already used, revoked or expired)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/free-bets/{freeBetId}/revoke [post]
+func (h *AppHandler) RevokeFreeBet(c *fiber.Ctx) error {
+	userID, freeBetID := c.Params("userId"), c.Params("freeBetId")
+	var req model.RevokeFreeBetRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			log.Printf("Error parsing request body for RevokeFreeBet (free bet: %s): %v", freeBetID, err)
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+		}
+	}
+
+	freeBet, err := h.service.RevokeFreeBet(userID, freeBetID, &req)
+	if err != nil {
+		log.Printf("Service error in RevokeFreeBet (user: %s, free bet: %s): %v", userID, freeBetID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke free bet"})
+	}
+	return c.Status(http.StatusOK).JSON(freeBet)
+}
+
+// ExpireFreeBets handles the request to sweep lapsed free-bet tokens.
+// @Summary Expire free bets
+// @Description Marks every ACTIVE token past its expiry as EXPIRED and returns them. Lapsed tokens are refused at redemption whether or not they have been swept.
+// @Tags Free Bets
+// @Produce json
+// @Success 200 {array} model.FreeBet "Free bets expired by this sweep"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /free-bets/expire [post]
+func (h *AppHandler) ExpireFreeBets(c *fiber.Ctx) error {
+	expired, err := h.service.ExpireFreeBets()
+	if err != nil {
+		log.Printf("Service error in ExpireFreeBets: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to expire free bets"})
+	}
+	return c.Status(http.StatusOK).JSON(expired)
+}
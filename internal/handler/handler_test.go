@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/memory"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/repotest"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/sqlite"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// backends are the repositories tests that depend on storage run against.
+var backends = []struct {
+	name    string
+	newRepo repotest.Factory
+}{
+	{"memory", func(t *testing.T) repotest.Repository { return memory.NewInMemoryBetRepository() }},
+	{"sqlite", func(t *testing.T) repotest.Repository {
+		repo, err := sqlite.NewSQLiteRepository(filepath.Join(t.TempDir(), "bets.db"))
+		if err != nil {
+			t.Fatalf("NewSQLiteRepository: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	}},
+}
+
+// newTestApp serves the API from a repository, as main does.
+func newTestApp(repo repotest.Repository) (*fiber.App, *AppHandler) {
+	h := NewAppHandler(service.NewBetService(repo, repo, repo, repo, repo, repo, repo, repo, repo))
+	app := fiber.New()
+	h.RegisterRoutes(app)
+	return app, h
+}
+
+// send makes a request with a JSON body, if any, and headers given as
+// name/value pairs, and returns the response and its body.
+func send(t *testing.T, app *fiber.App, method, path, body string, headers ...string) (*http.Response, []byte) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: reading body: %v", method, path, err)
+	}
+	return resp, data
+}
+
+// mustSend is send for requests expected to answer with status want.
+func mustSend(t *testing.T, app *fiber.App, want int, method, path, body string, headers ...string) []byte {
+	t.Helper()
+	resp, data := send(t, app, method, path, body, headers...)
+	if resp.StatusCode != want {
+		t.Fatalf("%s %s = %d %s, want %d", method, path, resp.StatusCode, data, want)
+	}
+	return data
+}
+
+// decode unmarshals a JSON response body into v.
+func decode(t *testing.T, data []byte, v any) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+}
+
+// createEvent creates an open event with one market of two selections,
+// <eventID>-home and <eventID>-away, both at even money.
+func createEvent(t *testing.T, app *fiber.App, eventID string) {
+	t.Helper()
+	body := fmt.Sprintf(`{"event_id":%[1]q,"name":"Event %[1]s","markets":[{"market_id":"%[1]s-result","name":"Result","selections":[`+
+		`{"selection_id":"%[1]s-home","name":"Home","odds":2},{"selection_id":"%[1]s-away","name":"Away","odds":2}]}]}`, eventID)
+	mustSend(t, app, http.StatusCreated, http.MethodPost, "/api/v1/events", body)
+}
+
+// placeBet places a single bet on a selection and returns it.
+func placeBet(t *testing.T, app *fiber.App, userID, selectionID, amount string) *model.Bet {
+	t.Helper()
+	var bet model.Bet
+	body := fmt.Sprintf(`{"user_id":%q,"selection_id":%q,"amount":%s}`, userID, selectionID, amount)
+	decode(t, mustSend(t, app, http.StatusCreated, http.MethodPost, "/api/v1/bets", body), &bet)
+	return &bet
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Headers of idempotent requests.
+const (
+	// IdempotencyKeyHeader carries a client-chosen key that makes a request
+	// safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed for a retry.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// unreplayedHeaders are response headers that describe the connection or the
+// moment a response was sent rather than the response itself, so they are not
+// stored for replay.
+var unreplayedHeaders = map[string]bool{
+	fiber.HeaderContentLength:    true,
+	fiber.HeaderDate:             true,
+	fiber.HeaderServer:           true,
+	fiber.HeaderConnection:       true,
+	fiber.HeaderTransferEncoding: true,
+}
+
+// idempotent makes the handler after it safe to retry. A request with an
+// Idempotency-Key is handled once; retries with the same key and the same
+// method, path, query and body get the stored response back, headers such
+// as ETag included, without being handled again. Requests without the
+// header are handled as usual. Server errors are not stored, so the client
+// can retry them.
+func (h *AppHandler) idempotent(c *fiber.Ctx) error {
+	key := c.Get(IdempotencyKeyHeader)
+	if key == "" {
+		return c.Next()
+	}
+
+	claim, err := h.service.BeginIdempotentRequest(key, requestFingerprint(c))
+	if err != nil {
+		log.Printf("Service error in idempotency check (key: %s): %v", key, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorUnprocessable); ok {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check idempotency key"})
+	}
+	if claim.Completed() {
+		// Responses stored before headers were kept are all JSON.
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		for name, value := range claim.Headers {
+			c.Set(name, value)
+		}
+		c.Set(IdempotentReplayedHeader, "true")
+		return c.Status(claim.StatusCode).Send(claim.Body)
+	}
+
+	if err := c.Next(); err != nil {
+		h.service.ReleaseIdempotentRequest(claim)
+		return err
+	}
+	status := c.Response().StatusCode()
+	if status >= http.StatusInternalServerError {
+		h.service.ReleaseIdempotentRequest(claim)
+		return nil
+	}
+	// The response buffers are reused once the request completes.
+	headers := map[string]string{}
+	c.Response().Header.VisitAll(func(name, value []byte) {
+		if n := string(name); !unreplayedHeaders[n] {
+			headers[n] = string(value)
+		}
+	})
+	body := append([]byte(nil), c.Response().Body()...)
+	if err := h.service.CompleteIdempotentRequest(claim, status, headers, body); err != nil {
+		log.Printf("Failed to store response for idempotency key %s: %v", key, err)
+	}
+	return nil
+}
+
+// requestFingerprint identifies a request by its method, path, query and
+// body. The query is left out when empty, so fingerprints of requests
+// without one are unchanged.
+func requestFingerprint(c *fiber.Ctx) string {
+	sum := sha256.New()
+	sum.Write([]byte(c.Method()))
+	sum.Write([]byte{0})
+	sum.Write([]byte(c.Path()))
+	if query := c.Request().URI().QueryString(); len(query) > 0 {
+		sum.Write([]byte{'?'})
+		sum.Write(query)
+	}
+	sum.Write([]byte{0})
+	sum.Write(c.Body())
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/memory"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const createAlice = `{"user_id":"alice","name":"Alice"}`
+
+func TestIdempotentReplay(t *testing.T) {
+	app, _ := newTestApp(memory.NewInMemoryBetRepository())
+
+	first, firstBody := send(t, app, http.MethodPost, "/api/v1/users", createAlice, IdempotencyKeyHeader, "key-1")
+	if first.StatusCode != http.StatusCreated {
+		t.Fatalf("first request = %d %s, want 201", first.StatusCode, firstBody)
+	}
+	if first.Header.Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("first request marked as replayed")
+	}
+
+	// Handling the request again would fail: alice already exists.
+	retry, retryBody := send(t, app, http.MethodPost, "/api/v1/users", createAlice, IdempotencyKeyHeader, "key-1")
+	if retry.StatusCode != http.StatusCreated || string(retryBody) != string(firstBody) {
+		t.Fatalf("retry = %d %s, want the stored 201 %s", retry.StatusCode, retryBody, firstBody)
+	}
+	if retry.Header.Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("retry not marked as replayed")
+	}
+	for _, name := range []string{fiber.HeaderETag, fiber.HeaderContentType} {
+		if got, want := retry.Header.Get(name), first.Header.Get(name); got != want {
+			t.Errorf("replayed %s = %q, want %q", name, got, want)
+		}
+	}
+
+	// Without the key the request is handled again.
+	mustSend(t, app, http.StatusConflict, http.MethodPost, "/api/v1/users", createAlice)
+}
+
+func TestIdempotentKeyReusedWithDifferentRequest(t *testing.T) {
+	app, _ := newTestApp(memory.NewInMemoryBetRepository())
+	mustSend(t, app, http.StatusCreated, http.MethodPost, "/api/v1/users", createAlice, IdempotencyKeyHeader, "key-1")
+
+	cases := []struct {
+		name, method, path, body string
+	}{
+		{"body", http.MethodPost, "/api/v1/users", `{"user_id":"bob","name":"Bob"}`},
+		{"path", http.MethodPost, "/api/v1/bets", createAlice},
+		{"query", http.MethodPost, "/api/v1/users?dry_run=true", createAlice},
+	}
+	for _, tc := range cases {
+		resp, body := send(t, app, tc.method, tc.path, tc.body, IdempotencyKeyHeader, "key-1")
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("%s: reused key = %d %s, want 422", tc.name, resp.StatusCode, body)
+		}
+	}
+	// bob was never created.
+	mustSend(t, app, http.StatusNotFound, http.MethodGet, "/api/v1/users/bob", "")
+}
+
+func TestIdempotentRequestInFlight(t *testing.T) {
+	app, h := newTestApp(memory.NewInMemoryBetRepository())
+	entered, release := make(chan struct{}), make(chan struct{})
+	app.Post("/slow", h.idempotent, func(c *fiber.Ctx) error {
+		close(entered)
+		<-release
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"done": true})
+	})
+
+	done := make(chan int)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/slow", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+	<-entered
+
+	resp, body := send(t, app, http.MethodPost, "/slow", `{}`, IdempotencyKeyHeader, "key-1")
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("retry while in flight = %d %s, want 409", resp.StatusCode, body)
+	}
+	close(release)
+	if status := <-done; status != http.StatusCreated {
+		t.Fatalf("first request = %d, want 201", status)
+	}
+
+	resp, _ = send(t, app, http.MethodPost, "/slow", `{}`, IdempotencyKeyHeader, "key-1")
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("retry once done = %d, want the replayed 201", resp.StatusCode)
+	}
+}
+
+func TestIdempotentServerErrorReleasesKey(t *testing.T) {
+	app, h := newTestApp(memory.NewInMemoryBetRepository())
+	var calls atomic.Int32
+	app.Post("/flaky", h.idempotent, func(c *fiber.Ctx) error {
+		if calls.Add(1) == 1 {
+			return c.Status(http.StatusServiceUnavailable).JSON(fiber.Map{"error": "try again"})
+		}
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"call": calls.Load()})
+	})
+
+	mustSend(t, app, http.StatusServiceUnavailable, http.MethodPost, "/flaky", `{}`, IdempotencyKeyHeader, "key-1")
+	retried := mustSend(t, app, http.StatusCreated, http.MethodPost, "/flaky", `{}`, IdempotencyKeyHeader, "key-1")
+	replayed := mustSend(t, app, http.StatusCreated, http.MethodPost, "/flaky", `{}`, IdempotencyKeyHeader, "key-1")
+	if string(replayed) != string(retried) || calls.Load() != 2 {
+		t.Fatalf("handled %d times, replayed %s after %s; want the second response replayed", calls.Load(), replayed, retried)
+	}
+}
+
+func TestIdempotencyKeyLength(t *testing.T) {
+	app, _ := newTestApp(memory.NewInMemoryBetRepository())
+
+	mustSend(t, app, http.StatusCreated, http.MethodPost, "/api/v1/users", createAlice, IdempotencyKeyHeader, strings.Repeat("k", 255))
+	resp, body := send(t, app, http.MethodPost, "/api/v1/users", `{"user_id":"bob","name":"Bob"}`, IdempotencyKeyHeader, strings.Repeat("k", 256))
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "at most 255") {
+		t.Fatalf("256-character key = %d %s, want 400", resp.StatusCode, body)
+	}
+	mustSend(t, app, http.StatusNotFound, http.MethodGet, "/api/v1/users/bob", "")
+}
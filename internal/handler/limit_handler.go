@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// --- Limit Handlers ---
+
+// limitScope reads which limits a request is about from its route: an
+// event's, a user's, or the global ones. The ID is copied, since it is
+// stored and fiber reuses the request's memory.
+func limitScope(c *fiber.Ctx) (model.LimitScope, string) {
+	if eventID := c.Params("eventId"); eventID != "" {
+		return model.LimitScopeEvent, utils.CopyString(eventID)
+	}
+	if userID := c.Params("userId"); userID != "" {
+		return model.LimitScopeUser, utils.CopyString(userID)
+	}
+	return model.LimitScopeGlobal, ""
+}
+
+// limitExceeded responds to a bet refused by a limit with its code and the
+// stakes the limits would accept, so clients can offer the largest one.
+func limitExceeded(c *fiber.Ctx, e *errors.ErrorLimitExceeded) error {
+	body := fiber.Map{"error": e.Error(), "code": e.Code, "max_stake": e.MaxStake, "currency": e.Currency}
+	if e.MinStake.IsPositive() {
+		body["min_stake"] = e.MinStake
+	}
+	return c.Status(http.StatusUnprocessableEntity).JSON(body)
+}
+
+// ListLimits handles the request to list every set of betting limits.
+// @Summary List betting limits
+// @Description Retrieves every stored set of limits: the global ones first, then per event and per user.
+// @Tags Limits
+// @Produce json
+// @Success 200 {array} model.Limits "List of limits"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /limits [get]
+func (h *AppHandler) ListLimits(c *fiber.Ctx) error {
+	limits, err := h.service.ListLimits()
+	if err != nil {
+		log.Printf("Service error in ListLimits: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve limits"})
+	}
+	return c.Status(http.StatusOK).JSON(limits)
+}
+
+// GetLimits handles the request to retrieve the limits of a scope.
+// @Summary Get betting limits
+// @Description Retrieves the global limits, or those of an event or user. A scope without limits returns every limit as zero (unset).
+// @Tags Limits
+// @Produce json
+// @Param eventId path string false "Event ID"
+// @Param userId path string false "User ID"
+// @Success 200 {object} model.Limits "Limits"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /limits/global [get]
+// @Router /limits/events/{eventId} [get]
+// @Router /limits/users/{userId} [get]
+func (h *AppHandler) GetLimits(c *fiber.Ctx) error {
+	scope, scopeID := limitScope(c)
+
+	limits, err := h.service.GetLimits(scope, scopeID)
+	if err != nil {
+		log.Printf("Service error in GetLimits (%s %s): %v", scope, scopeID, err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve limits"})
+	}
+	return c.Status(http.StatusOK).JSON(limits)
+}
+
+// SetLimits handles the request to set the limits of a scope.
+// @Summary Set betting limits
+// @Description Replaces the global limits, or those of an event or user: minimum and maximum stake, maximum potential payout per bet, maximum liability of the open bets on an event (not per user) and maximum liability of a user's bets per UTC day (not per event). Zero leaves a limit unset. Event and user limits replace global ones; where a bet's events and user set the same limit, the tightest applies.
+// @Tags Limits
+// @Accept json
+// @Produce json
+// @Param eventId path string false "Event ID"
+// @Param userId path string false "User ID"
+// @Param limits body model.SetLimitsRequest true "Limits"
+// @Success 200 {object} model.Limits "Limits set"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, minimum over maximum, limit not settable for the scope, unsupported currency)"
+// @Failure 404 {object} map[string]string "Not Found (event or user does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /limits/global [put]
+// @Router /limits/events/{eventId} [put]
+// @Router /limits/users/{userId} [put]
+func (h *AppHandler) SetLimits(c *fiber.Ctx) error {
+	scope, scopeID := limitScope(c)
+	var req model.SetLimitsRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for SetLimits: %v", err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	limits, err := h.service.SetLimits(scope, scopeID, &req)
+	if err != nil {
+		log.Printf("Service error in SetLimits (%s %s): %v", scope, scopeID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to set limits"})
+	}
+	return c.Status(http.StatusOK).JSON(limits)
+}
+
+// DeleteLimits handles the request to remove the limits of a scope.
+// @Summary Remove betting limits
+// @Description Removes the global limits, or those of an event or user, so that only the remaining ones apply.
+// @Tags Limits
+// @Produce json
+// @Param eventId path string false "Event ID"
+// @Param userId path string false "User ID"
+// @Success 200 {object} map[string]string "Limits removed"
+// @Failure 404 {object} map[string]string "Not Found (scope has no limits)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /limits/global [delete]
+// @Router /limits/events/{eventId} [delete]
+// @Router /limits/users/{userId} [delete]
+func (h *AppHandler) DeleteLimits(c *fiber.Ctx) error {
+	scope, scopeID := limitScope(c)
+
+	if err := h.service.DeleteLimits(scope, scopeID); err != nil {
+		log.Printf("Service error in DeleteLimits (%s %s): %v", scope, scopeID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to remove limits"})
+	}
+	return c.Status(http.StatusOK).JSON(fiber.Map{"message": fmt.Sprintf("Limits removed for %s %s", scope, scopeID)})
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// userState is everything a settlement can change for a user, as the API
+// reports it: the user with their balances, their ledger and their bets.
+func userState(t *testing.T, app *fiber.App, userID string) []byte {
+	t.Helper()
+	var state []byte
+	for _, path := range []string{"", "/transactions", "/bets"} {
+		state = append(state, mustSend(t, app, http.StatusOK, http.MethodGet, "/api/v1/users/"+userID+path, "")...)
+	}
+	return state
+}
+
+func balance(t *testing.T, app *fiber.App, userID string) money.Money {
+	t.Helper()
+	var user model.User
+	decode(t, mustSend(t, app, http.StatusOK, http.MethodGet, "/api/v1/users/"+userID, ""), &user)
+	return user.Balance
+}
+
+func TestSettleBetDryRun(t *testing.T) {
+	const settle = `{"markets":[{"market_id":"match-1-result","winning_selection_ids":["match-1-home"]}]}`
+	users := []string{"alice", "bob"}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			app, _ := newTestApp(backend.newRepo(t))
+			mustSend(t, app, http.StatusCreated, http.MethodPost, "/api/v1/users", createAlice)
+			mustSend(t, app, http.StatusCreated, http.MethodPost, "/api/v1/users", `{"user_id":"bob","name":"Bob"}`)
+			createEvent(t, app, "match-1")
+			placeBet(t, app, "alice", "match-1-home", "10")
+			placeBet(t, app, "alice", "match-1-away", "5")
+			placeBet(t, app, "bob", "match-1-home", "20")
+			placeBet(t, app, "bob", "match-1-away", "30")
+			mustSend(t, app, http.StatusOK, http.MethodPost, "/api/v1/events/match-1/close", "")
+
+			before := make(map[string][]byte)
+			balances := make(map[string]money.Money)
+			for _, id := range users {
+				before[id] = userState(t, app, id)
+				balances[id] = balance(t, app, id)
+			}
+			event := mustSend(t, app, http.StatusOK, http.MethodGet, "/api/v1/events/match-1", "")
+
+			var dryRun struct {
+				DryRun  bool                     `json:"dry_run"`
+				Preview *model.SettlementPreview `json:"preview"`
+			}
+			decode(t, mustSend(t, app, http.StatusOK, http.MethodPost, "/api/v1/bets/settle/match-1?dry_run=true", settle), &dryRun)
+			if !dryRun.DryRun || dryRun.Preview == nil {
+				t.Fatalf("dry run response = %+v, want a preview", dryRun)
+			}
+			preview := dryRun.Preview
+
+			// Nothing was changed: no balance, ledger entry, bet or event.
+			for _, id := range users {
+				if after := userState(t, app, id); !bytes.Equal(after, before[id]) {
+					t.Fatalf("state of %s after a dry run = %s, want %s", id, after, before[id])
+				}
+			}
+			if after := mustSend(t, app, http.StatusOK, http.MethodGet, "/api/v1/events/match-1", ""); !bytes.Equal(after, event) {
+				t.Fatalf("event after a dry run = %s, want %s", after, event)
+			}
+
+			var settled struct {
+				Summary *model.SettlementSummary `json:"summary"`
+			}
+			decode(t, mustSend(t, app, http.StatusOK, http.MethodPost, "/api/v1/bets/settle/match-1", settle), &settled)
+
+			// The preview is what the settlement did.
+			want, _ := json.Marshal(settled.Summary)
+			got, _ := json.Marshal(preview.Summary)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("previewed summary = %s, settled %s", got, want)
+			}
+			if len(preview.Bets) != 4 {
+				t.Fatalf("previewed %d bets, want 4", len(preview.Bets))
+			}
+			credited := make(map[string]money.Money)
+			for _, previewed := range preview.Bets {
+				var stored model.Bet
+				decode(t, mustSend(t, app, http.StatusOK, http.MethodGet, "/api/v1/bets/"+previewed.ID, ""), &stored)
+				if stored.Status != previewed.Status {
+					t.Fatalf("bet %s is %s, previewed %s", stored.ID, stored.Status, previewed.Status)
+				}
+				credited[stored.UserID] += previewed.Credited
+			}
+			deltas := make(map[string]money.Money)
+			for _, delta := range preview.BalanceDeltas {
+				if delta.Currency != money.EUR {
+					t.Fatalf("previewed delta %+v, want EUR", delta)
+				}
+				deltas[delta.UserID] = delta.Amount
+			}
+			for _, id := range users {
+				change := balance(t, app, id) - balances[id]
+				if deltas[id] != change || credited[id] != change {
+					t.Fatalf("balance of %s changed by %s, previewed %s and %s credited", id, change, deltas[id], credited[id])
+				}
+			}
+		})
+	}
+}
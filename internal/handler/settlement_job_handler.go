@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- Settlement Job Handlers ---
+
+// SubmitSettlementJob handles the request to settle an event in the
+// background.
+// @Summary Submit a settlement job
+// @Description Queues the settlement of a CLOSED event, with its result given as for a settlement, and returns the job at once. A worker settles the event's bets in batches, each one atomic, so large events never hold up other requests; follow its progress with GET /settlement-jobs/{jobId}. An event can have one unfinished job at a time.
+// @Tags Settlement Jobs
+// @Accept json
+// @Produce json
+// @Param job body model.SettlementJobRequest true "Event and its result"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success 202 {object} model.SettlementJob "Settlement job queued"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, invalid result)"
+// @Failure 404 {object} map[string]string "Not Found (unknown event or market)"
+// @Failure 409 {object} map[string]string "Conflict (event not CLOSED, event already has an unfinished job, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /settlement-jobs [post]
+func (h *AppHandler) SubmitSettlementJob(c *fiber.Ctx) error {
+	var req model.SettlementJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for SubmitSettlementJob: %v", err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	job, err := h.service.SubmitSettlementJob(&req)
+	if err != nil {
+		log.Printf("Service error in SubmitSettlementJob (event: %s): %v", req.EventID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to submit settlement job"})
+	}
+	return c.Status(http.StatusAccepted).JSON(job)
+}
+
+// ListSettlementJobs handles the request to list settlement jobs.
+// @Summary List settlement jobs
+// @Description Retrieves every settlement job, or those in the given states, oldest first. Summaries are in the reporting currency.
+// @Tags Settlement Jobs
+// @Produce json
+// @Param state query string false "Comma-separated states (QUEUED, RUNNING, COMPLETED, FAILED, CANCELLED)"
+// @Success 200 {array} model.SettlementJob "List of settlement jobs"
+// @Failure 400 {object} map[string]string "Bad Request (unknown state)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /settlement-jobs [get]
+func (h *AppHandler) ListSettlementJobs(c *fiber.Ctx) error {
+	var states []model.SettlementJobState
+	if query := c.Query("state"); query != "" {
+		for _, s := range strings.Split(query, ",") {
+			state := model.SettlementJobState(strings.ToUpper(strings.TrimSpace(s)))
+			switch state {
+			case model.JobQueued, model.JobRunning, model.JobCompleted, model.JobFailed, model.JobCancelled:
+				states = append(states, state)
+			default:
+				return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("unknown settlement job state %q", s)})
+			}
+		}
+	}
+
+	jobs, err := h.service.ListSettlementJobs(states...)
+	if err != nil {
+		log.Printf("Service error in ListSettlementJobs: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve settlement jobs"})
+	}
+	return c.Status(http.StatusOK).JSON(jobs)
+}
+
+// GetSettlementJob handles the request to retrieve a settlement job by ID.
+// @Summary Get settlement job by ID
+// @Description Reports a settlement job's state, how many of the event's bets it has processed out of the total, the errors it met and the summary of what it has settled, in the reporting currency. The summary is final once the job is COMPLETED.
+// @Tags Settlement Jobs
+// @Produce json
+// @Param jobId path string true "Settlement job ID"
+// @Success 200 {object} model.SettlementJob "Settlement job details"
+// @Failure 404 {object} map[string]string "Not Found (job does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /settlement-jobs/{jobId} [get]
+func (h *AppHandler) GetSettlementJob(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+
+	job, err := h.service.GetSettlementJob(jobID)
+	if err != nil {
+		log.Printf("Service error in GetSettlementJob (job: %s): %v", jobID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve settlement job"})
+	}
+	return c.Status(http.StatusOK).JSON(job)
+}
+
+// CancelSettlementJob handles the request to cancel a settlement job.
+// @Summary Cancel a settlement job
+// @Description Cancels a QUEUED or RUNNING job. A running job stops before its next batch; the bets it has already settled stay settled, and the event stays CLOSED so it can be settled again.
+// @Tags Settlement Jobs
+// @Produce json
+// @Param jobId path string true "Settlement job ID"
+// @Success 200 {object} model.SettlementJob "Settlement job cancelled"
+// @Failure 404 {object} map[string]string "Not Found (job does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (job already finished)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /settlement-jobs/{jobId}/cancel [post]
+func (h *AppHandler) CancelSettlementJob(c *fiber.Ctx) error {
+	jobID := c.Params("jobId")
+
+	job, err := h.service.CancelSettlementJob(jobID)
+	if err != nil {
+		log.Printf("Service error in CancelSettlementJob (job: %s): %v", jobID, err)
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to cancel settlement job"})
+	}
+	return c.Status(http.StatusOK).JSON(job)
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- Wallet Handlers ---
+
+// Deposit handles the request to deposit money into a user's wallet.
+// @Summary Deposit funds
+// @Description Credits a deposit to the user's wallet in its currency, opening the wallet if needed. The reference, typically the payment provider's ID, must not have been used by any deposit or withdrawal before.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param deposit body model.WalletOperationRequest true "Deposit amount and reference"
+// @Success 201 {object} model.WalletOperation "Deposit completed"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, unsupported currency)"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (reference already used, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/deposits [post]
+func (h *AppHandler) Deposit(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	var req model.WalletOperationRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for Deposit (user: %s): %v", userID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	op, err := h.service.Deposit(userID, &req)
+	if err != nil {
+		log.Printf("Service error in Deposit (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to deposit funds"})
+	}
+	return c.Status(http.StatusCreated).JSON(op)
+}
+
+// RequestWithdrawal handles the request to withdraw money from a user's wallet.
+// @Summary Request a withdrawal
+// @Description Reserves the amount out of the user's balance and records a PENDING withdrawal, to be approved or rejected later. The reference must not have been used by any deposit or withdrawal before.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param withdrawal body model.WalletOperationRequest true "Withdrawal amount and reference"
+// @Success 201 {object} model.WalletOperation "Withdrawal pending"
+// @Failure 400 {object} map[string]string "Bad Request (validation error, unsupported currency, no wallet in the currency, insufficient balance)"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 409 {object} map[string]string "Conflict (reference already used, Idempotency-Key still in progress)"
+// @Failure 422 {object} map[string]string "Unprocessable (Idempotency-Key reused with a different request)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/withdrawals [post]
+func (h *AppHandler) RequestWithdrawal(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	var req model.WalletOperationRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Error parsing request body for RequestWithdrawal (user: %s): %v", userID, err)
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+	}
+
+	op, err := h.service.RequestWithdrawal(userID, &req)
+	if err != nil {
+		log.Printf("Service error in RequestWithdrawal (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to request withdrawal"})
+	}
+	return c.Status(http.StatusCreated).JSON(op)
+}
+
+// ApproveWithdrawal handles the request to approve a pending withdrawal.
+// @Summary Approve a withdrawal
+// @Description Pays out a PENDING withdrawal. Its amount was already taken from the balance when it was requested.
+// @Tags Wallet
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param withdrawalId path string true "Withdrawal ID"
+// @Success 200 {object} model.WalletOperation "Withdrawal approved"
+// @Failure 404 {object} map[string]string "Not Found (withdrawal does not exist for this user)"
+// @Failure 409 {object} map[string]string "Conflict (withdrawal already approved or rejected)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/withdrawals/{withdrawalId}/approve [post]
+func (h *AppHandler) ApproveWithdrawal(c *fiber.Ctx) error {
+	userID, withdrawalID := c.Params("userId"), c.Params("withdrawalId")
+
+	op, err := h.service.ApproveWithdrawal(userID, withdrawalID)
+	if err != nil {
+		log.Printf("Service error in ApproveWithdrawal (user: %s, withdrawal: %s): %v", userID, withdrawalID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to approve withdrawal"})
+	}
+	return c.Status(http.StatusOK).JSON(op)
+}
+
+// RejectWithdrawal handles the request to reject a pending withdrawal.
+// @Summary Reject a withdrawal
+// @Description Rejects a PENDING withdrawal and returns its reserved amount to the user's balance.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param withdrawalId path string true "Withdrawal ID"
+// @Param rejection body model.RejectWithdrawalRequest false "Reason for the rejection"
+// @Success 200 {object} model.WalletOperation "Withdrawal rejected"
+// @Failure 400 {object} map[string]string "Bad Request (validation error)"
+// @Failure 404 {object} map[string]string "Not Found (withdrawal does not exist for this user)"
+// @Failure 409 {object} map[string]string "Conflict (withdrawal already approved or rejected)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/withdrawals/{withdrawalId}/reject [post]
+func (h *AppHandler) RejectWithdrawal(c *fiber.Ctx) error {
+	userID, withdrawalID := c.Params("userId"), c.Params("withdrawalId")
+	var req model.RejectWithdrawalRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			log.Printf("Error parsing request body for RejectWithdrawal (withdrawal: %s): %v", withdrawalID, err)
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Cannot parse JSON request body"})
+		}
+	}
+
+	op, err := h.service.RejectWithdrawal(userID, withdrawalID, &req)
+	if err != nil {
+		log.Printf("Service error in RejectWithdrawal (user: %s, withdrawal: %s): %v", userID, withdrawalID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reject withdrawal"})
+	}
+	return c.Status(http.StatusOK).JSON(op)
+}
+
+// ListDeposits handles the request to list a user's deposits.
+// @Summary List deposits
+// @Description Retrieves a user's deposits, oldest first.
+// @Tags Wallet
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {array} model.WalletOperation "User deposits"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/deposits [get]
+func (h *AppHandler) ListDeposits(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	ops, err := h.service.ListDeposits(userID)
+	if err != nil {
+		log.Printf("Service error in ListDeposits (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve deposits"})
+	}
+	return c.Status(http.StatusOK).JSON(ops)
+}
+
+// ListWithdrawals handles the request to list a user's withdrawals.
+// @Summary List withdrawals
+// @Description Retrieves a user's withdrawals, pending and resolved, oldest first.
+// @Tags Wallet
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {array} model.WalletOperation "User withdrawals"
+// @Failure 404 {object} map[string]string "Not Found (user does not exist)"
+// @Failure 500 {object} map[string]string "Internal Server Error"
+// @Router /users/{userId}/withdrawals [get]
+func (h *AppHandler) ListWithdrawals(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	ops, err := h.service.ListWithdrawals(userID)
+	if err != nil {
+		log.Printf("Service error in ListWithdrawals (user: %s): %v", userID, err)
+		if e, ok := err.(*errors.ErrorBadRequest); ok {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorNotFound); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": e.Error()})
+		}
+		if e, ok := err.(*errors.ErrorConflict); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": e.Error()})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve withdrawals"})
+	}
+	return c.Status(http.StatusOK).JSON(ops)
+}
@@ -0,0 +1,68 @@
+package ledger
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+// Journal is an in-memory, append-only store of journal entries with
+// per-account indexes. It is not safe for concurrent use; callers hold
+// their own lock so that postings and the state they explain change together.
+type Journal struct {
+	entries   []*model.JournalEntry
+	byAccount map[string][]*model.JournalEntry
+	balances  map[string]money.Money
+}
+
+// NewJournal creates an empty journal.
+func NewJournal() *Journal {
+	return &Journal{
+		byAccount: make(map[string][]*model.JournalEntry),
+		balances:  make(map[string]money.Money),
+	}
+}
+
+// Append validates and records an entry.
+func (j *Journal) Append(entry *model.JournalEntry) error {
+	if err := Validate(entry); err != nil {
+		return err
+	}
+	j.entries = append(j.entries, entry)
+	for _, p := range entry.Postings {
+		// Index each entry once per account even if it posts to it twice.
+		list := j.byAccount[p.Account]
+		if len(list) == 0 || list[len(list)-1] != entry {
+			j.byAccount[p.Account] = append(list, entry)
+		}
+		j.balances[p.Account] += p.Amount
+	}
+	return nil
+}
+
+// Balance returns the sum of all postings to an account.
+func (j *Journal) Balance(account string) money.Money {
+	return j.balances[account]
+}
+
+// Entries returns the entries touching any of the accounts, oldest first.
+func (j *Journal) Entries(accounts ...string) []*model.JournalEntry {
+	if len(accounts) == 1 {
+		list := j.byAccount[accounts[0]]
+		out := make([]*model.JournalEntry, len(list))
+		copy(out, list)
+		return out
+	}
+	touched := make(map[*model.JournalEntry]bool)
+	for _, account := range accounts {
+		for _, e := range j.byAccount[account] {
+			touched[e] = true
+		}
+	}
+	out := make([]*model.JournalEntry, 0, len(touched))
+	for _, e := range j.entries {
+		if touched[e] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,238 @@
+// Package ledger implements the double-entry journal behind every balance
+// change. Entries are append-only and always balanced, so any account's
+// balance can be rebuilt by summing its postings.
+package ledger
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// House accounts on the other side of user wallet postings. Every account
+// is kept per currency; use HouseAccount to name the one for a currency.
+const (
+	// HouseBookAccount receives stakes and funds payouts and refunds.
+	HouseBookAccount = "house:book"
+	// HouseAdjustmentsAccount funds manual adjustments and opening balances.
+	HouseAdjustmentsAccount = "house:adjustments"
+	// HouseDepositsAccount funds deposits; its balance is minus everything
+	// ever deposited.
+	HouseDepositsAccount = "house:deposits"
+	// HouseWithdrawalsAccount receives approved withdrawals.
+	HouseWithdrawalsAccount = "house:withdrawals"
+	// HouseFreeBetsAccount funds free-bet stakes; its balance is minus the
+	// value of every token redeemed.
+	HouseFreeBetsAccount = "house:freebets"
+)
+
+// HouseAccount returns the house account of the given kind in a currency.
+func HouseAccount(kind string, currency money.Currency) string {
+	return kind + ":" + string(currency)
+}
+
+// WalletAccount returns the ledger account holding a user's balance in a
+// currency.
+func WalletAccount(userID string, currency money.Currency) string {
+	return "user:" + userID + ":wallet:" + string(currency)
+}
+
+// WalletAccounts returns the ledger accounts of every wallet a user has.
+func WalletAccounts(user *model.User) []string {
+	accounts := make([]string, 0, len(user.Balances))
+	for currency := range user.Balances {
+		accounts = append(accounts, WalletAccount(user.ID, currency))
+	}
+	return accounts
+}
+
+// WithdrawalHoldAccount returns the ledger account holding a user's pending
+// withdrawals in a currency, reserved out of their wallet.
+func WithdrawalHoldAccount(userID string, currency money.Currency) string {
+	return "user:" + userID + ":withdrawals:" + string(currency)
+}
+
+// Validate checks that an entry has postings and that they sum to zero.
+func Validate(entry *model.JournalEntry) error {
+	if len(entry.Postings) < 2 {
+		return fmt.Errorf("journal entry %s must have at least two postings", entry.Type)
+	}
+	if entry.Currency == "" {
+		return fmt.Errorf("journal entry %s has no currency", entry.Type)
+	}
+	var sum money.Money
+	for _, p := range entry.Postings {
+		if p.Account == "" {
+			return fmt.Errorf("journal entry %s has a posting without an account", entry.Type)
+		}
+		sum += p.Amount
+	}
+	if !sum.IsZero() {
+		return fmt.Errorf("journal entry %s is unbalanced by %s", entry.Type, sum)
+	}
+	return nil
+}
+
+// newEntry builds an entry moving amount from one account to another. Both
+// accounts must be kept in currency.
+func newEntry(entryType model.EntryType, userID, betID, description, from, to string, amount money.Money, currency money.Currency) *model.JournalEntry {
+	return &model.JournalEntry{
+		ID:          uuid.New().String(),
+		Type:        entryType,
+		UserID:      userID,
+		BetID:       betID,
+		Description: description,
+		Currency:    currency,
+		Postings: []model.Posting{
+			{Account: from, Amount: amount.Neg()},
+			{Account: to, Amount: amount},
+		},
+		CreatedAt: time.Now(),
+	}
+}
+
+// StakeEntry debits a bet's stake from the user's wallet into the house book.
+// A free bet's stake comes out of the free-bets account instead.
+func StakeEntry(bet *model.Bet) *model.JournalEntry {
+	if bet.IsFree() {
+		return newEntry(model.EntryFreeBetStake, bet.UserID, bet.ID, fmt.Sprintf("free bet %s staked on %s", bet.FreeBetID, bet.Subject()),
+			HouseAccount(HouseFreeBetsAccount, bet.Currency), HouseAccount(HouseBookAccount, bet.Currency), bet.Amount, bet.Currency)
+	}
+	return newEntry(model.EntryStake, bet.UserID, bet.ID, fmt.Sprintf("stake on %s", bet.Subject()),
+		WalletAccount(bet.UserID, bet.Currency), HouseAccount(HouseBookAccount, bet.Currency), bet.Amount, bet.Currency)
+}
+
+// PayoutEntry credits a winning bet's payout from the house book to the user's wallet.
+func PayoutEntry(bet *model.Bet, payout money.Money) *model.JournalEntry {
+	return newEntry(model.EntryPayout, bet.UserID, bet.ID, fmt.Sprintf("payout for %s", bet.Subject()),
+		HouseAccount(HouseBookAccount, bet.Currency), WalletAccount(bet.UserID, bet.Currency), payout, bet.Currency)
+}
+
+// RefundEntry returns a bet's stake from the house book to the user's wallet.
+func RefundEntry(bet *model.Bet, amount money.Money, reason string) *model.JournalEntry {
+	return newEntry(model.EntryRefund, bet.UserID, bet.ID, reason,
+		HouseAccount(HouseBookAccount, bet.Currency), WalletAccount(bet.UserID, bet.Currency), amount, bet.Currency)
+}
+
+// CashOutEntry credits the value of cashing out stake of a bet from the
+// house book to the user's wallet.
+func CashOutEntry(bet *model.Bet, stake, value money.Money) *model.JournalEntry {
+	return newEntry(model.EntryCashOut, bet.UserID, bet.ID, fmt.Sprintf("cash-out of %s stake on %s", stake, bet.Subject()),
+		HouseAccount(HouseBookAccount, bet.Currency), WalletAccount(bet.UserID, bet.Currency), value, bet.Currency)
+}
+
+// AdjustmentEntry moves amount between the adjustments account and the
+// user's wallet in a currency. A positive amount credits the user.
+func AdjustmentEntry(userID string, amount money.Money, currency money.Currency, reason string) *model.JournalEntry {
+	return newEntry(model.EntryAdjustment, userID, "", reason,
+		HouseAccount(HouseAdjustmentsAccount, currency), WalletAccount(userID, currency), amount, currency)
+}
+
+// DepositEntry credits a deposit to the user's wallet.
+func DepositEntry(op *model.WalletOperation) *model.JournalEntry {
+	return newEntry(model.EntryDeposit, op.UserID, "", fmt.Sprintf("deposit %s", op.Reference),
+		HouseAccount(HouseDepositsAccount, op.Currency), WalletAccount(op.UserID, op.Currency), op.Amount, op.Currency)
+}
+
+// WithdrawalEntry reserves a requested withdrawal, moving it out of the
+// user's wallet into their withdrawal hold.
+func WithdrawalEntry(op *model.WalletOperation) *model.JournalEntry {
+	return newEntry(model.EntryWithdrawal, op.UserID, "", fmt.Sprintf("withdrawal %s requested", op.Reference),
+		WalletAccount(op.UserID, op.Currency), WithdrawalHoldAccount(op.UserID, op.Currency), op.Amount, op.Currency)
+}
+
+// WithdrawalPaidEntry pays an approved withdrawal out of the user's hold.
+func WithdrawalPaidEntry(op *model.WalletOperation) *model.JournalEntry {
+	return newEntry(model.EntryWithdrawalPaid, op.UserID, "", fmt.Sprintf("withdrawal %s approved", op.Reference),
+		WithdrawalHoldAccount(op.UserID, op.Currency), HouseAccount(HouseWithdrawalsAccount, op.Currency), op.Amount, op.Currency)
+}
+
+// WithdrawalReversalEntry returns a rejected withdrawal from the user's hold
+// to their wallet.
+func WithdrawalReversalEntry(op *model.WalletOperation) *model.JournalEntry {
+	return newEntry(model.EntryWithdrawalReversal, op.UserID, "", fmt.Sprintf("withdrawal %s rejected", op.Reference),
+		WithdrawalHoldAccount(op.UserID, op.Currency), WalletAccount(op.UserID, op.Currency), op.Amount, op.Currency)
+}
+
+// WalletDelta returns the net change an entry makes to the user's wallet in
+// the entry's currency.
+func WalletDelta(entry *model.JournalEntry, userID string) money.Money {
+	account := WalletAccount(userID, entry.Currency)
+	var delta money.Money
+	for _, p := range entry.Postings {
+		if p.Account == account {
+			delta += p.Amount
+		}
+	}
+	return delta
+}
+
+// Transactions projects entries onto a user's wallets in the order given,
+// computing the running balance of the entry's wallet after each one.
+func Transactions(userID string, entries []*model.JournalEntry) []*model.Transaction {
+	txs := make([]*model.Transaction, 0, len(entries))
+	balances := make(map[money.Currency]money.Money)
+	for _, e := range entries {
+		delta := WalletDelta(e, userID)
+		balances[e.Currency] += delta
+		txs = append(txs, &model.Transaction{
+			EntryID:      e.ID,
+			Type:         e.Type,
+			BetID:        e.BetID,
+			Description:  e.Description,
+			Currency:     e.Currency,
+			Amount:       delta,
+			BalanceAfter: balances[e.Currency],
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+	return txs
+}
+
+// SettlementEntry returns the entry crediting the user for a settled bet,
+// or nil if its status moves no money (a lost stake already sits in the house book).
+// A system bet is credited once with the total its lines return, which a
+// lost system bet may still have from void lines.
+func SettlementEntry(bet *model.Bet) *model.JournalEntry {
+	if bet.HasLines() {
+		returned := bet.Payout()
+		switch {
+		case returned.IsZero():
+			return nil
+		case bet.Status == model.StatusWon:
+			return PayoutEntry(bet, returned)
+		default:
+			return RefundEntry(bet, returned, fmt.Sprintf("stake refund for void lines of %s", bet.Subject()))
+		}
+	}
+	switch bet.Status {
+	case model.StatusWon:
+		return PayoutEntry(bet, bet.Payout())
+	case model.StatusVoid:
+		if bet.IsFree() {
+			// A free-bet stake was never the user's to refund.
+			return nil
+		}
+		return RefundEntry(bet, bet.OpenStake(), fmt.Sprintf("stake refund for void %s", bet.Subject()))
+	default:
+		return nil
+	}
+}
+
+// ClawbackEntry returns the entry taking back what a settled bet's
+// settlement credited to the user, or nil if it credited nothing. The
+// wallet may go negative.
+func ClawbackEntry(bet *model.Bet, reason string) *model.JournalEntry {
+	if bet.Status == model.StatusPlaced {
+		return nil
+	}
+	credit := SettlementEntry(bet)
+	if credit == nil {
+		return nil
+	}
+	return newEntry(model.EntryClawback, bet.UserID, bet.ID, fmt.Sprintf("settlement of %s corrected: %s", bet.Subject(), reason),
+		WalletAccount(bet.UserID, bet.Currency), HouseAccount(HouseBookAccount, bet.Currency), WalletDelta(credit, bet.UserID), bet.Currency)
+}
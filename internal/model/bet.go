@@ -1,11 +1,34 @@
 package model
 
 import (
+	"fmt"
 	"github.com/go-playground/validator/v10"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+// newValidator builds the shared validator and registers the custom tags
+// needed for the fixed-point money types.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// odds: decimal odds strictly greater than 1.0
+	_ = v.RegisterValidation("odds", func(fl validator.FieldLevel) bool {
+		if fl.Field().Kind() != reflect.Int64 {
+			return false
+		}
+		return money.Odds(fl.Field().Int()) > money.EvenOdds
+	})
+	return v
+}
+
+// PayoutRounding is applied when a stake multiplied by odds does not land on
+// a whole minor unit. Fractions are always kept by the house.
+const PayoutRounding = money.RoundDown
 
 type BetStatus string
 
@@ -13,34 +36,410 @@ const (
 	StatusPlaced BetStatus = "PLACED"
 	StatusWon    BetStatus = "WON"
 	StatusLost   BetStatus = "LOST"
+	// StatusVoid marks a bet on an abandoned or postponed event; its stake is refunded.
+	StatusVoid BetStatus = "VOID"
+	// StatusCashedOut marks a bet closed early by the player for its cash-out value.
+	StatusCashedOut BetStatus = "CASHED_OUT"
+)
+
+// BetType distinguishes single bets from multi-leg bets.
+type BetType string
+
+const (
+	BetTypeSingle      BetType = "SINGLE"
+	BetTypeAccumulator BetType = "ACCUMULATOR"
+	// BetTypeSystem bets split one stake across every combination of a
+	// given size of their legs; see BetLine.
+	BetTypeSystem BetType = "SYSTEM"
 )
 
+// MaxLegs is the largest number of legs a multi-leg bet may have.
+const MaxLegs = 20
+
+// MaxOdds caps the odds of a bet, for a multi-leg bet the product of all
+// its legs' odds, which bounds every line of a system bet too.
+const MaxOdds = 1000000 * money.EvenOdds
+
+// MaxPayout caps the stake and potential payout of a bet, in its currency.
+const MaxPayout = money.Money(10000000 * 100)
+
+// Bet is a stake on one selection (a single), on several legs that must
+// all win (an accumulator), or on combinations of legs (a system).
+// Multi-leg bets leave EventID, MarketID and SelectionID empty. Odds holds
+// the combined odds of an accumulator's legs; systems leave it zero and
+// price each line instead.
 type Bet struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id" validate:"required"`
-	EventID   string    `json:"event_id" validate:"required"`
-	Odds      float64   `json:"odds" validate:"required,gt=1"`
-	Amount    float64   `json:"amount" validate:"required,gt=0"`
-	Status    BetStatus `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	SettledAt time.Time `json:"settled_at,omitempty"`
+	ID          string      `json:"id"`
+	UserID      string      `json:"user_id" validate:"required"`
+	Type        BetType     `json:"type"`
+	EventID     string      `json:"event_id,omitempty"`
+	MarketID    string      `json:"market_id,omitempty"`
+	SelectionID string      `json:"selection_id,omitempty"`
+	Legs        []BetLeg    `json:"legs,omitempty"`
+	Lines       []BetLine   `json:"lines,omitempty"`
+	Odds        money.Odds  `json:"odds" validate:"required,odds"`
+	Amount      money.Money `json:"amount" validate:"required,gt=0"`
+	// Currency is the currency of the stake and of everything the bet
+	// returns; it is never converted.
+	Currency money.Currency `json:"currency"`
+	// FreeBetID is the token that staked the bet, if any. A free bet's
+	// stake was never the user's and is not returned.
+	FreeBetID string `json:"free_bet_id,omitempty"`
+	// BoostID is the boost that priced the bet, if any. Odds are then the
+	// boosted price and OriginalOdds the price it boosted.
+	BoostID      string     `json:"boost_id,omitempty"`
+	OriginalOdds money.Odds `json:"original_odds,omitempty"`
+	Status       BetStatus  `json:"status"`
+	// CashedOutStake is the part of Amount closed by cash-outs, and
+	// CashedOut the total credited for it. CashOuts lists each one.
+	CashedOutStake money.Money `json:"cashed_out_stake,omitempty"`
+	CashedOut      money.Money `json:"cashed_out,omitempty"`
+	CashOuts       []CashOut   `json:"cash_outs,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	SettledAt      time.Time   `json:"settled_at,omitempty"`
+	// Version starts at 1 and grows with every change to the stored bet.
+	Version int64 `json:"version"`
+}
+
+// OpenStake returns the part of the stake still riding on the bet's
+// outcome: Amount less any stake already cashed out.
+func (b *Bet) OpenStake() money.Money {
+	return b.Amount - b.CashedOutStake
 }
 
+// IsFree reports whether the bet was staked with a free-bet token.
+func (b *Bet) IsFree() bool {
+	return b.FreeBetID != ""
+}
+
+// CashStake returns the part of the stake that came from the user's
+// wallet: all of it, unless a free-bet token paid for it.
+func (b *Bet) CashStake() money.Money {
+	if b.IsFree() {
+		return money.Zero
+	}
+	return b.Amount
+}
+
+// BetLeg is one selection of a multi-leg bet. Its Status moves from PLACED
+// to WON, LOST or VOID when its event is settled.
+type BetLeg struct {
+	EventID     string     `json:"event_id"`
+	MarketID    string     `json:"market_id,omitempty"`
+	SelectionID string     `json:"selection_id,omitempty"`
+	Odds        money.Odds `json:"odds"`
+	Status      BetStatus  `json:"status"`
+	SettledAt   time.Time  `json:"settled_at,omitempty"`
+}
+
+// Clone returns a copy of the bet that shares no legs, lines or cash-outs
+// with the original.
+func (b *Bet) Clone() *Bet {
+	c := *b
+	if b.Legs != nil {
+		c.Legs = make([]BetLeg, len(b.Legs))
+		copy(c.Legs, b.Legs)
+	}
+	if b.Lines != nil {
+		c.Lines = make([]BetLine, len(b.Lines))
+		copy(c.Lines, b.Lines)
+	}
+	if b.CashOuts != nil {
+		c.CashOuts = make([]CashOut, len(b.CashOuts))
+		copy(c.CashOuts, b.CashOuts)
+	}
+	return &c
+}
+
+// IsMultiple reports whether the bet is made of legs.
+func (b *Bet) IsMultiple() bool {
+	return len(b.Legs) > 0
+}
+
+// EventIDs returns the distinct events the bet depends on.
+func (b *Bet) EventIDs() []string {
+	if !b.IsMultiple() {
+		return []string{b.EventID}
+	}
+	ids := make([]string, 0, len(b.Legs))
+	seen := make(map[string]bool, len(b.Legs))
+	for _, leg := range b.Legs {
+		if !seen[leg.EventID] {
+			seen[leg.EventID] = true
+			ids = append(ids, leg.EventID)
+		}
+	}
+	return ids
+}
+
+// OpenLegs returns how many legs are still waiting for their event.
+func (b *Bet) OpenLegs() int {
+	open := 0
+	for _, leg := range b.Legs {
+		if leg.Status == StatusPlaced {
+			open++
+		}
+	}
+	return open
+}
+
+// AwaitsEvent reports whether the PLACED bet still waits for eventID's
+// result: it is a single bet on the event, or has an open leg on it.
+func (b *Bet) AwaitsEvent(eventID string) bool {
+	if b.Status != StatusPlaced {
+		return false
+	}
+	if !b.IsMultiple() {
+		return b.EventID == eventID
+	}
+	for _, leg := range b.Legs {
+		if leg.EventID == eventID && leg.Status == StatusPlaced {
+			return true
+		}
+	}
+	return false
+}
+
+// AwaitedEvents returns the events whose result the PLACED bet still waits
+// for.
+func (b *Bet) AwaitedEvents() []string {
+	var ids []string
+	for _, id := range b.EventIDs() {
+		if b.AwaitsEvent(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Unsettle takes back the result eventID gave the bet, reporting whether it
+// had one. A single bet on the event returns to PLACED. A multi-leg bet has
+// its legs on the event reopened, along with the lines of a system bet they
+// decided, and is resolved again from its other legs: it stays decided only
+// if they decide it. Cashed-out bets are never reopened.
+//
+// A reopened bet can be left waiting for other events, including ones
+// whose settlement skipped it because it was already decided. Repositories
+// refuse corrections that would leave it waiting for a SETTLED one.
+func (b *Bet) Unsettle(eventID string) bool {
+	if b.Status == StatusCashedOut {
+		return false
+	}
+	if !b.IsMultiple() {
+		if b.EventID != eventID || b.Status == StatusPlaced {
+			return false
+		}
+		b.Status = StatusPlaced
+		b.SettledAt = time.Time{}
+		return true
+	}
+
+	var reopened []int
+	for i := range b.Legs {
+		leg := &b.Legs[i]
+		if leg.EventID == eventID && leg.Status != StatusPlaced {
+			leg.Status = StatusPlaced
+			leg.SettledAt = time.Time{}
+			reopened = append(reopened, i)
+		}
+	}
+	if len(reopened) == 0 {
+		return false
+	}
+	for i := range b.Lines {
+		line := &b.Lines[i]
+		if slices.ContainsFunc(reopened, line.includes) && legStatus(b.lineLegs(line)) == StatusPlaced {
+			line.Status = StatusPlaced
+			line.SettledAt = time.Time{}
+		}
+	}
+	// Only lines left open by the reopened legs change, so a bet the other
+	// legs still decide keeps its status and settlement time.
+	b.Resolve(b.SettledAt)
+	if b.Status == StatusPlaced {
+		b.SettledAt = time.Time{}
+	}
+	return true
+}
+
+// Resolve sets a multi-leg bet's status from its legs. An accumulator
+// follows legStatus. A system bet first resolves each of its lines, marking
+// newly decided ones as settled at now, and stays PLACED until every line is
+// decided; it has then won if any line won.
+func (b *Bet) Resolve(now time.Time) {
+	if !b.HasLines() {
+		b.Status = legStatus(b.Legs)
+		return
+	}
+	b.Status = b.resolveLines(now)
+}
+
+// legStatus derives the status of a combination of legs: any losing leg
+// loses it, otherwise it stays PLACED until every leg is known. A
+// combination whose legs are all void is void.
+func legStatus(legs []BetLeg) BetStatus {
+	won, open := 0, 0
+	for _, leg := range legs {
+		switch leg.Status {
+		case StatusLost:
+			return StatusLost
+		case StatusWon:
+			won++
+		case StatusPlaced:
+			open++
+		}
+	}
+	switch {
+	case open > 0:
+		return StatusPlaced
+	case won == 0:
+		return StatusVoid
+	default:
+		return StatusWon
+	}
+}
+
+// EffectiveOdds returns the odds the bet pays at. For accumulators void
+// legs drop out of the product.
+func (b *Bet) EffectiveOdds() money.Odds {
+	if !b.IsMultiple() {
+		return b.Odds
+	}
+	return combinedOdds(b.Legs)
+}
+
+// combinedOdds multiplies the odds of the legs that are not void.
+func combinedOdds(legs []BetLeg) money.Odds {
+	odds := make([]money.Odds, 0, len(legs))
+	for _, leg := range legs {
+		if leg.Status != StatusVoid {
+			odds = append(odds, leg.Odds)
+		}
+	}
+	combined, err := money.CombineOdds(PayoutRounding, odds...)
+	if err != nil {
+		// CheckPayout keeps the odds of every bet placed within MaxOdds.
+		panic(fmt.Sprintf("combined odds of %d legs: %v", len(odds), err))
+	}
+	return combined
+}
+
+// CheckPayout reports why the bet cannot be placed, if it cannot: its odds
+// must be at most MaxOdds, and its stake and potential payout at most
+// MaxPayout. It checks the legs of a multi-leg bet before anything is
+// priced from them.
+func (b *Bet) CheckPayout() error {
+	odds := b.Odds
+	if b.IsMultiple() {
+		all := make([]money.Odds, len(b.Legs))
+		for i, leg := range b.Legs {
+			all[i] = leg.Odds
+		}
+		var err error
+		if odds, err = money.CombineOdds(PayoutRounding, all...); err != nil {
+			return fmt.Errorf("combined odds of the legs are over the maximum of %s", MaxOdds)
+		}
+	}
+	if odds > MaxOdds {
+		return fmt.Errorf("odds %s are over the maximum of %s", odds, MaxOdds)
+	}
+	if b.Amount > MaxPayout {
+		return fmt.Errorf("stake %s %s is over the maximum payout of %s", b.Amount, b.Currency, MaxPayout)
+	}
+	payout := b.Amount.MulOdds(odds, PayoutRounding)
+	if b.HasLines() {
+		payout = money.Zero
+		for i := range b.Lines {
+			payout += b.Lines[i].Amount.MulOdds(combinedOdds(b.lineLegs(&b.Lines[i])), PayoutRounding)
+		}
+	}
+	if payout > MaxPayout {
+		return fmt.Errorf("potential payout %s %s is over the maximum of %s", payout, b.Currency, MaxPayout)
+	}
+	return nil
+}
+
+// Payout returns the amount credited to the user if the bet wins (its open
+// stake included, unless a free-bet token paid it). For a system bet it is the total returned by its lines
+// as they currently stand.
+func (b *Bet) Payout() money.Money {
+	if b.HasLines() {
+		return b.linesReturn()
+	}
+	payout := b.OpenStake().MulOdds(b.EffectiveOdds(), PayoutRounding)
+	if b.IsFree() {
+		// Only the winnings are paid; the stake is not returned.
+		payout -= b.OpenStake()
+	}
+	return payout
+}
+
+// Subject describes what the bet is on, for ledger descriptions and logs.
+func (b *Bet) Subject() string {
+	if b.IsMultiple() {
+		return fmt.Sprintf("%s of %d legs", strings.ToLower(string(b.Type)), len(b.Legs))
+	}
+	return "event " + b.EventID
+}
+
+// PlaceBetRequest defines the payload for placing a bet. A bet either backs
+// a selection (selection_id, priced at the selection's current odds) or, for
+// events without markets, names the event and odds directly.
 type PlaceBetRequest struct {
-	UserID  string  `json:"user_id" validate:"required"`
-	EventID string  `json:"event_id" validate:"required"`
-	Odds    float64 `json:"odds" validate:"required,gt=1"`
-	Amount  float64 `json:"amount" validate:"required,gt=0"`
+	UserID      string      `json:"user_id" validate:"required"`
+	EventID     string      `json:"event_id" validate:"required_without=SelectionID"`
+	SelectionID string      `json:"selection_id"`
+	Odds        money.Odds  `json:"odds" validate:"required_without=SelectionID,omitempty,odds"`
+	Amount      money.Money `json:"amount" validate:"required_without=FreeBetID,omitempty,gt=0"`
+	// Currency defaults to the user's home currency.
+	Currency string `json:"currency"`
+	// FreeBetID redeems a free-bet token instead of debiting the wallet.
+	// Amount and Currency then default to the token's.
+	FreeBetID string `json:"free_bet_id"`
+	// BoostID prices the bet with a boost. Odds, if sent, are still the
+	// unboosted price.
+	BoostID string `json:"boost_id"`
 }
 
 func (p *PlaceBetRequest) Validate() error {
 	return validate.Struct(p)
 }
 
+// Leg returns the selection the single bet backs, in the form shared with
+// multi-leg requests.
+func (p *PlaceBetRequest) Leg() BetLegRequest {
+	return BetLegRequest{EventID: p.EventID, SelectionID: p.SelectionID, Odds: p.Odds}
+}
+
+// BetLegRequest names one selection of a bet, either by selection_id or by
+// event_id and odds, as for a single bet.
+type BetLegRequest struct {
+	EventID     string     `json:"event_id" validate:"required_without=SelectionID"`
+	SelectionID string     `json:"selection_id"`
+	Odds        money.Odds `json:"odds" validate:"required_without=SelectionID,omitempty,odds"`
+}
+
+// PlaceAccumulatorRequest defines the payload for an accumulator: one stake
+// on several legs, each on a different event, that must all win.
+type PlaceAccumulatorRequest struct {
+	UserID string          `json:"user_id" validate:"required"`
+	Legs   []BetLegRequest `json:"legs" validate:"required,min=2,max=20,dive"`
+	Amount money.Money     `json:"amount" validate:"required,gt=0"`
+	// Currency defaults to the user's home currency.
+	Currency string `json:"currency"`
+}
+
+func (p *PlaceAccumulatorRequest) Validate() error {
+	return validate.Struct(p)
+}
+
+// SettleBetRequest defines how an event is settled: either one result for
+// every bet on the event, or the winning selections of each market.
 type SettleBetRequest struct {
-	Result string `json:"result" validate:"required,oneof=win lose"`
+	Result  string         `json:"result" validate:"required_without=Markets,omitempty,oneof=win lose void"`
+	Markets []MarketResult `json:"markets" validate:"omitempty,dive"`
 }
 
 func (s *SettleBetRequest) Validate() error {
 	return validate.Struct(s)
-}
\ No newline at end of file
+}
@@ -0,0 +1,285 @@
+package model
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBetPageSize is the page size of a bet listing that sets no limit.
+// ListBetsRequest caps the limit at 200.
+const DefaultBetPageSize = 50
+
+// BetSort names the field a bet listing is ordered by. Bets with equal
+// values are ordered by ID, so every listing has a stable order.
+type BetSort string
+
+const (
+	SortByCreatedAt BetSort = "created_at"
+	// SortBySettledAt lists bets not settled yet before settled ones.
+	SortBySettledAt BetSort = "settled_at"
+	SortByAmount    BetSort = "amount"
+)
+
+// BetQuery filters, orders and pages a bet listing. Empty filters match
+// every bet; time and stake ranges are inclusive. A settlement time range
+// only matches settled bets.
+type BetQuery struct {
+	UserID  string
+	EventID string
+	// Statuses matches bets in any of the listed statuses.
+	Statuses    []BetStatus
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	SettledFrom time.Time
+	SettledTo   time.Time
+	MinStake    money.Money
+	MaxStake    money.Money
+	Sort        BetSort
+	Desc        bool
+	Limit       int
+	// After resumes the listing after the bet a cursor points at.
+	After *BetCursor
+}
+
+// BetCursor is the position of a bet in a listing ordered by Sort: its
+// sort key and ID.
+type BetCursor struct {
+	Sort BetSort
+	Key  int64
+	ID   string
+}
+
+// BetPage is one page of a bet listing. NextCursor is empty on the last page.
+type BetPage struct {
+	Bets       []*Bet `json:"bets"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// SortKey returns the value the query orders a bet by.
+func (q *BetQuery) SortKey(b *Bet) int64 {
+	switch q.Sort {
+	case SortBySettledAt:
+		if b.SettledAt.IsZero() {
+			return 0
+		}
+		return b.SettledAt.UnixNano()
+	case SortByAmount:
+		return b.Amount.Minor()
+	default:
+		return b.CreatedAt.UnixNano()
+	}
+}
+
+// Less reports whether bet a comes before bet b in the listing.
+func (q *BetQuery) Less(a, b *Bet) bool {
+	return q.precedes(q.SortKey(a), a.ID, q.SortKey(b), b.ID)
+}
+
+// precedes reports whether the bet at sort key ka with ID a comes strictly
+// before the one at kb with ID b.
+func (q *BetQuery) precedes(ka int64, a string, kb int64, b string) bool {
+	switch {
+	case ka != kb:
+		return (ka < kb) != q.Desc
+	case a != b:
+		return (a < b) != q.Desc
+	default:
+		return false
+	}
+}
+
+// Matches reports whether a bet passes every filter of the query and comes
+// after its cursor.
+func (q *BetQuery) Matches(b *Bet) bool {
+	if q.UserID != "" && b.UserID != q.UserID {
+		return false
+	}
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, b.Status) {
+		return false
+	}
+	if q.EventID != "" && !b.onEvent(q.EventID) {
+		return false
+	}
+	if !q.CreatedFrom.IsZero() && b.CreatedAt.Before(q.CreatedFrom) ||
+		!q.CreatedTo.IsZero() && b.CreatedAt.After(q.CreatedTo) {
+		return false
+	}
+	if !q.SettledFrom.IsZero() || !q.SettledTo.IsZero() {
+		if b.SettledAt.IsZero() ||
+			!q.SettledFrom.IsZero() && b.SettledAt.Before(q.SettledFrom) ||
+			!q.SettledTo.IsZero() && b.SettledAt.After(q.SettledTo) {
+			return false
+		}
+	}
+	if !q.MinStake.IsZero() && b.Amount < q.MinStake ||
+		!q.MaxStake.IsZero() && b.Amount > q.MaxStake {
+		return false
+	}
+	return q.After == nil || q.precedes(q.After.Key, q.After.ID, q.SortKey(b), b.ID)
+}
+
+func (b *Bet) onEvent(eventID string) bool {
+	for _, id := range b.EventIDs() {
+		if id == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// Page cuts a page from bets that match the query, in listing order, of
+// which the caller read up to Limit+1 to tell whether another page follows.
+func (q *BetQuery) Page(bets []*Bet) *BetPage {
+	if bets == nil {
+		bets = []*Bet{}
+	}
+	page := &BetPage{Bets: bets}
+	if len(bets) > q.Limit {
+		page.Bets = bets[:q.Limit]
+		last := page.Bets[q.Limit-1]
+		page.NextCursor = BetCursor{Sort: q.Sort, Key: q.SortKey(last), ID: last.ID}.String()
+	}
+	return page
+}
+
+// String encodes the cursor as an opaque token.
+func (c BetCursor) String() string {
+	raw := fmt.Sprintf("%s:%d:%s", c.Sort, c.Key, c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseBetCursor decodes a cursor token for a listing ordered by sort.
+func ParseBetCursor(token string, sort BetSort) (*BetCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	key, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	if BetSort(parts[0]) != sort {
+		return nil, fmt.Errorf("cursor belongs to a listing sorted by %s, not %s", parts[0], sort)
+	}
+	return &BetCursor{Sort: sort, Key: key, ID: parts[2]}, nil
+}
+
+// betStatuses lists every status a bet can have.
+var betStatuses = []BetStatus{StatusPlaced, StatusWon, StatusLost, StatusVoid, StatusCashedOut}
+
+// ListBetsRequest holds the query parameters of a bet listing. Status is a
+// comma-separated list of statuses, times are RFC 3339 and stakes decimal
+// amounts.
+type ListBetsRequest struct {
+	UserID      string `query:"user_id"`
+	EventID     string `query:"event_id"`
+	Status      string `query:"status"`
+	CreatedFrom string `query:"created_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedTo   string `query:"created_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	SettledFrom string `query:"settled_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	SettledTo   string `query:"settled_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	MinStake    string `query:"min_stake"`
+	MaxStake    string `query:"max_stake"`
+	Sort        string `query:"sort" validate:"omitempty,oneof=created_at settled_at amount"`
+	Order       string `query:"order" validate:"omitempty,oneof=asc desc"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=200"`
+	Cursor      string `query:"cursor"`
+}
+
+func (req *ListBetsRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// Query converts validated parameters into a BetQuery, applying the default
+// order (oldest first) and page size.
+func (req *ListBetsRequest) Query() (*BetQuery, error) {
+	q := &BetQuery{
+		UserID:  req.UserID,
+		EventID: req.EventID,
+		Sort:    BetSort(req.Sort),
+		Desc:    req.Order == "desc",
+		Limit:   req.Limit,
+	}
+	if req.Status != "" {
+		for _, raw := range strings.Split(req.Status, ",") {
+			status := BetStatus(strings.ToUpper(strings.TrimSpace(raw)))
+			if !slices.Contains(betStatuses, status) {
+				return nil, fmt.Errorf("unknown bet status '%s'", raw)
+			}
+			q.Statuses = append(q.Statuses, status)
+		}
+	}
+	if q.Sort == "" {
+		q.Sort = SortByCreatedAt
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultBetPageSize
+	}
+
+	times := []struct {
+		raw string
+		dst *time.Time
+	}{
+		{req.CreatedFrom, &q.CreatedFrom},
+		{req.CreatedTo, &q.CreatedTo},
+		{req.SettledFrom, &q.SettledFrom},
+		{req.SettledTo, &q.SettledTo},
+	}
+	for _, t := range times {
+		if t.raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, t.raw)
+		if err != nil {
+			return nil, err
+		}
+		*t.dst = parsed
+	}
+	stakes := []struct {
+		name, raw string
+		dst       *money.Money
+	}{
+		{"min_stake", req.MinStake, &q.MinStake},
+		{"max_stake", req.MaxStake, &q.MaxStake},
+	}
+	for _, s := range stakes {
+		if s.raw == "" {
+			continue
+		}
+		parsed, err := money.Parse(s.raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", s.name, err)
+		}
+		if !parsed.IsPositive() {
+			return nil, fmt.Errorf("%s must be positive", s.name)
+		}
+		*s.dst = parsed
+	}
+
+	if !q.CreatedFrom.IsZero() && !q.CreatedTo.IsZero() && q.CreatedFrom.After(q.CreatedTo) {
+		return nil, fmt.Errorf("created_from is after created_to")
+	}
+	if !q.SettledFrom.IsZero() && !q.SettledTo.IsZero() && q.SettledFrom.After(q.SettledTo) {
+		return nil, fmt.Errorf("settled_from is after settled_to")
+	}
+	if !q.MinStake.IsZero() && !q.MaxStake.IsZero() && q.MinStake > q.MaxStake {
+		return nil, fmt.Errorf("min_stake is greater than max_stake")
+	}
+	if req.Cursor != "" {
+		after, err := ParseBetCursor(req.Cursor, q.Sort)
+		if err != nil {
+			return nil, err
+		}
+		q.After = after
+	}
+	return q, nil
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"math"
+)
+
+// BetStats summarises a set of bets, typically a user's whole history.
+// Its money is in Currency.
+type BetStats struct {
+	Currency  money.Currency `json:"currency"`
+	Bets      int            `json:"bets"`
+	Placed    int            `json:"placed"`
+	Won       int            `json:"won"`
+	Lost      int            `json:"lost"`
+	Voided    int            `json:"voided"`
+	CashedOut int            `json:"cashed_out"`
+	// TotalStaked is the stake of every bet, open or closed, that came from
+	// the user's wallet; free bets stake nothing.
+	TotalStaked money.Money `json:"total_staked"`
+	// TotalReturned is everything the bets credited back: payouts, refunds
+	// and cash-out values.
+	TotalReturned money.Money `json:"total_returned"`
+	// NetPnL is TotalReturned less the stake that is no longer open. Open
+	// stakes count once they settle.
+	NetPnL money.Money `json:"net_pnl"`
+	// WinRate is the share of bets settled WON or LOST that won, rounded to
+	// four decimals; 0 until one is.
+	WinRate float64 `json:"win_rate"`
+	// OpenExposure is the stake still at risk on PLACED bets.
+	OpenExposure money.Money `json:"open_exposure"`
+	// BoostedBets counts the bets priced by a boost, and BoostCost is
+	// what the boosts added to their winnings. Both are already part of the
+	// figures above.
+	BoostedBets int         `json:"boosted_bets"`
+	BoostCost   money.Money `json:"boost_cost"`
+}
+
+// Returned is what a bet has credited back to its user so far: its cash-out
+// values, plus its payout or refund once settled. A system bet returns what
+// its lines did, even when it lost overall.
+func (b *Bet) Returned() money.Money {
+	returned := b.CashedOut
+	switch {
+	case b.Status == StatusPlaced || b.Status == StatusCashedOut:
+	case b.HasLines(), b.Status == StatusWon:
+		returned += b.Payout()
+	case b.Status == StatusVoid && !b.IsFree():
+		returned += b.OpenStake()
+	}
+	return returned
+}
+
+// SummarizeBets aggregates bets in a single currency into BetStats.
+func SummarizeBets(bets []*Bet) *BetStats {
+	s := &BetStats{}
+	for _, b := range bets {
+		s.Currency = b.Currency
+		s.Bets++
+		s.TotalStaked += b.CashStake()
+		s.TotalReturned += b.Returned()
+		if b.IsBoosted() {
+			s.BoostedBets++
+			s.BoostCost += b.BoostCost()
+		}
+		switch b.Status {
+		case StatusPlaced:
+			s.Placed++
+			if !b.IsFree() {
+				s.OpenExposure += b.OpenStake()
+			}
+		case StatusWon:
+			s.Won++
+		case StatusLost:
+			s.Lost++
+		case StatusVoid:
+			s.Voided++
+		case StatusCashedOut:
+			s.CashedOut++
+		}
+	}
+	s.NetPnL = s.TotalReturned - (s.TotalStaked - s.OpenExposure)
+	s.setWinRate()
+	return s
+}
+
+func (s *BetStats) setWinRate() {
+	if decided := s.Won + s.Lost; decided > 0 {
+		s.WinRate = math.Round(float64(s.Won)/float64(decided)*10000) / 10000
+	}
+}
+
+// SummarizeBetsByCurrency aggregates bets into one BetStats per currency.
+func SummarizeBetsByCurrency(bets []*Bet) map[money.Currency]*BetStats {
+	byCurrency := make(map[money.Currency][]*Bet)
+	for _, b := range bets {
+		byCurrency[b.Currency] = append(byCurrency[b.Currency], b)
+	}
+	stats := make(map[money.Currency]*BetStats, len(byCurrency))
+	for currency, group := range byCurrency {
+		stats[currency] = SummarizeBets(group)
+	}
+	return stats
+}
+
+// MergeBetStats adds per-currency stats into one BetStats in currency,
+// converting their money with fx.
+func MergeBetStats(byCurrency map[money.Currency]*BetStats, fx *money.FXTable, currency money.Currency) (*BetStats, error) {
+	merged := &BetStats{Currency: currency}
+	for from, s := range byCurrency {
+		merged.Bets += s.Bets
+		merged.Placed += s.Placed
+		merged.Won += s.Won
+		merged.Lost += s.Lost
+		merged.Voided += s.Voided
+		merged.CashedOut += s.CashedOut
+		merged.BoostedBets += s.BoostedBets
+		for _, m := range []struct{ dst, src *money.Money }{
+			{&merged.TotalStaked, &s.TotalStaked},
+			{&merged.TotalReturned, &s.TotalReturned},
+			{&merged.NetPnL, &s.NetPnL},
+			{&merged.OpenExposure, &s.OpenExposure},
+			{&merged.BoostCost, &s.BoostCost},
+		} {
+			converted, err := fx.Convert(*m.src, from, currency)
+			if err != nil {
+				return nil, err
+			}
+			*m.dst += converted
+		}
+	}
+	merged.setWinRate()
+	return merged, nil
+}
+
+// UserBetHistory is a page of a user's bets together with a summary of all
+// of them.
+type UserBetHistory struct {
+	UserID string `json:"user_id"`
+	// Summary covers every bet in the reporting currency; ByCurrency breaks
+	// it down by the currency the bets were placed in.
+	Summary    *BetStats                    `json:"summary"`
+	ByCurrency map[money.Currency]*BetStats `json:"by_currency"`
+	*BetPage
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+// legsAt returns n legs at odds, on different events.
+func legsAt(n int, odds string) []BetLeg {
+	legs := make([]BetLeg, n)
+	for i := range legs {
+		legs[i] = BetLeg{EventID: string(rune('a' + i)), Odds: money.MustParseOdds(odds), Status: StatusPlaced}
+	}
+	return legs
+}
+
+func TestCheckPayout(t *testing.T) {
+	tests := []struct {
+		name string
+		bet  *Bet
+		ok   bool
+	}{
+		{"single", &Bet{Odds: money.MustParseOdds("2.5"), Amount: money.MustParse("100.00")}, true},
+		{"long single", &Bet{Odds: MaxOdds + 1, Amount: money.MustParse("1.00")}, false},
+		{"large stake", &Bet{Odds: money.MustParseOdds("1.01"), Amount: MaxPayout + 1}, false},
+		{"large payout", &Bet{Odds: money.MustParseOdds("2"), Amount: MaxPayout/2 + 1}, false},
+		{"accumulator", &Bet{Type: BetTypeAccumulator, Legs: legsAt(5, "3"), Amount: money.MustParse("10.00")}, true},
+		// 50^12 overflows an int64 of ten-thousandths.
+		{"overflowing accumulator", &Bet{Type: BetTypeAccumulator, Legs: legsAt(12, "50"), Amount: money.MustParse("1.00")}, false},
+		{"long accumulator", &Bet{Type: BetTypeAccumulator, Legs: legsAt(5, "20"), Amount: money.MustParse("1.00")}, false},
+	}
+	for _, tc := range tests {
+		if err := tc.bet.CheckPayout(); (err == nil) != tc.ok {
+			t.Errorf("%s: CheckPayout() = %v, want ok = %t", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestCheckPayoutSystem(t *testing.T) {
+	bet := &Bet{Type: BetTypeSystem, Legs: legsAt(3, "2"), Amount: money.MustParse("30.00")}
+	lines, err := SystemLines("", 2, len(bet.Legs), bet.Amount)
+	if err != nil {
+		t.Fatalf("SystemLines: %v", err)
+	}
+	bet.Lines = lines
+	if err := bet.CheckPayout(); err != nil {
+		t.Fatalf("CheckPayout: %v", err)
+	}
+
+	// Three doubles at 4 return four times the stake.
+	bet.Amount = MaxPayout / 3
+	if bet.Lines, err = SystemLines("", 2, len(bet.Legs), bet.Amount); err != nil {
+		t.Fatalf("SystemLines: %v", err)
+	}
+	if err := bet.CheckPayout(); err == nil {
+		t.Fatal("CheckPayout of a system bet returning over MaxPayout should fail")
+	}
+}
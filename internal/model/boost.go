@@ -0,0 +1,168 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"time"
+)
+
+// BoostStatus is whether a boost can still be used. Boosts are ACTIVE
+// until they are CANCELLED; outside their window they simply do not apply.
+type BoostStatus string
+
+const (
+	BoostActive    BoostStatus = "ACTIVE"
+	BoostCancelled BoostStatus = "CANCELLED"
+)
+
+// Boost is a promotion that prices single bets on an event, or on one of
+// its selections, at boosted odds between StartsAt and EndsAt. It raises the
+// winnings part of the price, odds − 1, by IncreaseBps basis points, so 2.0
+// boosted by 2500 (25%) becomes 2.25. Boosted bets keep the price they
+// would otherwise have had as OriginalOdds.
+type Boost struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	EventID string `json:"event_id"`
+	// SelectionID limits the boost to one selection of the event.
+	SelectionID string `json:"selection_id,omitempty"`
+	IncreaseBps int64  `json:"increase_bps"`
+	// MaxStake is the largest stake a boosted bet may have, in Currency;
+	// stakes in other currencies are converted to compare.
+	MaxStake money.Money    `json:"max_stake"`
+	Currency money.Currency `json:"currency"`
+	// MaxUsesPerUser is how many boosted bets each user may place.
+	MaxUsesPerUser int         `json:"max_uses_per_user"`
+	Status         BoostStatus `json:"status"`
+	StartsAt       time.Time   `json:"starts_at"`
+	EndsAt         time.Time   `json:"ends_at"`
+	CreatedAt      time.Time   `json:"created_at"`
+	CancelledAt    time.Time   `json:"cancelled_at,omitempty"`
+}
+
+// Clone returns a copy of the boost.
+func (b *Boost) Clone() *Boost {
+	c := *b
+	return &c
+}
+
+// Running reports whether the boost is ACTIVE and within its window.
+func (b *Boost) Running(now time.Time) bool {
+	return b.Status == BoostActive && !now.Before(b.StartsAt) && now.Before(b.EndsAt)
+}
+
+// Price returns the boosted odds for a bet the boost applies to.
+func (b *Boost) Price(odds money.Odds) (money.Odds, error) {
+	boosted, err := money.BoostOdds(odds, b.IncreaseBps, PayoutRounding)
+	if err != nil {
+		return 0, fmt.Errorf("odds %s boosted by %d bps are out of range", odds, b.IncreaseBps)
+	}
+	return boosted, nil
+}
+
+// CheckApplies reports why the boost cannot price bet, if it cannot: it
+// covers single bets on its event, or its selection, while it is running.
+// The stake limit and usage limit are checked separately.
+func (b *Boost) CheckApplies(bet *Bet, now time.Time) error {
+	switch {
+	case b.Status != BoostActive:
+		return fmt.Errorf("boost %s is %s", b.ID, b.Status)
+	case now.Before(b.StartsAt):
+		return fmt.Errorf("boost %s starts at %s", b.ID, b.StartsAt.Format(time.RFC3339))
+	case !now.Before(b.EndsAt):
+		return fmt.Errorf("boost %s ended at %s", b.ID, b.EndsAt.Format(time.RFC3339))
+	case bet.IsMultiple():
+		return fmt.Errorf("boosts only apply to single bets")
+	case bet.EventID != b.EventID:
+		return fmt.Errorf("boost %s does not apply to event %s", b.ID, bet.EventID)
+	case b.SelectionID != "" && bet.SelectionID != b.SelectionID:
+		return fmt.Errorf("boost %s only applies to selection %s", b.ID, b.SelectionID)
+	}
+	return nil
+}
+
+// IsBoosted reports whether the bet was priced by a boost.
+func (b *Bet) IsBoosted() bool {
+	return b.BoostID != ""
+}
+
+// BoostCost is what the boost added to a winning bet's payout: the payout
+// less what the original odds would have paid. It is zero for other bets.
+func (b *Bet) BoostCost() money.Money {
+	if !b.IsBoosted() || b.Status != StatusWon {
+		return money.Zero
+	}
+	return b.Payout() - b.OpenStake().MulOdds(b.OriginalOdds, PayoutRounding)
+}
+
+// BoostTotals is what a boost's bets in one currency staked, returned and
+// cost the house in extra winnings.
+type BoostTotals struct {
+	Staked   money.Money `json:"staked"`
+	Returned money.Money `json:"returned"`
+	Cost     money.Money `json:"cost"`
+}
+
+// BoostReport summarises the bets placed with a boost. TotalCost converts
+// the cost in every currency to Currency.
+type BoostReport struct {
+	Boost     *Boost                          `json:"boost"`
+	Bets      int                             `json:"bets"`
+	Users     int                             `json:"users"`
+	Open      int                             `json:"open"`
+	Currency  money.Currency                  `json:"currency"`
+	TotalCost money.Money                     `json:"total_cost"`
+	Totals    map[money.Currency]*BoostTotals `json:"totals"`
+}
+
+// SummarizeBoost reports on the bets placed with boost, converting their
+// cost to currency with fx.
+func SummarizeBoost(boost *Boost, bets []*Bet, fx *money.FXTable, currency money.Currency) (*BoostReport, error) {
+	r := &BoostReport{Boost: boost, Currency: currency, Totals: make(map[money.Currency]*BoostTotals)}
+	users := make(map[string]bool)
+	for _, b := range bets {
+		r.Bets++
+		users[b.UserID] = true
+		if b.Status == StatusPlaced {
+			r.Open++
+		}
+		totals, ok := r.Totals[b.Currency]
+		if !ok {
+			totals = &BoostTotals{}
+			r.Totals[b.Currency] = totals
+		}
+		totals.Staked += b.Amount
+		totals.Returned += b.Returned()
+		totals.Cost += b.BoostCost()
+	}
+	r.Users = len(users)
+	for from, totals := range r.Totals {
+		cost, err := fx.Convert(totals.Cost, from, currency)
+		if err != nil {
+			return nil, err
+		}
+		r.TotalCost += cost
+	}
+	return r, nil
+}
+
+// CreateBoostRequest defines the payload for creating a boost.
+type CreateBoostRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	EventID     string `json:"event_id" validate:"required"`
+	SelectionID string `json:"selection_id"`
+	// IncreaseBps raises the winnings part of the odds, at most tenfold.
+	IncreaseBps int64       `json:"increase_bps" validate:"required,gt=0,max=100000"`
+	MaxStake    money.Money `json:"max_stake" validate:"required,gt=0"`
+	// Currency of MaxStake; defaults to the reporting currency.
+	Currency string `json:"currency"`
+	// MaxUsesPerUser defaults to 1.
+	MaxUsesPerUser int `json:"max_uses_per_user" validate:"omitempty,gt=0"`
+	// StartsAt defaults to now.
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at" validate:"required"`
+}
+
+func (req *CreateBoostRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"time"
+)
+
+// basisPoints is one whole in basis points, the unit of the cash-out margin.
+const basisPoints = 10000
+
+// CashOutQuote is the price offered to close some or all of the open stake
+// of a PLACED bet early, together with the selection odds it was computed
+// from. A quote is only honoured while those odds are still current.
+type CashOutQuote struct {
+	BetID    string        `json:"bet_id"`
+	Stake    money.Money   `json:"stake"`
+	Value    money.Money   `json:"value"`
+	Prices   []QuotedPrice `json:"prices"`
+	QuotedAt time.Time     `json:"quoted_at"`
+}
+
+// CashOut records one executed cash-out of part or all of a bet's stake.
+type CashOut struct {
+	Stake     money.Money `json:"stake"`
+	Value     money.Money `json:"value"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// QuotedPrice is the current odds of one open selection of a quoted bet.
+type QuotedPrice struct {
+	SelectionID string     `json:"selection_id"`
+	Odds        money.Odds `json:"odds"`
+}
+
+// CashOutRequest defines the payload for executing a cash-out: the value
+// of the quote the player accepted and, for a partial cash-out, the part of
+// the open stake it closes. Stake defaults to all of it.
+type CashOutRequest struct {
+	Stake money.Money `json:"stake" validate:"omitempty,gt=0"`
+	Value money.Money `json:"value" validate:"required,gt=0"`
+}
+
+func (req *CashOutRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// OpenSelections returns the selections a PLACED bet still depends on, in
+// leg order. Only bets whose open legs all back a selection can be priced
+// for cash-out.
+func (b *Bet) OpenSelections() ([]string, error) {
+	if b.Status != StatusPlaced {
+		return nil, fmt.Errorf("bet %s is %s, only PLACED bets can be cashed out", b.ID, b.Status)
+	}
+	if b.HasLines() {
+		return nil, fmt.Errorf("cash-out is not offered on system bets")
+	}
+	if b.IsFree() {
+		return nil, fmt.Errorf("cash-out is not offered on free bets")
+	}
+	if b.IsBoosted() {
+		return nil, fmt.Errorf("cash-out is not offered on boosted bets")
+	}
+	if !b.IsMultiple() {
+		if b.SelectionID == "" {
+			return nil, fmt.Errorf("bet %s has no selection to price", b.ID)
+		}
+		return []string{b.SelectionID}, nil
+	}
+	var ids []string
+	for _, leg := range b.Legs {
+		if leg.Status != StatusPlaced {
+			continue
+		}
+		if leg.SelectionID == "" {
+			return nil, fmt.Errorf("a leg of bet %s on event %s has no selection to price", b.ID, leg.EventID)
+		}
+		ids = append(ids, leg.SelectionID)
+	}
+	return ids, nil
+}
+
+// CheckCashOutEvents reports why the bet cannot be cashed out because of
+// the events it still awaits, if it cannot: a price is only offered while
+// every one of them takes bets (see Event.CheckBettable). getEvent's errors
+// are returned as they are.
+func (b *Bet) CheckCashOutEvents(getEvent func(eventID string) (*Event, error)) error {
+	for _, eventID := range b.AwaitedEvents() {
+		event, err := getEvent(eventID)
+		if err != nil {
+			return err
+		}
+		if err := event.CheckBettable(); err != nil {
+			return fmt.Errorf("cash-out of bet %s is not offered: %w", b.ID, err)
+		}
+	}
+	return nil
+}
+
+// CashOutValue prices stake of the bet at the current odds of its open
+// selections: the stake times the odds taken, divided by the current odds,
+// less a margin in basis points. Settled legs keep the odds they won at.
+func (b *Bet) CashOutValue(stake money.Money, prices []QuotedPrice, marginBps int64) money.Money {
+	current := make([]money.Odds, len(prices))
+	for i, p := range prices {
+		current[i] = p.Odds
+	}
+	now, err := money.CombineOdds(PayoutRounding, current...)
+	if err != nil || now.Raw() > math.MaxInt64/basisPoints {
+		// Odds this long leave nothing worth cashing out.
+		return money.Zero
+	}
+	taken := b.EffectiveOdds().Raw() * (basisPoints - marginBps)
+	return stake.MulRatio(taken, now.Raw()*basisPoints, PayoutRounding)
+}
+
+// CheckCashOutStake reports whether stake can be cashed out of the bet.
+func (b *Bet) CheckCashOutStake(stake money.Money) error {
+	if !stake.IsPositive() || stake > b.OpenStake() {
+		return fmt.Errorf("cash-out stake %s must be positive and at most the open stake %s", stake, b.OpenStake())
+	}
+	return nil
+}
+
+// ApplyCashOut records a checked quote on the bet. The quoted stake is
+// closed; the bet stays PLACED on the rest of its stake, or becomes
+// CASHED_OUT when none is left.
+func (b *Bet) ApplyCashOut(quote *CashOutQuote, now time.Time) {
+	b.CashOuts = append(b.CashOuts, CashOut{Stake: quote.Stake, Value: quote.Value, CreatedAt: now})
+	b.CashedOutStake += quote.Stake
+	b.CashedOut += quote.Value
+	if b.OpenStake().IsZero() {
+		b.Status = StatusCashedOut
+		b.SettledAt = now
+	}
+}
+
+// CheckQuote reports whether a quote still holds for the bet: the bet is
+// PLACED with at least the quoted stake open, every event it awaits still
+// takes bets according to getEvent, it depends on exactly the quoted
+// selections, and each one is still priced at the quoted odds according to
+// currentOdds.
+func CheckQuote(bet *Bet, quote *CashOutQuote, getEvent func(eventID string) (*Event, error), currentOdds func(selectionID string) (money.Odds, error)) error {
+	open, err := bet.OpenSelections()
+	if err != nil {
+		return err
+	}
+	if err := bet.CheckCashOutEvents(getEvent); err != nil {
+		return err
+	}
+	if err := bet.CheckCashOutStake(quote.Stake); err != nil {
+		return err
+	}
+	if len(open) != len(quote.Prices) {
+		return fmt.Errorf("bet %s has changed since it was quoted", bet.ID)
+	}
+	for i, p := range quote.Prices {
+		if open[i] != p.SelectionID {
+			return fmt.Errorf("bet %s has changed since it was quoted", bet.ID)
+		}
+		odds, err := currentOdds(p.SelectionID)
+		if err != nil {
+			return err
+		}
+		if odds != p.Odds {
+			return fmt.Errorf("odds for selection '%s' have changed: quoted %s, current %s", p.SelectionID, p.Odds, odds)
+		}
+	}
+	return nil
+}
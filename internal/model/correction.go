@@ -0,0 +1,117 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"maps"
+	"slices"
+	"time"
+)
+
+// CorrectionAction is what a settlement correction does to an event.
+type CorrectionAction string
+
+const (
+	// CorrectionResettle takes back an event's result and applies a
+	// corrected one.
+	CorrectionResettle CorrectionAction = "RESETTLE"
+	// CorrectionUnsettle takes back an event's result, leaving its bets
+	// PLACED until the event is settled again.
+	CorrectionUnsettle CorrectionAction = "UNSETTLE"
+)
+
+// SettlementCorrection is the audit record of a correction to an event's
+// settlement, stored in the same step as the correction itself.
+type SettlementCorrection struct {
+	ID       string           `json:"id"`
+	EventID  string           `json:"event_id"`
+	Action   CorrectionAction `json:"action"`
+	Operator string           `json:"operator"`
+	Reason   string           `json:"reason"`
+	// Result is the corrected result a resettlement applied.
+	Result *SettleBetRequest `json:"result,omitempty"`
+	// BetsReopened counts the bets whose result on the event was taken back.
+	BetsReopened int `json:"bets_reopened"`
+	// ClawedBack totals, per currency, what the reopened bets' settlement
+	// had credited and the correction took back from wallets. Currency and
+	// TotalClawedBack convert it to the reporting currency.
+	ClawedBack      map[money.Currency]money.Money `json:"clawed_back"`
+	Currency        money.Currency                 `json:"currency"`
+	TotalClawedBack money.Money                    `json:"total_clawed_back"`
+	// Settlement summarises the corrected result of a resettlement.
+	Settlement *SettlementSummary `json:"settlement,omitempty"`
+	// EventStatus is the event's status after the correction. It is empty
+	// for an unknown event.
+	EventStatus EventStatus `json:"event_status,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// Clone returns a copy of the correction that shares nothing with the
+// original.
+func (c *SettlementCorrection) Clone() *SettlementCorrection {
+	copied := *c
+	if c.Result != nil {
+		result := *c.Result
+		result.Markets = slices.Clone(c.Result.Markets)
+		for i := range result.Markets {
+			result.Markets[i].WinningSelectionIDs = slices.Clone(result.Markets[i].WinningSelectionIDs)
+		}
+		copied.Result = &result
+	}
+	if c.ClawedBack != nil {
+		copied.ClawedBack = maps.Clone(c.ClawedBack)
+	}
+	if c.Settlement != nil {
+		copied.Settlement = c.Settlement.Clone()
+	}
+	return &copied
+}
+
+// Reopen adds a reopened bet, and what was clawed back from it in its
+// currency, to the correction.
+func (c *SettlementCorrection) Reopen(bet *Bet, clawedBack money.Money) {
+	c.BetsReopened++
+	if c.ClawedBack == nil {
+		c.ClawedBack = make(map[money.Currency]money.Money)
+	}
+	c.ClawedBack[bet.Currency] += clawedBack
+}
+
+// Report converts the per-currency totals, and those of the settlement, to
+// currency.
+func (c *SettlementCorrection) Report(fx *money.FXTable, currency money.Currency) error {
+	c.Currency = currency
+	c.TotalClawedBack = money.Zero
+	for from, amount := range c.ClawedBack {
+		converted, err := fx.Convert(amount, from, currency)
+		if err != nil {
+			return err
+		}
+		c.TotalClawedBack += converted
+	}
+	if c.Settlement != nil {
+		return c.Settlement.Report(fx, currency)
+	}
+	return nil
+}
+
+// CorrectionRequest names who corrects an event's settlement and why; both
+// are kept in the audit record.
+type CorrectionRequest struct {
+	Operator string `json:"operator" validate:"required,max=64"`
+	Reason   string `json:"reason" validate:"required,max=255"`
+}
+
+func (req *CorrectionRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// ResettleRequest defines the payload for resettling an event: the
+// corrected result, given as for a settlement, and who corrects it and why.
+type ResettleRequest struct {
+	SettleBetRequest
+	CorrectionRequest
+}
+
+func (req *ResettleRequest) Validate() error {
+	return validate.Struct(req)
+}
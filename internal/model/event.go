@@ -0,0 +1,206 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EventStatus is where an event is in its lifecycle. Events are created
+// OPEN, the only status that takes bets. Trading can be SUSPENDED and
+// resumed while the event runs; once it ends the event is CLOSED, and it is
+// SETTLED when its result has decided every bet on it. An event that will
+// not finish is CANCELLED, voiding its open bets. Correcting a settled
+// event's result takes it back to CLOSED while its bets wait for the
+// corrected one.
+type EventStatus string
+
+const (
+	EventOpen      EventStatus = "OPEN"
+	EventSuspended EventStatus = "SUSPENDED"
+	EventClosed    EventStatus = "CLOSED"
+	EventSettled   EventStatus = "SETTLED"
+	EventCancelled EventStatus = "CANCELLED"
+)
+
+// eventTransitions holds the statuses each status can move to. CANCELLED
+// is final, and SETTLED is only left through a settlement correction.
+var eventTransitions = map[EventStatus][]EventStatus{
+	EventOpen:      {EventSuspended, EventClosed, EventCancelled},
+	EventSuspended: {EventOpen, EventClosed, EventCancelled},
+	EventClosed:    {EventSettled, EventCancelled},
+}
+
+// Event is a fixture that bets can be placed on, e.g. a football match.
+type Event struct {
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Status  EventStatus `json:"status"`
+	Markets []*Market   `json:"markets"`
+	// StatusChangedAt is when the event last changed status.
+	StatusChangedAt time.Time `json:"status_changed_at,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// CheckTransition reports why the event cannot move to status, if it
+// cannot.
+func (e *Event) CheckTransition(status EventStatus) error {
+	if !slices.Contains(eventTransitions[e.Status], status) {
+		return fmt.Errorf("event %s is %s and cannot become %s", e.ID, e.Status, status)
+	}
+	return nil
+}
+
+// CheckBettable reports why the event does not take bets, if it does not.
+func (e *Event) CheckBettable() error {
+	if e.Status != EventOpen {
+		return fmt.Errorf("event %s is %s and does not take bets", e.ID, e.Status)
+	}
+	return nil
+}
+
+// CheckSettleable reports why the event's bets cannot be settled with a
+// result, if they cannot: results are only taken once it is CLOSED. A
+// CANCELLED event only has its bets voided (see CheckVoidable).
+func (e *Event) CheckSettleable() error {
+	if e.Status != EventClosed {
+		return fmt.Errorf("event %s is %s; only CLOSED events can be settled", e.ID, e.Status)
+	}
+	return nil
+}
+
+// CheckVoidable reports why the event's bets cannot be voided for its
+// cancellation, if they cannot: only a CANCELLED event voids its bets.
+func (e *Event) CheckVoidable() error {
+	if e.Status != EventCancelled {
+		return fmt.Errorf("event %s is %s; only CANCELLED events have their bets voided", e.ID, e.Status)
+	}
+	return nil
+}
+
+// StatusAfterSettlement returns the status a settlement leaves the event
+// in: a CLOSED event becomes SETTLED once no bet awaits its result.
+func (e *Event) StatusAfterSettlement(awaiting bool) EventStatus {
+	if e.Status == EventClosed && !awaiting {
+		return EventSettled
+	}
+	return e.Status
+}
+
+// CheckCorrectable reports why the event's settlement cannot be corrected,
+// if it cannot: only results taken while it is CLOSED, or since it was
+// SETTLED, are corrected. A CANCELLED event's voided bets stay void.
+func (e *Event) CheckCorrectable() error {
+	if e.Status != EventClosed && e.Status != EventSettled {
+		return fmt.Errorf("event %s is %s; only CLOSED or SETTLED events can have their settlement corrected", e.ID, e.Status)
+	}
+	return nil
+}
+
+// Market is a question about an event with mutually exclusive answers,
+// e.g. "Match result".
+type Market struct {
+	ID         string       `json:"id"`
+	EventID    string       `json:"event_id"`
+	Name       string       `json:"name"`
+	Selections []*Selection `json:"selections"`
+}
+
+// Selection is one answer in a market that can be backed, e.g. "Home".
+// Odds holds the current price offered for it.
+type Selection struct {
+	ID       string     `json:"id"`
+	MarketID string     `json:"market_id"`
+	EventID  string     `json:"event_id"`
+	Name     string     `json:"name"`
+	Odds     money.Odds `json:"odds"`
+}
+
+// Clone returns a deep copy of the event, its markets and selections.
+func (e *Event) Clone() *Event {
+	c := *e
+	c.Markets = make([]*Market, len(e.Markets))
+	for i, m := range e.Markets {
+		c.Markets[i] = m.Clone()
+	}
+	return &c
+}
+
+// Clone returns a deep copy of the market and its selections.
+func (m *Market) Clone() *Market {
+	c := *m
+	c.Selections = make([]*Selection, len(m.Selections))
+	for i, sel := range m.Selections {
+		copied := *sel
+		c.Selections[i] = &copied
+	}
+	return &c
+}
+
+// Market returns the market with the given ID, or nil.
+func (e *Event) Market(marketID string) *Market {
+	for _, m := range e.Markets {
+		if m.ID == marketID {
+			return m
+		}
+	}
+	return nil
+}
+
+// Selection returns the selection with the given ID, or nil.
+func (m *Market) Selection(selectionID string) *Selection {
+	for _, sel := range m.Selections {
+		if sel.ID == selectionID {
+			return sel
+		}
+	}
+	return nil
+}
+
+// CreateEventRequest defines the payload for creating an event with its markets.
+type CreateEventRequest struct {
+	EventID string                `json:"event_id" validate:"required"`
+	Name    string                `json:"name" validate:"required"`
+	Markets []CreateMarketRequest `json:"markets" validate:"dive"`
+}
+
+func (req *CreateEventRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// CreateMarketRequest defines a market and its selections. IDs are
+// generated when omitted.
+type CreateMarketRequest struct {
+	MarketID   string                   `json:"market_id"`
+	Name       string                   `json:"name" validate:"required"`
+	Selections []CreateSelectionRequest `json:"selections" validate:"required,min=2,dive"`
+}
+
+func (req *CreateMarketRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// CreateSelectionRequest defines a selection and its opening price.
+type CreateSelectionRequest struct {
+	SelectionID string     `json:"selection_id"`
+	Name        string     `json:"name" validate:"required"`
+	Odds        money.Odds `json:"odds" validate:"required,odds"`
+}
+
+// UpdateOddsRequest defines the payload for repricing a selection.
+type UpdateOddsRequest struct {
+	Odds money.Odds `json:"odds" validate:"required,odds"`
+}
+
+func (req *UpdateOddsRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// MarketResult names the winning selections of one market. Every other
+// selection in the market loses. A void market refunds all its bets.
+type MarketResult struct {
+	MarketID            string   `json:"market_id" validate:"required"`
+	WinningSelectionIDs []string `json:"winning_selection_ids" validate:"required_without=Void"`
+	Void                bool     `json:"void"`
+}
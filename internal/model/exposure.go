@@ -0,0 +1,265 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"sort"
+)
+
+// ExposureEntry totals what the open bets backing one outcome of an event,
+// in one currency, have staked and stand to be paid. Repositories keep one
+// entry per outcome and currency and update it as bets are placed, cashed
+// out and settled (see ExposureChange). Bets placed on an event without a
+// selection back the event itself, with an empty MarketID and SelectionID.
+type ExposureEntry struct {
+	EventID     string
+	MarketID    string
+	SelectionID string
+	Currency    money.Currency
+	Bets        int
+	// Stake is the open stake the house holds, so free bets add none.
+	Stake money.Money
+	// Payout is what the bets pay if the outcome happens and every other
+	// open leg of theirs wins too.
+	Payout money.Money
+}
+
+// Exposure returns what a PLACED bet adds to the outcomes it backs: one
+// entry per open leg, or a single one for a single bet. A system bet adds
+// to each leg only the lines that include it.
+func (b *Bet) Exposure() []ExposureEntry {
+	if b.Status != StatusPlaced {
+		return nil
+	}
+	stake := b.OpenStake()
+	if b.IsFree() {
+		stake = money.Zero
+	}
+	if !b.IsMultiple() {
+		return []ExposureEntry{{EventID: b.EventID, MarketID: b.MarketID, SelectionID: b.SelectionID, Currency: b.Currency,
+			Bets: 1, Stake: stake, Payout: b.PotentialPayout()}}
+	}
+
+	var entries []ExposureEntry
+	for pos, leg := range b.Legs {
+		if leg.Status != StatusPlaced {
+			continue
+		}
+		e := ExposureEntry{EventID: leg.EventID, MarketID: leg.MarketID, SelectionID: leg.SelectionID, Currency: b.Currency, Bets: 1}
+		if !b.HasLines() {
+			e.Stake, e.Payout = stake, b.PotentialPayout()
+			entries = append(entries, e)
+			continue
+		}
+		for _, line := range b.Lines {
+			if line.Status == StatusPlaced && line.includes(pos) {
+				e.Stake += line.Amount
+				e.Payout += line.Amount.MulOdds(line.Odds, PayoutRounding)
+			}
+		}
+		if e.Payout.IsPositive() {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// includes reports whether the line combines the leg at pos.
+func (l *BetLine) includes(pos int) bool {
+	for _, p := range l.Legs {
+		if p == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// ExposureChange returns how a change to a bet, from before to after, moves
+// the exposure of each outcome, leaving out outcomes it does not move.
+// before is nil for a new bet.
+func ExposureChange(before, after *Bet) []ExposureEntry {
+	type key struct {
+		eventID, marketID, selectionID string
+		currency                       money.Currency
+	}
+	var (
+		order   []key
+		changes = make(map[key]*ExposureEntry)
+	)
+	add := func(e ExposureEntry, sign int) {
+		k := key{e.EventID, e.MarketID, e.SelectionID, e.Currency}
+		c, ok := changes[k]
+		if !ok {
+			c = &ExposureEntry{EventID: e.EventID, MarketID: e.MarketID, SelectionID: e.SelectionID, Currency: e.Currency}
+			changes[k] = c
+			order = append(order, k)
+		}
+		c.Bets += sign * e.Bets
+		c.Stake += money.Money(sign) * e.Stake
+		c.Payout += money.Money(sign) * e.Payout
+	}
+	if before != nil {
+		for _, e := range before.Exposure() {
+			add(e, -1)
+		}
+	}
+	if after != nil {
+		for _, e := range after.Exposure() {
+			add(e, 1)
+		}
+	}
+
+	var moved []ExposureEntry
+	for _, k := range order {
+		if c := changes[k]; c.Bets != 0 || !c.Stake.IsZero() || !c.Payout.IsZero() {
+			moved = append(moved, *c)
+		}
+	}
+	return moved
+}
+
+// OutcomeExposure is the exposure of one outcome. Liability is what the
+// house loses if it happens: the payout to its backers less every stake on
+// its market, which the house keeps from the losing outcomes. A negative
+// liability is a profit.
+type OutcomeExposure struct {
+	SelectionID     string      `json:"selection_id,omitempty"`
+	Bets            int         `json:"bets"`
+	Stake           money.Money `json:"stake"`
+	PotentialPayout money.Money `json:"potential_payout"`
+	Liability       money.Money `json:"liability"`
+}
+
+// MarketExposure is the exposure of one market: the stake on it and the
+// liability of each of its backed outcomes. WorstCaseLiability is the
+// highest of them, for the outcome named by WorstCaseSelectionID.
+type MarketExposure struct {
+	MarketID             string             `json:"market_id,omitempty"`
+	Bets                 int                `json:"bets"`
+	Stake                money.Money        `json:"stake"`
+	PotentialPayout      money.Money        `json:"potential_payout"`
+	WorstCaseLiability   money.Money        `json:"worst_case_liability"`
+	WorstCaseSelectionID string             `json:"worst_case_selection_id,omitempty"`
+	Outcomes             []*OutcomeExposure `json:"outcomes"`
+}
+
+// EventExposure is what the house stands to lose on an event from its
+// open bets, in Currency. Markets settle independently, so the event's
+// WorstCaseLiability is the sum of their worst cases. PotentialPayout is
+// what every backed outcome would pay together.
+type EventExposure struct {
+	EventID            string            `json:"event_id"`
+	Currency           money.Currency    `json:"currency"`
+	Bets               int               `json:"bets"`
+	Stake              money.Money       `json:"stake"`
+	PotentialPayout    money.Money       `json:"potential_payout"`
+	WorstCaseLiability money.Money       `json:"worst_case_liability"`
+	Markets            []*MarketExposure `json:"markets"`
+}
+
+// SummarizeExposure builds the exposure of an event from its entries,
+// converting every currency to currency with fx. Markets and outcomes are
+// ordered by ID.
+func SummarizeExposure(eventID string, entries []*ExposureEntry, fx *money.FXTable, currency money.Currency) (*EventExposure, error) {
+	ex := &EventExposure{EventID: eventID, Currency: currency, Markets: []*MarketExposure{}}
+	markets := make(map[string]*MarketExposure)
+	outcomes := make(map[[2]string]*OutcomeExposure)
+	for _, e := range entries {
+		stake, err := fx.Convert(e.Stake, e.Currency, currency)
+		if err != nil {
+			return nil, err
+		}
+		payout, err := fx.Convert(e.Payout, e.Currency, currency)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := markets[e.MarketID]
+		if !ok {
+			m = &MarketExposure{MarketID: e.MarketID}
+			markets[e.MarketID] = m
+			ex.Markets = append(ex.Markets, m)
+		}
+		o, ok := outcomes[[2]string{e.MarketID, e.SelectionID}]
+		if !ok {
+			o = &OutcomeExposure{SelectionID: e.SelectionID}
+			outcomes[[2]string{e.MarketID, e.SelectionID}] = o
+			m.Outcomes = append(m.Outcomes, o)
+		}
+		o.Bets += e.Bets
+		o.Stake += stake
+		o.PotentialPayout += payout
+		m.Bets += e.Bets
+		m.Stake += stake
+		m.PotentialPayout += payout
+	}
+
+	sort.Slice(ex.Markets, func(i, j int) bool { return ex.Markets[i].MarketID < ex.Markets[j].MarketID })
+	for _, m := range ex.Markets {
+		sort.Slice(m.Outcomes, func(i, j int) bool { return m.Outcomes[i].SelectionID < m.Outcomes[j].SelectionID })
+		for i, o := range m.Outcomes {
+			o.Liability = o.PotentialPayout - m.Stake
+			if i == 0 || o.Liability > m.WorstCaseLiability {
+				m.WorstCaseLiability = o.Liability
+				m.WorstCaseSelectionID = o.SelectionID
+			}
+		}
+		ex.Bets += m.Bets
+		ex.Stake += m.Stake
+		ex.PotentialPayout += m.PotentialPayout
+		ex.WorstCaseLiability += m.WorstCaseLiability
+	}
+	return ex, nil
+}
+
+// DefaultTopExposure is how many events the riskiest-events view lists when
+// no limit is given. TopExposureRequest caps the limit at 100.
+const DefaultTopExposure = 10
+
+// TopExposureRequest defines the query parameters of the riskiest-events view.
+type TopExposureRequest struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+func (req *TopExposureRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// ExposureRanking lists the events the house stands to lose most on, by
+// worst-case liability in Currency, highest first.
+type ExposureRanking struct {
+	Currency money.Currency   `json:"currency"`
+	Events   []*EventExposure `json:"events"`
+}
+
+// RankExposure summarizes the exposure of every event with entries and
+// keeps the limit with the highest worst-case liability. Ties are ordered
+// by event ID.
+func RankExposure(entries []*ExposureEntry, limit int, fx *money.FXTable, currency money.Currency) (*ExposureRanking, error) {
+	var eventIDs []string
+	byEvent := make(map[string][]*ExposureEntry)
+	for _, e := range entries {
+		if _, seen := byEvent[e.EventID]; !seen {
+			eventIDs = append(eventIDs, e.EventID)
+		}
+		byEvent[e.EventID] = append(byEvent[e.EventID], e)
+	}
+
+	r := &ExposureRanking{Currency: currency, Events: make([]*EventExposure, 0, len(eventIDs))}
+	for _, eventID := range eventIDs {
+		ex, err := SummarizeExposure(eventID, byEvent[eventID], fx, currency)
+		if err != nil {
+			return nil, err
+		}
+		r.Events = append(r.Events, ex)
+	}
+	sort.Slice(r.Events, func(i, j int) bool {
+		a, b := r.Events[i], r.Events[j]
+		if a.WorstCaseLiability != b.WorstCaseLiability {
+			return a.WorstCaseLiability > b.WorstCaseLiability
+		}
+		return a.EventID < b.EventID
+	})
+	if len(r.Events) > limit {
+		r.Events = r.Events[:limit]
+	}
+	return r, nil
+}
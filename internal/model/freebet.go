@@ -0,0 +1,115 @@
+package model
+
+import (
+	"fmt"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"time"
+)
+
+// FreeBetStatus is where a free-bet token is in its lifecycle. Tokens are
+// granted ACTIVE and end USED by a bet, REVOKED, or EXPIRED.
+type FreeBetStatus string
+
+const (
+	FreeBetActive  FreeBetStatus = "ACTIVE"
+	FreeBetUsed    FreeBetStatus = "USED"
+	FreeBetRevoked FreeBetStatus = "REVOKED"
+	FreeBetExpired FreeBetStatus = "EXPIRED"
+)
+
+// FreeBet is a token granted to a user that stakes a single bet of Value
+// in Currency without touching their wallet. The stake is not returned: a
+// winning free bet pays only its winnings, stake × (odds − 1), and a lost
+// or void one pays nothing.
+type FreeBet struct {
+	ID       string         `json:"id"`
+	UserID   string         `json:"user_id"`
+	Value    money.Money    `json:"value"`
+	Currency money.Currency `json:"currency"`
+	// EventIDs limits the token to bets on these events; empty allows any.
+	EventIDs []string `json:"event_ids,omitempty"`
+	// MinOdds is the lowest price the token may be staked at; zero allows any.
+	MinOdds   money.Odds    `json:"min_odds,omitempty"`
+	Status    FreeBetStatus `json:"status"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	// BetID is the bet a USED token staked.
+	BetID string `json:"bet_id,omitempty"`
+	// Reason explains a revoked token.
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ClosedAt is when the token was used, revoked or expired.
+	ClosedAt time.Time `json:"closed_at,omitempty"`
+}
+
+// Clone returns a copy of the token that shares no memory with it.
+func (f *FreeBet) Clone() *FreeBet {
+	c := *f
+	if f.EventIDs != nil {
+		c.EventIDs = make([]string, len(f.EventIDs))
+		copy(c.EventIDs, f.EventIDs)
+	}
+	return &c
+}
+
+// Lapsed reports whether an ACTIVE token has passed its expiry.
+func (f *FreeBet) Lapsed(now time.Time) bool {
+	return f.Status == FreeBetActive && !now.Before(f.ExpiresAt)
+}
+
+// Refresh reports a lapsed token as EXPIRED. Repositories only store the
+// change when expired tokens are swept.
+func (f *FreeBet) Refresh(now time.Time) {
+	if f.Lapsed(now) {
+		f.Status = FreeBetExpired
+		f.ClosedAt = f.ExpiresAt
+	}
+}
+
+// CheckEligible reports why the token cannot stake bet, if it cannot. A
+// token stakes a single bet of exactly its value, in its currency, on an
+// eligible event and at eligible odds. Its status and expiry are checked
+// separately.
+func (f *FreeBet) CheckEligible(bet *Bet) error {
+	switch {
+	case bet.IsMultiple():
+		return fmt.Errorf("free bets can only stake single bets")
+	case bet.Currency != f.Currency:
+		return fmt.Errorf("free bet %s is in %s, not %s", f.ID, f.Currency, bet.Currency)
+	case bet.Amount != f.Value:
+		return fmt.Errorf("free bet %s stakes exactly %s, not %s", f.ID, f.Value, bet.Amount)
+	case bet.Odds < f.MinOdds:
+		return fmt.Errorf("free bet %s needs odds of at least %s, got %s", f.ID, f.MinOdds, bet.Odds)
+	}
+	if len(f.EventIDs) > 0 {
+		for _, id := range f.EventIDs {
+			if id == bet.EventID {
+				return nil
+			}
+		}
+		return fmt.Errorf("free bet %s cannot be used on event %s", f.ID, bet.EventID)
+	}
+	return nil
+}
+
+// GrantFreeBetRequest defines the payload for granting a free-bet token.
+type GrantFreeBetRequest struct {
+	Value money.Money `json:"value" validate:"required,gt=0"`
+	// Currency defaults to the user's home currency.
+	Currency  string     `json:"currency"`
+	ExpiresAt time.Time  `json:"expires_at" validate:"required"`
+	EventIDs  []string   `json:"event_ids" validate:"omitempty,dive,required"`
+	MinOdds   money.Odds `json:"min_odds" validate:"omitempty,odds"`
+}
+
+func (req *GrantFreeBetRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// RevokeFreeBetRequest defines the optional payload for revoking a token.
+type RevokeFreeBetRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+func (req *RevokeFreeBetRequest) Validate() error {
+	return validate.Struct(req)
+}
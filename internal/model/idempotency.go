@@ -0,0 +1,44 @@
+package model
+
+import (
+	"maps"
+	"time"
+)
+
+// IdempotencyRecord remembers a request made with an Idempotency-Key and,
+// once it has been handled, the response to replay for retries of it.
+type IdempotencyRecord struct {
+	Key string
+	// Fingerprint identifies the request (method, path, query and body) the key
+	// was first used with.
+	Fingerprint string
+	// StatusCode, Headers and Body hold the response; StatusCode is 0 while
+	// the request is still being handled.
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+	// CreatedAt identifies the claim on the key. ExpiresAt is when the key
+	// is forgotten: a short lease after CreatedAt while the request is
+	// handled, so a claim left behind by a crash soon lapses, and the TTL
+	// once the response is stored.
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the response of the request has been stored.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Expired reports whether the record has outlived its lease or TTL at now.
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// Clone returns a copy of the record that shares no memory with it.
+func (r *IdempotencyRecord) Clone() *IdempotencyRecord {
+	c := *r
+	c.Headers = maps.Clone(r.Headers)
+	c.Body = append([]byte(nil), r.Body...)
+	return &c
+}
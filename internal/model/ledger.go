@@ -0,0 +1,77 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"time"
+)
+
+// EntryType describes why money moved between ledger accounts.
+type EntryType string
+
+const (
+	EntryStake      EntryType = "STAKE"
+	EntryPayout     EntryType = "PAYOUT"
+	EntryRefund     EntryType = "REFUND"
+	EntryAdjustment EntryType = "ADJUSTMENT"
+	EntryCashOut    EntryType = "CASHOUT"
+	EntryDeposit    EntryType = "DEPOSIT"
+	// EntryWithdrawal reserves a requested withdrawal out of the wallet;
+	// EntryWithdrawalPaid pays it out once approved and
+	// EntryWithdrawalReversal returns it to the wallet if rejected.
+	EntryWithdrawal         EntryType = "WITHDRAWAL"
+	EntryWithdrawalPaid     EntryType = "WITHDRAWAL_PAID"
+	EntryWithdrawalReversal EntryType = "WITHDRAWAL_REVERSAL"
+	// EntryFreeBetStake stakes a free bet out of the house's promotions
+	// budget rather than the user's wallet.
+	EntryFreeBetStake EntryType = "FREE_BET_STAKE"
+	// EntryClawback takes back what a bet's settlement credited when the
+	// settlement is corrected.
+	EntryClawback EntryType = "CLAWBACK"
+)
+
+// Posting is one leg of a journal entry. A positive amount increases the
+// account's balance, a negative amount decreases it.
+type Posting struct {
+	Account string      `json:"account"`
+	Amount  money.Money `json:"amount"`
+}
+
+// JournalEntry is an immutable, balanced record of a movement of money.
+// The amounts of its postings always sum to zero.
+type JournalEntry struct {
+	ID          string    `json:"id"`
+	Type        EntryType `json:"type"`
+	UserID      string    `json:"user_id"`
+	BetID       string    `json:"bet_id,omitempty"`
+	Description string    `json:"description,omitempty"`
+	// Currency is the currency of every posting; an entry never mixes them.
+	Currency  money.Currency `json:"currency"`
+	Postings  []Posting      `json:"postings"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Transaction is a journal entry seen from a single user's wallet.
+type Transaction struct {
+	EntryID     string         `json:"entry_id"`
+	Type        EntryType      `json:"type"`
+	BetID       string         `json:"bet_id,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Currency    money.Currency `json:"currency"`
+	Amount      money.Money    `json:"amount"`
+	// BalanceAfter is the balance of the wallet in Currency.
+	BalanceAfter money.Money `json:"balance_after"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// AdjustBalanceRequest defines the payload for a manual balance adjustment.
+// A positive amount credits the user, a negative amount debits them.
+type AdjustBalanceRequest struct {
+	Amount money.Money `json:"amount" validate:"required"`
+	Reason string      `json:"reason" validate:"required"`
+	// Currency selects the wallet; it defaults to the user's home currency.
+	Currency string `json:"currency"`
+}
+
+func (req *AdjustBalanceRequest) Validate() error {
+	return validate.Struct(req)
+}
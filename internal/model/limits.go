@@ -0,0 +1,301 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"time"
+)
+
+// LimitScope is what a set of limits applies to: every bet, the bets on
+// one event, or the bets of one user.
+type LimitScope string
+
+const (
+	LimitScopeGlobal LimitScope = "global"
+	LimitScopeEvent  LimitScope = "event"
+	LimitScopeUser   LimitScope = "user"
+)
+
+// Limits caps what bets may stake and risk, in Currency. A zero limit is
+// unset. Liability is what the house stands to lose if bets win (see
+// Bet.Liability). MaxEventLiability caps the liability of the open bets on
+// an event and cannot be set per user; MaxDailyLiability caps the liability
+// of the bets a user places in one UTC day and cannot be set per event.
+type Limits struct {
+	Scope LimitScope `json:"scope"`
+	// ScopeID is the event or user ID; empty for global limits.
+	ScopeID           string         `json:"scope_id,omitempty"`
+	MinStake          money.Money    `json:"min_stake"`
+	MaxStake          money.Money    `json:"max_stake"`
+	MaxPayout         money.Money    `json:"max_payout"`
+	MaxEventLiability money.Money    `json:"max_event_liability"`
+	MaxDailyLiability money.Money    `json:"max_daily_liability"`
+	Currency          money.Currency `json:"currency"`
+	UpdatedAt         time.Time      `json:"updated_at,omitempty"`
+}
+
+// Clone returns a copy of the limits.
+func (l *Limits) Clone() *Limits {
+	c := *l
+	return &c
+}
+
+// amounts returns pointers to every limit, so they can be changed together.
+func (l *Limits) amounts() []*money.Money {
+	return []*money.Money{&l.MinStake, &l.MaxStake, &l.MaxPayout, &l.MaxEventLiability, &l.MaxDailyLiability}
+}
+
+// In returns the limits converted to currency with fx.
+func (l *Limits) In(currency money.Currency, fx *money.FXTable) (*Limits, error) {
+	c := l.Clone()
+	for _, m := range c.amounts() {
+		converted, err := fx.Convert(*m, l.Currency, currency)
+		if err != nil {
+			return nil, err
+		}
+		*m = converted
+	}
+	c.Currency = currency
+	return c, nil
+}
+
+// ResolveLimits combines the limit sets that apply to a bet into one, in
+// currency. A limit set for the bet's events or user replaces the global
+// one; where several of them set it, the tightest applies: the lowest
+// maximum and the highest minimum. global and any of specific may be nil.
+func ResolveLimits(global *Limits, specific []*Limits, fx *money.FXTable, currency money.Currency) (*Limits, error) {
+	resolved := &Limits{Currency: currency}
+	if global != nil {
+		converted, err := global.In(currency, fx)
+		if err != nil {
+			return nil, err
+		}
+		resolved = converted
+	}
+	var narrowed Limits
+	for _, l := range specific {
+		if l == nil {
+			continue
+		}
+		converted, err := l.In(currency, fx)
+		if err != nil {
+			return nil, err
+		}
+		tightest(narrowed.amounts(), converted.amounts())
+	}
+	into := resolved.amounts()
+	for i, m := range narrowed.amounts() {
+		if m.IsPositive() {
+			*into[i] = *m
+		}
+	}
+	return resolved, nil
+}
+
+// tightest narrows each limit in into by the same limit in from. The first
+// limit is the minimum stake; the rest are maxima.
+func tightest(into, from []*money.Money) {
+	for i, m := range from {
+		switch {
+		case !m.IsPositive():
+		case !into[i].IsPositive(), i == 0 && *m > *into[i], i > 0 && *m < *into[i]:
+			*into[i] = *m
+		}
+	}
+}
+
+// PotentialPayout returns what the bet pays if every open leg, or every
+// line of a system bet, wins (its open stake included, unless a free-bet
+// token paid it).
+func (b *Bet) PotentialPayout() money.Money {
+	if b.IsFree() {
+		return b.Liability()
+	}
+	return b.Liability() + b.OpenStake()
+}
+
+// Liability returns what the house stands to lose if the bet wins in full:
+// the winnings on its open stake, stake × (odds − 1). For a free bet that
+// is everything it would pay.
+func (b *Bet) Liability() money.Money {
+	if b.HasLines() {
+		var total money.Money
+		for _, line := range b.Lines {
+			total += line.Amount.MulOdds(line.Odds, PayoutRounding) - line.Amount
+		}
+		return total
+	}
+	stake := b.OpenStake()
+	return stake.MulOdds(b.EffectiveOdds(), PayoutRounding) - stake
+}
+
+// LimitDay returns the start of the UTC day t falls in, from which daily
+// liability is counted.
+func LimitDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// PlacementLiability is the liability a new bet adds to, per currency, as
+// it stands when the bet is placed.
+type PlacementLiability struct {
+	// Events holds the liability of the PLACED bets on each of the bet's events.
+	Events map[string]map[money.Currency]money.Money
+	// Daily is the liability of the bets the user placed since LimitDay.
+	Daily map[money.Currency]money.Money
+}
+
+// MeasureLiability totals the liability of the open bets on each event and
+// of a user's bets placed today.
+func MeasureLiability(eventBets map[string][]*Bet, dayBets []*Bet) *PlacementLiability {
+	p := &PlacementLiability{Events: make(map[string]map[money.Currency]money.Money, len(eventBets)), Daily: make(map[money.Currency]money.Money)}
+	for eventID, bets := range eventBets {
+		totals := make(map[money.Currency]money.Money)
+		for _, b := range bets {
+			totals[b.Currency] += b.Liability()
+		}
+		p.Events[eventID] = totals
+	}
+	for _, b := range dayBets {
+		p.Daily[b.Currency] += b.Liability()
+	}
+	return p
+}
+
+// PlacementCheck vets a bet against the liability it adds to. Repositories
+// call it under the lock or transaction that places the bet, after its
+// currency is defaulted; an error places nothing and is returned as is.
+type PlacementCheck func(bet *Bet, liability *PlacementLiability) error
+
+// LimitCode names the limit a bet breaks.
+type LimitCode string
+
+const (
+	LimitMinStake       LimitCode = "STAKE_BELOW_MINIMUM"
+	LimitMaxStake       LimitCode = "MAX_STAKE_EXCEEDED"
+	LimitMaxPayout      LimitCode = "MAX_PAYOUT_EXCEEDED"
+	LimitEventLiability LimitCode = "EVENT_LIABILITY_EXCEEDED"
+	LimitDailyLiability LimitCode = "DAILY_LIABILITY_EXCEEDED"
+)
+
+// LimitBreach is the first limit a bet breaks. MinStake and MaxStake are
+// the smallest and largest stakes the limits would accept for the same bet;
+// MaxStake is zero when they accept none or set no maximum.
+type LimitBreach struct {
+	Code     LimitCode
+	Message  string
+	MinStake money.Money
+	MaxStake money.Money
+}
+
+func (b *LimitBreach) Error() string {
+	return b.Message
+}
+
+// CheckLimits reports the first limit bet breaks, if any, as a
+// *LimitBreach. l must be in the bet's currency; the liability it adds to
+// is converted to it with fx. Payout and liability grow with the stake, so
+// the largest stake accepted scales them down to the tightest maximum.
+func CheckLimits(l *Limits, bet *Bet, liability *PlacementLiability, fx *money.FXTable) error {
+	var (
+		stake  = bet.Amount
+		payout = bet.PotentialPayout()
+		risk   = bet.Liability()
+		breach *LimitBreach
+		// allowed is the largest stake every maximum accepts; -1 while
+		// none is set.
+		allowed = money.Money(-1)
+	)
+	fail := func(code LimitCode, format string, args ...any) {
+		if breach == nil {
+			breach = &LimitBreach{Code: code, Message: fmt.Sprintf(format, args...)}
+		}
+	}
+	// capAt lowers allowed to the stake at which per, the bet's payout or
+	// liability, would use up headroom.
+	capAt := func(headroom, per money.Money) {
+		max := money.Zero
+		if headroom.IsPositive() {
+			if !per.IsPositive() {
+				return
+			}
+			max = headroom.MulRatio(stake.Minor(), per.Minor(), money.RoundDown)
+		}
+		if allowed < 0 || max < allowed {
+			allowed = max
+		}
+	}
+	used := func(totals map[money.Currency]money.Money) (money.Money, error) {
+		var sum money.Money
+		for currency, m := range totals {
+			converted, err := fx.Convert(m, currency, bet.Currency)
+			if err != nil {
+				return money.Zero, err
+			}
+			sum += converted
+		}
+		return sum, nil
+	}
+
+	if l.MinStake.IsPositive() && stake < l.MinStake {
+		fail(LimitMinStake, "stake %s %s is below the minimum of %s", stake, bet.Currency, l.MinStake)
+	}
+	if l.MaxStake.IsPositive() {
+		capAt(l.MaxStake, stake)
+		if stake > l.MaxStake {
+			fail(LimitMaxStake, "stake %s %s is over the maximum of %s", stake, bet.Currency, l.MaxStake)
+		}
+	}
+	if l.MaxPayout.IsPositive() {
+		capAt(l.MaxPayout, payout)
+		if payout > l.MaxPayout {
+			fail(LimitMaxPayout, "potential payout %s %s is over the maximum of %s", payout, bet.Currency, l.MaxPayout)
+		}
+	}
+	if l.MaxEventLiability.IsPositive() {
+		for _, eventID := range bet.EventIDs() {
+			open, err := used(liability.Events[eventID])
+			if err != nil {
+				return err
+			}
+			capAt(l.MaxEventLiability-open, risk)
+			if open+risk > l.MaxEventLiability {
+				fail(LimitEventLiability, "liability on event %s would reach %s %s, over the maximum of %s", eventID, open+risk, bet.Currency, l.MaxEventLiability)
+			}
+		}
+	}
+	if l.MaxDailyLiability.IsPositive() {
+		today, err := used(liability.Daily)
+		if err != nil {
+			return err
+		}
+		capAt(l.MaxDailyLiability-today, risk)
+		if today+risk > l.MaxDailyLiability {
+			fail(LimitDailyLiability, "liability of today's bets would reach %s %s, over the daily maximum of %s", today+risk, bet.Currency, l.MaxDailyLiability)
+		}
+	}
+
+	if breach == nil {
+		return nil
+	}
+	breach.MinStake = l.MinStake
+	if allowed > 0 && allowed >= l.MinStake {
+		breach.MaxStake = allowed
+	}
+	return breach
+}
+
+// SetLimitsRequest defines the payload for setting the limits of a scope.
+// It replaces every limit of the scope; zero leaves one unset.
+type SetLimitsRequest struct {
+	MinStake          money.Money `json:"min_stake" validate:"gte=0"`
+	MaxStake          money.Money `json:"max_stake" validate:"gte=0"`
+	MaxPayout         money.Money `json:"max_payout" validate:"gte=0"`
+	MaxEventLiability money.Money `json:"max_event_liability" validate:"gte=0"`
+	MaxDailyLiability money.Money `json:"max_daily_liability" validate:"gte=0"`
+	// Currency of the limits; defaults to the reporting currency.
+	Currency string `json:"currency"`
+}
+
+func (req *SetLimitsRequest) Validate() error {
+	return validate.Struct(req)
+}
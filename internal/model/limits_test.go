@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimitDay(t *testing.T) {
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	east := time.FixedZone("UTC+2", 2*60*60)
+	west := time.FixedZone("UTC-2", -2*60*60)
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"midnight starts the day", day, day},
+		{"last instant of the day before", day.Add(-time.Nanosecond), day.AddDate(0, 0, -1)},
+		{"noon", day.Add(12 * time.Hour), day},
+		{"last instant of the day", day.Add(24*time.Hour - time.Nanosecond), day},
+		// Days are UTC days, whatever zone the time is in.
+		{"after local midnight, before UTC midnight", time.Date(2026, 10, 17, 1, 30, 0, 0, east), day.AddDate(0, 0, -1)},
+		{"before local midnight, after UTC midnight", time.Date(2026, 10, 16, 23, 30, 0, 0, west), day},
+	}
+	for _, tc := range tests {
+		got := LimitDay(tc.t)
+		if !got.Equal(tc.want) || got.Location() != time.UTC {
+			t.Errorf("%s: LimitDay(%s) = %s, want %s", tc.name, tc.t, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"cmp"
+	"slices"
+)
+
+// BetSettler decides the outcome of a single PLACED bet during event
+// settlement. It receives a copy of the bet and sets its Status; returning
+// an error aborts the whole settlement and nothing is applied.
+type BetSettler func(bet *Bet) error
+
+// SettlementSummary reports what an event settlement changed. Money is
+// totalled per currency in Totals; TotalPayout and TotalRefunded convert
+// those totals to Currency, the reporting currency.
+type SettlementSummary struct {
+	EventID     string `json:"event_id"`
+	BetsSettled int    `json:"bets_settled"`
+	Won         int    `json:"won"`
+	Lost        int    `json:"lost"`
+	Voided      int    `json:"voided"`
+	// Pending counts multi-leg bets that had a leg settled but still wait
+	// for other events.
+	Pending     int            `json:"pending"`
+	Currency    money.Currency `json:"currency"`
+	TotalPayout money.Money    `json:"total_payout"`
+	// TotalRefunded is the stake returned on voided bets.
+	TotalRefunded money.Money                          `json:"total_refunded"`
+	Totals        map[money.Currency]*SettlementTotals `json:"totals"`
+	// EventStatus is the event's status after the settlement: SETTLED once
+	// every bet on it is decided, CLOSED while some wait for results of
+	// other markets, or CANCELLED. It is empty for an unknown event.
+	EventStatus EventStatus `json:"event_status,omitempty"`
+}
+
+// SettlementTotals is the money a settlement credited in one currency.
+type SettlementTotals struct {
+	Payout   money.Money `json:"payout"`
+	Refunded money.Money `json:"refunded"`
+}
+
+// Clone returns a copy of the summary that shares no totals with the
+// original.
+func (s *SettlementSummary) Clone() *SettlementSummary {
+	c := *s
+	if s.Totals != nil {
+		c.Totals = make(map[money.Currency]*SettlementTotals, len(s.Totals))
+		for currency, totals := range s.Totals {
+			copied := *totals
+			c.Totals[currency] = &copied
+		}
+	}
+	return &c
+}
+
+// Record adds a settled bet and the amount credited for it, in the bet's
+// currency, to the summary.
+func (s *SettlementSummary) Record(bet *Bet, credited money.Money) {
+	s.BetsSettled++
+	switch bet.Status {
+	case StatusWon:
+		s.Won++
+	case StatusLost:
+		s.Lost++
+	case StatusVoid:
+		s.Voided++
+	}
+	if s.Totals == nil {
+		s.Totals = make(map[money.Currency]*SettlementTotals)
+	}
+	totals, ok := s.Totals[bet.Currency]
+	if !ok {
+		totals = &SettlementTotals{}
+		s.Totals[bet.Currency] = totals
+	}
+	if bet.Status == StatusVoid {
+		totals.Refunded += credited
+		return
+	}
+	totals.Payout += credited
+}
+
+// Report converts the per-currency totals to currency.
+func (s *SettlementSummary) Report(fx *money.FXTable, currency money.Currency) error {
+	s.Currency = currency
+	s.TotalPayout, s.TotalRefunded = money.Zero, money.Zero
+	for from, totals := range s.Totals {
+		payout, err := fx.Convert(totals.Payout, from, currency)
+		if err != nil {
+			return err
+		}
+		refunded, err := fx.Convert(totals.Refunded, from, currency)
+		if err != nil {
+			return err
+		}
+		s.TotalPayout += payout
+		s.TotalRefunded += refunded
+	}
+	return nil
+}
+
+// SettlementPreview is what settling an event would change, worked out by
+// the settlement itself and then discarded.
+type SettlementPreview struct {
+	Summary *SettlementSummary `json:"summary"`
+	// Bets are the bets the settlement would change, as it would leave them.
+	Bets []*PreviewedBet `json:"bets"`
+	// BalanceDeltas are what the settlement would credit to each user's
+	// wallets, ordered by user and currency. Users it credits nothing are
+	// left out.
+	BalanceDeltas []*BalanceDelta `json:"balance_deltas"`
+	// HouseProfit is, per currency, the cash stake still riding on the bets
+	// the settlement would decide less what it would credit for them; a loss
+	// is negative. TotalHouseProfit converts it to the summary's currency.
+	HouseProfit      map[money.Currency]money.Money `json:"house_profit"`
+	TotalHouseProfit money.Money                    `json:"total_house_profit"`
+}
+
+// PreviewedBet is a bet as a settlement would leave it, with what the
+// settlement would credit for it.
+type PreviewedBet struct {
+	*Bet
+	Credited money.Money `json:"credited"`
+}
+
+// BalanceDelta is the change to a user's wallet in one currency.
+type BalanceDelta struct {
+	UserID   string         `json:"user_id"`
+	Currency money.Currency `json:"currency"`
+	Amount   money.Money    `json:"amount"`
+}
+
+// Add adds a bet the settlement would change, and the amount it would
+// credit for it in the bet's currency, to the preview. Only decided bets
+// count towards the house's profit.
+func (p *SettlementPreview) Add(bet *Bet, credited money.Money) {
+	p.Bets = append(p.Bets, &PreviewedBet{Bet: bet, Credited: credited})
+	if bet.Status == StatusPlaced {
+		return
+	}
+	if p.HouseProfit == nil {
+		p.HouseProfit = make(map[money.Currency]money.Money)
+	}
+	held := bet.OpenStake()
+	if bet.IsFree() {
+		held = money.Zero
+	}
+	p.HouseProfit[bet.Currency] += held - credited
+
+	if credited.IsZero() {
+		return
+	}
+	i, found := slices.BinarySearchFunc(p.BalanceDeltas, bet, func(d *BalanceDelta, b *Bet) int {
+		return cmp.Or(cmp.Compare(d.UserID, b.UserID), cmp.Compare(d.Currency, b.Currency))
+	})
+	if !found {
+		p.BalanceDeltas = slices.Insert(p.BalanceDeltas, i, &BalanceDelta{UserID: bet.UserID, Currency: bet.Currency})
+	}
+	p.BalanceDeltas[i].Amount += credited
+}
+
+// Report converts the summary's totals and the house's profit to currency.
+func (p *SettlementPreview) Report(fx *money.FXTable, currency money.Currency) error {
+	if err := p.Summary.Report(fx, currency); err != nil {
+		return err
+	}
+	p.TotalHouseProfit = money.Zero
+	for from, profit := range p.HouseProfit {
+		converted, err := fx.Convert(profit, from, currency)
+		if err != nil {
+			return err
+		}
+		p.TotalHouseProfit += converted
+	}
+	return nil
+}
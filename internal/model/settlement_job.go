@@ -0,0 +1,82 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// SettlementJobState is where a settlement job is in its lifecycle.
+type SettlementJobState string
+
+const (
+	// JobQueued jobs wait for a worker.
+	JobQueued SettlementJobState = "QUEUED"
+	// JobRunning jobs are settling their event's bets batch by batch.
+	JobRunning SettlementJobState = "RUNNING"
+	// JobCompleted jobs went through every bet on their event.
+	JobCompleted SettlementJobState = "COMPLETED"
+	// JobFailed jobs stopped on an error; the batches settled before it
+	// stay settled.
+	JobFailed SettlementJobState = "FAILED"
+	// JobCancelled jobs were cancelled; the batches settled before it stay
+	// settled.
+	JobCancelled SettlementJobState = "CANCELLED"
+)
+
+// Finished reports whether a job in the state will not change any more.
+func (s SettlementJobState) Finished() bool {
+	return s == JobCompleted || s == JobFailed || s == JobCancelled
+}
+
+// SettlementJob settles an event in the background, a batch of bets at a
+// time, so no single step holds the repository for long. Each batch is
+// settled atomically together with the job's progress.
+type SettlementJob struct {
+	ID      string             `json:"id"`
+	EventID string             `json:"event_id"`
+	Request SettleBetRequest   `json:"request"`
+	State   SettlementJobState `json:"state"`
+	// Total is the number of PLACED bets on the event when the job started,
+	// and Processed how many of them it has gone through. Bets cashed out
+	// while the job runs are skipped, so Processed can end below Total.
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	// Errors lists what went wrong, oldest first. Failed batches are
+	// retried, so a job can complete with errors.
+	Errors []string `json:"errors,omitempty"`
+	// Summary totals the bets settled so far; it is final once the job is
+	// COMPLETED.
+	Summary *SettlementSummary `json:"summary,omitempty"`
+	// Cursor is where the repository resumes the job; its meaning is the
+	// repository's.
+	Cursor     int64     `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Clone returns a copy of the job that shares nothing with the original.
+func (j *SettlementJob) Clone() *SettlementJob {
+	c := *j
+	c.Request.Markets = slices.Clone(j.Request.Markets)
+	for i := range c.Request.Markets {
+		c.Request.Markets[i].WinningSelectionIDs = slices.Clone(j.Request.Markets[i].WinningSelectionIDs)
+	}
+	c.Errors = slices.Clone(j.Errors)
+	if j.Summary != nil {
+		c.Summary = j.Summary.Clone()
+	}
+	return &c
+}
+
+// SettlementJobRequest defines the payload for submitting a settlement
+// job: the event and its result, given as for a settlement.
+type SettlementJobRequest struct {
+	EventID string `json:"event_id" validate:"required"`
+	SettleBetRequest
+}
+
+func (req *SettlementJobRequest) Validate() error {
+	return validate.Struct(req)
+}
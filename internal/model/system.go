@@ -0,0 +1,200 @@
+package model
+
+import (
+	"fmt"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"time"
+)
+
+// MaxSystemLines caps the number of lines one system bet may expand into.
+// It admits every common full-cover bet up to a Goliath (247 lines).
+const MaxSystemLines = 255
+
+// Named systems and the fold sizes they combine.
+var systems = map[string]struct {
+	legs  int
+	folds []int
+}{
+	// trixie: 3 doubles and a treble on 3 selections.
+	"trixie": {legs: 3, folds: []int{2, 3}},
+	// yankee: 6 doubles, 4 trebles and a four-fold on 4 selections.
+	"yankee": {legs: 4, folds: []int{2, 3, 4}},
+	// lucky15: a yankee plus 4 singles.
+	"lucky15": {legs: 4, folds: []int{1, 2, 3, 4}},
+}
+
+// BetLine is one combination of a system bet's legs, settled on its own
+// like an accumulator of those legs.
+type BetLine struct {
+	// Legs holds positions in the parent bet's Legs.
+	Legs      []int       `json:"legs"`
+	Odds      money.Odds  `json:"odds"`
+	Amount    money.Money `json:"amount"`
+	Status    BetStatus   `json:"status"`
+	SettledAt time.Time   `json:"settled_at,omitempty"`
+}
+
+// HasLines reports whether the bet is a system bet split into lines.
+func (b *Bet) HasLines() bool {
+	return len(b.Lines) > 0
+}
+
+// lineLegs returns the legs a line combines.
+func (b *Bet) lineLegs(line *BetLine) []BetLeg {
+	legs := make([]BetLeg, len(line.Legs))
+	for i, pos := range line.Legs {
+		legs[i] = b.Legs[pos]
+	}
+	return legs
+}
+
+// LineReturn returns what a decided line gives back: its payout at the odds
+// of its non-void legs if it won, its stake if it is void, nothing otherwise.
+func (b *Bet) LineReturn(line *BetLine) money.Money {
+	switch line.Status {
+	case StatusWon:
+		return line.Amount.MulOdds(combinedOdds(b.lineLegs(line)), PayoutRounding)
+	case StatusVoid:
+		return line.Amount
+	default:
+		return money.Zero
+	}
+}
+
+func (b *Bet) linesReturn() money.Money {
+	var total money.Money
+	for i := range b.Lines {
+		total += b.LineReturn(&b.Lines[i])
+	}
+	return total
+}
+
+// resolveLines settles every line its legs decide and returns the status of
+// the whole system bet.
+func (b *Bet) resolveLines(now time.Time) BetStatus {
+	won, void, open := 0, 0, 0
+	for i := range b.Lines {
+		line := &b.Lines[i]
+		if line.Status == StatusPlaced {
+			if line.Status = legStatus(b.lineLegs(line)); line.Status != StatusPlaced {
+				line.SettledAt = now
+			}
+		}
+		switch line.Status {
+		case StatusWon:
+			won++
+		case StatusVoid:
+			void++
+		case StatusPlaced:
+			open++
+		}
+	}
+	switch {
+	case open > 0:
+		return StatusPlaced
+	case won > 0:
+		return StatusWon
+	case void == len(b.Lines):
+		return StatusVoid
+	default:
+		return StatusLost
+	}
+}
+
+// SystemLines expands a system bet on n legs into its lines, splitting stake
+// evenly across them. system names a bet from the systems table; otherwise
+// fold gives the size of every combination (k-from-n).
+func SystemLines(system string, fold, n int, stake money.Money) ([]BetLine, error) {
+	var folds []int
+	if system != "" {
+		def, ok := systems[system]
+		if !ok {
+			return nil, fmt.Errorf("unknown system '%s'", system)
+		}
+		if n != def.legs {
+			return nil, fmt.Errorf("a %s needs exactly %d legs, got %d", system, def.legs, n)
+		}
+		folds = def.folds
+	} else {
+		if fold < 1 || fold > n {
+			return nil, fmt.Errorf("fold must be between 1 and the number of legs (%d), got %d", n, fold)
+		}
+		folds = []int{fold}
+	}
+
+	var combos [][]int
+	for _, k := range folds {
+		if len(combos)+binomial(n, k) > MaxSystemLines {
+			return nil, fmt.Errorf("system expands into more than %d lines", MaxSystemLines)
+		}
+		combos = append(combos, combinations(n, k)...)
+	}
+
+	lines := int64(len(combos))
+	if stake.Minor()%lines != 0 {
+		return nil, fmt.Errorf("stake %s does not split evenly across %d lines", stake, lines)
+	}
+	unit := money.FromMinor(stake.Minor() / lines)
+
+	result := make([]BetLine, len(combos))
+	for i, legs := range combos {
+		result[i] = BetLine{Legs: legs, Amount: unit, Status: StatusPlaced}
+	}
+	return result, nil
+}
+
+// PriceLines sets each line's odds from the legs it combines.
+func (b *Bet) PriceLines() {
+	for i := range b.Lines {
+		b.Lines[i].Odds = combinedOdds(b.lineLegs(&b.Lines[i]))
+	}
+}
+
+// combinations returns every k-element subset of 0..n-1 in lexicographic order.
+func combinations(n, k int) [][]int {
+	var result [][]int
+	combo := make([]int, k)
+	var walk func(start, depth int)
+	walk = func(start, depth int) {
+		if depth == k {
+			result = append(result, append([]int(nil), combo...))
+			return
+		}
+		for i := start; i <= n-(k-depth); i++ {
+			combo[depth] = i
+			walk(i+1, depth+1)
+		}
+	}
+	walk(0, 0)
+	return result
+}
+
+// binomial returns n choose k.
+func binomial(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	c := 1
+	for i := 1; i <= k; i++ {
+		c = c * (n - k + i) / i
+	}
+	return c
+}
+
+// PlaceSystemRequest defines the payload for a system bet: a total stake
+// split evenly across combinations of the legs. Either system names a
+// standard bet (trixie, yankee, lucky15) or fold sets the size of every
+// combination, for a generic k-from-n.
+type PlaceSystemRequest struct {
+	UserID string          `json:"user_id" validate:"required"`
+	System string          `json:"system" validate:"required_without=Fold,omitempty,oneof=trixie yankee lucky15"`
+	Fold   int             `json:"fold" validate:"omitempty,min=1"`
+	Legs   []BetLegRequest `json:"legs" validate:"required,min=2,max=20,dive"`
+	Amount money.Money     `json:"amount" validate:"required,gt=0"`
+	// Currency defaults to the user's home currency.
+	Currency string `json:"currency"`
+}
+
+func (p *PlaceSystemRequest) Validate() error {
+	return validate.Struct(p)
+}
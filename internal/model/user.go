@@ -1,20 +1,76 @@
 package model
 
 import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
 	"time"
 )
 
 type User struct {
-	ID        string    `json:"id"`
-	Balance   float64   `json:"balance"` 
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID string `json:"id"`
+	// Currency is the user's home currency, which their opening balance is
+	// credited in and their bets default to. Balance is their balance in it.
+	Currency money.Currency `json:"currency"`
+	Balance  money.Money    `json:"balance"`
+	// Balances holds the balance of every wallet the user has, one per
+	// currency, including the home one. A wallet opens with the first money
+	// moved in its currency.
+	Balances  map[money.Currency]money.Money `json:"balances"`
+	CreatedAt time.Time                      `json:"created_at"`
+	UpdatedAt time.Time                      `json:"updated_at"`
+	// Version starts at 1 and grows with every change to the stored user,
+	// balance changes included.
+	Version int64 `json:"version"`
+}
+
+// Clone returns a copy of the user that shares no memory with it.
+func (u *User) Clone() *User {
+	c := *u
+	if u.Balances != nil {
+		c.Balances = make(map[money.Currency]money.Money, len(u.Balances))
+		for currency, balance := range u.Balances {
+			c.Balances[currency] = balance
+		}
+	}
+	return &c
+}
+
+// Wallet returns the user's balance in a currency and whether they have a
+// wallet in it.
+func (u *User) Wallet(currency money.Currency) (money.Money, bool) {
+	balance, ok := u.Balances[currency]
+	return balance, ok
+}
+
+// SetBalance records the balance of the user's wallet in a currency,
+// opening it if needed.
+func (u *User) SetBalance(currency money.Currency, balance money.Money) {
+	if u.Balances == nil {
+		u.Balances = make(map[money.Currency]money.Money)
+	}
+	u.Balances[currency] = balance
+	if currency == u.Currency {
+		u.Balance = balance
+	}
+}
+
+// UserBalance is a user's balances together with their total converted to
+// the reporting currency.
+type UserBalance struct {
+	UserID   string                         `json:"user_id"`
+	Currency money.Currency                 `json:"currency"`
+	Balance  money.Money                    `json:"balance"`
+	Balances map[money.Currency]money.Money `json:"balances"`
+	// Total is the sum of all balances in ReportingCurrency.
+	Total             money.Money    `json:"total"`
+	ReportingCurrency money.Currency `json:"reporting_currency"`
 }
 
 // CreateUserRequest defines the payload for creating a new user.
 type CreateUserRequest struct {
 	UserID string `json:"user_id" validate:"required"`
-	Name string `json:"name" validate:"required"`
+	Name   string `json:"name" validate:"required"`
+	// Currency is the user's home currency; it defaults to EUR.
+	Currency string `json:"currency"`
 }
 
 func (req *CreateUserRequest) Validate() error {
@@ -28,4 +84,4 @@ type UpdateUserRequest struct {
 func (req *UpdateUserRequest) Validate() error {
 	// Add validation rules if needed for updatable fields
 	return validate.Struct(req)
-}
\ No newline at end of file
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"time"
+)
+
+// WalletOperationType tells deposits from withdrawals.
+type WalletOperationType string
+
+const (
+	OperationDeposit    WalletOperationType = "DEPOSIT"
+	OperationWithdrawal WalletOperationType = "WITHDRAWAL"
+)
+
+// WalletOperationStatus is where a wallet operation is in its workflow.
+// Deposits complete at once; withdrawals wait PENDING, with their amount
+// reserved, until they are APPROVED or REJECTED.
+type WalletOperationStatus string
+
+const (
+	OperationCompleted WalletOperationStatus = "COMPLETED"
+	OperationPending   WalletOperationStatus = "PENDING"
+	OperationApproved  WalletOperationStatus = "APPROVED"
+	OperationRejected  WalletOperationStatus = "REJECTED"
+)
+
+// WalletOperation is a deposit into or withdrawal out of a user's wallet.
+// Reference is chosen by the client, typically the payment provider's ID,
+// and is unique across all operations so the same payment is never booked
+// twice.
+type WalletOperation struct {
+	ID        string                `json:"id"`
+	UserID    string                `json:"user_id"`
+	Type      WalletOperationType   `json:"type"`
+	Reference string                `json:"reference"`
+	Amount    money.Money           `json:"amount"`
+	Currency  money.Currency        `json:"currency"`
+	Status    WalletOperationStatus `json:"status"`
+	// Reason explains a rejected withdrawal.
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ResolvedAt is when the operation completed, was approved or was
+	// rejected; it is nil while a withdrawal is pending.
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Clone returns a copy of the operation.
+func (o *WalletOperation) Clone() *WalletOperation {
+	c := *o
+	if o.ResolvedAt != nil {
+		resolvedAt := *o.ResolvedAt
+		c.ResolvedAt = &resolvedAt
+	}
+	return &c
+}
+
+// WalletOperationRequest defines the payload for a deposit or a withdrawal.
+type WalletOperationRequest struct {
+	Amount    money.Money `json:"amount" validate:"required,gt=0"`
+	Reference string      `json:"reference" validate:"required,max=64"`
+	// Currency defaults to the user's home currency. A deposit in a new
+	// currency opens a wallet in it.
+	Currency string `json:"currency"`
+}
+
+func (req *WalletOperationRequest) Validate() error {
+	return validate.Struct(req)
+}
+
+// RejectWithdrawalRequest defines the optional payload for rejecting a
+// pending withdrawal.
+type RejectWithdrawalRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+func (req *RejectWithdrawalRequest) Validate() error {
+	return validate.Struct(req)
+}
@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"math"
+	"slices"
+	"sort"
+)
+
+// betSorts lists every order a bet listing can ask for.
+var betSorts = []model.BetSort{model.SortByCreatedAt, model.SortBySettledAt, model.SortByAmount}
+
+// maxListingChunk caps the entries of one chunk of a betIndex, so adding or
+// moving a bet shifts at most that many entries.
+const maxListingChunk = 512
+
+// betEntry is a bet in a betIndex with the sort key it was indexed under.
+type betEntry struct {
+	key int64
+	bet *model.Bet
+}
+
+// before reports whether the entry comes strictly before the position of
+// sort key key and bet ID id in ascending order.
+func (e betEntry) before(key int64, id string) bool {
+	return e.key < key || e.key == key && e.bet.ID < id
+}
+
+// betIndex holds bets in ascending order of one sort key and then ID. The
+// entries are split into chunks, each sorted and all in order, so a listing
+// seeks to its first bet without sorting and an update moves one entry.
+type betIndex struct {
+	sort   model.BetSort
+	chunks [][]betEntry
+}
+
+// betPos is the position of an entry in a betIndex: its chunk and offset.
+// The position after the last entry is {len(chunks), 0}.
+type betPos struct {
+	chunk, offset int
+}
+
+func sortKey(sort model.BetSort, bet *model.Bet) int64 {
+	query := model.BetQuery{Sort: sort}
+	return query.SortKey(bet)
+}
+
+// search returns the position of the first entry not before sort key key
+// and bet ID id.
+func (x *betIndex) search(key int64, id string) betPos {
+	c := sort.Search(len(x.chunks), func(i int) bool {
+		chunk := x.chunks[i]
+		return !chunk[len(chunk)-1].before(key, id)
+	})
+	if c == len(x.chunks) {
+		return betPos{chunk: c}
+	}
+	chunk := x.chunks[c]
+	return betPos{chunk: c, offset: sort.Search(len(chunk), func(i int) bool { return !chunk[i].before(key, id) })}
+}
+
+// insert adds a bet under a sort key, splitting its chunk once full.
+func (x *betIndex) insert(key int64, bet *model.Bet) {
+	entry := betEntry{key: key, bet: bet}
+	pos := x.search(key, bet.ID)
+	if pos.chunk == len(x.chunks) {
+		if pos.chunk == 0 {
+			x.chunks = [][]betEntry{{entry}}
+			return
+		}
+		pos = betPos{chunk: pos.chunk - 1, offset: len(x.chunks[pos.chunk-1])}
+	}
+	chunk := slices.Insert(x.chunks[pos.chunk], pos.offset, entry)
+	if len(chunk) <= maxListingChunk {
+		x.chunks[pos.chunk] = chunk
+		return
+	}
+	half := len(chunk) / 2
+	x.chunks[pos.chunk] = chunk[:half:half]
+	x.chunks = slices.Insert(x.chunks, pos.chunk+1, slices.Clone(chunk[half:]))
+}
+
+// delete removes the bet indexed under a sort key, dropping its chunk once
+// empty.
+func (x *betIndex) delete(key int64, id string) {
+	pos := x.search(key, id)
+	if pos.chunk == len(x.chunks) || x.chunks[pos.chunk][pos.offset].bet.ID != id {
+		return
+	}
+	chunk := slices.Delete(x.chunks[pos.chunk], pos.offset, pos.offset+1)
+	if len(chunk) == 0 {
+		x.chunks = slices.Delete(x.chunks, pos.chunk, pos.chunk+1)
+		return
+	}
+	x.chunks[pos.chunk] = chunk
+}
+
+// ascend calls yield with the entries from pos onwards until it returns false.
+func (x *betIndex) ascend(pos betPos, yield func(betEntry) bool) {
+	for c := pos.chunk; c < len(x.chunks); c++ {
+		chunk := x.chunks[c]
+		for i := pos.offset; i < len(chunk); i++ {
+			if !yield(chunk[i]) {
+				return
+			}
+		}
+		pos.offset = 0
+	}
+}
+
+// descend calls yield with the entries before pos, last first, until it
+// returns false.
+func (x *betIndex) descend(pos betPos, yield func(betEntry) bool) {
+	for c := pos.chunk; c >= 0; c-- {
+		if c == len(x.chunks) {
+			continue
+		}
+		chunk := x.chunks[c]
+		i := len(chunk) - 1
+		if c == pos.chunk {
+			i = pos.offset - 1
+		}
+		for ; i >= 0; i-- {
+			if !yield(chunk[i]) {
+				return
+			}
+		}
+	}
+}
+
+// betListing keeps a set of bets in every listing order.
+type betListing struct {
+	size    int
+	indexes map[model.BetSort]*betIndex
+}
+
+func newBetListing() *betListing {
+	l := &betListing{indexes: make(map[model.BetSort]*betIndex, len(betSorts))}
+	for _, s := range betSorts {
+		l.indexes[s] = &betIndex{sort: s}
+	}
+	return l
+}
+
+// add lists a stored bet at the position of keys, the bet itself or the
+// updated copy it is about to be overwritten with.
+func (l *betListing) add(bet, keys *model.Bet) {
+	for _, x := range l.indexes {
+		x.insert(sortKey(x.sort, keys), bet)
+	}
+	l.size++
+}
+
+// remove unlists a stored bet, found by its current sort keys.
+func (l *betListing) remove(bet *model.Bet) {
+	for _, x := range l.indexes {
+		x.delete(sortKey(x.sort, bet), bet.ID)
+	}
+	l.size--
+}
+
+// move relists a stored bet at the position of its updated copy, in the
+// orders whose sort key changes.
+func (l *betListing) move(bet, updated *model.Bet) {
+	for _, x := range l.indexes {
+		from, to := sortKey(x.sort, bet), sortKey(x.sort, updated)
+		if from != to {
+			x.delete(from, bet.ID)
+			x.insert(to, bet)
+		}
+	}
+}
+
+// find reads the bets matching a query in listing order, up to Limit+1 of
+// them. It seeks past the cursor and outside the query's range of sort keys
+// rather than reading those bets.
+func (l *betListing) find(query *model.BetQuery) []*model.Bet {
+	x := l.indexes[query.Sort]
+	lo, hi := keyRange(query)
+	var matched []*model.Bet
+	collect := func(e betEntry) bool {
+		if e.key < lo || e.key > hi {
+			return false
+		}
+		if query.Matches(e.bet) {
+			matched = append(matched, e.bet)
+		}
+		return len(matched) <= query.Limit
+	}
+
+	after := query.After
+	if !query.Desc {
+		pos := x.search(lo, "")
+		if after != nil && after.Key >= lo {
+			pos = x.search(after.Key, after.ID)
+		}
+		x.ascend(pos, collect)
+		return matched
+	}
+	pos := betPos{chunk: len(x.chunks)}
+	if after != nil && after.Key <= hi {
+		pos = x.search(after.Key, after.ID)
+	} else if hi < math.MaxInt64 {
+		pos = x.search(hi+1, "")
+	}
+	x.descend(pos, collect)
+	return matched
+}
+
+// keyRange returns the inclusive range of sort keys a query's filters leave
+// open in the order it lists by.
+func keyRange(query *model.BetQuery) (lo, hi int64) {
+	lo, hi = math.MinInt64, math.MaxInt64
+	switch query.Sort {
+	case model.SortBySettledAt:
+		if !query.SettledFrom.IsZero() || !query.SettledTo.IsZero() {
+			// Bets not settled yet sort at 0 and match no settlement range.
+			lo = 1
+		}
+		if !query.SettledFrom.IsZero() {
+			lo = max(lo, query.SettledFrom.UnixNano())
+		}
+		if !query.SettledTo.IsZero() {
+			hi = query.SettledTo.UnixNano()
+		}
+	case model.SortByAmount:
+		if !query.MinStake.IsZero() {
+			lo = query.MinStake.Minor()
+		}
+		if !query.MaxStake.IsZero() {
+			hi = query.MaxStake.Minor()
+		}
+	default:
+		if !query.CreatedFrom.IsZero() {
+			lo = query.CreatedFrom.UnixNano()
+		}
+		if !query.CreatedTo.IsZero() {
+			hi = query.CreatedTo.UnixNano()
+		}
+	}
+	return lo, hi
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+// TestBetIndexChunks checks a betIndex against a plain sorted slice through
+// enough inserts, moves and deletes to split and drop chunks.
+func TestBetIndexChunks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	index := &betIndex{sort: model.SortByAmount}
+	keys := make(map[*model.Bet]int64)
+	check := func(step int) {
+		t.Helper()
+		var want []betEntry
+		for bet, key := range keys {
+			want = append(want, betEntry{key: key, bet: bet})
+		}
+		slices.SortFunc(want, func(a, b betEntry) int {
+			if a.before(b.key, b.bet.ID) {
+				return -1
+			}
+			return 1
+		})
+		var got []betEntry
+		index.ascend(betPos{}, func(e betEntry) bool {
+			got = append(got, e)
+			return true
+		})
+		if !slices.Equal(got, want) {
+			t.Fatalf("step %d: index holds %d entries out of order, want %d", step, len(got), len(want))
+		}
+		var reversed []betEntry
+		index.descend(betPos{chunk: len(index.chunks)}, func(e betEntry) bool {
+			reversed = append(reversed, e)
+			return true
+		})
+		slices.Reverse(reversed)
+		if !slices.Equal(reversed, want) {
+			t.Fatalf("step %d: descending order differs from ascending", step)
+		}
+		for _, chunk := range index.chunks {
+			if len(chunk) == 0 || len(chunk) > maxListingChunk {
+				t.Fatalf("step %d: chunk of %d entries", step, len(chunk))
+			}
+		}
+	}
+
+	var bets []*model.Bet
+	for step := 0; step < 5000; step++ {
+		switch op := rng.Intn(10); {
+		case op < 6 || len(bets) == 0:
+			bet := &model.Bet{ID: fmt.Sprintf("bet-%05d", step), Amount: money.FromMinor(rng.Int63n(100))}
+			bets = append(bets, bet)
+			keys[bet] = bet.Amount.Minor()
+			index.insert(keys[bet], bet)
+		case op < 8:
+			bet := bets[rng.Intn(len(bets))]
+			index.delete(keys[bet], bet.ID)
+			keys[bet] = rng.Int63n(100)
+			index.insert(keys[bet], bet)
+		default:
+			i := rng.Intn(len(bets))
+			index.delete(keys[bets[i]], bets[i].ID)
+			delete(keys, bets[i])
+			bets = slices.Delete(bets, i, i+1)
+		}
+		if step%250 == 0 {
+			check(step)
+		}
+	}
+	check(5000)
+	if len(index.chunks) < 2 {
+		t.Fatalf("index never split: %d chunks", len(index.chunks))
+	}
+}
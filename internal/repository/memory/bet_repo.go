@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/ledger"
 	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
 	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
 	"fmt"
 	"sync"
 	"time"
@@ -10,13 +12,53 @@ import (
 	"github.com/google/uuid"
 )
 
+// defaultBalance is credited to users created without an explicit balance.
+var defaultBalance = money.FromUnits(1000)
+
 // InMemoryBetRepository stores bets and user balances in memory.
 // It uses mutexes for concurrency safety[cite: 4].
+// Every balance change is recorded in the journal under the same lock, and
+// user balances are always read back from it.
 type InMemoryBetRepository struct {
 	mu      sync.RWMutex
 	bets    map[string]*model.Bet   
 	betsByEvent map[string][]*model.Bet 
+	betsByUser  map[string][]*model.Bet
+	// Listing indexes: every bet, and the bets of each user, event and
+	// status, each kept in every listing order.
+	listing         *betListing
+	listingByUser   map[string]*betListing
+	listingByEvent  map[string]*betListing
+	listingByStatus map[model.BetStatus]*betListing
 	users   map[string]*model.User  
+	journal *ledger.Journal
+	events     map[string]*model.Event
+	markets    map[string]*model.Market
+	selections map[string]*model.Selection
+	idempotency map[string]*model.IdempotencyRecord
+	// Deposits and withdrawals by ID, by reference and by user, oldest first.
+	walletOps       map[string]*model.WalletOperation
+	walletOpsByRef  map[string]*model.WalletOperation
+	walletOpsByUser map[string][]*model.WalletOperation
+	// Free-bet tokens by ID and by user, oldest first.
+	freeBets       map[string]*model.FreeBet
+	freeBetsByUser map[string][]*model.FreeBet
+	// Boosts by ID, in the order they were created, and the bets placed
+	// with each.
+	boosts           map[string]*model.Boost
+	boostsByCreation []*model.Boost
+	betsByBoost      map[string][]*model.Bet
+	// Limits by scope (see limitsKey).
+	limits map[string]*model.Limits
+	// Exposure entries by event, kept up to date by applyExposure.
+	exposure map[string]map[exposureKey]*model.ExposureEntry
+	// Settlement corrections by event, oldest first.
+	corrections map[string][]*model.SettlementCorrection
+	// Settlement jobs by ID and in the order they were created.
+	settlementJobs           map[string]*model.SettlementJob
+	settlementJobsByCreation []*model.SettlementJob
+	// nextIdempotencySweep is when expired idempotency records are next purged.
+	nextIdempotencySweep time.Time
 }
 
 // NewInMemoryBetRepository creates a new in-memory repository.
@@ -24,12 +66,51 @@ func NewInMemoryBetRepository() *InMemoryBetRepository {
 	return &InMemoryBetRepository{
 		bets:    make(map[string]*model.Bet),
 		betsByEvent: make(map[string][]*model.Bet),
+		betsByUser:   make(map[string][]*model.Bet),
+		listing:         newBetListing(),
+		listingByUser:   make(map[string]*betListing),
+		listingByEvent:  make(map[string]*betListing),
+		listingByStatus: make(map[model.BetStatus]*betListing),
 		users:   make(map[string]*model.User),
+		journal: ledger.NewJournal(),
+		events:     make(map[string]*model.Event),
+		markets:    make(map[string]*model.Market),
+		selections: make(map[string]*model.Selection),
+		idempotency: make(map[string]*model.IdempotencyRecord),
+		walletOps:       make(map[string]*model.WalletOperation),
+		walletOpsByRef:  make(map[string]*model.WalletOperation),
+		walletOpsByUser: make(map[string][]*model.WalletOperation),
+		freeBets:       make(map[string]*model.FreeBet),
+		freeBetsByUser: make(map[string][]*model.FreeBet),
+		boosts:         make(map[string]*model.Boost),
+		betsByBoost:    make(map[string][]*model.Bet),
+		limits:         make(map[string]*model.Limits),
+		exposure:       make(map[string]map[exposureKey]*model.ExposureEntry),
+		corrections:    make(map[string][]*model.SettlementCorrection),
+		settlementJobs: make(map[string]*model.SettlementJob),
+	}
+}
+
+// post appends an entry to the journal and refreshes the cached balance of
+// the user it concerns, bumping their version when it changes. Callers must
+// hold the write lock.
+func (r *InMemoryBetRepository) post(entry *model.JournalEntry) error {
+	if err := r.journal.Append(entry); err != nil {
+		return fmt.Errorf("internal error: %w", err)
+	}
+	if ledger.WalletDelta(entry, entry.UserID).IsZero() {
+		return nil
+	}
+	if user, exists := r.users[entry.UserID]; exists {
+		user.SetBalance(entry.Currency, r.journal.Balance(ledger.WalletAccount(user.ID, entry.Currency)))
+		user.UpdatedAt = time.Now()
+		user.Version++
 	}
+	return nil
 }
 
 // PlaceBet stores a new bet and updates the user's balance.
-func (r *InMemoryBetRepository) PlaceBet(bet *model.Bet) (*model.Bet, error) {
+func (r *InMemoryBetRepository) PlaceBet(bet *model.Bet, check model.PlacementCheck) (*model.Bet, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -39,23 +120,63 @@ func (r *InMemoryBetRepository) PlaceBet(bet *model.Bet) (*model.Bet, error) {
 		return nil, &errors.ErrorNotFound{Entity: "User", ID: bet.UserID}
 	}
 
-	if user.Balance < bet.Amount {
-		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("insufficient balance: current %.2f, required %.2f", user.Balance, bet.Amount)}
+	if bet.Currency == "" {
+		bet.Currency = user.Currency
+	}
+	if err := r.checkBettable(bet); err != nil {
+		return nil, err
+	}
+	var freeBet *model.FreeBet
+	now := time.Now()
+	if bet.IsFree() {
+		freeBet = r.freeBets[bet.FreeBetID]
+		if err := checkFreeBet(freeBet, bet, now); err != nil {
+			return nil, err
+		}
+	} else if err := checkFunds(user, bet.Currency, bet.Amount); err != nil {
+		return nil, err
+	}
+	if bet.IsBoosted() {
+		if err := r.checkBoost(bet); err != nil {
+			return nil, err
+		}
+	}
+	if check != nil {
+		if err := check(bet, r.placementLiability(bet, now)); err != nil {
+			return nil, err
+		}
 	}
 
 	bet.ID = uuid.New().String() 
 	bet.Status = model.StatusPlaced
-	bet.CreatedAt = time.Now()
-
-	r.bets[bet.ID] = bet
-	r.betsByEvent[bet.EventID] = append(r.betsByEvent[bet.EventID], bet)
-
+	bet.CreatedAt = now
+	bet.Version = 1
+	if bet.Type == "" {
+		bet.Type = model.BetTypeSingle
+	}
 
 	// Deduct amount from user balance
-	user.Balance -= bet.Amount
-	user.UpdatedAt = time.Now()
-	r.users[user.ID] = user 
+	if err := r.post(ledger.StakeEntry(bet)); err != nil {
+		return nil, err
+	}
+	if freeBet != nil {
+		freeBet.Status = model.FreeBetUsed
+		freeBet.BetID = bet.ID
+		freeBet.ClosedAt = now
+	}
 
+	// The caller keeps its bet; the repository stores its own copy.
+	stored := bet.Clone()
+	r.bets[stored.ID] = stored
+	for _, eventID := range stored.EventIDs() {
+		r.betsByEvent[eventID] = append(r.betsByEvent[eventID], stored)
+	}
+	r.betsByUser[stored.UserID] = append(r.betsByUser[stored.UserID], stored)
+	if stored.IsBoosted() {
+		r.betsByBoost[stored.BoostID] = append(r.betsByBoost[stored.BoostID], stored)
+	}
+	r.list(stored)
+	r.applyExposure(nil, stored)
 
 	return bet, nil
 }
@@ -74,7 +195,7 @@ func (r *InMemoryBetRepository) FindBetsByEvent(eventID string) ([]*model.Bet, e
 	placedBets := []*model.Bet{}
 	for _, b := range bets {
 		if b.Status == model.StatusPlaced {
-			placedBets = append(placedBets, b)
+			placedBets = append(placedBets, b.Clone())
 		}
 	}
 
@@ -85,7 +206,8 @@ func (r *InMemoryBetRepository) FindBetsByEvent(eventID string) ([]*model.Bet, e
 	return placedBets, nil
 }
 
-// UpdateBet updates the status and settlement time of a bet.
+// UpdateBet updates the status and settlement time of a bet. A non-zero
+// Version must match the stored bet's.
 func (r *InMemoryBetRepository) UpdateBet(bet *model.Bet) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -99,29 +221,332 @@ func (r *InMemoryBetRepository) UpdateBet(bet *model.Bet) error {
 	if existingBet.Status != model.StatusPlaced {
 		return &errors.ErrorConflict{Message: fmt.Sprintf("bet %s already settled with status %s", bet.ID, existingBet.Status)}
 	}
+	if bet.Version != 0 && bet.Version != existingBet.Version {
+		return &errors.ErrorPreconditionFailed{Message: fmt.Sprintf("bet %s is at version %d, not %d", bet.ID, existingBet.Version, bet.Version)}
+	}
 
 
-	existingBet.Status = bet.Status
-	existingBet.SettledAt = time.Now()
-	r.bets[bet.ID] = existingBet 
-
+	settled := existingBet.Clone()
+	settled.Status = bet.Status
+	settled.SettledAt = time.Now()
 
 	// Update user balance if the bet won
-	if bet.Status == model.StatusWon {
-		user, userExists := r.users[existingBet.UserID]
-		if !userExists {
-			return fmt.Errorf("internal error: user %s not found for winning bet %s", existingBet.UserID, bet.ID)
+	if entry := ledger.SettlementEntry(settled); entry != nil {
+		if _, userExists := r.users[settled.UserID]; !userExists {
+			return fmt.Errorf("internal error: user %s not found for winning bet %s", settled.UserID, bet.ID)
+		}
+		if err := r.post(entry); err != nil {
+			return err
 		}
-		payout := existingBet.Amount * existingBet.Odds
-		user.Balance += payout
-		user.UpdatedAt = time.Now()
-		r.users[user.ID] = user 
 	}
 
+	r.store(settled)
 
 	return nil
 }
 
+// GetBet retrieves a copy of a bet by ID.
+func (r *InMemoryBetRepository) GetBet(betID string) (*model.Bet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bet, exists := r.bets[betID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Bet", ID: betID}
+	}
+	return bet.Clone(), nil
+}
+
+// FindBets lists bets matching a query. The bets are read in order from the
+// smallest listing index covering one of its filters, starting at the cursor;
+// the rest of the filters are checked bet by bet.
+func (r *InMemoryBetRepository) FindBets(query *model.BetQuery) (*model.BetPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*model.Bet
+	if listing := r.listingFor(query); listing != nil {
+		matched = listing.find(query)
+	}
+	for i, bet := range matched {
+		matched[i] = bet.Clone()
+	}
+	return query.Page(matched), nil
+}
+
+// UserBetStats summarises a user's bets from the per-user index.
+func (r *InMemoryBetRepository) UserBetStats(userID string) (map[money.Currency]*model.BetStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return model.SummarizeBetsByCurrency(r.betsByUser[userID]), nil
+}
+
+// listingFor returns the smallest listing index known to hold every match
+// of the query, or nil if no bet can match. Callers must hold the lock.
+func (r *InMemoryBetRepository) listingFor(query *model.BetQuery) *betListing {
+	best := r.listing
+	narrow := func(listing *betListing) {
+		if best != nil && (listing == nil || listing.size < best.size) {
+			best = listing
+		}
+	}
+	if query.UserID != "" {
+		narrow(r.listingByUser[query.UserID])
+	}
+	if query.EventID != "" {
+		narrow(r.listingByEvent[query.EventID])
+	}
+	if len(query.Statuses) == 1 {
+		narrow(r.listingByStatus[query.Statuses[0]])
+	}
+	return best
+}
+
+// list adds a new bet to the listing indexes. Callers must hold the write
+// lock.
+func (r *InMemoryBetRepository) list(bet *model.Bet) {
+	r.listing.add(bet, bet)
+	if r.listingByUser[bet.UserID] == nil {
+		r.listingByUser[bet.UserID] = newBetListing()
+	}
+	r.listingByUser[bet.UserID].add(bet, bet)
+	for _, eventID := range bet.EventIDs() {
+		if r.listingByEvent[eventID] == nil {
+			r.listingByEvent[eventID] = newBetListing()
+		}
+		r.listingByEvent[eventID].add(bet, bet)
+	}
+	r.statusListing(bet.Status).add(bet, bet)
+}
+
+// relist moves a stored bet within the listing indexes to where its updated
+// copy belongs. Callers must hold the write lock.
+func (r *InMemoryBetRepository) relist(bet, updated *model.Bet) {
+	r.listing.move(bet, updated)
+	r.listingByUser[bet.UserID].move(bet, updated)
+	for _, eventID := range bet.EventIDs() {
+		r.listingByEvent[eventID].move(bet, updated)
+	}
+	if bet.Status == updated.Status {
+		r.listingByStatus[bet.Status].move(bet, updated)
+		return
+	}
+	r.listingByStatus[bet.Status].remove(bet)
+	r.statusListing(updated.Status).add(bet, updated)
+}
+
+// statusListing returns the listing index of a status, creating it if
+// needed. Callers must hold the write lock.
+func (r *InMemoryBetRepository) statusListing(status model.BetStatus) *betListing {
+	if r.listingByStatus[status] == nil {
+		r.listingByStatus[status] = newBetListing()
+	}
+	return r.listingByStatus[status]
+}
+
+// store overwrites a stored bet with its updated copy, bumping its version,
+// moving it within the listing indexes and moving its exposure. Callers must
+// hold the write lock.
+func (r *InMemoryBetRepository) store(updated *model.Bet) {
+	bet := r.bets[updated.ID]
+	r.relist(bet, updated)
+	r.applyExposure(bet, updated)
+	updated.Version = bet.Version + 1
+	*bet = *updated
+}
+
+// CashOutBet closes the quoted stake of a PLACED bet for the quoted value.
+// The quote is checked and the value credited under the write lock, so odds
+// cannot move between the check and the payment.
+func (r *InMemoryBetRepository) CashOutBet(quote *model.CashOutQuote) (*model.Bet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bet, exists := r.bets[quote.BetID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Bet", ID: quote.BetID}
+	}
+	getEvent := func(eventID string) (*model.Event, error) {
+		event, exists := r.events[eventID]
+		if !exists {
+			return nil, &errors.ErrorNotFound{Entity: "Event", ID: eventID}
+		}
+		return event, nil
+	}
+	err := model.CheckQuote(bet, quote, getEvent, func(selectionID string) (money.Odds, error) {
+		sel, exists := r.selections[selectionID]
+		if !exists {
+			return 0, &errors.ErrorNotFound{Entity: "Selection", ID: selectionID}
+		}
+		return sel.Odds, nil
+	})
+	if err != nil {
+		if _, ok := err.(*errors.ErrorNotFound); ok {
+			return nil, err
+		}
+		return nil, &errors.ErrorConflict{Message: err.Error()}
+	}
+	if _, userExists := r.users[bet.UserID]; !userExists {
+		return nil, fmt.Errorf("internal error: user %s not found for bet %s", bet.UserID, bet.ID)
+	}
+
+	cashed := bet.Clone()
+	cashed.ApplyCashOut(quote, time.Now())
+	if err := r.post(ledger.CashOutEntry(cashed, quote.Stake, quote.Value)); err != nil {
+		return nil, err
+	}
+	r.store(cashed)
+	return cashed.Clone(), nil
+}
+
+// SettleEvent settles every PLACED bet on an event as one atomic step.
+// settle decides each bet's outcome on a copy; all outcomes and payouts are
+// validated before anything is written, so a failure leaves every bet and
+// balance untouched.
+func (r *InMemoryBetRepository) SettleEvent(eventID string, settle model.BetSettler) (*model.SettlementSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkNoSettlementJob(eventID); err != nil {
+		return nil, err
+	}
+	return r.settleEvent(eventID, (*model.Event).CheckSettleable, settle, nil)
+}
+
+// VoidEvent voids every PLACED bet on a cancelled event as one atomic step,
+// as SettleEvent settles them.
+func (r *InMemoryBetRepository) VoidEvent(eventID string, void model.BetSettler) (*model.SettlementSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkNoSettlementJob(eventID); err != nil {
+		return nil, err
+	}
+	return r.settleEvent(eventID, (*model.Event).CheckVoidable, void, nil)
+}
+
+// PreviewSettlement works out a settlement as SettleEvent does, without
+// applying it.
+func (r *InMemoryBetRepository) PreviewSettlement(eventID string, settle model.BetSettler) (*model.SettlementPreview, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preview := &model.SettlementPreview{}
+	summary, err := r.settleEvent(eventID, (*model.Event).CheckSettleable, settle, preview)
+	if err != nil {
+		return nil, err
+	}
+	preview.Summary = summary
+	return preview, nil
+}
+
+// settleEvent settles the PLACED bets on an event, once check accepts the
+// event if it is known. With a non-nil preview, the bets it would change are
+// added to it and nothing is applied. The caller holds the lock.
+func (r *InMemoryBetRepository) settleEvent(eventID string, check func(*model.Event) error, settle model.BetSettler, preview *model.SettlementPreview) (*model.SettlementSummary, error) {
+	event := r.events[eventID]
+	if event != nil {
+		if err := check(event); err != nil {
+			return nil, &errors.ErrorConflict{Message: err.Error()}
+		}
+	}
+	summary := &model.SettlementSummary{EventID: eventID}
+	settled, err := r.settleBets(eventID, r.betsByEvent[eventID], settle, summary, preview)
+	if err != nil {
+		return nil, err
+	}
+	if event != nil {
+		summary.EventStatus = event.StatusAfterSettlement(settled.awaiting)
+	}
+	if preview != nil {
+		return summary, nil
+	}
+
+	// Everything has been validated; apply the changes.
+	if err := r.applySettlement(settled); err != nil {
+		return nil, err
+	}
+	if event != nil && event.Status != summary.EventStatus {
+		event.Status = summary.EventStatus
+		event.StatusChangedAt = settled.at
+	}
+	return summary, nil
+}
+
+// settlement is the outcome of settling bets, worked out on copies and
+// validated but not yet applied.
+type settlement struct {
+	at      time.Time
+	updates []*model.Bet
+	entries []*model.JournalEntry
+	// awaiting is set when a bet settled still awaits the event's result.
+	awaiting bool
+}
+
+// settleBets settles the PLACED bets among bets on eventID with settle,
+// recording them in summary and, if non-nil, preview. Nothing is applied
+// until applySettlement. The caller holds the lock.
+func (r *InMemoryBetRepository) settleBets(eventID string, bets []*model.Bet, settle model.BetSettler, summary *model.SettlementSummary, preview *model.SettlementPreview) (*settlement, error) {
+	settled := &settlement{at: time.Now()}
+	for _, bet := range bets {
+		if bet.Status != model.StatusPlaced {
+			continue
+		}
+		updated := bet.Clone()
+		if err := settle(updated); err != nil {
+			return nil, fmt.Errorf("failed to settle bet %s: %w", bet.ID, err)
+		}
+		if updated.AwaitsEvent(eventID) {
+			settled.awaiting = true
+		}
+		if updated.Status == model.StatusPlaced {
+			// A multi-leg bet can have legs settled without being decided.
+			if updated.OpenLegs() < bet.OpenLegs() {
+				settled.updates = append(settled.updates, updated)
+				summary.Pending++
+				if preview != nil {
+					preview.Add(updated, money.Zero)
+				}
+			}
+			continue
+		}
+		updated.SettledAt = settled.at
+
+		var credited money.Money
+		if entry := ledger.SettlementEntry(updated); entry != nil {
+			if _, userExists := r.users[updated.UserID]; !userExists {
+				return nil, fmt.Errorf("internal error: user %s not found for bet %s", updated.UserID, bet.ID)
+			}
+			if err := ledger.Validate(entry); err != nil {
+				return nil, fmt.Errorf("internal error: %w", err)
+			}
+			settled.entries = append(settled.entries, entry)
+			credited = ledger.WalletDelta(entry, updated.UserID)
+		}
+		settled.updates = append(settled.updates, updated)
+		summary.Record(updated, credited)
+		if preview != nil {
+			preview.Add(updated, credited)
+		}
+	}
+	return settled, nil
+}
+
+// applySettlement posts the entries and stores the bets of a settlement.
+// The caller holds the write lock.
+func (r *InMemoryBetRepository) applySettlement(settled *settlement) error {
+	for _, entry := range settled.entries {
+		if err := r.post(entry); err != nil {
+			return err
+		}
+	}
+	for _, updated := range settled.updates {
+		r.store(updated)
+	}
+	return nil
+}
+
 // CreateUser adds a new user to the repository.
 func (r *InMemoryBetRepository) CreateUser(user *model.User) (*model.User, error) {
 	r.mu.Lock()
@@ -131,16 +556,27 @@ func (r *InMemoryBetRepository) CreateUser(user *model.User) (*model.User, error
 		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("user with ID '%s' already exists", user.ID)}
 	}
 
-	// Set defaults
-	user.CreatedAt = time.Now()
-	user.UpdatedAt = time.Now()
-	if user.Balance == 0 {
-		user.Balance = 1000.0
+	// Set defaults; posting the opening balance brings the version to 1.
+	stored := user.Clone()
+	stored.CreatedAt = time.Now()
+	stored.UpdatedAt = time.Now()
+	stored.Version = 0
+	if stored.Currency == "" {
+		stored.Currency = money.DefaultCurrency
 	}
+	opening := stored.Balance
+	if opening.IsZero() {
+		opening = defaultBalance
+	}
+	stored.Balance = money.Zero
+	stored.Balances = nil
 
-
-	r.users[user.ID] = user
-	return user, nil
+	r.users[stored.ID] = stored
+	if err := r.post(ledger.AdjustmentEntry(stored.ID, opening, stored.Currency, "opening balance")); err != nil {
+		delete(r.users, stored.ID)
+		return nil, err
+	}
+	return stored.Clone(), nil
 }
 
 // GetUser retrieves a specific user by ID.
@@ -152,7 +588,7 @@ func (r *InMemoryBetRepository) GetUser(userID string) (*model.User, error) {
 	if !exists {
 		return nil, &errors.ErrorNotFound{Entity: "User", ID: userID}
 	}
-	return user, nil
+	return user.Clone(), nil
 }
 
 // ListUsers retrieves all users.
@@ -162,12 +598,13 @@ func (r *InMemoryBetRepository) ListUsers() ([]*model.User, error) {
 
 	userList := make([]*model.User, 0, len(r.users))
 	for _, user := range r.users {
-		userList = append(userList, user)
+		userList = append(userList, user.Clone())
 	}
 	return userList, nil
 }
 
-// UpdateUser updates details of an existing user.
+// UpdateUser updates details of an existing user. A non-zero Version must
+// match the stored user's.
 func (r *InMemoryBetRepository) UpdateUser(user *model.User) (*model.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -176,30 +613,126 @@ func (r *InMemoryBetRepository) UpdateUser(user *model.User) (*model.User, error
 	if !exists {
 		return nil, &errors.ErrorNotFound{Entity: "User", ID: user.ID}
 	}
+	if err := checkUserVersion(existingUser, user.Version); err != nil {
+		return nil, err
+	}
 
 	existingUser.UpdatedAt = time.Now()
-	r.users[user.ID] = existingUser
+	existingUser.Version++
 
-	return existingUser, nil
+	return existingUser.Clone(), nil
 }
 
-// DeleteUser removes a user from the repository.
-func (r *InMemoryBetRepository) DeleteUser(userID string) error {
+// checkFunds reports why a user cannot pay amount out of their wallet in
+// currency: they have no such wallet, or too little in it.
+func checkFunds(user *model.User, currency money.Currency, amount money.Money) error {
+	balance, ok := user.Wallet(currency)
+	if !ok {
+		return &errors.ErrorBadRequest{Message: fmt.Sprintf("user %s has no %s wallet; amounts are not converted between currencies", user.ID, currency)}
+	}
+	if balance < amount {
+		return &errors.ErrorBadRequest{Message: fmt.Sprintf("insufficient balance: current %s %s, required %s", balance, currency, amount)}
+	}
+	return nil
+}
+
+// checkUserVersion reports a stale version; version 0 matches any.
+func checkUserVersion(user *model.User, version int64) error {
+	if version != 0 && version != user.Version {
+		return &errors.ErrorPreconditionFailed{Message: fmt.Sprintf("user %s is at version %d, not %d", user.ID, user.Version, version)}
+	}
+	return nil
+}
+
+// DeleteUser removes a user from the repository. A non-zero version must
+// match the stored user's.
+func (r *InMemoryBetRepository) DeleteUser(userID string, version int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.users[userID]; !exists {
+	user, exists := r.users[userID]
+	if !exists {
 		return &errors.ErrorNotFound{Entity: "User", ID: userID}
 	}
+	if err := checkUserVersion(user, version); err != nil {
+		return err
+	}
+	if r.hasPendingWithdrawals(userID) {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("user %s has pending withdrawals", userID)}
+	}
+	// Their open bets could not be settled without them.
+	for _, bet := range r.betsByUser[userID] {
+		if bet.Status == model.StatusPlaced {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("user %s has open bets", userID)}
+		}
+	}
+	// Close the wallets so a user later created with the same ID starts from zero.
+	for currency, balance := range user.Balances {
+		if balance.IsZero() {
+			continue
+		}
+		if err := r.post(ledger.AdjustmentEntry(userID, balance.Neg(), currency, "account closed")); err != nil {
+			return err
+		}
+	}
+	// Revoke their tokens too, so a user later created with the same ID
+	// does not inherit them.
+	for _, f := range r.freeBetsByUser[userID] {
+		if f.Status == model.FreeBetActive {
+			f.Status = model.FreeBetRevoked
+			f.Reason = "account closed"
+			f.ClosedAt = time.Now()
+		}
+	}
 	delete(r.users, userID)
 	return nil
 }
 
+// AdjustBalance applies a manual credit (positive amount) or debit (negative
+// amount) to a user's wallet in currency.
+func (r *InMemoryBetRepository) AdjustBalance(userID string, amount money.Money, currency money.Currency, reason string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[userID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "User", ID: userID}
+	}
+	if currency == "" {
+		currency = user.Currency
+	}
+	if balance, _ := user.Wallet(currency); (balance + amount).IsNegative() {
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("insufficient balance: current %s %s, adjustment %s", balance, currency, amount)}
+	}
+
+	if err := r.post(ledger.AdjustmentEntry(userID, amount, currency, reason)); err != nil {
+		return nil, err
+	}
+	return user.Clone(), nil
+}
+
+// ListTransactions returns the ledger history of a user's wallet, oldest first.
+func (r *InMemoryBetRepository) ListTransactions(userID string) ([]*model.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[userID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "User", ID: userID}
+	}
+	return ledger.Transactions(userID, r.journal.Entries(ledger.WalletAccounts(user)...)), nil
+}
+
 
 // Modify FindOrCreateUser to use the new CreateUser logic
 func (r *InMemoryBetRepository) FindOrCreateUser(userID string) (*model.User, error) {
+    // The clone is taken under the lock, as balance changes write the
+    // stored user in place.
     r.mu.RLock()
     user, exists := r.users[userID]
+    if exists {
+        user = user.Clone()
+    }
     r.mu.RUnlock() 
 
     if exists {
@@ -214,6 +747,9 @@ func (r *InMemoryBetRepository) FindOrCreateUser(userID string) (*model.User, er
             // User was created by another request, try getting it again
              r.mu.RLock()
              user, exists = r.users[userID]
+             if exists {
+                 user = user.Clone()
+             }
              r.mu.RUnlock()
              if exists {
                  return user, nil
@@ -228,7 +764,7 @@ func (r *InMemoryBetRepository) FindOrCreateUser(userID string) (*model.User, er
 
 
 // Modify GetUserBalance to rely on GetUser
-func (r *InMemoryBetRepository) GetUserBalance(userID string) (float64, error) {
+func (r *InMemoryBetRepository) GetUserBalance(userID string) (money.Money, error) {
 	user, err := r.GetUser(userID) // Use the GetUser method
 	if err != nil {
 		return 0, err
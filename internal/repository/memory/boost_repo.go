@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateBoost stores a new boost.
+func (r *InMemoryBetRepository) CreateBoost(boost *model.Boost) (*model.Boost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := boost.Clone()
+	stored.ID = uuid.New().String()
+	stored.Status = model.BoostActive
+	stored.CreatedAt = time.Now()
+
+	r.boosts[stored.ID] = stored
+	r.boostsByCreation = append(r.boostsByCreation, stored)
+	return stored.Clone(), nil
+}
+
+// GetBoost retrieves a boost by its ID.
+func (r *InMemoryBetRepository) GetBoost(boostID string) (*model.Boost, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	boost, exists := r.boosts[boostID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Boost", ID: boostID}
+	}
+	return boost.Clone(), nil
+}
+
+// ListBoosts returns every boost, oldest first.
+func (r *InMemoryBetRepository) ListBoosts() ([]*model.Boost, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	boosts := make([]*model.Boost, 0, len(r.boostsByCreation))
+	for _, b := range r.boostsByCreation {
+		boosts = append(boosts, b.Clone())
+	}
+	return boosts, nil
+}
+
+// CancelBoost cancels an active boost.
+func (r *InMemoryBetRepository) CancelBoost(boostID string) (*model.Boost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	boost, exists := r.boosts[boostID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Boost", ID: boostID}
+	}
+	if boost.Status != model.BoostActive {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("boost %s is already %s", boostID, boost.Status)}
+	}
+	boost.Status = model.BoostCancelled
+	boost.CancelledAt = time.Now()
+	return boost.Clone(), nil
+}
+
+// FindBetsByBoost returns the bets placed with a boost, oldest first.
+func (r *InMemoryBetRepository) FindBetsByBoost(boostID string) ([]*model.Bet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.boosts[boostID]; !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Boost", ID: boostID}
+	}
+	bets := make([]*model.Bet, 0, len(r.betsByBoost[boostID]))
+	for _, b := range r.betsByBoost[boostID] {
+		bets = append(bets, b.Clone())
+	}
+	return bets, nil
+}
+
+// checkBoost reports why the user cannot place bet with its boost: the
+// boost does not exist, was cancelled, or they have used it up. Callers
+// must hold the lock.
+func (r *InMemoryBetRepository) checkBoost(bet *model.Bet) error {
+	boost, exists := r.boosts[bet.BoostID]
+	if !exists {
+		return &errors.ErrorNotFound{Entity: "Boost", ID: bet.BoostID}
+	}
+	used := 0
+	for _, b := range r.betsByBoost[boost.ID] {
+		if b.UserID == bet.UserID {
+			used++
+		}
+	}
+	return checkBoostUsage(boost, used)
+}
+
+// checkBoostUsage reports a cancelled boost, or one a user has already
+// used the given number of times and may not use again.
+func checkBoostUsage(boost *model.Boost, used int) error {
+	if boost.Status != model.BoostActive {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("boost %s is %s", boost.ID, boost.Status)}
+	}
+	if used >= boost.MaxUsesPerUser {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("boost %s can be used %d time(s) per user and has been used %d", boost.ID, boost.MaxUsesPerUser, used)}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/memory"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		return memory.NewInMemoryBetRepository()
+	})
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/ledger"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CorrectSettlement reopens the bets an event's result decided and, for a
+// resettlement, settles them again, as one atomic step. As in SettleEvent,
+// every change is worked out on copies and validated before anything is
+// written.
+func (r *InMemoryBetRepository) CorrectSettlement(correction *model.SettlementCorrection, settle model.BetSettler) (*model.SettlementCorrection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	eventID := correction.EventID
+	if err := r.checkNoSettlementJob(eventID); err != nil {
+		return nil, err
+	}
+	event := r.events[eventID]
+	if event != nil {
+		if err := event.CheckCorrectable(); err != nil {
+			return nil, &errors.ErrorConflict{Message: err.Error()}
+		}
+	}
+	stored := correction.Clone()
+	stored.ID = uuid.New().String()
+	stored.CreatedAt = time.Now()
+	resettle := stored.Action == model.CorrectionResettle
+	if resettle {
+		stored.Settlement = &model.SettlementSummary{EventID: eventID}
+	}
+
+	var (
+		updates  []*model.Bet
+		entries  []*model.JournalEntry
+		awaiting bool
+	)
+	for _, bet := range r.betsByEvent[eventID] {
+		updated := bet.Clone()
+		reopened := updated.Unsettle(eventID)
+		if !reopened && !(resettle && bet.Status == model.StatusPlaced) {
+			if bet.AwaitsEvent(eventID) {
+				awaiting = true
+			}
+			continue
+		}
+		if _, userExists := r.users[bet.UserID]; !userExists {
+			return nil, fmt.Errorf("internal error: user %s not found for bet %s", bet.UserID, bet.ID)
+		}
+
+		if reopened {
+			var clawedBack money.Money
+			if updated.Status == model.StatusPlaced {
+				if entry := ledger.ClawbackEntry(bet, stored.Reason); entry != nil {
+					if err := ledger.Validate(entry); err != nil {
+						return nil, fmt.Errorf("internal error: %w", err)
+					}
+					entries = append(entries, entry)
+					clawedBack = ledger.WalletDelta(entry, bet.UserID).Neg()
+				}
+			}
+			stored.Reopen(updated, clawedBack)
+		}
+
+		if resettle && updated.Status == model.StatusPlaced {
+			openLegs := updated.OpenLegs()
+			if err := settle(updated); err != nil {
+				return nil, fmt.Errorf("failed to settle bet %s: %w", bet.ID, err)
+			}
+			if updated.Status == model.StatusPlaced {
+				if updated.OpenLegs() < openLegs {
+					stored.Settlement.Pending++
+				} else if !reopened {
+					// Nothing changed for it.
+					awaiting = awaiting || updated.AwaitsEvent(eventID)
+					continue
+				}
+			} else {
+				updated.SettledAt = stored.CreatedAt
+				var credited money.Money
+				if entry := ledger.SettlementEntry(updated); entry != nil {
+					if err := ledger.Validate(entry); err != nil {
+						return nil, fmt.Errorf("internal error: %w", err)
+					}
+					entries = append(entries, entry)
+					credited = ledger.WalletDelta(entry, updated.UserID)
+				}
+				stored.Settlement.Record(updated, credited)
+			}
+		}
+		if reopened {
+			// Events that are already SETTLED take no more results.
+			for _, id := range updated.AwaitedEvents() {
+				if other := r.events[id]; id != eventID && other != nil && other.Status == model.EventSettled {
+					return nil, &errors.ErrorConflict{Message: fmt.Sprintf("bet %s would be reopened waiting for event %s, which is already SETTLED", bet.ID, id)}
+				}
+			}
+		}
+		if updated.AwaitsEvent(eventID) {
+			awaiting = true
+		}
+		updates = append(updates, updated)
+	}
+
+	// Everything has been validated; apply the changes.
+	for _, entry := range entries {
+		if err := r.post(entry); err != nil {
+			return nil, err
+		}
+	}
+	for _, updated := range updates {
+		r.store(updated)
+	}
+	if event != nil {
+		status := model.EventClosed
+		if resettle && !awaiting {
+			status = model.EventSettled
+		}
+		if event.Status != status {
+			event.Status = status
+			event.StatusChangedAt = stored.CreatedAt
+		}
+		stored.EventStatus = event.Status
+		if stored.Settlement != nil {
+			stored.Settlement.EventStatus = event.Status
+		}
+	}
+	r.corrections[eventID] = append(r.corrections[eventID], stored)
+	return stored.Clone(), nil
+}
+
+// ListSettlementCorrections returns the corrections of an event in the
+// order they were made.
+func (r *InMemoryBetRepository) ListSettlementCorrections(eventID string) ([]*model.SettlementCorrection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	corrections := make([]*model.SettlementCorrection, 0, len(r.corrections[eventID]))
+	for _, correction := range r.corrections[eventID] {
+		corrections = append(corrections, correction.Clone())
+	}
+	return corrections, nil
+}
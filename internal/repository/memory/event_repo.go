@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateEvent stores a new event together with its markets and selections.
+// Missing market and selection IDs are generated.
+func (r *InMemoryBetRepository) CreateEvent(event *model.Event) (*model.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.events[event.ID]; exists {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("event with ID '%s' already exists", event.ID)}
+	}
+
+	stored := event.Clone()
+	stored.Status = model.EventOpen
+	stored.CreatedAt = time.Now()
+	stored.StatusChangedAt = stored.CreatedAt
+	if err := r.checkMarkets(stored.ID, stored.Markets); err != nil {
+		return nil, err
+	}
+
+	r.events[stored.ID] = stored
+	for _, m := range stored.Markets {
+		r.indexMarket(m)
+	}
+	return stored.Clone(), nil
+}
+
+// AddMarket adds a market with its selections to an existing event.
+func (r *InMemoryBetRepository) AddMarket(eventID string, market *model.Market) (*model.Market, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, exists := r.events[eventID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Event", ID: eventID}
+	}
+
+	stored := market.Clone()
+	if err := r.checkMarkets(eventID, []*model.Market{stored}); err != nil {
+		return nil, err
+	}
+
+	event.Markets = append(event.Markets, stored)
+	r.indexMarket(stored)
+	return stored.Clone(), nil
+}
+
+// GetEvent retrieves an event with its markets and selections.
+func (r *InMemoryBetRepository) GetEvent(eventID string) (*model.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	event, exists := r.events[eventID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Event", ID: eventID}
+	}
+	return event.Clone(), nil
+}
+
+// SetEventStatus moves an event to status. An event with an unfinished
+// settlement job cannot be cancelled.
+func (r *InMemoryBetRepository) SetEventStatus(eventID string, status model.EventStatus) (*model.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, exists := r.events[eventID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Event", ID: eventID}
+	}
+	if err := event.CheckTransition(status); err != nil {
+		return nil, &errors.ErrorConflict{Message: err.Error()}
+	}
+	if status == model.EventCancelled {
+		if err := r.checkNoSettlementJob(eventID); err != nil {
+			return nil, err
+		}
+	}
+	event.Status = status
+	event.StatusChangedAt = time.Now()
+	return event.Clone(), nil
+}
+
+// checkBettable reports a conflict if a known event of the bet does not
+// take bets. Callers must hold the lock.
+func (r *InMemoryBetRepository) checkBettable(bet *model.Bet) error {
+	for _, eventID := range bet.EventIDs() {
+		if event, exists := r.events[eventID]; exists {
+			if err := event.CheckBettable(); err != nil {
+				return &errors.ErrorConflict{Message: err.Error()}
+			}
+		}
+	}
+	return nil
+}
+
+// ListEvents retrieves all events ordered by ID.
+func (r *InMemoryBetRepository) ListEvents() ([]*model.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]*model.Event, 0, len(r.events))
+	for _, event := range r.events {
+		events = append(events, event.Clone())
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+// GetSelection retrieves a selection by its ID.
+func (r *InMemoryBetRepository) GetSelection(selectionID string) (*model.Selection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sel, exists := r.selections[selectionID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Selection", ID: selectionID}
+	}
+	copied := *sel
+	return &copied, nil
+}
+
+// UpdateSelectionOdds reprices a selection.
+func (r *InMemoryBetRepository) UpdateSelectionOdds(selectionID string, odds money.Odds) (*model.Selection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sel, exists := r.selections[selectionID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Selection", ID: selectionID}
+	}
+	sel.Odds = odds
+	copied := *sel
+	return &copied, nil
+}
+
+// checkMarkets assigns missing IDs and links markets and selections to
+// their event, rejecting IDs that are already in use. Callers must hold the
+// write lock.
+func (r *InMemoryBetRepository) checkMarkets(eventID string, markets []*model.Market) error {
+	seenMarkets := make(map[string]bool)
+	seenSelections := make(map[string]bool)
+	for _, m := range markets {
+		if m.ID == "" {
+			m.ID = uuid.New().String()
+		}
+		if _, exists := r.markets[m.ID]; exists || seenMarkets[m.ID] {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("market with ID '%s' already exists", m.ID)}
+		}
+		seenMarkets[m.ID] = true
+		m.EventID = eventID
+
+		for _, sel := range m.Selections {
+			if sel.ID == "" {
+				sel.ID = uuid.New().String()
+			}
+			if _, exists := r.selections[sel.ID]; exists || seenSelections[sel.ID] {
+				return &errors.ErrorConflict{Message: fmt.Sprintf("selection with ID '%s' already exists", sel.ID)}
+			}
+			seenSelections[sel.ID] = true
+			sel.MarketID = m.ID
+			sel.EventID = eventID
+		}
+	}
+	return nil
+}
+
+// indexMarket registers a stored market and its selections for lookup by ID.
+func (r *InMemoryBetRepository) indexMarket(m *model.Market) {
+	r.markets[m.ID] = m
+	for _, sel := range m.Selections {
+		r.selections[sel.ID] = sel
+	}
+}
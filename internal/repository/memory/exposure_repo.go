@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"sort"
+)
+
+// exposureKey identifies an outcome's exposure entry within its event.
+type exposureKey struct {
+	marketID, selectionID string
+	currency              money.Currency
+}
+
+// applyExposure moves the exposure entries a bet backs from its state before
+// a change to its state after it; before is nil for a new bet. Entries no
+// open bet backs any more are dropped. Callers must hold the write lock.
+func (r *InMemoryBetRepository) applyExposure(before, after *model.Bet) {
+	for _, change := range model.ExposureChange(before, after) {
+		entries := r.exposure[change.EventID]
+		if entries == nil {
+			entries = make(map[exposureKey]*model.ExposureEntry)
+			r.exposure[change.EventID] = entries
+		}
+		key := exposureKey{change.MarketID, change.SelectionID, change.Currency}
+		entry, exists := entries[key]
+		if !exists {
+			entry = &model.ExposureEntry{EventID: change.EventID, MarketID: change.MarketID, SelectionID: change.SelectionID, Currency: change.Currency}
+			entries[key] = entry
+		}
+		entry.Bets += change.Bets
+		entry.Stake += change.Stake
+		entry.Payout += change.Payout
+		if entry.Bets <= 0 {
+			delete(entries, key)
+			if len(entries) == 0 {
+				delete(r.exposure, change.EventID)
+			}
+		}
+	}
+}
+
+// EventExposure returns copies of the exposure entries of an event.
+func (r *InMemoryBetRepository) EventExposure(eventID string) ([]*model.ExposureEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.exposureOf(eventID), nil
+}
+
+// ListExposure returns copies of the exposure entries of every event.
+func (r *InMemoryBetRepository) ListExposure() ([]*model.ExposureEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	eventIDs := make([]string, 0, len(r.exposure))
+	for eventID := range r.exposure {
+		eventIDs = append(eventIDs, eventID)
+	}
+	sort.Strings(eventIDs)
+	entries := []*model.ExposureEntry{}
+	for _, eventID := range eventIDs {
+		entries = append(entries, r.exposureOf(eventID)...)
+	}
+	return entries, nil
+}
+
+// exposureOf copies the exposure entries of an event. Callers must hold the
+// lock.
+func (r *InMemoryBetRepository) exposureOf(eventID string) []*model.ExposureEntry {
+	entries := make([]*model.ExposureEntry, 0, len(r.exposure[eventID]))
+	for _, entry := range r.exposure[eventID] {
+		c := *entry
+		entries = append(entries, &c)
+	}
+	return entries
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateFreeBet grants a free-bet token to an existing user.
+func (r *InMemoryBetRepository) CreateFreeBet(freeBet *model.FreeBet) (*model.FreeBet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[freeBet.UserID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "User", ID: freeBet.UserID}
+	}
+
+	stored := freeBet.Clone()
+	if stored.Currency == "" {
+		stored.Currency = user.Currency
+	}
+	stored.ID = uuid.New().String()
+	stored.Status = model.FreeBetActive
+	stored.CreatedAt = time.Now()
+
+	r.freeBets[stored.ID] = stored
+	r.freeBetsByUser[stored.UserID] = append(r.freeBetsByUser[stored.UserID], stored)
+	return stored.Clone(), nil
+}
+
+// GetFreeBet retrieves a free-bet token by its ID.
+func (r *InMemoryBetRepository) GetFreeBet(freeBetID string) (*model.FreeBet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	freeBet, exists := r.freeBets[freeBetID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Free bet", ID: freeBetID}
+	}
+	return freeBet.Clone(), nil
+}
+
+// ListFreeBets returns a user's free-bet tokens, oldest first.
+func (r *InMemoryBetRepository) ListFreeBets(userID string) ([]*model.FreeBet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return nil, &errors.ErrorNotFound{Entity: "User", ID: userID}
+	}
+	freeBets := []*model.FreeBet{}
+	for _, f := range r.freeBetsByUser[userID] {
+		freeBets = append(freeBets, f.Clone())
+	}
+	return freeBets, nil
+}
+
+// RevokeFreeBet revokes one of a user's active tokens.
+func (r *InMemoryBetRepository) RevokeFreeBet(userID, freeBetID, reason string) (*model.FreeBet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	freeBet, exists := r.freeBets[freeBetID]
+	if !exists || freeBet.UserID != userID {
+		return nil, &errors.ErrorNotFound{Entity: "Free bet", ID: freeBetID}
+	}
+	now := time.Now()
+	if err := checkFreeBetActive(freeBet, now); err != nil {
+		return nil, err
+	}
+	freeBet.Status = model.FreeBetRevoked
+	freeBet.Reason = reason
+	freeBet.ClosedAt = now
+	return freeBet.Clone(), nil
+}
+
+// ExpireFreeBets marks every active token that lapsed by now as expired and
+// returns them in the order they expired.
+func (r *InMemoryBetRepository) ExpireFreeBets(now time.Time) ([]*model.FreeBet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := []*model.FreeBet{}
+	for _, f := range r.freeBets {
+		if f.Lapsed(now) {
+			f.Refresh(now)
+			expired = append(expired, f.Clone())
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		if !expired[i].ExpiresAt.Equal(expired[j].ExpiresAt) {
+			return expired[i].ExpiresAt.Before(expired[j].ExpiresAt)
+		}
+		return expired[i].ID < expired[j].ID
+	})
+	return expired, nil
+}
+
+// checkFreeBetActive reports why a token can no longer be used or revoked.
+func checkFreeBetActive(freeBet *model.FreeBet, now time.Time) error {
+	if freeBet.Lapsed(now) {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("free bet %s expired at %s", freeBet.ID, freeBet.ExpiresAt.Format(time.RFC3339))}
+	}
+	if freeBet.Status != model.FreeBetActive {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("free bet %s is already %s", freeBet.ID, freeBet.Status)}
+	}
+	return nil
+}
+
+// checkFreeBet reports why a token, nil if it does not exist, cannot stake
+// bet.
+func checkFreeBet(freeBet *model.FreeBet, bet *model.Bet, now time.Time) error {
+	if freeBet == nil || freeBet.UserID != bet.UserID {
+		return &errors.ErrorNotFound{Entity: "Free bet", ID: bet.FreeBetID}
+	}
+	if err := checkFreeBetActive(freeBet, now); err != nil {
+		return err
+	}
+	if err := freeBet.CheckEligible(bet); err != nil {
+		return &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"time"
+)
+
+// idempotencySweepInterval is how often expired idempotency records are
+// purged in bulk; an expired record is also replaced when its key is reused.
+const idempotencySweepInterval = time.Minute
+
+// ClaimIdempotencyKey stores a new record unless a live one holds its key,
+// which is returned instead.
+func (r *InMemoryBetRepository) ClaimIdempotencyKey(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.After(r.nextIdempotencySweep) {
+		for key, stored := range r.idempotency {
+			if stored.Expired(now) {
+				delete(r.idempotency, key)
+			}
+		}
+		r.nextIdempotencySweep = now.Add(idempotencySweepInterval)
+	}
+
+	if stored, exists := r.idempotency[record.Key]; exists && !stored.Expired(now) {
+		return stored.Clone(), nil
+	}
+	r.idempotency[record.Key] = record.Clone()
+	return nil, nil
+}
+
+// CompleteIdempotencyKey stores the response and expiry of record over the
+// claim it was made with.
+func (r *InMemoryBetRepository) CompleteIdempotencyKey(record *model.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.idempotency[record.Key]
+	if !exists || !stored.CreatedAt.Equal(record.CreatedAt) {
+		return &errors.ErrorNotFound{Entity: "Idempotency key", ID: record.Key}
+	}
+	completed := record.Clone()
+	completed.Fingerprint = stored.Fingerprint
+	r.idempotency[record.Key] = completed
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the claim record was made with, if it still
+// holds its key.
+func (r *InMemoryBetRepository) ReleaseIdempotencyKey(record *model.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, exists := r.idempotency[record.Key]; exists && stored.CreatedAt.Equal(record.CreatedAt) {
+		delete(r.idempotency, record.Key)
+	}
+	return nil
+}
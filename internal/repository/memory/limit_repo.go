@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"sort"
+	"time"
+)
+
+// limitsKey identifies the limits of a scope, and names them when missing.
+func limitsKey(scope model.LimitScope, scopeID string) string {
+	if scopeID == "" {
+		return string(scope)
+	}
+	return string(scope) + ":" + scopeID
+}
+
+// SaveLimits stores the limits of a scope, replacing any it had.
+func (r *InMemoryBetRepository) SaveLimits(limits *model.Limits) (*model.Limits, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := limits.Clone()
+	stored.UpdatedAt = time.Now()
+	r.limits[limitsKey(stored.Scope, stored.ScopeID)] = stored
+	return stored.Clone(), nil
+}
+
+// GetLimits retrieves the limits of a scope.
+func (r *InMemoryBetRepository) GetLimits(scope model.LimitScope, scopeID string) (*model.Limits, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key := limitsKey(scope, scopeID)
+	limits, exists := r.limits[key]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Limits", ID: key}
+	}
+	return limits.Clone(), nil
+}
+
+// ListLimits returns every set of limits, global first, then by scope and ID.
+func (r *InMemoryBetRepository) ListLimits() ([]*model.Limits, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]*model.Limits, 0, len(r.limits))
+	for _, l := range r.limits {
+		all = append(all, l.Clone())
+	}
+	sort.Slice(all, func(i, j int) bool {
+		a, b := all[i], all[j]
+		if (a.Scope == model.LimitScopeGlobal) != (b.Scope == model.LimitScopeGlobal) {
+			return a.Scope == model.LimitScopeGlobal
+		}
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		return a.ScopeID < b.ScopeID
+	})
+	return all, nil
+}
+
+// DeleteLimits removes the limits of a scope.
+func (r *InMemoryBetRepository) DeleteLimits(scope model.LimitScope, scopeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := limitsKey(scope, scopeID)
+	if _, exists := r.limits[key]; !exists {
+		return &errors.ErrorNotFound{Entity: "Limits", ID: key}
+	}
+	delete(r.limits, key)
+	return nil
+}
+
+// placementLiability measures the liability a new bet adds to: the open
+// bets on each of its events and the user's bets placed today. Callers
+// must hold the lock.
+func (r *InMemoryBetRepository) placementLiability(bet *model.Bet, now time.Time) *model.PlacementLiability {
+	eventBets := make(map[string][]*model.Bet)
+	for _, eventID := range bet.EventIDs() {
+		placed := []*model.Bet{}
+		for _, b := range r.betsByEvent[eventID] {
+			if b.Status == model.StatusPlaced {
+				placed = append(placed, b)
+			}
+		}
+		eventBets[eventID] = placed
+	}
+	// A user's bets are held in the order they were placed.
+	day := model.LimitDay(now)
+	var today []*model.Bet
+	userBets := r.betsByUser[bet.UserID]
+	for i := len(userBets) - 1; i >= 0 && !userBets[i].CreatedAt.Before(day); i-- {
+		today = append(today, userBets[i])
+	}
+	return model.MeasureLiability(eventBets, today)
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+// TestDailyLiabilityWindow checks that the daily liability counts the bets
+// placed from LimitDay onwards, a bet at midnight included.
+func TestDailyLiabilityWindow(t *testing.T) {
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	placedAt := func(at time.Time, stake string) *model.Bet {
+		return &model.Bet{UserID: "alice", EventID: "e1", Odds: money.MustParseOdds("2"), Amount: money.MustParse(stake),
+			Currency: money.EUR, Status: model.StatusPlaced, CreatedAt: at}
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"first instant of the day", day, "20.00"},
+		{"during the day", day.Add(12 * time.Hour), "60.00"},
+		{"last instant of the day", day.Add(24*time.Hour - time.Nanosecond), "60.00"},
+		{"next day", day.Add(24 * time.Hour), "0.00"},
+	}
+	for _, tc := range tests {
+		r := NewInMemoryBetRepository()
+		// Bets are held in the order they were placed; only those up to
+		// now have been placed.
+		for _, bet := range []*model.Bet{
+			placedAt(day.Add(-time.Nanosecond), "10.00"),
+			placedAt(day, "20.00"),
+			placedAt(day.Add(time.Hour), "40.00"),
+		} {
+			if !bet.CreatedAt.After(tc.now) {
+				r.betsByUser["alice"] = append(r.betsByUser["alice"], bet)
+			}
+		}
+		liability := r.placementLiability(placedAt(tc.now, "1.00"), tc.now)
+		if got := liability.Daily[money.EUR]; got != money.MustParse(tc.want) {
+			t.Errorf("%s: daily liability = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
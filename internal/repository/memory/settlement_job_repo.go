@@ -0,0 +1,220 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateSettlementJob stores a new QUEUED job.
+func (r *InMemoryBetRepository) CreateSettlementJob(job *model.SettlementJob) (*model.SettlementJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkNoSettlementJob(job.EventID); err != nil {
+		return nil, err
+	}
+	stored := job.Clone()
+	stored.ID = uuid.New().String()
+	stored.State = model.JobQueued
+	stored.CreatedAt = time.Now()
+	stored.UpdatedAt = stored.CreatedAt
+
+	r.settlementJobs[stored.ID] = stored
+	r.settlementJobsByCreation = append(r.settlementJobsByCreation, stored)
+	return stored.Clone(), nil
+}
+
+// checkNoSettlementJob reports a conflict if the event has an unfinished
+// settlement job, which settles its bets alone until it finishes. The caller
+// holds the lock.
+func (r *InMemoryBetRepository) checkNoSettlementJob(eventID string) error {
+	for _, job := range r.settlementJobsByCreation {
+		if job.EventID == eventID && !job.State.Finished() {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("event %s has settlement job %s %s", eventID, job.ID, job.State)}
+		}
+	}
+	return nil
+}
+
+// GetSettlementJob retrieves a settlement job by its ID.
+func (r *InMemoryBetRepository) GetSettlementJob(jobID string) (*model.SettlementJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.settlementJobs[jobID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Settlement job", ID: jobID}
+	}
+	return job.Clone(), nil
+}
+
+// ListSettlementJobs returns the jobs in any of states, oldest first.
+func (r *InMemoryBetRepository) ListSettlementJobs(states ...model.SettlementJobState) ([]*model.SettlementJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := []*model.SettlementJob{}
+	for _, job := range r.settlementJobsByCreation {
+		if len(states) == 0 || slices.Contains(states, job.State) {
+			jobs = append(jobs, job.Clone())
+		}
+	}
+	return jobs, nil
+}
+
+// ClaimSettlementJob starts the oldest QUEUED job.
+func (r *InMemoryBetRepository) ClaimSettlementJob() (*model.SettlementJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, job := range r.settlementJobsByCreation {
+		if job.State != model.JobQueued {
+			continue
+		}
+		total := 0
+		for _, bet := range r.betsByEvent[job.EventID] {
+			if bet.Status == model.StatusPlaced {
+				total++
+			}
+		}
+		job.State = model.JobRunning
+		job.Total = total
+		job.Summary = &model.SettlementSummary{EventID: job.EventID}
+		job.StartedAt = time.Now()
+		job.UpdatedAt = job.StartedAt
+		return job.Clone(), nil
+	}
+	return nil, nil
+}
+
+// SettleJobBatch settles the next batch of a running job's bets under the
+// write lock, which is released between batches. The job's Cursor is the
+// position in the event's bets, in placement order, to resume from.
+func (r *InMemoryBetRepository) SettleJobBatch(jobID string, settle model.BetSettler, limit int) (*model.SettlementJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, err := r.unfinishedSettlementJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.State != model.JobRunning {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("settlement job %s is %s, not RUNNING", jobID, job.State)}
+	}
+	event := r.events[job.EventID]
+	if event != nil {
+		if err := event.CheckSettleable(); err != nil {
+			// The event moved on under the job; it settles nothing more.
+			now := time.Now()
+			job.Errors = append(job.Errors, err.Error())
+			job.State = model.JobFailed
+			job.FinishedAt = now
+			job.UpdatedAt = now
+			return job.Clone(), nil
+		}
+	}
+
+	onEvent := r.betsByEvent[job.EventID]
+	next := int(job.Cursor)
+	var batch []*model.Bet
+	for next < len(onEvent) && len(batch) < limit {
+		if onEvent[next].Status == model.StatusPlaced {
+			batch = append(batch, onEvent[next])
+		}
+		next++
+	}
+
+	updated := job.Clone()
+	now := time.Now()
+	updated.UpdatedAt = now
+	if len(batch) == 0 {
+		// Every bet has been through; finish as SettleEvent would.
+		awaiting := slices.ContainsFunc(onEvent, func(bet *model.Bet) bool {
+			return bet.Status == model.StatusPlaced && bet.AwaitsEvent(job.EventID)
+		})
+		if event != nil {
+			if status := event.StatusAfterSettlement(awaiting); status != event.Status {
+				event.Status = status
+				event.StatusChangedAt = now
+			}
+			updated.Summary.EventStatus = event.Status
+		}
+		updated.State = model.JobCompleted
+		updated.FinishedAt = now
+		*job = *updated
+		return job.Clone(), nil
+	}
+
+	settled, err := r.settleBets(job.EventID, batch, settle, updated.Summary, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.applySettlement(settled); err != nil {
+		return nil, err
+	}
+	updated.Processed += len(batch)
+	updated.Cursor = int64(next)
+	*job = *updated
+	return job.Clone(), nil
+}
+
+// AddSettlementJobError records an error on an unfinished job.
+func (r *InMemoryBetRepository) AddSettlementJobError(jobID, message string) (*model.SettlementJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, err := r.unfinishedSettlementJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	job.Errors = append(job.Errors, message)
+	job.UpdatedAt = time.Now()
+	return job.Clone(), nil
+}
+
+// FailSettlementJob records an error on an unfinished job and fails it.
+func (r *InMemoryBetRepository) FailSettlementJob(jobID, message string) (*model.SettlementJob, error) {
+	return r.finishSettlementJob(jobID, model.JobFailed, message)
+}
+
+// CancelSettlementJob cancels an unfinished job.
+func (r *InMemoryBetRepository) CancelSettlementJob(jobID string) (*model.SettlementJob, error) {
+	return r.finishSettlementJob(jobID, model.JobCancelled, "")
+}
+
+// finishSettlementJob stops an unfinished job in state, recording message
+// as an error unless it is empty.
+func (r *InMemoryBetRepository) finishSettlementJob(jobID string, state model.SettlementJobState, message string) (*model.SettlementJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, err := r.unfinishedSettlementJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if message != "" {
+		job.Errors = append(job.Errors, message)
+	}
+	job.State = state
+	job.FinishedAt = time.Now()
+	job.UpdatedAt = job.FinishedAt
+	return job.Clone(), nil
+}
+
+// unfinishedSettlementJob returns the stored job, which must not have
+// finished. The caller holds the lock.
+func (r *InMemoryBetRepository) unfinishedSettlementJob(jobID string) (*model.SettlementJob, error) {
+	job, exists := r.settlementJobs[jobID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "Settlement job", ID: jobID}
+	}
+	if job.State.Finished() {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("settlement job %s has already finished as %s", jobID, job.State)}
+	}
+	return job, nil
+}
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/ledger"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateWalletOperation records a deposit or withdrawal and posts its
+// journal entry under the same lock.
+func (r *InMemoryBetRepository) CreateWalletOperation(op *model.WalletOperation) (*model.WalletOperation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[op.UserID]
+	if !exists {
+		return nil, &errors.ErrorNotFound{Entity: "User", ID: op.UserID}
+	}
+	if _, used := r.walletOpsByRef[op.Reference]; used {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("reference '%s' has already been used", op.Reference)}
+	}
+
+	stored := op.Clone()
+	if stored.Currency == "" {
+		stored.Currency = user.Currency
+	}
+	stored.ID = uuid.New().String()
+	stored.CreatedAt = time.Now()
+	var entry *model.JournalEntry
+	switch stored.Type {
+	case model.OperationDeposit:
+		stored.Status = model.OperationCompleted
+		stored.ResolvedAt = &stored.CreatedAt
+		entry = ledger.DepositEntry(stored)
+	case model.OperationWithdrawal:
+		if err := checkFunds(user, stored.Currency, stored.Amount); err != nil {
+			return nil, err
+		}
+		stored.Status = model.OperationPending
+		entry = ledger.WithdrawalEntry(stored)
+	default:
+		return nil, fmt.Errorf("unknown wallet operation type %q", stored.Type)
+	}
+	if err := r.post(entry); err != nil {
+		return nil, err
+	}
+
+	r.walletOps[stored.ID] = stored
+	r.walletOpsByRef[stored.Reference] = stored
+	r.walletOpsByUser[stored.UserID] = append(r.walletOpsByUser[stored.UserID], stored)
+	return stored.Clone(), nil
+}
+
+// ResolveWithdrawal approves or rejects a pending withdrawal, moving its
+// reserved amount under the same lock.
+func (r *InMemoryBetRepository) ResolveWithdrawal(userID, operationID string, status model.WalletOperationStatus, reason string) (*model.WalletOperation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	op, exists := r.walletOps[operationID]
+	if !exists || op.UserID != userID || op.Type != model.OperationWithdrawal {
+		return nil, &errors.ErrorNotFound{Entity: "Withdrawal", ID: operationID}
+	}
+	if op.Status != model.OperationPending {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("withdrawal %s is already %s", operationID, op.Status)}
+	}
+
+	resolved := op.Clone()
+	resolved.Status = status
+	resolved.Reason = reason
+	resolvedAt := time.Now()
+	resolved.ResolvedAt = &resolvedAt
+	var entry *model.JournalEntry
+	switch status {
+	case model.OperationApproved:
+		entry = ledger.WithdrawalPaidEntry(resolved)
+	case model.OperationRejected:
+		entry = ledger.WithdrawalReversalEntry(resolved)
+	default:
+		return nil, fmt.Errorf("cannot resolve a withdrawal as %s", status)
+	}
+	if err := r.post(entry); err != nil {
+		return nil, err
+	}
+
+	*op = *resolved
+	return resolved, nil
+}
+
+// ListWalletOperations returns a user's operations of one type, oldest first.
+func (r *InMemoryBetRepository) ListWalletOperations(userID string, opType model.WalletOperationType) ([]*model.WalletOperation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.users[userID]; !exists {
+		return nil, &errors.ErrorNotFound{Entity: "User", ID: userID}
+	}
+	ops := []*model.WalletOperation{}
+	for _, op := range r.walletOpsByUser[userID] {
+		if op.Type == opType {
+			ops = append(ops, op.Clone())
+		}
+	}
+	return ops, nil
+}
+
+// hasPendingWithdrawals reports whether a user has funds reserved for
+// withdrawal. Callers must hold the lock.
+func (r *InMemoryBetRepository) hasPendingWithdrawals(userID string) bool {
+	for _, op := range r.walletOpsByUser[userID] {
+		if op.Type == model.OperationWithdrawal && op.Status == model.OperationPending {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,3357 @@
+// Package repotest is a conformance suite for implementations of the
+// service layer's repository interfaces. Every storage backend must pass it,
+// typically from a test in its own package:
+//
+//	func TestConformance(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repotest.Repository {
+//			return memory.NewInMemoryBetRepository()
+//		})
+//	}
+package repotest
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/service"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Repository is the full set of operations a storage backend provides.
+type Repository interface {
+	service.BetRepository
+	service.UserRepository
+	service.EventRepository
+	service.IdempotencyRepository
+	service.WalletRepository
+	service.FreeBetRepository
+	service.BoostRepository
+	service.LimitRepository
+	service.SettlementJobRepository
+}
+
+// Factory returns a new, empty repository for a single test.
+type Factory func(t *testing.T) Repository
+
+// Run executes every conformance test against repositories built by newRepo.
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo Repository)
+	}{
+		{"CreateUser", testCreateUser},
+		{"CreateUserConflict", testCreateUserConflict},
+		{"GetUserNotFound", testGetUserNotFound},
+		{"ListUsers", testListUsers},
+		{"DeleteUser", testDeleteUser},
+		{"FindOrCreateUser", testFindOrCreateUser},
+		{"FindOrCreateUserDuringBalanceChanges", testFindOrCreateUserDuringBalanceChanges},
+		{"UserVersions", testUserVersions},
+		{"StaleUserVersion", testStaleUserVersion},
+		{"ReturnsCopies", testReturnsCopies},
+		{"PlaceBetDebitsBalance", testPlaceBetDebitsBalance},
+		{"PlaceBetInsufficientBalance", testPlaceBetInsufficientBalance},
+		{"PlaceBetUnknownUser", testPlaceBetUnknownUser},
+		{"FindBetsByEventOnlyPlaced", testFindBetsByEventOnlyPlaced},
+		{"FindBetsFilters", testFindBetsFilters},
+		{"FindBetsPaginates", testFindBetsPaginates},
+		{"FindBetsPagesAfterUpdates", testFindBetsPagesAfterUpdates},
+		{"UserBetStats", testUserBetStats},
+		{"UpdateBetWonCreditsPayout", testUpdateBetWonCreditsPayout},
+		{"UpdateBetLostKeepsBalance", testUpdateBetLostKeepsBalance},
+		{"UpdateBetAlreadySettled", testUpdateBetAlreadySettled},
+		{"UpdateBetNotFound", testUpdateBetNotFound},
+		{"BetVersions", testBetVersions},
+		{"SettleEvent", testSettleEvent},
+		{"SettleEventRollsBackOnError", testSettleEventRollsBackOnError},
+		{"SettleEventSkipsUndecided", testSettleEventSkipsUndecided},
+		{"SettleEventVoidRefundsStake", testSettleEventVoidRefundsStake},
+		{"AccumulatorSettlesAcrossEvents", testAccumulatorSettlesAcrossEvents},
+		{"AccumulatorLosingLeg", testAccumulatorLosingLeg},
+		{"SystemLinesSettleIndependently", testSystemLinesSettleIndependently},
+		{"CreateEvent", testCreateEvent},
+		{"CreateEventConflicts", testCreateEventConflicts},
+		{"AddMarket", testAddMarket},
+		{"PlaceBetOnSelection", testPlaceBetOnSelection},
+		{"EventStatusTransitions", testEventStatusTransitions},
+		{"PlaceBetNeedsOpenEvent", testPlaceBetNeedsOpenEvent},
+		{"SettleEventNeedsClosedEvent", testSettleEventNeedsClosedEvent},
+		{"VoidEventNeedsCancelledEvent", testVoidEventNeedsCancelledEvent},
+		{"PreviewSettlementChangesNothing", testPreviewSettlementChangesNothing},
+		{"SettlementJobLifecycle", testSettlementJobLifecycle},
+		{"CancelSettlementJob", testCancelSettlementJob},
+		{"SettlementJobBlocksSettlement", testSettlementJobBlocksSettlement},
+		{"CancelEventMidJob", testCancelEventMidJob},
+		{"UnsettleEventReopensBets", testUnsettleEventReopensBets},
+		{"ResettleEventCorrectsResult", testResettleEventCorrectsResult},
+		{"ResettleAccumulatorLeg", testResettleAccumulatorLeg},
+		{"CorrectionCannotReopenOnSettledEvent", testCorrectionCannotReopenOnSettledEvent},
+		{"CashOutBet", testCashOutBet},
+		{"CashOutRejectsStaleQuote", testCashOutRejectsStaleQuote},
+		{"PartialCashOut", testPartialCashOut},
+		{"CashOutNeedsOpenEvent", testCashOutNeedsOpenEvent},
+		{"IdempotencyKeyLifecycle", testIdempotencyKeyLifecycle},
+		{"IdempotencyKeyExpires", testIdempotencyKeyExpires},
+		{"IdempotencyLeaseLapses", testIdempotencyLeaseLapses},
+		{"ConcurrentIdempotencyClaims", testConcurrentIdempotencyClaims},
+		{"AdjustBalance", testAdjustBalance},
+		{"Deposit", testDeposit},
+		{"WithdrawalApproved", testWithdrawalApproved},
+		{"WithdrawalRejected", testWithdrawalRejected},
+		{"WithdrawalInsufficientBalance", testWithdrawalInsufficientBalance},
+		{"WalletReferencesUnique", testWalletReferencesUnique},
+		{"DeleteUserWithPendingWithdrawal", testDeleteUserWithPendingWithdrawal},
+		{"DeleteUserWithOpenBets", testDeleteUserWithOpenBets},
+		{"CreateUserInCurrency", testCreateUserInCurrency},
+		{"CurrencyWallets", testCurrencyWallets},
+		{"StakeNeedsWalletInCurrency", testStakeNeedsWalletInCurrency},
+		{"SettlementTotalsPerCurrency", testSettlementTotalsPerCurrency},
+		{"FreeBetLifecycle", testFreeBetLifecycle},
+		{"FreeBetWinPaysWinningsOnly", testFreeBetWinPaysWinningsOnly},
+		{"FreeBetVoidPaysNothing", testFreeBetVoidPaysNothing},
+		{"FreeBetEligibility", testFreeBetEligibility},
+		{"ExpireFreeBets", testExpireFreeBets},
+		{"BoostLifecycle", testBoostLifecycle},
+		{"BoostUsageLimit", testBoostUsageLimit},
+		{"BoostCost", testBoostCost},
+		{"LimitsLifecycle", testLimitsLifecycle},
+		{"PlaceBetMeasuresLiability", testPlaceBetMeasuresLiability},
+		{"PlaceBetWithinLimits", testPlaceBetWithinLimits},
+		{"ExposureTracksOpenBets", testExposureTracksOpenBets},
+		{"ExposureTracksAccumulatorLegs", testExposureTracksAccumulatorLegs},
+		{"TransactionsExplainBalance", testTransactionsExplainBalance},
+		{"ConcurrentPlaceBet", testConcurrentPlaceBet},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newRepo(t))
+		})
+	}
+}
+
+// --- helpers ---
+
+func mustCreateUser(t *testing.T, repo Repository, id string, balance money.Money) *model.User {
+	t.Helper()
+	user, err := repo.CreateUser(&model.User{ID: id, Balance: balance})
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", id, err)
+	}
+	return user
+}
+
+func mustPlaceBet(t *testing.T, repo Repository, userID, eventID, odds, amount string) *model.Bet {
+	t.Helper()
+	bet, err := repo.PlaceBet(&model.Bet{
+		UserID:  userID,
+		EventID: eventID,
+		Odds:    money.MustParseOdds(odds),
+		Amount:  money.MustParse(amount),
+	}, nil)
+	if err != nil {
+		t.Fatalf("PlaceBet(%s, %s): %v", userID, eventID, err)
+	}
+	return bet
+}
+
+func assertBalance(t *testing.T, repo Repository, userID, want string) {
+	t.Helper()
+	got, err := repo.GetUserBalance(userID)
+	if err != nil {
+		t.Fatalf("GetUserBalance(%s): %v", userID, err)
+	}
+	if got != money.MustParse(want) {
+		t.Fatalf("balance of %s = %s, want %s", userID, got, want)
+	}
+}
+
+// eurTotals returns what a settlement credited in EUR, the default
+// currency test users hold.
+func eurTotals(summary *model.SettlementSummary) model.SettlementTotals {
+	if totals, ok := summary.Totals[money.EUR]; ok {
+		return *totals
+	}
+	return model.SettlementTotals{}
+}
+
+func mustGrantFreeBet(t *testing.T, repo Repository, freeBet *model.FreeBet) *model.FreeBet {
+	t.Helper()
+	if freeBet.ExpiresAt.IsZero() {
+		freeBet.ExpiresAt = time.Now().Add(time.Hour)
+	}
+	granted, err := repo.CreateFreeBet(freeBet)
+	if err != nil {
+		t.Fatalf("CreateFreeBet(%s): %v", freeBet.UserID, err)
+	}
+	return granted
+}
+
+func mustCreateBoost(t *testing.T, repo Repository, eventID string, maxUses int) *model.Boost {
+	t.Helper()
+	boost, err := repo.CreateBoost(&model.Boost{
+		Name:           "boost on " + eventID,
+		EventID:        eventID,
+		IncreaseBps:    2500,
+		MaxStake:       money.MustParse("50.00"),
+		Currency:       money.EUR,
+		MaxUsesPerUser: maxUses,
+		StartsAt:       time.Now().Add(-time.Minute),
+		EndsAt:         time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateBoost(%s): %v", eventID, err)
+	}
+	return boost
+}
+
+// placeBoosted places a bet priced by boost at odds, as the service would.
+func placeBoosted(repo Repository, boost *model.Boost, userID, odds, amount string) (*model.Bet, error) {
+	original := money.MustParseOdds(odds)
+	boosted, err := boost.Price(original)
+	if err != nil {
+		return nil, err
+	}
+	return repo.PlaceBet(&model.Bet{
+		UserID:       userID,
+		EventID:      boost.EventID,
+		Odds:         boosted,
+		OriginalOdds: original,
+		Amount:       money.MustParse(amount),
+		BoostID:      boost.ID,
+	}, nil)
+}
+
+func settle(t *testing.T, repo Repository, bet *model.Bet, status model.BetStatus) error {
+	t.Helper()
+	update := *bet
+	update.Status = status
+	return repo.UpdateBet(&update)
+}
+
+// --- users ---
+
+func testCreateUser(t *testing.T, repo Repository) {
+	user := mustCreateUser(t, repo, "alice", money.Zero)
+	if user.ID != "alice" {
+		t.Fatalf("ID = %q, want alice", user.ID)
+	}
+	if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Fatalf("timestamps not set: %+v", user)
+	}
+	if !user.Balance.IsPositive() {
+		t.Fatalf("new user without balance should get the default opening balance, got %s", user.Balance)
+	}
+
+	mustCreateUser(t, repo, "bob", money.MustParse("12.34"))
+	assertBalance(t, repo, "bob", "12.34")
+}
+
+func testCreateUserConflict(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.Zero)
+	_, err := repo.CreateUser(&model.User{ID: "alice"})
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("duplicate CreateUser error = %v, want *errors.ErrorConflict", err)
+	}
+}
+
+func testGetUserNotFound(t *testing.T, repo Repository) {
+	_, err := repo.GetUser("nobody")
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("GetUser error = %v, want *errors.ErrorNotFound", err)
+	}
+	_, err = repo.GetUserBalance("nobody")
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("GetUserBalance error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+func testListUsers(t *testing.T, repo Repository) {
+	users, err := repo.ListUsers()
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("new repository has %d users", len(users))
+	}
+	mustCreateUser(t, repo, "alice", money.Zero)
+	mustCreateUser(t, repo, "bob", money.Zero)
+	users, err = repo.ListUsers()
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("ListUsers returned %d users, want 2", len(users))
+	}
+}
+
+func testDeleteUser(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("50.00"))
+	if err := repo.DeleteUser("alice", 0); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := repo.GetUser("alice"); err == nil {
+		t.Fatal("deleted user is still returned")
+	}
+	if _, ok := repo.DeleteUser("alice", 0).(*errors.ErrorNotFound); !ok {
+		t.Fatal("deleting a missing user should return *errors.ErrorNotFound")
+	}
+
+	// A user re-created with the same ID starts from their new opening balance only.
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	assertBalance(t, repo, "alice", "10.00")
+}
+
+func testFindOrCreateUser(t *testing.T, repo Repository) {
+	created, err := repo.FindOrCreateUser("alice")
+	if err != nil {
+		t.Fatalf("FindOrCreateUser: %v", err)
+	}
+	found, err := repo.FindOrCreateUser("alice")
+	if err != nil {
+		t.Fatalf("FindOrCreateUser: %v", err)
+	}
+	if created.ID != found.ID || !found.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("FindOrCreateUser created a second user: %+v vs %+v", created, found)
+	}
+}
+
+func testFindOrCreateUserDuringBalanceChanges(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	const n = 500
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		<-start
+		for i := 0; i < n; i++ {
+			if _, err := repo.AdjustBalance("alice", money.MustParse("1.00"), "", "top-up"); err != nil {
+				t.Errorf("AdjustBalance: %v", err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		<-start
+		for i := 0; i < n; i++ {
+			user, err := repo.FindOrCreateUser("alice")
+			if err != nil {
+				t.Errorf("FindOrCreateUser: %v", err)
+				return
+			}
+			if balance, _ := user.Wallet(user.Currency); balance != user.Balance {
+				t.Errorf("FindOrCreateUser returned wallet %s and balance %s", balance, user.Balance)
+				return
+			}
+		}
+	}()
+	close(start)
+	wg.Wait()
+
+	user, err := repo.FindOrCreateUser("alice")
+	if err != nil {
+		t.Fatalf("FindOrCreateUser: %v", err)
+	}
+	if want := money.MustParse("510.00"); user.Balance != want {
+		t.Fatalf("balance = %s, want %s", user.Balance, want)
+	}
+}
+
+// --- bets ---
+
+func assertUserVersion(t *testing.T, repo Repository, userID string, want int64) {
+	t.Helper()
+	user, err := repo.GetUser(userID)
+	if err != nil {
+		t.Fatalf("GetUser(%s): %v", userID, err)
+	}
+	if user.Version != want {
+		t.Fatalf("version of %s = %d, want %d", userID, user.Version, want)
+	}
+}
+
+func testUserVersions(t *testing.T, repo Repository) {
+	user := mustCreateUser(t, repo, "alice", money.MustParse("50.00"))
+	if user.Version != 1 {
+		t.Fatalf("created user version = %d, want 1", user.Version)
+	}
+	updated, err := repo.UpdateUser(user)
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Fatalf("updated user version = %d, want 2", updated.Version)
+	}
+
+	// Balance changes are changes to the user too.
+	mustPlaceBet(t, repo, "alice", "e1", "2.00", "10.00")
+	assertUserVersion(t, repo, "alice", 3)
+	adjusted, err := repo.AdjustBalance("alice", money.MustParse("1.00"), "", "goodwill")
+	if err != nil {
+		t.Fatalf("AdjustBalance: %v", err)
+	}
+	if adjusted.Version != 4 {
+		t.Fatalf("adjusted user version = %d, want 4", adjusted.Version)
+	}
+
+	// Version 0 updates unconditionally.
+	if _, err := repo.UpdateUser(&model.User{ID: "alice"}); err != nil {
+		t.Fatalf("unconditional UpdateUser: %v", err)
+	}
+	assertUserVersion(t, repo, "alice", 5)
+}
+
+func testStaleUserVersion(t *testing.T, repo Repository) {
+	user := mustCreateUser(t, repo, "alice", money.MustParse("50.00"))
+	if _, err := repo.AdjustBalance("alice", money.MustParse("1.00"), "", "goodwill"); err != nil {
+		t.Fatalf("AdjustBalance: %v", err)
+	}
+
+	_, err := repo.UpdateUser(user)
+	if _, ok := err.(*errors.ErrorPreconditionFailed); !ok {
+		t.Fatalf("stale UpdateUser error = %v, want *errors.ErrorPreconditionFailed", err)
+	}
+	if _, ok := repo.DeleteUser("alice", user.Version).(*errors.ErrorPreconditionFailed); !ok {
+		t.Fatal("stale DeleteUser should return *errors.ErrorPreconditionFailed")
+	}
+	assertUserVersion(t, repo, "alice", 2)
+	assertBalance(t, repo, "alice", "51.00")
+
+	if err := repo.DeleteUser("alice", 2); err != nil {
+		t.Fatalf("DeleteUser at current version: %v", err)
+	}
+	_, err = repo.UpdateUser(user)
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("UpdateUser of a deleted user error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+func testReturnsCopies(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("50.00"))
+	user, err := repo.GetUser("alice")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	user.Balance = money.MustParse("999.00")
+	user.Version = 99
+	assertBalance(t, repo, "alice", "50.00")
+	assertUserVersion(t, repo, "alice", 1)
+
+	bet := mustPlaceBet(t, repo, "alice", "e1", "2.00", "10.00")
+	bet.Status = model.StatusWon
+	bet.Amount = money.MustParse("1.00")
+	open, err := repo.FindBetsByEvent("e1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 1 || open[0].Amount != money.MustParse("10.00") {
+		t.Fatalf("open bets after changing the placed bet = %v, want the 10.00 stake", open)
+	}
+	open[0].Status = model.StatusLost
+	stored, err := repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if stored.Status != model.StatusPlaced || stored.Amount != money.MustParse("10.00") {
+		t.Fatalf("stored bet = %s %s, want PLACED 10.00", stored.Status, stored.Amount)
+	}
+}
+
+func testPlaceBetDebitsBalance(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	bet := mustPlaceBet(t, repo, "alice", "match-1", "2.5", "40.10")
+	if bet.ID == "" {
+		t.Fatal("PlaceBet did not assign an ID")
+	}
+	if bet.Status != model.StatusPlaced {
+		t.Fatalf("status = %s, want %s", bet.Status, model.StatusPlaced)
+	}
+	if bet.CreatedAt.IsZero() {
+		t.Fatal("PlaceBet did not set CreatedAt")
+	}
+	assertBalance(t, repo, "alice", "59.90")
+}
+
+func testPlaceBetInsufficientBalance(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	_, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("10.01")}, nil)
+	if _, ok := err.(*errors.ErrorBadRequest); !ok {
+		t.Fatalf("PlaceBet error = %v, want *errors.ErrorBadRequest", err)
+	}
+	assertBalance(t, repo, "alice", "10.00")
+	bets, err := repo.FindBetsByEvent("match-1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(bets) != 0 {
+		t.Fatalf("rejected bet was stored: %+v", bets)
+	}
+}
+
+func testPlaceBetUnknownUser(t *testing.T, repo Repository) {
+	_, err := repo.PlaceBet(&model.Bet{UserID: "nobody", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("1.00")}, nil)
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("PlaceBet error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+func testFindBetsByEventOnlyPlaced(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	first := mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+	mustPlaceBet(t, repo, "alice", "match-1", "3", "10.00")
+	mustPlaceBet(t, repo, "alice", "match-2", "3", "10.00")
+
+	if err := settle(t, repo, first, model.StatusLost); err != nil {
+		t.Fatalf("UpdateBet: %v", err)
+	}
+	bets, err := repo.FindBetsByEvent("match-1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(bets) != 1 || bets[0].ID == first.ID {
+		t.Fatalf("FindBetsByEvent returned %+v, want only the unsettled bet", bets)
+	}
+
+	bets, err = repo.FindBetsByEvent("no-such-event")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent on unknown event: %v", err)
+	}
+	if len(bets) != 0 {
+		t.Fatalf("unknown event returned %d bets", len(bets))
+	}
+}
+
+func findBets(t *testing.T, repo Repository, query model.BetQuery) *model.BetPage {
+	t.Helper()
+	if query.Sort == "" {
+		query.Sort = model.SortByCreatedAt
+	}
+	if query.Limit == 0 {
+		query.Limit = model.DefaultBetPageSize
+	}
+	page, err := repo.FindBets(&query)
+	if err != nil {
+		t.Fatalf("FindBets(%+v): %v", query, err)
+	}
+	return page
+}
+
+func betIDs(bets []*model.Bet) []string {
+	ids := make([]string, len(bets))
+	for i, bet := range bets {
+		ids[i] = bet.ID
+	}
+	return ids
+}
+
+func testFindBetsFilters(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("100.00"))
+	small := mustPlaceBet(t, repo, "alice", "match-1", "2", "5.00")
+	large := mustPlaceBet(t, repo, "alice", "match-2", "2", "20.00")
+	bobs := mustPlaceBet(t, repo, "bob", "match-1", "2", "10.00")
+	acca := mustPlaceAccumulator(t, repo, "bob", "4.00", leg("match-2", "2"), leg("match-3", "2"))
+	if err := settle(t, repo, small, model.StatusWon); err != nil {
+		t.Fatalf("UpdateBet: %v", err)
+	}
+	settledAt := time.Now()
+
+	cases := []struct {
+		name  string
+		query model.BetQuery
+		want  []*model.Bet
+	}{
+		{"all", model.BetQuery{}, []*model.Bet{small, large, bobs, acca}},
+		{"user", model.BetQuery{UserID: "alice"}, []*model.Bet{small, large}},
+		{"event with legs", model.BetQuery{EventID: "match-2"}, []*model.Bet{large, acca}},
+		{"status", model.BetQuery{Statuses: []model.BetStatus{model.StatusPlaced}}, []*model.Bet{large, bobs, acca}},
+		{"user and status", model.BetQuery{UserID: "alice", Statuses: []model.BetStatus{model.StatusWon}}, []*model.Bet{small}},
+		{"statuses", model.BetQuery{Statuses: []model.BetStatus{model.StatusWon, model.StatusLost}}, []*model.Bet{small}},
+		{"stake range", model.BetQuery{MinStake: money.MustParse("5.00"), MaxStake: money.MustParse("10.00")}, []*model.Bet{small, bobs}},
+		{"created from", model.BetQuery{CreatedFrom: bobs.CreatedAt}, []*model.Bet{bobs, acca}},
+		{"created to", model.BetQuery{CreatedTo: large.CreatedAt}, []*model.Bet{small, large}},
+		{"settled", model.BetQuery{SettledTo: settledAt}, []*model.Bet{small}},
+		{"no user", model.BetQuery{UserID: "carol"}, nil},
+		{"amount desc", model.BetQuery{Sort: model.SortByAmount, Desc: true}, []*model.Bet{large, bobs, small, acca}},
+	}
+	for _, tc := range cases {
+		got := betIDs(findBets(t, repo, tc.query).Bets)
+		if want := betIDs(tc.want); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, want)
+		}
+	}
+
+	page := findBets(t, repo, model.BetQuery{EventID: "match-3"})
+	if len(page.Bets) != 1 || len(page.Bets[0].Legs) != 2 {
+		t.Fatalf("accumulator listed without its legs: %+v", page.Bets)
+	}
+}
+
+func testFindBetsPaginates(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	var placed []*model.Bet
+	for _, amount := range []string{"3.00", "1.00", "2.00", "1.00", "3.00"} {
+		placed = append(placed, mustPlaceBet(t, repo, "alice", "match-1", "2", amount))
+	}
+
+	for _, desc := range []bool{false, true} {
+		query := model.BetQuery{Sort: model.SortByAmount, Desc: desc, Limit: 2}
+		all := findBets(t, repo, model.BetQuery{Sort: model.SortByAmount, Desc: desc}).Bets
+		var paged []*model.Bet
+		for pages := 0; ; pages++ {
+			if pages == len(placed) {
+				t.Fatalf("pagination did not end (desc=%v)", desc)
+			}
+			page := findBets(t, repo, query)
+			if len(page.Bets) > query.Limit {
+				t.Fatalf("page of %d bets exceeds limit %d", len(page.Bets), query.Limit)
+			}
+			paged = append(paged, page.Bets...)
+			if page.NextCursor == "" {
+				break
+			}
+			after, err := model.ParseBetCursor(page.NextCursor, query.Sort)
+			if err != nil {
+				t.Fatalf("ParseBetCursor: %v", err)
+			}
+			query.After = after
+		}
+		if got, want := betIDs(paged), betIDs(all); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("paged listing (desc=%v) = %v, want %v", desc, got, want)
+		}
+		for i := 1; i < len(all); i++ {
+			if (all[i-1].Amount > all[i].Amount) != desc && all[i-1].Amount != all[i].Amount {
+				t.Fatalf("listing (desc=%v) out of order at %d", desc, i)
+			}
+		}
+	}
+}
+
+func testFindBetsPagesAfterUpdates(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("1000.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("1000.00"))
+	var placed []*model.Bet
+	for i, amount := range []string{"3.00", "1.00", "2.00", "1.00", "5.00", "4.00", "2.00", "3.00", "1.00", "6.00", "2.00", "5.00"} {
+		user, event := "alice", "match-1"
+		if i%3 == 0 {
+			user = "bob"
+		}
+		if i%2 == 0 {
+			event = "match-2"
+		}
+		placed = append(placed, mustPlaceBet(t, repo, user, event, "2", amount))
+	}
+	// Settling moves bets in the settled_at order and between statuses.
+	for i, status := range []model.BetStatus{model.StatusWon, model.StatusLost, model.StatusVoid, model.StatusLost} {
+		if err := settle(t, repo, placed[len(placed)-1-3*i], status); err != nil {
+			t.Fatalf("UpdateBet: %v", err)
+		}
+	}
+	var stored []*model.Bet
+	for _, bet := range placed {
+		got, err := repo.GetBet(bet.ID)
+		if err != nil {
+			t.Fatalf("GetBet: %v", err)
+		}
+		stored = append(stored, got)
+	}
+
+	filters := []model.BetQuery{
+		{},
+		{UserID: "bob"},
+		{EventID: "match-2"},
+		{Statuses: []model.BetStatus{model.StatusPlaced}},
+		{Statuses: []model.BetStatus{model.StatusLost}},
+		{MinStake: money.MustParse("2.00"), MaxStake: money.MustParse("5.00")},
+		{CreatedFrom: placed[3].CreatedAt, CreatedTo: placed[9].CreatedAt},
+		{SettledFrom: stored[len(stored)-4].SettledAt},
+	}
+	for _, sort := range []model.BetSort{model.SortByCreatedAt, model.SortBySettledAt, model.SortByAmount} {
+		for _, desc := range []bool{false, true} {
+			for i, filter := range filters {
+				query := filter
+				query.Sort, query.Desc, query.Limit = sort, desc, 3
+				var want []*model.Bet
+				for _, bet := range stored {
+					if query.Matches(bet) {
+						want = append(want, bet)
+					}
+				}
+				slices.SortFunc(want, func(a, b *model.Bet) int {
+					if query.Less(a, b) {
+						return -1
+					}
+					return 1
+				})
+
+				var paged []*model.Bet
+				for pages := 0; ; pages++ {
+					if pages > len(placed) {
+						t.Fatalf("pagination did not end (sort=%s desc=%v filter %d)", sort, desc, i)
+					}
+					page := findBets(t, repo, query)
+					paged = append(paged, page.Bets...)
+					if page.NextCursor == "" {
+						break
+					}
+					after, err := model.ParseBetCursor(page.NextCursor, sort)
+					if err != nil {
+						t.Fatalf("ParseBetCursor: %v", err)
+					}
+					query.After = after
+				}
+				if got, want := betIDs(paged), betIDs(want); fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Errorf("sort=%s desc=%v filter %d: got %v, want %v", sort, desc, i, got, want)
+				}
+			}
+		}
+	}
+}
+
+func testUserBetStats(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("100.00"))
+	won := mustPlaceBet(t, repo, "alice", "match-1", "2.5", "10.00")
+	lost := mustPlaceBet(t, repo, "alice", "match-2", "2", "5.00")
+	void := mustPlaceBet(t, repo, "alice", "match-3", "2", "4.00")
+	mustPlaceBet(t, repo, "alice", "match-4", "2", "6.00")
+	mustPlaceBet(t, repo, "bob", "match-1", "2", "50.00")
+	for bet, status := range map[*model.Bet]model.BetStatus{won: model.StatusWon, lost: model.StatusLost, void: model.StatusVoid} {
+		if err := settle(t, repo, bet, status); err != nil {
+			t.Fatalf("UpdateBet: %v", err)
+		}
+	}
+
+	stats, err := repo.UserBetStats("alice")
+	if err != nil {
+		t.Fatalf("UserBetStats: %v", err)
+	}
+	if len(stats) != 1 || stats[money.EUR] == nil {
+		t.Fatalf("stats = %v, want EUR stats only", stats)
+	}
+	want := model.BetStats{
+		Currency: money.EUR,
+		Bets:     4, Placed: 1, Won: 1, Lost: 1, Voided: 1,
+		TotalStaked:   money.MustParse("25.00"),
+		TotalReturned: money.MustParse("29.00"),
+		NetPnL:        money.MustParse("10.00"),
+		WinRate:       0.5,
+		OpenExposure:  money.MustParse("6.00"),
+	}
+	if *stats[money.EUR] != want {
+		t.Fatalf("stats = %+v, want %+v", *stats[money.EUR], want)
+	}
+	// The P&L agrees with the balance once open stakes are left aside.
+	assertBalance(t, repo, "alice", "104.00")
+
+	stats, err = repo.UserBetStats("carol")
+	if err != nil {
+		t.Fatalf("UserBetStats without bets: %v", err)
+	}
+	if len(stats) != 0 {
+		t.Fatalf("stats without bets = %v", stats)
+	}
+}
+
+func testUpdateBetWonCreditsPayout(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	bet := mustPlaceBet(t, repo, "alice", "match-1", "1.333", "10.01")
+	if err := settle(t, repo, bet, model.StatusWon); err != nil {
+		t.Fatalf("UpdateBet: %v", err)
+	}
+	// 10.01 * 1.333 = 13.34333, rounded down to 13.34.
+	assertBalance(t, repo, "alice", "103.33")
+}
+
+func testUpdateBetLostKeepsBalance(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	bet := mustPlaceBet(t, repo, "alice", "match-1", "2", "25.00")
+	if err := settle(t, repo, bet, model.StatusLost); err != nil {
+		t.Fatalf("UpdateBet: %v", err)
+	}
+	assertBalance(t, repo, "alice", "75.00")
+}
+
+func testUpdateBetAlreadySettled(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	bet := mustPlaceBet(t, repo, "alice", "match-1", "2", "25.00")
+	if err := settle(t, repo, bet, model.StatusWon); err != nil {
+		t.Fatalf("UpdateBet: %v", err)
+	}
+	err := settle(t, repo, bet, model.StatusWon)
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("second UpdateBet error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "125.00")
+}
+
+func testUpdateBetNotFound(t *testing.T, repo Repository) {
+	err := repo.UpdateBet(&model.Bet{ID: "missing", Status: model.StatusWon})
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("UpdateBet error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+func testBetVersions(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("50.00"))
+	bet := mustPlaceBet(t, repo, "alice", "e1", "2.00", "10.00")
+	if bet.Version != 1 {
+		t.Fatalf("placed bet version = %d, want 1", bet.Version)
+	}
+
+	stale := *bet
+	stale.Version = 2
+	if _, ok := settle(t, repo, &stale, model.StatusWon).(*errors.ErrorPreconditionFailed); !ok {
+		t.Fatal("settling with the wrong version should return *errors.ErrorPreconditionFailed")
+	}
+	assertBalance(t, repo, "alice", "40.00")
+
+	if err := settle(t, repo, bet, model.StatusWon); err != nil {
+		t.Fatalf("settle at current version: %v", err)
+	}
+	settled, err := repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if settled.Version != 2 {
+		t.Fatalf("settled bet version = %d, want 2", settled.Version)
+	}
+
+	// Settling a whole event bumps the version of every bet it settles.
+	other := mustPlaceBet(t, repo, "alice", "e2", "2.00", "5.00")
+	if _, err := repo.SettleEvent("e2", func(b *model.Bet) error {
+		b.Status = model.StatusLost
+		return nil
+	}); err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	settled, err = repo.GetBet(other.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if settled.Version != 2 {
+		t.Fatalf("event-settled bet version = %d, want 2", settled.Version)
+	}
+}
+
+func testSettleEvent(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("100.00"))
+	mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+	mustPlaceBet(t, repo, "bob", "match-1", "3.5", "20.00")
+	mustPlaceBet(t, repo, "bob", "match-2", "2", "5.00")
+
+	summary, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		if bet.UserID == "alice" {
+			bet.Status = model.StatusLost
+		} else {
+			bet.Status = model.StatusWon
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if summary.BetsSettled != 2 || summary.Won != 1 || summary.Lost != 1 {
+		t.Fatalf("summary = %+v, want 2 settled, 1 won, 1 lost", summary)
+	}
+	if eurTotals(summary).Payout != money.MustParse("70.00") {
+		t.Fatalf("total payout = %s, want 70.00", eurTotals(summary).Payout)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+	assertBalance(t, repo, "bob", "145.00")
+
+	open, err := repo.FindBetsByEvent("match-1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 0 {
+		t.Fatalf("%d bets still PLACED after settlement", len(open))
+	}
+	open, err = repo.FindBetsByEvent("match-2")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 1 {
+		t.Fatal("settling one event touched bets on another")
+	}
+
+	// Settling again finds nothing left to settle.
+	summary, err = repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusWon
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("second SettleEvent: %v", err)
+	}
+	if summary.BetsSettled != 0 {
+		t.Fatalf("second settlement settled %d bets", summary.BetsSettled)
+	}
+	assertBalance(t, repo, "bob", "145.00")
+}
+
+func testSettleEventRollsBackOnError(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	for i := 0; i < 5; i++ {
+		mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+	}
+
+	calls := 0
+	_, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		calls++
+		if calls == 3 {
+			return fmt.Errorf("boom")
+		}
+		bet.Status = model.StatusWon
+		return nil
+	})
+	if err == nil {
+		t.Fatal("SettleEvent should fail when a bet cannot be settled")
+	}
+	assertBalance(t, repo, "alice", "50.00")
+	open, err := repo.FindBetsByEvent("match-1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 5 {
+		t.Fatalf("%d of 5 bets still PLACED after a failed settlement, want all", len(open))
+	}
+}
+
+func testSettleEventSkipsUndecided(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	decided := mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+	mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+
+	summary, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		if bet.ID == decided.ID {
+			bet.Status = model.StatusWon
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if summary.BetsSettled != 1 {
+		t.Fatalf("settled %d bets, want 1", summary.BetsSettled)
+	}
+	open, err := repo.FindBetsByEvent("match-1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 1 || open[0].ID == decided.ID {
+		t.Fatalf("undecided bet should stay PLACED, got %+v", open)
+	}
+}
+
+func testSettleEventVoidRefundsStake(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustPlaceBet(t, repo, "alice", "match-1", "2.5", "10.00")
+	mustPlaceBet(t, repo, "alice", "match-1", "1.8", "15.50")
+
+	summary, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusVoid
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if summary.Voided != 2 || eurTotals(summary).Refunded != money.MustParse("25.50") || !eurTotals(summary).Payout.IsZero() {
+		t.Fatalf("summary = %+v, want 2 voided, 25.50 refunded, no payout", summary)
+	}
+	assertBalance(t, repo, "alice", "100.00")
+
+	txs, err := repo.ListTransactions("alice")
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	refunds := 0
+	for _, tx := range txs {
+		if tx.Type == model.EntryRefund {
+			refunds++
+		}
+	}
+	if refunds != 2 {
+		t.Fatalf("got %d refund transactions, want 2: %s", refunds, describe(txs))
+	}
+}
+
+// --- accumulators ---
+
+func mustPlaceAccumulator(t *testing.T, repo Repository, userID, amount string, legs ...model.BetLeg) *model.Bet {
+	t.Helper()
+	bet := &model.Bet{
+		UserID: userID,
+		Type:   model.BetTypeAccumulator,
+		Legs:   legs,
+		Amount: money.MustParse(amount),
+	}
+	bet.Odds = bet.EffectiveOdds()
+	placed, err := repo.PlaceBet(bet, nil)
+	if err != nil {
+		t.Fatalf("PlaceBet(accumulator): %v", err)
+	}
+	return placed
+}
+
+func leg(eventID, odds string) model.BetLeg {
+	return model.BetLeg{EventID: eventID, Odds: money.MustParseOdds(odds), Status: model.StatusPlaced}
+}
+
+// settleLegs settles the legs on eventID with status, the way the service
+// settles an event.
+func settleLegs(t *testing.T, repo Repository, eventID string, status model.BetStatus) *model.SettlementSummary {
+	t.Helper()
+	summary, err := repo.SettleEvent(eventID, func(bet *model.Bet) error {
+		for i := range bet.Legs {
+			if bet.Legs[i].EventID == eventID {
+				bet.Legs[i].Status = status
+			}
+		}
+		bet.Resolve(time.Now())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SettleEvent(%s): %v", eventID, err)
+	}
+	return summary
+}
+
+func testAccumulatorSettlesAcrossEvents(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	acc := mustPlaceAccumulator(t, repo, "alice", "10.00", leg("match-1", "2"), leg("match-2", "3"), leg("match-3", "1.5"))
+	if acc.Odds != money.MustParseOdds("9") {
+		t.Fatalf("combined odds = %s, want 9", acc.Odds)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+
+	for _, eventID := range []string{"match-1", "match-2", "match-3"} {
+		open, err := repo.FindBetsByEvent(eventID)
+		if err != nil {
+			t.Fatalf("FindBetsByEvent(%s): %v", eventID, err)
+		}
+		if len(open) != 1 || open[0].ID != acc.ID {
+			t.Fatalf("FindBetsByEvent(%s) = %+v, want the accumulator", eventID, open)
+		}
+	}
+
+	summary := settleLegs(t, repo, "match-1", model.StatusWon)
+	if summary.BetsSettled != 0 || summary.Pending != 1 {
+		t.Fatalf("summary = %+v, want 0 settled, 1 pending", summary)
+	}
+	open, err := repo.FindBetsByEvent("match-2")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 1 || len(open[0].Legs) != 3 || open[0].Legs[0].Status != model.StatusWon {
+		t.Fatalf("first leg should be stored as WON, got %+v", open)
+	}
+
+	// A void leg drops out of the odds product: 10.00 x 2 x 1.5.
+	settleLegs(t, repo, "match-2", model.StatusVoid)
+	summary = settleLegs(t, repo, "match-3", model.StatusWon)
+	if summary.BetsSettled != 1 || summary.Won != 1 || eurTotals(summary).Payout != money.MustParse("30.00") {
+		t.Fatalf("summary = %+v, want 1 won paying 30.00", summary)
+	}
+	assertBalance(t, repo, "alice", "120.00")
+}
+
+func testAccumulatorLosingLeg(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustPlaceAccumulator(t, repo, "alice", "10.00", leg("match-1", "2"), leg("match-2", "3"))
+
+	summary := settleLegs(t, repo, "match-1", model.StatusLost)
+	if summary.BetsSettled != 1 || summary.Lost != 1 {
+		t.Fatalf("summary = %+v, want 1 lost", summary)
+	}
+	open, err := repo.FindBetsByEvent("match-2")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 0 {
+		t.Fatalf("a lost accumulator is still open on its other event: %+v", open)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+}
+
+func testSystemLinesSettleIndependently(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	lines, err := model.SystemLines("trixie", 0, 3, money.MustParse("40.00"))
+	if err != nil {
+		t.Fatalf("SystemLines: %v", err)
+	}
+	bet := &model.Bet{
+		UserID: "alice",
+		Type:   model.BetTypeSystem,
+		Legs:   []model.BetLeg{leg("match-1", "2"), leg("match-2", "3"), leg("match-3", "1.5")},
+		Lines:  lines,
+		Amount: money.MustParse("40.00"),
+	}
+	bet.PriceLines()
+	if _, err := repo.PlaceBet(bet, nil); err != nil {
+		t.Fatalf("PlaceBet(system): %v", err)
+	}
+	assertBalance(t, repo, "alice", "60.00")
+
+	settleLegs(t, repo, "match-1", model.StatusWon)
+	summary := settleLegs(t, repo, "match-2", model.StatusLost)
+	if summary.BetsSettled != 0 || summary.Pending != 1 {
+		t.Fatalf("summary = %+v, want the system bet pending", summary)
+	}
+	open, err := repo.FindBetsByEvent("match-3")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 1 || len(open[0].Lines) != 4 {
+		t.Fatalf("FindBetsByEvent(match-3) = %+v, want the system bet with 4 lines", open)
+	}
+	lost := 0
+	for _, line := range open[0].Lines {
+		if line.Status == model.StatusLost {
+			lost++
+		}
+	}
+	if lost != 3 {
+		t.Fatalf("%d lines stored as LOST, want the 3 containing match-2", lost)
+	}
+
+	// Only the match-1/match-3 double wins: 10.00 x 2 x 1.5.
+	summary = settleLegs(t, repo, "match-3", model.StatusWon)
+	if summary.Won != 1 || eurTotals(summary).Payout != money.MustParse("30.00") {
+		t.Fatalf("summary = %+v, want 1 won paying 30.00", summary)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+}
+
+// --- events ---
+
+func sampleEvent(id string) *model.Event {
+	return &model.Event{
+		ID:   id,
+		Name: "Home v Away",
+		Markets: []*model.Market{{
+			ID:   id + "-result",
+			Name: "Match result",
+			Selections: []*model.Selection{
+				{ID: id + "-home", Name: "Home", Odds: money.MustParseOdds("2.1")},
+				{ID: id + "-away", Name: "Away", Odds: money.MustParseOdds("3.4")},
+				{Name: "Draw", Odds: money.MustParseOdds("3.2")},
+			},
+		}},
+	}
+}
+
+func testCreateEvent(t *testing.T, repo Repository) {
+	created, err := repo.CreateEvent(sampleEvent("match-1"))
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	if created.CreatedAt.IsZero() {
+		t.Fatal("CreateEvent did not set CreatedAt")
+	}
+
+	event, err := repo.GetEvent("match-1")
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if len(event.Markets) != 1 || len(event.Markets[0].Selections) != 3 {
+		t.Fatalf("event = %+v, want 1 market with 3 selections", event)
+	}
+	market := event.Markets[0]
+	if market.EventID != "match-1" {
+		t.Fatalf("market event_id = %q, want match-1", market.EventID)
+	}
+	draw := market.Selections[2]
+	if draw.ID == "" || draw.MarketID != market.ID || draw.EventID != "match-1" {
+		t.Fatalf("generated selection not linked: %+v", draw)
+	}
+
+	sel, err := repo.GetSelection("match-1-away")
+	if err != nil {
+		t.Fatalf("GetSelection: %v", err)
+	}
+	if sel.Odds != money.MustParseOdds("3.4") || sel.MarketID != "match-1-result" {
+		t.Fatalf("selection = %+v", sel)
+	}
+
+	if _, err := repo.GetEvent("nope"); err == nil {
+		t.Fatal("GetEvent on unknown event should fail")
+	}
+	if _, ok := func() error { _, err := repo.GetSelection("nope"); return err }().(*errors.ErrorNotFound); !ok {
+		t.Fatal("GetSelection on unknown selection should return *errors.ErrorNotFound")
+	}
+
+	events, err := repo.ListEvents()
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("ListEvents returned %d events, want 1", len(events))
+	}
+}
+
+func testCreateEventConflicts(t *testing.T, repo Repository) {
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	_, err := repo.CreateEvent(sampleEvent("match-1"))
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("duplicate event error = %v, want *errors.ErrorConflict", err)
+	}
+
+	// A second event reusing a selection ID is rejected as a whole.
+	dup := sampleEvent("match-2")
+	dup.Markets[0].Selections[0].ID = "match-1-home"
+	_, err = repo.CreateEvent(dup)
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("duplicate selection error = %v, want *errors.ErrorConflict", err)
+	}
+	if _, err := repo.GetEvent("match-2"); err == nil {
+		t.Fatal("rejected event was stored")
+	}
+}
+
+func testAddMarket(t *testing.T, repo Repository) {
+	if _, err := repo.CreateEvent(&model.Event{ID: "match-1", Name: "Home v Away"}); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	market, err := repo.AddMarket("match-1", &model.Market{
+		Name: "Total goals",
+		Selections: []*model.Selection{
+			{Name: "Over 2.5", Odds: money.MustParseOdds("1.9")},
+			{Name: "Under 2.5", Odds: money.MustParseOdds("1.9")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+	if market.ID == "" || market.EventID != "match-1" {
+		t.Fatalf("market = %+v", market)
+	}
+	event, err := repo.GetEvent("match-1")
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if len(event.Markets) != 1 || event.Markets[0].ID != market.ID {
+		t.Fatalf("event markets = %+v", event.Markets)
+	}
+	if _, err := repo.AddMarket("nope", &model.Market{Name: "x"}); err == nil {
+		t.Fatal("AddMarket on unknown event should fail")
+	}
+}
+
+func testPlaceBetOnSelection(t *testing.T, repo Repository) {
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	_, err := repo.PlaceBet(&model.Bet{
+		UserID:      "alice",
+		EventID:     "match-1",
+		MarketID:    "match-1-result",
+		SelectionID: "match-1-home",
+		Odds:        money.MustParseOdds("2.1"),
+		Amount:      money.MustParse("10.00"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("PlaceBet: %v", err)
+	}
+	bets, err := repo.FindBetsByEvent("match-1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(bets) != 1 || bets[0].MarketID != "match-1-result" || bets[0].SelectionID != "match-1-home" {
+		t.Fatalf("stored bet = %+v, want market and selection kept", bets)
+	}
+}
+
+// --- event lifecycle ---
+
+// closeEvent closes an event so that it can be settled.
+func closeEvent(t *testing.T, repo Repository, eventID string) {
+	t.Helper()
+	if _, err := repo.SetEventStatus(eventID, model.EventClosed); err != nil {
+		t.Fatalf("SetEventStatus(%s, CLOSED): %v", eventID, err)
+	}
+}
+
+func testEventStatusTransitions(t *testing.T, repo Repository) {
+	created, err := repo.CreateEvent(sampleEvent("match-1"))
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	if created.Status != model.EventOpen || created.StatusChangedAt.IsZero() {
+		t.Fatalf("created event status = %s at %v, want OPEN with a time", created.Status, created.StatusChangedAt)
+	}
+
+	for _, step := range []struct {
+		status model.EventStatus
+		ok     bool
+	}{
+		{model.EventSuspended, true},
+		{model.EventSettled, false},
+		{model.EventOpen, true},
+		{model.EventClosed, true},
+		{model.EventOpen, false},
+		{model.EventSuspended, false},
+		{model.EventSettled, true},
+		{model.EventCancelled, false},
+	} {
+		event, err := repo.SetEventStatus("match-1", step.status)
+		if !step.ok {
+			if _, ok := err.(*errors.ErrorConflict); !ok {
+				t.Fatalf("SetEventStatus(%s) error = %v, want *errors.ErrorConflict", step.status, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("SetEventStatus(%s): %v", step.status, err)
+		}
+		if event.Status != step.status {
+			t.Fatalf("status = %s, want %s", event.Status, step.status)
+		}
+	}
+	event, err := repo.GetEvent("match-1")
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if event.Status != model.EventSettled || event.StatusChangedAt.Before(created.StatusChangedAt) {
+		t.Fatalf("stored event = %+v, want SETTLED", event)
+	}
+
+	if _, err := repo.SetEventStatus("nope", model.EventClosed); err == nil {
+		t.Fatal("SetEventStatus on unknown event should fail")
+	} else if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("SetEventStatus on unknown event error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+func testPlaceBetNeedsOpenEvent(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	if _, err := repo.SetEventStatus("match-1", model.EventSuspended); err != nil {
+		t.Fatalf("SetEventStatus: %v", err)
+	}
+
+	single := &model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("10.00")}
+	if _, err := repo.PlaceBet(single.Clone(), nil); err == nil {
+		t.Fatal("PlaceBet on a suspended event should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("PlaceBet error = %v, want *errors.ErrorConflict", err)
+	}
+	acc := &model.Bet{
+		UserID: "alice",
+		Type:   model.BetTypeAccumulator,
+		Legs:   []model.BetLeg{leg("match-1", "2"), leg("match-2", "3")},
+		Amount: money.MustParse("10.00"),
+	}
+	acc.Odds = acc.EffectiveOdds()
+	if _, err := repo.PlaceBet(acc, nil); err == nil {
+		t.Fatal("PlaceBet with a leg on a suspended event should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("PlaceBet(accumulator) error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "100.00")
+
+	if _, err := repo.SetEventStatus("match-1", model.EventOpen); err != nil {
+		t.Fatalf("SetEventStatus: %v", err)
+	}
+	if _, err := repo.PlaceBet(single.Clone(), nil); err != nil {
+		t.Fatalf("PlaceBet on a resumed event: %v", err)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+}
+
+func testSettleEventNeedsClosedEvent(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	event := sampleEvent("match-1")
+	event.Markets = append(event.Markets, &model.Market{
+		ID:   "match-1-goals",
+		Name: "Total goals",
+		Selections: []*model.Selection{
+			{ID: "match-1-over", Name: "Over 2.5", Odds: money.MustParseOdds("1.9")},
+			{ID: "match-1-under", Name: "Under 2.5", Odds: money.MustParseOdds("1.9")},
+		},
+	})
+	if _, err := repo.CreateEvent(event); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	placeOnSelection(t, repo, "match-1-home", "10.00")
+	placeOnSelection(t, repo, "match-1-over", "10.00")
+
+	// Settles the bets of one market, leaving the others open.
+	settleMarket := func(marketID string) (*model.SettlementSummary, error) {
+		return repo.SettleEvent("match-1", func(bet *model.Bet) error {
+			if bet.MarketID == marketID {
+				bet.Status = model.StatusLost
+			}
+			return nil
+		})
+	}
+	if _, err := settleMarket("match-1-result"); err == nil {
+		t.Fatal("SettleEvent on an OPEN event should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("SettleEvent error = %v, want *errors.ErrorConflict", err)
+	}
+
+	closeEvent(t, repo, "match-1")
+	summary, err := settleMarket("match-1-result")
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if summary.BetsSettled != 1 || summary.EventStatus != model.EventClosed {
+		t.Fatalf("summary = %+v, want 1 settled and the event still CLOSED", summary)
+	}
+	summary, err = settleMarket("match-1-goals")
+	if err != nil {
+		t.Fatalf("second SettleEvent: %v", err)
+	}
+	if summary.BetsSettled != 1 || summary.EventStatus != model.EventSettled {
+		t.Fatalf("summary = %+v, want 1 settled and the event SETTLED", summary)
+	}
+	stored, err := repo.GetEvent("match-1")
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if stored.Status != model.EventSettled {
+		t.Fatalf("stored status = %s, want SETTLED", stored.Status)
+	}
+	if _, err := settleMarket("match-1-goals"); err == nil {
+		t.Fatal("SettleEvent on a SETTLED event should fail")
+	}
+}
+
+func testVoidEventNeedsCancelledEvent(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	bet := placeOnSelection(t, repo, "match-1-home", "10.00")
+	void := func(bet *model.Bet) error {
+		bet.Status = model.StatusVoid
+		return nil
+	}
+
+	closeEvent(t, repo, "match-1")
+	if _, err := repo.VoidEvent("match-1", void); err == nil {
+		t.Fatal("VoidEvent on a CLOSED event should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("VoidEvent error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+
+	if _, err := repo.SetEventStatus("match-1", model.EventCancelled); err != nil {
+		t.Fatalf("SetEventStatus(CANCELLED): %v", err)
+	}
+	if _, err := repo.SettleEvent("match-1", void); err == nil {
+		t.Fatal("SettleEvent on a CANCELLED event should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("SettleEvent error = %v, want *errors.ErrorConflict", err)
+	}
+	if _, err := repo.PreviewSettlement("match-1", void); err == nil {
+		t.Fatal("PreviewSettlement on a CANCELLED event should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("PreviewSettlement error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+
+	summary, err := repo.VoidEvent("match-1", void)
+	if err != nil {
+		t.Fatalf("VoidEvent: %v", err)
+	}
+	if summary.BetsSettled != 1 || summary.EventStatus != model.EventCancelled {
+		t.Fatalf("summary = %+v, want 1 voided and the event still CANCELLED", summary)
+	}
+	assertBalance(t, repo, "alice", "100.00")
+	stored, err := repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if stored.Status != model.StatusVoid {
+		t.Fatalf("stored status = %s, want VOID", stored.Status)
+	}
+}
+
+func testPreviewSettlementChangesNothing(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	placeOnSelection(t, repo, "match-1-home", "10.00")
+	placeOnSelection(t, repo, "match-1-away", "20.00")
+	mustPlaceBet(t, repo, "bob", "match-1", "2", "5.00")
+	closeEvent(t, repo, "match-1")
+
+	preview, err := repo.PreviewSettlement("match-1", settleSelection("match-1-home"))
+	if err != nil {
+		t.Fatalf("PreviewSettlement: %v", err)
+	}
+	summary := preview.Summary
+	if summary.BetsSettled != 3 || summary.Won != 1 || summary.Lost != 2 || summary.EventStatus != model.EventSettled {
+		t.Fatalf("summary = %+v, want 3 settled, 1 won, 2 lost and the event SETTLED", summary)
+	}
+	if len(preview.Bets) != 3 {
+		t.Fatalf("preview has %d bets, want 3", len(preview.Bets))
+	}
+	if len(preview.BalanceDeltas) != 1 || preview.BalanceDeltas[0].UserID != "alice" || preview.BalanceDeltas[0].Amount != money.MustParse("21.00") {
+		t.Fatalf("balance deltas = %+v, want alice +21.00 only", preview.BalanceDeltas)
+	}
+	if profit := preview.HouseProfit[money.DefaultCurrency]; profit != money.MustParse("14.00") {
+		t.Fatalf("house profit = %s, want 14.00", profit)
+	}
+
+	// Nothing was changed.
+	assertBalance(t, repo, "alice", "70.00")
+	assertBalance(t, repo, "bob", "95.00")
+	open, err := repo.FindBetsByEvent("match-1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 3 {
+		t.Fatalf("%d of 3 bets still PLACED after a preview, want all", len(open))
+	}
+	stored, err := repo.GetEvent("match-1")
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if stored.Status != model.EventClosed {
+		t.Fatalf("event is %s after a preview, want CLOSED", stored.Status)
+	}
+
+	// The settlement itself matches the preview.
+	settled, err := repo.SettleEvent("match-1", settleSelection("match-1-home"))
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if settled.BetsSettled != summary.BetsSettled || eurTotals(settled) != eurTotals(summary) || settled.EventStatus != summary.EventStatus {
+		t.Fatalf("settlement = %+v, want it to match the preview %+v", settled, summary)
+	}
+	assertBalance(t, repo, "alice", "91.00")
+}
+
+// --- settlement jobs ---
+
+// mustStartSettlementJob queues a job for eventID and claims it.
+func mustStartSettlementJob(t *testing.T, repo Repository, eventID string) *model.SettlementJob {
+	t.Helper()
+	queued, err := repo.CreateSettlementJob(&model.SettlementJob{EventID: eventID})
+	if err != nil {
+		t.Fatalf("CreateSettlementJob: %v", err)
+	}
+	if queued.ID == "" || queued.State != model.JobQueued {
+		t.Fatalf("created job = %+v, want an ID and QUEUED", queued)
+	}
+	job, err := repo.ClaimSettlementJob()
+	if err != nil {
+		t.Fatalf("ClaimSettlementJob: %v", err)
+	}
+	if job == nil || job.ID != queued.ID || job.State != model.JobRunning {
+		t.Fatalf("claimed job = %+v, want %s RUNNING", job, queued.ID)
+	}
+	return job
+}
+
+func testSettlementJobBlocksSettlement(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	placeOnSelection(t, repo, "match-1-home", "10.00")
+	closeEvent(t, repo, "match-1")
+	if _, err := repo.CreateSettlementJob(&model.SettlementJob{EventID: "match-1"}); err != nil {
+		t.Fatalf("CreateSettlementJob: %v", err)
+	}
+
+	assertBlocked := func(state string) {
+		t.Helper()
+		if _, err := repo.SettleEvent("match-1", settleSelection("match-1-away")); err == nil {
+			t.Fatalf("SettleEvent with a %s job should fail", state)
+		} else if _, ok := err.(*errors.ErrorConflict); !ok {
+			t.Fatalf("SettleEvent error = %v, want *errors.ErrorConflict", err)
+		}
+		for _, action := range []model.CorrectionAction{model.CorrectionResettle, model.CorrectionUnsettle} {
+			if _, err := repo.CorrectSettlement(correction("match-1", action), settleSelection("match-1-away")); err == nil {
+				t.Fatalf("CorrectSettlement(%s) with a %s job should fail", action, state)
+			} else if _, ok := err.(*errors.ErrorConflict); !ok {
+				t.Fatalf("CorrectSettlement(%s) error = %v, want *errors.ErrorConflict", action, err)
+			}
+		}
+	}
+	assertBlocked("QUEUED")
+	job, err := repo.ClaimSettlementJob()
+	if err != nil || job == nil {
+		t.Fatalf("ClaimSettlementJob = %+v, %v; want the queued job", job, err)
+	}
+	assertBlocked("RUNNING")
+	assertBalance(t, repo, "alice", "90.00")
+
+	// The job itself still settles the event.
+	for job.State == model.JobRunning {
+		if job, err = repo.SettleJobBatch(job.ID, settleSelection("match-1-home"), 10); err != nil {
+			t.Fatalf("SettleJobBatch: %v", err)
+		}
+	}
+	if job.State != model.JobCompleted || job.Summary.Won != 1 {
+		t.Fatalf("finished job = %+v, want COMPLETED with 1 won", job)
+	}
+	assertBalance(t, repo, "alice", "111.00")
+
+	// Once it has finished, the settlement can be corrected.
+	if _, err := repo.CorrectSettlement(correction("match-1", model.CorrectionResettle), settleSelection("match-1-away")); err != nil {
+		t.Fatalf("CorrectSettlement after the job: %v", err)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+}
+
+func testSettlementJobLifecycle(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	placeOnSelection(t, repo, "match-1-home", "10.00")
+	placeOnSelection(t, repo, "match-1-away", "20.00")
+	placeOnSelection(t, repo, "match-1-home", "5.00")
+	closeEvent(t, repo, "match-1")
+
+	job := mustStartSettlementJob(t, repo, "match-1")
+	if job.Total != 3 || job.Processed != 0 {
+		t.Fatalf("started job = %+v, want 0 of 3 processed", job)
+	}
+	if _, err := repo.CreateSettlementJob(&model.SettlementJob{EventID: "match-1"}); err == nil {
+		t.Fatal("a second job for an event with an unfinished one should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("CreateSettlementJob error = %v, want *errors.ErrorConflict", err)
+	}
+	if next, err := repo.ClaimSettlementJob(); err != nil || next != nil {
+		t.Fatalf("ClaimSettlementJob with nothing queued = %+v, %v; want nil, nil", next, err)
+	}
+
+	job, err := repo.SettleJobBatch(job.ID, settleSelection("match-1-home"), 2)
+	if err != nil {
+		t.Fatalf("SettleJobBatch: %v", err)
+	}
+	if job.State != model.JobRunning || job.Processed != 2 || job.Summary.BetsSettled != 2 {
+		t.Fatalf("job after the first batch = %+v, want RUNNING with 2 processed", job)
+	}
+	// The batch is settled, the event not yet.
+	assertBalance(t, repo, "alice", "86.00")
+	if stored, err := repo.GetEvent("match-1"); err != nil || stored.Status != model.EventClosed {
+		t.Fatalf("event after the first batch = %+v, %v; want CLOSED", stored, err)
+	}
+
+	for job.State == model.JobRunning {
+		if job, err = repo.SettleJobBatch(job.ID, settleSelection("match-1-home"), 2); err != nil {
+			t.Fatalf("SettleJobBatch: %v", err)
+		}
+	}
+	summary := job.Summary
+	if job.State != model.JobCompleted || job.Processed != 3 || job.FinishedAt.IsZero() {
+		t.Fatalf("finished job = %+v, want COMPLETED with 3 processed", job)
+	}
+	if summary.BetsSettled != 3 || summary.Won != 2 || summary.Lost != 1 || summary.EventStatus != model.EventSettled {
+		t.Fatalf("summary = %+v, want 3 settled, 2 won, 1 lost and the event SETTLED", summary)
+	}
+	assertBalance(t, repo, "alice", "96.50")
+	if stored, err := repo.GetEvent("match-1"); err != nil || stored.Status != model.EventSettled {
+		t.Fatalf("event after the job = %+v, %v; want SETTLED", stored, err)
+	}
+
+	stored, err := repo.GetSettlementJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetSettlementJob: %v", err)
+	}
+	if stored.State != model.JobCompleted || stored.Processed != 3 || stored.Summary == nil || stored.Summary.BetsSettled != 3 {
+		t.Fatalf("stored job = %+v, want it COMPLETED with its summary", stored)
+	}
+	if _, err := repo.SettleJobBatch(job.ID, settleSelection("match-1-home"), 2); err == nil {
+		t.Fatal("SettleJobBatch on a COMPLETED job should fail")
+	}
+	completed, err := repo.ListSettlementJobs(model.JobCompleted)
+	if err != nil {
+		t.Fatalf("ListSettlementJobs: %v", err)
+	}
+	if len(completed) != 1 || completed[0].ID != job.ID {
+		t.Fatalf("COMPLETED jobs = %+v, want only %s", completed, job.ID)
+	}
+}
+
+func testCancelEventMidJob(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	for _, id := range []string{"match-1", "match-2"} {
+		if _, err := repo.CreateEvent(sampleEvent(id)); err != nil {
+			t.Fatalf("CreateEvent(%s): %v", id, err)
+		}
+		placeOnSelection(t, repo, id+"-home", "10.00")
+		placeOnSelection(t, repo, id+"-home", "10.00")
+		closeEvent(t, repo, id)
+	}
+	job := mustStartSettlementJob(t, repo, "match-1")
+	job, err := repo.SettleJobBatch(job.ID, settleSelection("match-1-home"), 1)
+	if err != nil {
+		t.Fatalf("SettleJobBatch: %v", err)
+	}
+	assertBalance(t, repo, "alice", "81.00")
+
+	// The event cannot be cancelled under the job.
+	if _, err := repo.SetEventStatus("match-1", model.EventCancelled); err == nil {
+		t.Fatal("cancelling an event mid-job should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("SetEventStatus(CANCELLED) error = %v, want *errors.ErrorConflict", err)
+	}
+	event, err := repo.GetEvent("match-1")
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if event.Status != model.EventClosed {
+		t.Fatalf("event status = %s, want CLOSED", event.Status)
+	}
+
+	// Once the job is cancelled, so can the event be, voiding the bet the
+	// job left.
+	if _, err := repo.CancelSettlementJob(job.ID); err != nil {
+		t.Fatalf("CancelSettlementJob: %v", err)
+	}
+	if _, err := repo.SetEventStatus("match-1", model.EventCancelled); err != nil {
+		t.Fatalf("SetEventStatus(CANCELLED) after the job: %v", err)
+	}
+	summary, err := repo.VoidEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusVoid
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("VoidEvent: %v", err)
+	}
+	if summary.Voided != 1 {
+		t.Fatalf("summary = %+v, want 1 voided", summary)
+	}
+	assertBalance(t, repo, "alice", "91.00")
+
+	// A job whose event is no longer CLOSED fails without settling more.
+	job = mustStartSettlementJob(t, repo, "match-2")
+	if _, err := repo.SetEventStatus("match-2", model.EventSettled); err != nil {
+		t.Fatalf("SetEventStatus(SETTLED): %v", err)
+	}
+	job, err = repo.SettleJobBatch(job.ID, settleSelection("match-2-home"), 10)
+	if err != nil {
+		t.Fatalf("SettleJobBatch on a SETTLED event: %v", err)
+	}
+	if job.State != model.JobFailed || job.Processed != 0 || len(job.Errors) != 1 || job.FinishedAt.IsZero() {
+		t.Fatalf("job = %+v, want FAILED with 1 error and nothing processed", job)
+	}
+	stored, err := repo.GetSettlementJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetSettlementJob: %v", err)
+	}
+	if stored.State != model.JobFailed {
+		t.Fatalf("stored job state = %s, want FAILED", stored.State)
+	}
+	assertBalance(t, repo, "alice", "91.00")
+}
+
+func testCancelSettlementJob(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	placeOnSelection(t, repo, "match-1-home", "10.00")
+	placeOnSelection(t, repo, "match-1-away", "20.00")
+	closeEvent(t, repo, "match-1")
+
+	if _, err := repo.GetSettlementJob("missing"); err == nil {
+		t.Fatal("GetSettlementJob of an unknown job should fail")
+	} else if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("GetSettlementJob error = %v, want *errors.ErrorNotFound", err)
+	}
+
+	job := mustStartSettlementJob(t, repo, "match-1")
+	job, err := repo.SettleJobBatch(job.ID, settleSelection("match-1-home"), 1)
+	if err != nil {
+		t.Fatalf("SettleJobBatch: %v", err)
+	}
+	if job, err = repo.AddSettlementJobError(job.ID, "batch failed"); err != nil {
+		t.Fatalf("AddSettlementJobError: %v", err)
+	}
+	if job, err = repo.CancelSettlementJob(job.ID); err != nil {
+		t.Fatalf("CancelSettlementJob: %v", err)
+	}
+	if job.State != model.JobCancelled || job.Processed != 1 || len(job.Errors) != 1 {
+		t.Fatalf("cancelled job = %+v, want CANCELLED with 1 processed and 1 error", job)
+	}
+
+	// The job stops; what it settled stays settled and the rest stays open.
+	if _, err := repo.SettleJobBatch(job.ID, settleSelection("match-1-home"), 1); err == nil {
+		t.Fatal("SettleJobBatch on a CANCELLED job should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("SettleJobBatch error = %v, want *errors.ErrorConflict", err)
+	}
+	if _, err := repo.CancelSettlementJob(job.ID); err == nil {
+		t.Fatal("cancelling a CANCELLED job should fail")
+	}
+	if _, err := repo.FailSettlementJob(job.ID, "too late"); err == nil {
+		t.Fatal("failing a CANCELLED job should fail")
+	}
+	assertBalance(t, repo, "alice", "91.00")
+	open, err := repo.FindBetsByEvent("match-1")
+	if err != nil {
+		t.Fatalf("FindBetsByEvent: %v", err)
+	}
+	if len(open) != 1 {
+		t.Fatalf("%d bets still PLACED after cancelling, want 1", len(open))
+	}
+	if stored, err := repo.GetEvent("match-1"); err != nil || stored.Status != model.EventClosed {
+		t.Fatalf("event after cancelling = %+v, %v; want CLOSED", stored, err)
+	}
+
+	// A finished job no longer blocks a new one for the event.
+	retry := mustStartSettlementJob(t, repo, "match-1")
+	if retry.Total != 1 {
+		t.Fatalf("retry job total = %d, want the 1 bet left", retry.Total)
+	}
+	if _, err := repo.FailSettlementJob(retry.ID, "event feed down"); err != nil {
+		t.Fatalf("FailSettlementJob: %v", err)
+	}
+	jobs, err := repo.ListSettlementJobs()
+	if err != nil {
+		t.Fatalf("ListSettlementJobs: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != job.ID || jobs[1].ID != retry.ID || jobs[1].State != model.JobFailed {
+		t.Fatalf("jobs = %+v, want %s then %s FAILED", jobs, job.ID, retry.ID)
+	}
+}
+
+// --- settlement corrections ---
+
+// correction returns the audit record of a correction by "ops".
+func correction(eventID string, action model.CorrectionAction) *model.SettlementCorrection {
+	return &model.SettlementCorrection{EventID: eventID, Action: action, Operator: "ops", Reason: "wrong result"}
+}
+
+// settleSelection returns a settler under which bets on selectionID win and
+// every other single bet loses.
+func settleSelection(selectionID string) model.BetSettler {
+	return func(bet *model.Bet) error {
+		bet.Status = model.StatusLost
+		if bet.SelectionID == selectionID {
+			bet.Status = model.StatusWon
+		}
+		return nil
+	}
+}
+
+func testUnsettleEventReopensBets(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	home := placeOnSelection(t, repo, "match-1-home", "10.00")
+	away := placeOnSelection(t, repo, "match-1-away", "10.00")
+	closeEvent(t, repo, "match-1")
+	if _, err := repo.SettleEvent("match-1", settleSelection("match-1-home")); err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	assertBalance(t, repo, "alice", "101.00")
+	// The winnings are spent, so taking them back leaves alice short.
+	if _, err := repo.AdjustBalance("alice", money.MustParse("-100.00"), "", "spent"); err != nil {
+		t.Fatalf("AdjustBalance: %v", err)
+	}
+
+	unsettled, err := repo.CorrectSettlement(correction("match-1", model.CorrectionUnsettle), nil)
+	if err != nil {
+		t.Fatalf("CorrectSettlement(UNSETTLE): %v", err)
+	}
+	if unsettled.ID == "" || unsettled.CreatedAt.IsZero() || unsettled.Settlement != nil {
+		t.Fatalf("correction = %+v, want an ID, a time and no settlement", unsettled)
+	}
+	if unsettled.BetsReopened != 2 || unsettled.ClawedBack[money.EUR] != money.MustParse("21.00") {
+		t.Fatalf("correction = %+v, want 2 bets reopened and 21.00 clawed back", unsettled)
+	}
+	if unsettled.EventStatus != model.EventClosed {
+		t.Fatalf("event status = %s, want CLOSED", unsettled.EventStatus)
+	}
+	assertBalance(t, repo, "alice", "-20.00")
+	for _, bet := range []*model.Bet{home, away} {
+		stored, err := repo.GetBet(bet.ID)
+		if err != nil {
+			t.Fatalf("GetBet: %v", err)
+		}
+		if stored.Status != model.StatusPlaced || !stored.SettledAt.IsZero() || stored.Version != bet.Version+2 {
+			t.Fatalf("reopened bet = %+v, want PLACED at version %d", stored, bet.Version+2)
+		}
+	}
+	if exposure := exposureBySelection(t, repo, "match-1"); len(exposure) != 2 {
+		t.Fatalf("exposure = %+v, want both bets open again", exposure)
+	}
+
+	txs, err := repo.ListTransactions("alice")
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	last := txs[len(txs)-1]
+	if last.Type != model.EntryClawback || last.BetID != home.ID || last.Amount != money.MustParse("-21.00") {
+		t.Fatalf("last transaction = %+v, want a 21.00 clawback of the winning bet", last)
+	}
+
+	// Unsettled, the event can be settled again.
+	summary, err := repo.SettleEvent("match-1", settleSelection("match-1-away"))
+	if err != nil {
+		t.Fatalf("SettleEvent after unsettling: %v", err)
+	}
+	if summary.Won != 1 || summary.Lost != 1 || summary.EventStatus != model.EventSettled {
+		t.Fatalf("summary = %+v, want 1 won, 1 lost and the event SETTLED", summary)
+	}
+	assertBalance(t, repo, "alice", "14.00")
+
+	corrections, err := repo.ListSettlementCorrections("match-1")
+	if err != nil {
+		t.Fatalf("ListSettlementCorrections: %v", err)
+	}
+	if len(corrections) != 1 || corrections[0].ID != unsettled.ID || corrections[0].Operator != "ops" ||
+		corrections[0].Reason != "wrong result" || corrections[0].Action != model.CorrectionUnsettle {
+		t.Fatalf("corrections = %+v, want the unsettlement", corrections)
+	}
+}
+
+func testResettleEventCorrectsResult(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	home := placeOnSelection(t, repo, "match-1-home", "10.00")
+	away := placeOnSelection(t, repo, "match-1-away", "10.00")
+
+	if _, err := repo.CorrectSettlement(correction("match-1", model.CorrectionResettle), settleSelection("match-1-away")); err == nil {
+		t.Fatal("CorrectSettlement on an OPEN event should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("CorrectSettlement error = %v, want *errors.ErrorConflict", err)
+	}
+
+	closeEvent(t, repo, "match-1")
+	if _, err := repo.SettleEvent("match-1", settleSelection("match-1-home")); err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	assertBalance(t, repo, "alice", "101.00")
+
+	// A failing settler changes nothing.
+	_, err := repo.CorrectSettlement(correction("match-1", model.CorrectionResettle), func(bet *model.Bet) error {
+		return fmt.Errorf("result feed down")
+	})
+	if err == nil {
+		t.Fatal("CorrectSettlement should fail when a bet cannot be settled")
+	}
+	assertBalance(t, repo, "alice", "101.00")
+	if stored, err := repo.GetBet(home.ID); err != nil || stored.Status != model.StatusWon {
+		t.Fatalf("GetBet after failed correction = %+v, %v; want WON", stored, err)
+	}
+
+	fixed := correction("match-1", model.CorrectionResettle)
+	fixed.Result = &model.SettleBetRequest{Markets: []model.MarketResult{{MarketID: "match-1-result", WinningSelectionIDs: []string{"match-1-away"}}}}
+	resettled, err := repo.CorrectSettlement(fixed, settleSelection("match-1-away"))
+	if err != nil {
+		t.Fatalf("CorrectSettlement(RESETTLE): %v", err)
+	}
+	if resettled.BetsReopened != 2 || resettled.ClawedBack[money.EUR] != money.MustParse("21.00") {
+		t.Fatalf("correction = %+v, want 2 bets reopened and 21.00 clawed back", resettled)
+	}
+	settlement := resettled.Settlement
+	if settlement == nil || settlement.Won != 1 || settlement.Lost != 1 || eurTotals(settlement).Payout != money.MustParse("34.00") {
+		t.Fatalf("settlement = %+v, want 1 won paying 34.00 and 1 lost", settlement)
+	}
+	if resettled.EventStatus != model.EventSettled {
+		t.Fatalf("event status = %s, want SETTLED", resettled.EventStatus)
+	}
+	assertBalance(t, repo, "alice", "114.00")
+	for bet, want := range map[string]model.BetStatus{home.ID: model.StatusLost, away.ID: model.StatusWon} {
+		stored, err := repo.GetBet(bet)
+		if err != nil {
+			t.Fatalf("GetBet: %v", err)
+		}
+		if stored.Status != want || stored.SettledAt.IsZero() {
+			t.Fatalf("resettled bet = %+v, want %s", stored, want)
+		}
+	}
+	if exposure := exposureBySelection(t, repo, "match-1"); len(exposure) != 0 {
+		t.Fatalf("exposure after resettling = %+v, want none", exposure)
+	}
+
+	corrections, err := repo.ListSettlementCorrections("match-1")
+	if err != nil {
+		t.Fatalf("ListSettlementCorrections: %v", err)
+	}
+	if len(corrections) != 1 || corrections[0].Result == nil || len(corrections[0].Result.Markets) != 1 ||
+		corrections[0].Settlement == nil || corrections[0].Settlement.Won != 1 {
+		t.Fatalf("corrections = %+v, want the resettlement with its result", corrections)
+	}
+}
+
+func testResettleAccumulatorLeg(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	acc := mustPlaceAccumulator(t, repo, "alice", "10.00", leg("match-1", "2"), leg("match-2", "3"))
+	settleLegs(t, repo, "match-1", model.StatusWon)
+	settleLegs(t, repo, "match-2", model.StatusWon)
+	assertBalance(t, repo, "alice", "150.00")
+
+	// Reopening the first leg reopens the bet and takes back its payout,
+	// though it was paid when the second event settled.
+	unsettled, err := repo.CorrectSettlement(correction("match-1", model.CorrectionUnsettle), nil)
+	if err != nil {
+		t.Fatalf("CorrectSettlement(UNSETTLE): %v", err)
+	}
+	if unsettled.BetsReopened != 1 || unsettled.ClawedBack[money.EUR] != money.MustParse("60.00") {
+		t.Fatalf("correction = %+v, want 1 bet reopened and 60.00 clawed back", unsettled)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+	stored, err := repo.GetBet(acc.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if stored.Status != model.StatusPlaced || stored.Legs[0].Status != model.StatusPlaced || stored.Legs[1].Status != model.StatusWon {
+		t.Fatalf("reopened accumulator = %+v, want PLACED with only the second leg won", stored)
+	}
+	if open, err := repo.FindBetsByEvent("match-1"); err != nil || len(open) != 1 {
+		t.Fatalf("FindBetsByEvent(match-1) = %+v, %v; want the accumulator", open, err)
+	}
+
+	resettled, err := repo.CorrectSettlement(correction("match-1", model.CorrectionResettle), func(bet *model.Bet) error {
+		bet.Legs[0].Status = model.StatusVoid
+		bet.Resolve(time.Now())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CorrectSettlement(RESETTLE): %v", err)
+	}
+	// Nothing was left to reopen; the void leg drops out of the odds.
+	if resettled.BetsReopened != 0 || resettled.Settlement.Won != 1 || eurTotals(resettled.Settlement).Payout != money.MustParse("30.00") {
+		t.Fatalf("correction = %+v, settlement = %+v; want the accumulator won paying 30.00", resettled, resettled.Settlement)
+	}
+	assertBalance(t, repo, "alice", "120.00")
+}
+
+func testCorrectionCannotReopenOnSettledEvent(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	for _, id := range []string{"match-1", "match-2"} {
+		if _, err := repo.CreateEvent(sampleEvent(id)); err != nil {
+			t.Fatalf("CreateEvent: %v", err)
+		}
+	}
+	acc := mustPlaceAccumulator(t, repo, "alice", "10.00", leg("match-1", "2"), leg("match-2", "3"))
+	closeEvent(t, repo, "match-1")
+	closeEvent(t, repo, "match-2")
+	// The lost accumulator is skipped when match-2 settles, so its leg on
+	// match-2 stays open.
+	settleLegs(t, repo, "match-1", model.StatusLost)
+	settleLegs(t, repo, "match-2", model.StatusWon)
+
+	winFirstLeg := func(bet *model.Bet) error {
+		bet.Legs[0].Status = model.StatusWon
+		bet.Resolve(time.Now())
+		return nil
+	}
+	for _, c := range []struct {
+		action model.CorrectionAction
+		settle model.BetSettler
+	}{
+		{model.CorrectionUnsettle, nil},
+		{model.CorrectionResettle, winFirstLeg},
+	} {
+		_, err := repo.CorrectSettlement(correction("match-1", c.action), c.settle)
+		if _, ok := err.(*errors.ErrorConflict); !ok {
+			t.Fatalf("CorrectSettlement(%s) error = %v, want *errors.ErrorConflict", c.action, err)
+		}
+	}
+	assertBalance(t, repo, "alice", "90.00")
+	if stored, err := repo.GetBet(acc.ID); err != nil || stored.Status != model.StatusLost {
+		t.Fatalf("GetBet after refused corrections = %+v, %v; want LOST", stored, err)
+	}
+	if stored, err := repo.GetEvent("match-1"); err != nil || stored.Status != model.EventSettled {
+		t.Fatalf("event after refused corrections = %+v, %v; want SETTLED", stored, err)
+	}
+
+	// A correction that decides the bet again does not leave it waiting.
+	resettled, err := repo.CorrectSettlement(correction("match-1", model.CorrectionResettle), func(bet *model.Bet) error {
+		bet.Legs[0].Status = model.StatusLost
+		bet.Resolve(time.Now())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CorrectSettlement(RESETTLE): %v", err)
+	}
+	if resettled.BetsReopened != 1 || resettled.Settlement.Lost != 1 || resettled.EventStatus != model.EventSettled {
+		t.Fatalf("correction = %+v, settlement = %+v; want the accumulator lost again", resettled, resettled.Settlement)
+	}
+}
+
+// --- ledger ---
+
+// --- cash-out ---
+
+func placeOnSelection(t *testing.T, repo Repository, selectionID, amount string) *model.Bet {
+	t.Helper()
+	sel, err := repo.GetSelection(selectionID)
+	if err != nil {
+		t.Fatalf("GetSelection(%s): %v", selectionID, err)
+	}
+	bet, err := repo.PlaceBet(&model.Bet{
+		UserID:      "alice",
+		EventID:     sel.EventID,
+		MarketID:    sel.MarketID,
+		SelectionID: sel.ID,
+		Odds:        sel.Odds,
+		Amount:      money.MustParse(amount),
+	}, nil)
+	if err != nil {
+		t.Fatalf("PlaceBet: %v", err)
+	}
+	return bet
+}
+
+func quote(bet *model.Bet, selectionID, odds string) *model.CashOutQuote {
+	prices := []model.QuotedPrice{{SelectionID: selectionID, Odds: money.MustParseOdds(odds)}}
+	return &model.CashOutQuote{BetID: bet.ID, Stake: bet.OpenStake(), Value: bet.CashOutValue(bet.OpenStake(), prices, 0), Prices: prices}
+}
+
+func testCashOutBet(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	bet := placeOnSelection(t, repo, "match-1-home", "10.00")
+	if _, err := repo.UpdateSelectionOdds("match-1-home", money.MustParseOdds("3")); err != nil {
+		t.Fatalf("UpdateSelectionOdds: %v", err)
+	}
+
+	// 10.00 taken at 2.1, now priced at 3.
+	q := quote(bet, "match-1-home", "3")
+	if q.Value != money.MustParse("7.00") {
+		t.Fatalf("cash-out value = %s, want 7.00", q.Value)
+	}
+	cashed, err := repo.CashOutBet(q)
+	if err != nil {
+		t.Fatalf("CashOutBet: %v", err)
+	}
+	if cashed.Status != model.StatusCashedOut || cashed.CashedOut != q.Value {
+		t.Fatalf("cashed-out bet = %+v", cashed)
+	}
+	assertBalance(t, repo, "alice", "97.00")
+
+	stored, err := repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if stored.Status != model.StatusCashedOut {
+		t.Fatalf("stored status = %s, want CASHED_OUT", stored.Status)
+	}
+	if _, err := repo.CashOutBet(q); err == nil {
+		t.Fatal("cashing out twice should fail")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("second CashOutBet error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "97.00")
+
+	closeEvent(t, repo, "match-1")
+	summary, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusWon
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if summary.BetsSettled != 0 {
+		t.Fatal("settlement should skip a cashed-out bet")
+	}
+}
+
+func testCashOutRejectsStaleQuote(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	bet := placeOnSelection(t, repo, "match-1-home", "10.00")
+
+	stale := quote(bet, "match-1-home", "2.1")
+	if _, err := repo.UpdateSelectionOdds("match-1-home", money.MustParseOdds("2.5")); err != nil {
+		t.Fatalf("UpdateSelectionOdds: %v", err)
+	}
+	_, err := repo.CashOutBet(stale)
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("CashOutBet with stale odds error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+
+	stored, err := repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if stored.Status != model.StatusPlaced {
+		t.Fatalf("bet status after a rejected cash-out = %s, want PLACED", stored.Status)
+	}
+}
+
+func testPartialCashOut(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	bet := placeOnSelection(t, repo, "match-1-home", "10.00")
+	if _, err := repo.UpdateSelectionOdds("match-1-home", money.MustParseOdds("3")); err != nil {
+		t.Fatalf("UpdateSelectionOdds: %v", err)
+	}
+
+	// 4.00 of the 10.00 taken at 2.1, now priced at 3.
+	q := quote(bet, "match-1-home", "3")
+	q.Stake = money.MustParse("4.00")
+	q.Value = bet.CashOutValue(q.Stake, q.Prices, 0)
+	if q.Value != money.MustParse("2.80") {
+		t.Fatalf("cash-out value = %s, want 2.80", q.Value)
+	}
+	cashed, err := repo.CashOutBet(q)
+	if err != nil {
+		t.Fatalf("CashOutBet: %v", err)
+	}
+	if cashed.Status != model.StatusPlaced || cashed.OpenStake() != money.MustParse("6.00") {
+		t.Fatalf("partly cashed-out bet = %+v", cashed)
+	}
+	assertBalance(t, repo, "alice", "92.80")
+
+	// The rest is cashed out at the same price in a second step.
+	stored, err := repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	q = quote(stored, "match-1-home", "3")
+	q.Stake = money.MustParse("1.00")
+	q.Value = stored.CashOutValue(q.Stake, q.Prices, 0)
+	if _, err := repo.CashOutBet(q); err != nil {
+		t.Fatalf("second CashOutBet: %v", err)
+	}
+	stored, err = repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if stored.Amount != money.MustParse("10.00") || stored.CashedOutStake != money.MustParse("5.00") ||
+		stored.CashedOut != money.MustParse("3.50") || len(stored.CashOuts) != 2 {
+		t.Fatalf("stored bet = %+v", stored)
+	}
+	if stored.CashOuts[0].Stake != money.MustParse("4.00") || stored.CashOuts[1].Stake != money.MustParse("1.00") {
+		t.Fatalf("cash-out history = %+v", stored.CashOuts)
+	}
+
+	// Cashing out more than is left fails.
+	q = quote(stored, "match-1-home", "3")
+	q.Stake = money.MustParse("5.01")
+	if _, err := repo.CashOutBet(q); err == nil {
+		t.Fatal("cashing out more than the open stake should fail")
+	}
+
+	// The remaining 5.00 wins at the odds taken.
+	closeEvent(t, repo, "match-1")
+	if _, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusWon
+		return nil
+	}); err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	assertBalance(t, repo, "alice", "104.00")
+}
+
+func testCashOutNeedsOpenEvent(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	bet := placeOnSelection(t, repo, "match-1-home", "10.00")
+
+	// A quote taken while the event is OPEN no longer holds once it is not.
+	q := quote(bet, "match-1-home", "2.1")
+	q.Stake = money.MustParse("4.00")
+	q.Value = bet.CashOutValue(q.Stake, q.Prices, 0)
+	balance := "90.00"
+	for _, status := range []model.EventStatus{model.EventSuspended, model.EventClosed, model.EventCancelled} {
+		if _, err := repo.SetEventStatus("match-1", status); err != nil {
+			t.Fatalf("SetEventStatus(%s): %v", status, err)
+		}
+		if _, err := repo.CashOutBet(q); err == nil {
+			t.Fatalf("CashOutBet on a %s event should fail", status)
+		} else if _, ok := err.(*errors.ErrorConflict); !ok {
+			t.Fatalf("CashOutBet on a %s event error = %v, want *errors.ErrorConflict", status, err)
+		}
+		assertBalance(t, repo, "alice", balance)
+
+		if status == model.EventSuspended {
+			// Resumed, the same quote holds again.
+			if _, err := repo.SetEventStatus("match-1", model.EventOpen); err != nil {
+				t.Fatalf("SetEventStatus(OPEN): %v", err)
+			}
+			if _, err := repo.CashOutBet(q); err != nil {
+				t.Fatalf("CashOutBet on a resumed event: %v", err)
+			}
+			balance = "94.00"
+			assertBalance(t, repo, "alice", balance)
+			stored, err := repo.GetBet(bet.ID)
+			if err != nil {
+				t.Fatalf("GetBet: %v", err)
+			}
+			q = quote(stored, "match-1-home", "2.1")
+		}
+	}
+}
+
+func idempotencyRecord(key string, ttl time.Duration) *model.IdempotencyRecord {
+	now := time.Now()
+	return &model.IdempotencyRecord{Key: key, Fingerprint: "fp-" + key, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+}
+
+// completed returns claim with a response stored for the rest of ttl.
+func completed(claim *model.IdempotencyRecord, statusCode int, body string, ttl time.Duration) *model.IdempotencyRecord {
+	done := claim.Clone()
+	done.StatusCode = statusCode
+	done.Headers = map[string]string{"Content-Type": "application/json", "ETag": `"1"`}
+	done.Body = []byte(body)
+	done.ExpiresAt = claim.CreatedAt.Add(ttl)
+	return done
+}
+
+func testIdempotencyKeyLifecycle(t *testing.T, repo Repository) {
+	claim := idempotencyRecord("k1", time.Minute)
+	existing, err := repo.ClaimIdempotencyKey(claim)
+	if err != nil || existing != nil {
+		t.Fatalf("first claim = %+v, %v; want nil, nil", existing, err)
+	}
+	existing, err = repo.ClaimIdempotencyKey(idempotencyRecord("k1", time.Minute))
+	if err != nil {
+		t.Fatalf("second claim: %v", err)
+	}
+	if existing == nil || existing.Completed() || existing.Fingerprint != "fp-k1" {
+		t.Fatalf("second claim = %+v, want the pending record", existing)
+	}
+
+	done := completed(claim, 201, `{"id":"b1"}`, time.Hour)
+	if err := repo.CompleteIdempotencyKey(done); err != nil {
+		t.Fatalf("CompleteIdempotencyKey: %v", err)
+	}
+	existing, err = repo.ClaimIdempotencyKey(idempotencyRecord("k1", time.Minute))
+	if err != nil {
+		t.Fatalf("claim after completion: %v", err)
+	}
+	if existing == nil || existing.StatusCode != 201 || string(existing.Body) != `{"id":"b1"}` || existing.Headers["ETag"] != `"1"` {
+		t.Fatalf("claim after completion = %+v, want the stored response and headers", existing)
+	}
+	if !existing.ExpiresAt.Equal(done.ExpiresAt) {
+		t.Fatalf("completed record expires at %s, want the TTL's %s", existing.ExpiresAt, done.ExpiresAt)
+	}
+
+	if err := repo.ReleaseIdempotencyKey(claim); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey: %v", err)
+	}
+	if existing, err := repo.ClaimIdempotencyKey(idempotencyRecord("k1", time.Minute)); err != nil || existing != nil {
+		t.Fatalf("claim after release = %+v, %v; want nil, nil", existing, err)
+	}
+
+	if err := repo.CompleteIdempotencyKey(completed(idempotencyRecord("unknown", time.Minute), 200, "", time.Hour)); err == nil {
+		t.Fatal("completing an unclaimed key should fail")
+	} else if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("CompleteIdempotencyKey error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+func testIdempotencyKeyExpires(t *testing.T, repo Repository) {
+	claim := idempotencyRecord("k1", time.Minute)
+	if _, err := repo.ClaimIdempotencyKey(claim); err != nil {
+		t.Fatalf("ClaimIdempotencyKey: %v", err)
+	}
+	if err := repo.CompleteIdempotencyKey(completed(claim, 201, "old", time.Millisecond)); err != nil {
+		t.Fatalf("CompleteIdempotencyKey: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	existing, err := repo.ClaimIdempotencyKey(idempotencyRecord("k1", time.Minute))
+	if err != nil || existing != nil {
+		t.Fatalf("claim of expired key = %+v, %v; want nil, nil", existing, err)
+	}
+}
+
+func testIdempotencyLeaseLapses(t *testing.T, repo Repository) {
+	// A claim whose request never completes, as after a crash.
+	abandoned := idempotencyRecord("k1", time.Millisecond)
+	if _, err := repo.ClaimIdempotencyKey(abandoned); err != nil {
+		t.Fatalf("ClaimIdempotencyKey: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	retry := idempotencyRecord("k1", time.Minute)
+	if existing, err := repo.ClaimIdempotencyKey(retry); err != nil || existing != nil {
+		t.Fatalf("claim after the lease lapsed = %+v, %v; want nil, nil", existing, err)
+	}
+	// The lapsed claim can neither complete nor release the retry's.
+	if _, ok := repo.CompleteIdempotencyKey(completed(abandoned, 201, "late", time.Hour)).(*errors.ErrorNotFound); !ok {
+		t.Fatal("completing a lapsed claim should return *errors.ErrorNotFound")
+	}
+	if err := repo.ReleaseIdempotencyKey(abandoned); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey: %v", err)
+	}
+	existing, err := repo.ClaimIdempotencyKey(idempotencyRecord("k1", time.Minute))
+	if err != nil {
+		t.Fatalf("claim during the retry: %v", err)
+	}
+	if existing == nil || !existing.CreatedAt.Equal(retry.CreatedAt) {
+		t.Fatalf("claim during the retry = %+v, want the retry's pending claim", existing)
+	}
+}
+
+func testConcurrentIdempotencyClaims(t *testing.T, repo Repository) {
+	const n = 20
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		claimed int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			existing, err := repo.ClaimIdempotencyKey(idempotencyRecord("k1", time.Hour))
+			if err != nil {
+				t.Errorf("ClaimIdempotencyKey: %v", err)
+				return
+			}
+			if existing == nil {
+				mu.Lock()
+				claimed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if claimed != 1 {
+		t.Fatalf("%d of %d concurrent claims succeeded, want exactly 1", claimed, n)
+	}
+}
+
+func testAdjustBalance(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	user, err := repo.AdjustBalance("alice", money.MustParse("2.50"), "", "goodwill")
+	if err != nil {
+		t.Fatalf("AdjustBalance: %v", err)
+	}
+	if user.Balance != money.MustParse("12.50") {
+		t.Fatalf("returned balance = %s, want 12.50", user.Balance)
+	}
+	_, err = repo.AdjustBalance("alice", money.MustParse("-12.51"), "", "too much")
+	if _, ok := err.(*errors.ErrorBadRequest); !ok {
+		t.Fatalf("overdrawing AdjustBalance error = %v, want *errors.ErrorBadRequest", err)
+	}
+	assertBalance(t, repo, "alice", "12.50")
+	_, err = repo.AdjustBalance("nobody", money.MustParse("1.00"), "", "x")
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("AdjustBalance on unknown user error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+// --- deposits and withdrawals ---
+
+func walletOp(t *testing.T, repo Repository, userID string, opType model.WalletOperationType, ref, amount string) *model.WalletOperation {
+	t.Helper()
+	op, err := repo.CreateWalletOperation(&model.WalletOperation{
+		UserID:    userID,
+		Type:      opType,
+		Reference: ref,
+		Amount:    money.MustParse(amount),
+	})
+	if err != nil {
+		t.Fatalf("CreateWalletOperation(%s %s): %v", opType, ref, err)
+	}
+	return op
+}
+
+func walletOpIDs(t *testing.T, repo Repository, userID string, opType model.WalletOperationType) []string {
+	t.Helper()
+	ops, err := repo.ListWalletOperations(userID, opType)
+	if err != nil {
+		t.Fatalf("ListWalletOperations(%s, %s): %v", userID, opType, err)
+	}
+	ids := make([]string, len(ops))
+	for i, op := range ops {
+		ids[i] = op.ID + ":" + string(op.Status)
+	}
+	return ids
+}
+
+func testDeposit(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	op := walletOp(t, repo, "alice", model.OperationDeposit, "psp-1", "25.50")
+	if op.ID == "" || op.Status != model.OperationCompleted || op.CreatedAt.IsZero() {
+		t.Fatalf("deposit = %+v, want a COMPLETED operation with an ID", op)
+	}
+	assertBalance(t, repo, "alice", "35.50")
+
+	if got, want := walletOpIDs(t, repo, "alice", model.OperationDeposit), []string{op.ID + ":COMPLETED"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("deposits = %v, want %v", got, want)
+	}
+	if got := walletOpIDs(t, repo, "alice", model.OperationWithdrawal); len(got) != 0 {
+		t.Fatalf("withdrawals = %v, want none", got)
+	}
+	_, err := repo.CreateWalletOperation(&model.WalletOperation{UserID: "nobody", Type: model.OperationDeposit, Reference: "psp-2", Amount: money.MustParse("1.00")})
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("deposit for unknown user error = %v, want *errors.ErrorNotFound", err)
+	}
+	_, err = repo.ListWalletOperations("nobody", model.OperationDeposit)
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("listing operations of an unknown user error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+func testWithdrawalApproved(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("50.00"))
+	op := walletOp(t, repo, "alice", model.OperationWithdrawal, "wd-1", "20.00")
+	if op.Status != model.OperationPending || op.ResolvedAt != nil {
+		t.Fatalf("withdrawal = %s resolved at %v, want PENDING and unresolved", op.Status, op.ResolvedAt)
+	}
+	// The amount is reserved while the withdrawal is pending.
+	assertBalance(t, repo, "alice", "30.00")
+	_, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "e1", Odds: money.MustParseOdds("2.00"), Amount: money.MustParse("30.01")}, nil)
+	if _, ok := err.(*errors.ErrorBadRequest); !ok {
+		t.Fatalf("staking reserved funds error = %v, want *errors.ErrorBadRequest", err)
+	}
+
+	approved, err := repo.ResolveWithdrawal("alice", op.ID, model.OperationApproved, "")
+	if err != nil {
+		t.Fatalf("ResolveWithdrawal: %v", err)
+	}
+	if approved.Status != model.OperationApproved || approved.ResolvedAt == nil {
+		t.Fatalf("approved withdrawal = %+v", approved)
+	}
+	assertBalance(t, repo, "alice", "30.00")
+
+	_, err = repo.ResolveWithdrawal("alice", op.ID, model.OperationRejected, "too late")
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("resolving an approved withdrawal error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "30.00")
+	if got, want := walletOpIDs(t, repo, "alice", model.OperationWithdrawal), []string{op.ID + ":APPROVED"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("withdrawals = %v, want %v", got, want)
+	}
+}
+
+func testWithdrawalRejected(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("50.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("50.00"))
+	op := walletOp(t, repo, "alice", model.OperationWithdrawal, "wd-1", "20.00")
+
+	_, err := repo.ResolveWithdrawal("bob", op.ID, model.OperationRejected, "")
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("resolving another user's withdrawal error = %v, want *errors.ErrorNotFound", err)
+	}
+	_, err = repo.ResolveWithdrawal("alice", "missing", model.OperationRejected, "")
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("resolving a missing withdrawal error = %v, want *errors.ErrorNotFound", err)
+	}
+
+	rejected, err := repo.ResolveWithdrawal("alice", op.ID, model.OperationRejected, "card expired")
+	if err != nil {
+		t.Fatalf("ResolveWithdrawal: %v", err)
+	}
+	if rejected.Status != model.OperationRejected || rejected.Reason != "card expired" {
+		t.Fatalf("rejected withdrawal = %+v", rejected)
+	}
+	assertBalance(t, repo, "alice", "50.00")
+
+	txs, err := repo.ListTransactions("alice")
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	if got, want := describe(txs), "[ADJUSTMENT 50.00 -> 50.00] [WITHDRAWAL -20.00 -> 30.00] [WITHDRAWAL_REVERSAL 20.00 -> 50.00] "; got != want {
+		t.Fatalf("transactions = %s, want %s", got, want)
+	}
+}
+
+func testWithdrawalInsufficientBalance(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	walletOp(t, repo, "alice", model.OperationWithdrawal, "wd-1", "6.00")
+	_, err := repo.CreateWalletOperation(&model.WalletOperation{UserID: "alice", Type: model.OperationWithdrawal, Reference: "wd-2", Amount: money.MustParse("4.01")})
+	if _, ok := err.(*errors.ErrorBadRequest); !ok {
+		t.Fatalf("withdrawing more than the balance error = %v, want *errors.ErrorBadRequest", err)
+	}
+	assertBalance(t, repo, "alice", "4.00")
+	// The rejected request did not use up its reference.
+	walletOp(t, repo, "alice", model.OperationWithdrawal, "wd-2", "4.00")
+	assertBalance(t, repo, "alice", "0.00")
+}
+
+func testWalletReferencesUnique(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("10.00"))
+	walletOp(t, repo, "alice", model.OperationDeposit, "ref-1", "5.00")
+
+	for _, op := range []*model.WalletOperation{
+		{UserID: "alice", Type: model.OperationDeposit, Reference: "ref-1", Amount: money.MustParse("5.00")},
+		{UserID: "bob", Type: model.OperationDeposit, Reference: "ref-1", Amount: money.MustParse("5.00")},
+		{UserID: "alice", Type: model.OperationWithdrawal, Reference: "ref-1", Amount: money.MustParse("1.00")},
+	} {
+		_, err := repo.CreateWalletOperation(op)
+		if _, ok := err.(*errors.ErrorConflict); !ok {
+			t.Fatalf("%s by %s reusing a reference error = %v, want *errors.ErrorConflict", op.Type, op.UserID, err)
+		}
+	}
+	assertBalance(t, repo, "alice", "15.00")
+	assertBalance(t, repo, "bob", "10.00")
+}
+
+func testDeleteUserWithPendingWithdrawal(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	op := walletOp(t, repo, "alice", model.OperationWithdrawal, "wd-1", "4.00")
+	if _, ok := repo.DeleteUser("alice", 0).(*errors.ErrorConflict); !ok {
+		t.Fatal("deleting a user with a pending withdrawal should return *errors.ErrorConflict")
+	}
+	if _, err := repo.ResolveWithdrawal("alice", op.ID, model.OperationApproved, ""); err != nil {
+		t.Fatalf("ResolveWithdrawal: %v", err)
+	}
+	if err := repo.DeleteUser("alice", 0); err != nil {
+		t.Fatalf("DeleteUser after the withdrawal was approved: %v", err)
+	}
+}
+
+// --- currencies ---
+
+func testDeleteUserWithOpenBets(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	bet := mustPlaceBet(t, repo, "alice", "match-1", "2", "4.00")
+	if _, ok := repo.DeleteUser("alice", 0).(*errors.ErrorConflict); !ok {
+		t.Fatal("deleting a user with an open bet should return *errors.ErrorConflict")
+	}
+	if err := settle(t, repo, bet, model.StatusWon); err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+	if err := repo.DeleteUser("alice", 0); err != nil {
+		t.Fatalf("DeleteUser after the bet was settled: %v", err)
+	}
+}
+
+func testCreateUserInCurrency(t *testing.T, repo Repository) {
+	user := mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	if user.Currency != money.DefaultCurrency {
+		t.Fatalf("currency = %s, want the default %s", user.Currency, money.DefaultCurrency)
+	}
+
+	user, err := repo.CreateUser(&model.User{ID: "bob", Currency: money.GBP, Balance: money.MustParse("20.00")})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if user.Currency != money.GBP || user.Balance != money.MustParse("20.00") || fmt.Sprint(user.Balances) != "map[GBP:20.00]" {
+		t.Fatalf("user = %+v, want a single GBP wallet holding 20.00", user)
+	}
+	assertBalance(t, repo, "bob", "20.00")
+	bet := mustPlaceBet(t, repo, "bob", "match-1", "2", "5.00")
+	if bet.Currency != money.GBP {
+		t.Fatalf("bet currency = %s, want the user's GBP", bet.Currency)
+	}
+	assertBalance(t, repo, "bob", "15.00")
+}
+
+func testCurrencyWallets(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	// A deposit in a new currency opens a wallet in it.
+	_, err := repo.CreateWalletOperation(&model.WalletOperation{UserID: "alice", Type: model.OperationDeposit, Reference: "psp-1", Amount: money.MustParse("50.00"), Currency: money.GBP})
+	if err != nil {
+		t.Fatalf("GBP deposit: %v", err)
+	}
+	bet, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2.5"), Amount: money.MustParse("20.00"), Currency: money.GBP}, nil)
+	if err != nil {
+		t.Fatalf("GBP bet: %v", err)
+	}
+	if err := settle(t, repo, bet, model.StatusWon); err != nil {
+		t.Fatalf("UpdateBet: %v", err)
+	}
+	got, err := repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if got.Currency != money.GBP {
+		t.Fatalf("stored bet currency = %s, want GBP", got.Currency)
+	}
+
+	// The payout lands in the GBP wallet; the EUR one is untouched.
+	user, err := repo.GetUser("alice")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user.Balance != money.MustParse("100.00") || fmt.Sprint(user.Balances) != "map[EUR:100.00 GBP:80.00]" {
+		t.Fatalf("balances = %s %v, want 100.00 and map[EUR:100.00 GBP:80.00]", user.Balance, user.Balances)
+	}
+	if _, err := repo.AdjustBalance("alice", money.MustParse("-80.01"), money.GBP, "too much"); err == nil {
+		t.Fatal("overdrawing the GBP wallet should fail even with EUR to spare")
+	}
+
+	txs, err := repo.ListTransactions("alice")
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	if got, want := describe(txs), "[ADJUSTMENT 100.00 -> 100.00] [DEPOSIT 50.00 -> 50.00] [STAKE -20.00 -> 30.00] [PAYOUT 50.00 -> 80.00] "; got != want {
+		t.Fatalf("transactions = %s, want %s", got, want)
+	}
+	for i, want := range []money.Currency{money.EUR, money.GBP, money.GBP, money.GBP} {
+		if txs[i].Currency != want {
+			t.Fatalf("transaction %d currency = %s, want %s", i, txs[i].Currency, want)
+		}
+	}
+
+	stats, err := repo.UserBetStats("alice")
+	if err != nil {
+		t.Fatalf("UserBetStats: %v", err)
+	}
+	if len(stats) != 1 || stats[money.GBP] == nil || stats[money.GBP].TotalReturned != money.MustParse("50.00") {
+		t.Fatalf("stats = %v, want GBP stats returning 50.00", stats)
+	}
+}
+
+func testStakeNeedsWalletInCurrency(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	// Stakes are never converted: 100.00 EUR does not cover a USD bet.
+	_, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("1.00"), Currency: money.USD}, nil)
+	if _, ok := err.(*errors.ErrorBadRequest); !ok {
+		t.Fatalf("bet without a USD wallet error = %v, want *errors.ErrorBadRequest", err)
+	}
+	_, err = repo.CreateWalletOperation(&model.WalletOperation{UserID: "alice", Type: model.OperationWithdrawal, Reference: "wd-1", Amount: money.MustParse("1.00"), Currency: money.USD})
+	if _, ok := err.(*errors.ErrorBadRequest); !ok {
+		t.Fatalf("withdrawal without a USD wallet error = %v, want *errors.ErrorBadRequest", err)
+	}
+	user, err := repo.GetUser("alice")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if fmt.Sprint(user.Balances) != "map[EUR:100.00]" {
+		t.Fatalf("balances = %v, want map[EUR:100.00]", user.Balances)
+	}
+}
+
+func testSettlementTotalsPerCurrency(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateUser(&model.User{ID: "bob", Currency: money.GBP, Balance: money.MustParse("100.00")}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+	mustPlaceBet(t, repo, "bob", "match-1", "3", "10.00")
+
+	summary, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusWon
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if eurTotals(summary).Payout != money.MustParse("20.00") || summary.Totals[money.GBP] == nil || summary.Totals[money.GBP].Payout != money.MustParse("30.00") {
+		t.Fatalf("totals = %v, want 20.00 EUR and 30.00 GBP", summary.Totals)
+	}
+	assertBalance(t, repo, "alice", "110.00")
+	assertBalance(t, repo, "bob", "120.00")
+}
+
+func testTransactionsExplainBalance(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	won := mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+	lost := mustPlaceBet(t, repo, "alice", "match-2", "2", "20.00")
+	if err := settle(t, repo, won, model.StatusWon); err != nil {
+		t.Fatalf("UpdateBet: %v", err)
+	}
+	if err := settle(t, repo, lost, model.StatusLost); err != nil {
+		t.Fatalf("UpdateBet: %v", err)
+	}
+	if _, err := repo.AdjustBalance("alice", money.MustParse("-5.00"), "", "fee"); err != nil {
+		t.Fatalf("AdjustBalance: %v", err)
+	}
+
+	txs, err := repo.ListTransactions("alice")
+	if err != nil {
+		t.Fatalf("ListTransactions: %v", err)
+	}
+	wantTypes := []model.EntryType{model.EntryAdjustment, model.EntryStake, model.EntryStake, model.EntryPayout, model.EntryAdjustment}
+	if len(txs) != len(wantTypes) {
+		t.Fatalf("got %d transactions, want %d: %s", len(txs), len(wantTypes), describe(txs))
+	}
+	var sum money.Money
+	for i, tx := range txs {
+		if tx.Type != wantTypes[i] {
+			t.Fatalf("transaction %d type = %s, want %s: %s", i, tx.Type, wantTypes[i], describe(txs))
+		}
+		sum += tx.Amount
+		if tx.BalanceAfter != sum {
+			t.Fatalf("transaction %d balance_after = %s, want running sum %s", i, tx.BalanceAfter, sum)
+		}
+	}
+	assertBalance(t, repo, "alice", sum.String())
+
+	if _, err := repo.ListTransactions("nobody"); err == nil {
+		t.Fatal("ListTransactions on unknown user should fail")
+	}
+}
+
+func testConcurrentPlaceBet(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("10.00"))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	placed := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: fmt.Sprintf("match-%d", i), Odds: money.MustParseOdds("2"), Amount: money.MustParse("1.00")}, nil)
+			if err == nil {
+				mu.Lock()
+				placed++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if placed != 10 {
+		t.Fatalf("%d concurrent bets of 1.00 were accepted on a 10.00 balance, want 10", placed)
+	}
+	assertBalance(t, repo, "alice", "0.00")
+}
+
+func describe(txs []*model.Transaction) string {
+	s := ""
+	for _, tx := range txs {
+		s += fmt.Sprintf("[%s %s -> %s] ", tx.Type, tx.Amount, tx.BalanceAfter)
+	}
+	return s
+}
+
+// --- free bets ---
+
+func testFreeBetLifecycle(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("100.00"))
+	_, err := repo.CreateFreeBet(&model.FreeBet{UserID: "nobody", Value: money.MustParse("5.00"), ExpiresAt: time.Now().Add(time.Hour)})
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("CreateFreeBet for unknown user error = %v, want *errors.ErrorNotFound", err)
+	}
+
+	used := mustGrantFreeBet(t, repo, &model.FreeBet{UserID: "alice", Value: money.MustParse("5.00")})
+	if used.ID == "" || used.Status != model.FreeBetActive || used.Currency != money.EUR || used.CreatedAt.IsZero() {
+		t.Fatalf("granted free bet = %+v", used)
+	}
+	revoked := mustGrantFreeBet(t, repo, &model.FreeBet{UserID: "alice", Value: money.MustParse("2.00")})
+
+	// Only the owner can stake the token, and staking it leaves the wallet alone.
+	_, err = repo.PlaceBet(&model.Bet{UserID: "bob", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00"), FreeBetID: used.ID}, nil)
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("staking another user's free bet error = %v, want *errors.ErrorNotFound", err)
+	}
+	bet, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00"), FreeBetID: used.ID}, nil)
+	if err != nil {
+		t.Fatalf("PlaceBet with free bet: %v", err)
+	}
+	assertBalance(t, repo, "alice", "100.00")
+	stored, err := repo.GetBet(bet.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if stored.FreeBetID != used.ID {
+		t.Fatalf("stored bet free bet = %q, want %q", stored.FreeBetID, used.ID)
+	}
+	_, err = repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00"), FreeBetID: used.ID}, nil)
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("reusing a free bet error = %v, want *errors.ErrorConflict", err)
+	}
+
+	if _, err := repo.RevokeFreeBet("bob", revoked.ID, "wrong user"); err == nil {
+		t.Fatal("revoking another user's free bet succeeded")
+	}
+	got, err := repo.RevokeFreeBet("alice", revoked.ID, "issued by mistake")
+	if err != nil {
+		t.Fatalf("RevokeFreeBet: %v", err)
+	}
+	if got.Status != model.FreeBetRevoked || got.Reason != "issued by mistake" || got.ClosedAt.IsZero() {
+		t.Fatalf("revoked free bet = %+v", got)
+	}
+	if _, err := repo.RevokeFreeBet("alice", used.ID, ""); err == nil {
+		t.Fatal("revoking a used free bet succeeded")
+	} else if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("revoking a used free bet error = %v, want *errors.ErrorConflict", err)
+	}
+	_, err = repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("2.00"), FreeBetID: revoked.ID}, nil)
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("staking a revoked free bet error = %v, want *errors.ErrorConflict", err)
+	}
+
+	list, err := repo.ListFreeBets("alice")
+	if err != nil {
+		t.Fatalf("ListFreeBets: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != used.ID || list[0].Status != model.FreeBetUsed || list[0].BetID != bet.ID || list[1].Status != model.FreeBetRevoked {
+		t.Fatalf("free bets = %+v, want the used token then the revoked one", list)
+	}
+}
+
+func testFreeBetWinPaysWinningsOnly(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	freeBet := mustGrantFreeBet(t, repo, &model.FreeBet{UserID: "alice", Value: money.MustParse("10.00")})
+	if _, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("3.5"), Amount: money.MustParse("10.00"), FreeBetID: freeBet.ID}, nil); err != nil {
+		t.Fatalf("PlaceBet: %v", err)
+	}
+
+	summary, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusWon
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	// 10.00 × (3.5 − 1): the stake is not returned.
+	if eurTotals(summary).Payout != money.MustParse("25.00") {
+		t.Fatalf("payout = %s, want 25.00", eurTotals(summary).Payout)
+	}
+	assertBalance(t, repo, "alice", "125.00")
+
+	stats, err := repo.UserBetStats("alice")
+	if err != nil {
+		t.Fatalf("UserBetStats: %v", err)
+	}
+	if s := stats[money.EUR]; s == nil || !s.TotalStaked.IsZero() || s.TotalReturned != money.MustParse("25.00") || s.NetPnL != money.MustParse("25.00") {
+		t.Fatalf("stats = %+v, want nothing staked and 25.00 returned", stats[money.EUR])
+	}
+}
+
+func testFreeBetVoidPaysNothing(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	freeBet := mustGrantFreeBet(t, repo, &model.FreeBet{UserID: "alice", Value: money.MustParse("10.00")})
+	if _, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("10.00"), FreeBetID: freeBet.ID}, nil); err != nil {
+		t.Fatalf("PlaceBet: %v", err)
+	}
+	summary, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusVoid
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if summary.Voided != 1 || !eurTotals(summary).Refunded.IsZero() {
+		t.Fatalf("summary = %+v, want 1 voided and nothing refunded", summary)
+	}
+	assertBalance(t, repo, "alice", "100.00")
+}
+
+func testFreeBetEligibility(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	freeBet := mustGrantFreeBet(t, repo, &model.FreeBet{UserID: "alice", Value: money.MustParse("5.00"),
+		EventIDs: []string{"match-1", "match-2"}, MinOdds: money.MustParseOdds("1.5")})
+	stored, err := repo.GetFreeBet(freeBet.ID)
+	if err != nil {
+		t.Fatalf("GetFreeBet: %v", err)
+	}
+	if fmt.Sprint(stored.EventIDs) != "[match-1 match-2]" || stored.MinOdds != money.MustParseOdds("1.5") {
+		t.Fatalf("stored free bet = %+v", stored)
+	}
+
+	for _, bet := range []*model.Bet{
+		{EventID: "match-3", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00")},
+		{EventID: "match-1", Odds: money.MustParseOdds("1.4"), Amount: money.MustParse("5.00")},
+		{EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("4.00")},
+		{EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00"), Currency: money.GBP},
+	} {
+		bet.UserID, bet.FreeBetID = "alice", freeBet.ID
+		_, err := repo.PlaceBet(bet, nil)
+		if _, ok := err.(*errors.ErrorBadRequest); !ok {
+			t.Fatalf("ineligible free bet on %s at %s for %s %s error = %v, want *errors.ErrorBadRequest", bet.EventID, bet.Odds, bet.Amount, bet.Currency, err)
+		}
+	}
+	if _, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-2", Odds: money.MustParseOdds("1.5"), Amount: money.MustParse("5.00"), FreeBetID: freeBet.ID}, nil); err != nil {
+		t.Fatalf("eligible free bet: %v", err)
+	}
+	_, err = repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00"), FreeBetID: "no-such-token"}, nil)
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("unknown free bet error = %v, want *errors.ErrorNotFound", err)
+	}
+}
+
+func testExpireFreeBets(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	now := time.Now()
+	soon := mustGrantFreeBet(t, repo, &model.FreeBet{UserID: "alice", Value: money.MustParse("5.00"), ExpiresAt: now.Add(time.Minute)})
+	later := mustGrantFreeBet(t, repo, &model.FreeBet{UserID: "alice", Value: money.MustParse("5.00"), ExpiresAt: now.Add(time.Hour)})
+
+	expired, err := repo.ExpireFreeBets(now)
+	if err != nil {
+		t.Fatalf("ExpireFreeBets: %v", err)
+	}
+	if len(expired) != 0 {
+		t.Fatalf("expired %d free bets before any lapsed", len(expired))
+	}
+	expired, err = repo.ExpireFreeBets(now.Add(2 * time.Minute))
+	if err != nil {
+		t.Fatalf("ExpireFreeBets: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != soon.ID || expired[0].Status != model.FreeBetExpired || !expired[0].ClosedAt.Equal(soon.ExpiresAt) {
+		t.Fatalf("expired = %+v, want only %s", expired, soon.ID)
+	}
+	_, err = repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00"), FreeBetID: soon.ID}, nil)
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("staking an expired free bet error = %v, want *errors.ErrorConflict", err)
+	}
+	stored, err := repo.GetFreeBet(later.ID)
+	if err != nil {
+		t.Fatalf("GetFreeBet: %v", err)
+	}
+	if stored.Status != model.FreeBetActive {
+		t.Fatalf("unexpired free bet status = %s, want ACTIVE", stored.Status)
+	}
+	if expired, err := repo.ExpireFreeBets(now.Add(2 * time.Minute)); err != nil || len(expired) != 0 {
+		t.Fatalf("second sweep = %v, %v; want nothing", expired, err)
+	}
+}
+
+// --- boosts ---
+
+func testBoostLifecycle(t *testing.T, repo Repository) {
+	boost := mustCreateBoost(t, repo, "match-1", 1)
+	if boost.ID == "" || boost.Status != model.BoostActive || boost.CreatedAt.IsZero() {
+		t.Fatalf("created boost = %+v", boost)
+	}
+	second := mustCreateBoost(t, repo, "match-2", 1)
+
+	got, err := repo.GetBoost(boost.ID)
+	if err != nil {
+		t.Fatalf("GetBoost: %v", err)
+	}
+	if got.EventID != "match-1" || got.IncreaseBps != 2500 || got.MaxStake != money.MustParse("50.00") || got.Currency != money.EUR ||
+		got.MaxUsesPerUser != 1 || !got.EndsAt.Equal(boost.EndsAt) {
+		t.Fatalf("stored boost = %+v, want %+v", got, boost)
+	}
+	if _, err := repo.GetBoost("no-such-boost"); err == nil {
+		t.Fatal("GetBoost of an unknown boost succeeded")
+	} else if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("GetBoost error = %v, want *errors.ErrorNotFound", err)
+	}
+
+	cancelled, err := repo.CancelBoost(second.ID)
+	if err != nil {
+		t.Fatalf("CancelBoost: %v", err)
+	}
+	if cancelled.Status != model.BoostCancelled || cancelled.CancelledAt.IsZero() {
+		t.Fatalf("cancelled boost = %+v", cancelled)
+	}
+	_, err = repo.CancelBoost(second.ID)
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("cancelling twice error = %v, want *errors.ErrorConflict", err)
+	}
+
+	boosts, err := repo.ListBoosts()
+	if err != nil {
+		t.Fatalf("ListBoosts: %v", err)
+	}
+	if len(boosts) != 2 || boosts[0].ID != boost.ID || boosts[1].Status != model.BoostCancelled {
+		t.Fatalf("boosts = %+v, want both, oldest first", boosts)
+	}
+
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	_, err = placeBoosted(repo, second, "alice", "2", "10.00")
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("bet with a cancelled boost error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "100.00")
+}
+
+func testBoostUsageLimit(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("100.00"))
+	boost := mustCreateBoost(t, repo, "match-1", 2)
+
+	first, err := placeBoosted(repo, boost, "alice", "2", "10.00")
+	if err != nil {
+		t.Fatalf("first boosted bet: %v", err)
+	}
+	stored, err := repo.GetBet(first.ID)
+	if err != nil {
+		t.Fatalf("GetBet: %v", err)
+	}
+	if stored.BoostID != boost.ID || stored.Odds != money.MustParseOdds("2.25") || stored.OriginalOdds != money.MustParseOdds("2") {
+		t.Fatalf("stored bet = %+v, want boosted odds 2.25 over 2", stored)
+	}
+	if _, err := placeBoosted(repo, boost, "alice", "2", "10.00"); err != nil {
+		t.Fatalf("second boosted bet: %v", err)
+	}
+	_, err = placeBoosted(repo, boost, "alice", "2", "10.00")
+	if _, ok := err.(*errors.ErrorConflict); !ok {
+		t.Fatalf("boosted bet over the usage limit error = %v, want *errors.ErrorConflict", err)
+	}
+	assertBalance(t, repo, "alice", "80.00")
+	// The limit is per user.
+	if _, err := placeBoosted(repo, boost, "bob", "2", "10.00"); err != nil {
+		t.Fatalf("boosted bet by another user: %v", err)
+	}
+	mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+
+	bets, err := repo.FindBetsByBoost(boost.ID)
+	if err != nil {
+		t.Fatalf("FindBetsByBoost: %v", err)
+	}
+	if len(bets) != 3 || bets[0].ID != first.ID || bets[2].UserID != "bob" {
+		t.Fatalf("boosted bets = %d, want alice's two then bob's", len(bets))
+	}
+}
+
+func testBoostCost(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	boost := mustCreateBoost(t, repo, "match-1", 2)
+	if _, err := placeBoosted(repo, boost, "alice", "3", "10.00"); err != nil {
+		t.Fatalf("boosted bet: %v", err)
+	}
+	mustPlaceBet(t, repo, "alice", "match-1", "3", "10.00")
+
+	if _, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusWon
+		return nil
+	}); err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	// 10.00 at 3.5 (3 boosted by 25%) pays 35.00, 5.00 more than at 3.
+	assertBalance(t, repo, "alice", "145.00")
+	stats, err := repo.UserBetStats("alice")
+	if err != nil {
+		t.Fatalf("UserBetStats: %v", err)
+	}
+	if s := stats[money.EUR]; s == nil || s.BoostedBets != 1 || s.BoostCost != money.MustParse("5.00") || s.TotalReturned != money.MustParse("65.00") {
+		t.Fatalf("stats = %+v, want 1 boosted bet costing 5.00", stats[money.EUR])
+	}
+
+	bets, err := repo.FindBetsByBoost(boost.ID)
+	if err != nil {
+		t.Fatalf("FindBetsByBoost: %v", err)
+	}
+	report, err := model.SummarizeBoost(boost, bets, money.MustParseFXTable("EUR=1"), money.EUR)
+	if err != nil {
+		t.Fatalf("SummarizeBoost: %v", err)
+	}
+	if report.Bets != 1 || report.TotalCost != money.MustParse("5.00") || report.Totals[money.EUR].Returned != money.MustParse("35.00") {
+		t.Fatalf("report = %+v, want 1 bet costing 5.00", report)
+	}
+}
+
+// --- limits ---
+
+func testLimitsLifecycle(t *testing.T, repo Repository) {
+	if _, err := repo.GetLimits(model.LimitScopeGlobal, ""); err == nil {
+		t.Fatal("GetLimits before any were set succeeded")
+	} else if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("GetLimits error = %v, want *errors.ErrorNotFound", err)
+	}
+	for _, l := range []*model.Limits{
+		{Scope: model.LimitScopeUser, ScopeID: "alice", MaxStake: money.MustParse("50.00"), Currency: money.EUR},
+		{Scope: model.LimitScopeEvent, ScopeID: "match-1", MaxEventLiability: money.MustParse("500.00"), Currency: money.EUR},
+		{Scope: model.LimitScopeGlobal, MaxStake: money.MustParse("100.00"), Currency: money.EUR},
+	} {
+		if _, err := repo.SaveLimits(l); err != nil {
+			t.Fatalf("SaveLimits(%s %s): %v", l.Scope, l.ScopeID, err)
+		}
+	}
+	// Saving again replaces every limit of the scope.
+	if _, err := repo.SaveLimits(&model.Limits{Scope: model.LimitScopeGlobal, MinStake: money.MustParse("1.00"), MaxPayout: money.MustParse("1000.00"), Currency: money.GBP}); err != nil {
+		t.Fatalf("SaveLimits(global) again: %v", err)
+	}
+	global, err := repo.GetLimits(model.LimitScopeGlobal, "")
+	if err != nil {
+		t.Fatalf("GetLimits(global): %v", err)
+	}
+	if global.MinStake != money.MustParse("1.00") || !global.MaxStake.IsZero() || global.MaxPayout != money.MustParse("1000.00") ||
+		global.Currency != money.GBP || global.UpdatedAt.IsZero() {
+		t.Fatalf("global limits = %+v, want the replaced set", global)
+	}
+
+	all, err := repo.ListLimits()
+	if err != nil {
+		t.Fatalf("ListLimits: %v", err)
+	}
+	if len(all) != 3 || all[0].Scope != model.LimitScopeGlobal || all[1].ScopeID != "match-1" || all[2].ScopeID != "alice" {
+		t.Fatalf("ListLimits = %d sets, want global, event then user", len(all))
+	}
+
+	if err := repo.DeleteLimits(model.LimitScopeEvent, "match-1"); err != nil {
+		t.Fatalf("DeleteLimits: %v", err)
+	}
+	if err := repo.DeleteLimits(model.LimitScopeEvent, "match-1"); err == nil {
+		t.Fatal("deleting limits twice succeeded")
+	} else if _, ok := err.(*errors.ErrorNotFound); !ok {
+		t.Fatalf("DeleteLimits again error = %v, want *errors.ErrorNotFound", err)
+	}
+	if l, err := repo.GetLimits(model.LimitScopeUser, "alice"); err != nil || l.MaxStake != money.MustParse("50.00") {
+		t.Fatalf("GetLimits(alice) = %+v, %v; want max stake 50.00", l, err)
+	}
+}
+
+func testPlaceBetMeasuresLiability(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustCreateUser(t, repo, "bob", money.MustParse("100.00"))
+	mustPlaceBet(t, repo, "alice", "match-1", "2", "10.00")
+	mustPlaceBet(t, repo, "bob", "match-1", "3", "5.00")
+	lost := mustPlaceBet(t, repo, "bob", "match-1", "2", "1.00")
+	if err := settle(t, repo, lost, model.StatusLost); err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+	mustPlaceBet(t, repo, "alice", "match-2", "1.5", "10.00")
+
+	refused := &errors.ErrorLimitExceeded{Code: "TEST", Message: "refused"}
+	var seen *model.PlacementLiability
+	_, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00")},
+		func(bet *model.Bet, liability *model.PlacementLiability) error {
+			if bet.Currency != money.EUR {
+				t.Errorf("check saw currency %q, want the user's", bet.Currency)
+			}
+			seen = liability
+			return refused
+		})
+	if err != refused {
+		t.Fatalf("PlaceBet error = %v, want the check's error as is", err)
+	}
+	assertBalance(t, repo, "alice", "80.00")
+	// Open bets on match-1 risk 10.00 and 10.00; bob's lost bet no longer
+	// counts. alice's bets today risk 10.00 and 5.00.
+	if got := seen.Events["match-1"][money.EUR]; got != money.MustParse("20.00") {
+		t.Fatalf("event liability = %s, want 20.00", got)
+	}
+	if got := seen.Daily[money.EUR]; got != money.MustParse("15.00") {
+		t.Fatalf("daily liability = %s, want 15.00", got)
+	}
+
+	if _, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds("2"), Amount: money.MustParse("5.00")},
+		func(*model.Bet, *model.PlacementLiability) error { return nil }); err != nil {
+		t.Fatalf("PlaceBet with a passing check: %v", err)
+	}
+	assertBalance(t, repo, "alice", "75.00")
+}
+
+func testPlaceBetWithinLimits(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	limits := &model.Limits{MaxEventLiability: money.MustParse("25.00"), Currency: money.EUR}
+	fx := money.MustParseFXTable("EUR=1")
+	check := func(bet *model.Bet, liability *model.PlacementLiability) error {
+		return model.CheckLimits(limits, bet, liability, fx)
+	}
+	place := func(odds, amount string) error {
+		_, err := repo.PlaceBet(&model.Bet{UserID: "alice", EventID: "match-1", Odds: money.MustParseOdds(odds), Amount: money.MustParse(amount)}, check)
+		return err
+	}
+
+	if err := place("2", "10.00"); err != nil {
+		t.Fatalf("bet within the limit: %v", err)
+	}
+	// 10.00 at 3 would add 20.00 to the 10.00 already at risk.
+	err := place("3", "10.00")
+	breach, ok := err.(*model.LimitBreach)
+	if !ok || breach.Code != model.LimitEventLiability || breach.MaxStake != money.MustParse("7.50") {
+		t.Fatalf("bet over the limit error = %#v, want an event liability breach allowing 7.50", err)
+	}
+	assertBalance(t, repo, "alice", "90.00")
+	if err := place("3", "7.50"); err != nil {
+		t.Fatalf("bet at the largest allowed stake: %v", err)
+	}
+	assertBalance(t, repo, "alice", "82.50")
+}
+
+// --- exposure ---
+
+// exposureBySelection returns an event's exposure entries by selection ID.
+func exposureBySelection(t *testing.T, repo Repository, eventID string) map[string]model.ExposureEntry {
+	t.Helper()
+	entries, err := repo.EventExposure(eventID)
+	if err != nil {
+		t.Fatalf("EventExposure(%s): %v", eventID, err)
+	}
+	bySelection := make(map[string]model.ExposureEntry, len(entries))
+	for _, e := range entries {
+		if _, dup := bySelection[e.SelectionID]; dup {
+			t.Fatalf("EventExposure(%s) has two entries for selection %q", eventID, e.SelectionID)
+		}
+		bySelection[e.SelectionID] = *e
+	}
+	return bySelection
+}
+
+func assertExposure(t *testing.T, got model.ExposureEntry, bets int, stake, payout string) {
+	t.Helper()
+	if got.Bets != bets || got.Stake != money.MustParse(stake) || got.Payout != money.MustParse(payout) {
+		t.Fatalf("exposure of %s = %+v, want %d bets staking %s to pay %s", got.SelectionID, got, bets, stake, payout)
+	}
+}
+
+func testExposureTracksOpenBets(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	if _, err := repo.CreateEvent(sampleEvent("match-1")); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	big := placeOnSelection(t, repo, "match-1-home", "10.00")
+	placeOnSelection(t, repo, "match-1-home", "5.00")
+	away := placeOnSelection(t, repo, "match-1-away", "4.00")
+
+	exposure := exposureBySelection(t, repo, "match-1")
+	if len(exposure) != 2 {
+		t.Fatalf("exposure = %+v, want home and away", exposure)
+	}
+	home := exposure["match-1-home"]
+	if home.MarketID != "match-1-result" || home.Currency != money.EUR {
+		t.Fatalf("home exposure = %+v, want market and currency kept", home)
+	}
+	assertExposure(t, home, 2, "15.00", "31.50")
+	assertExposure(t, exposure["match-1-away"], 1, "4.00", "13.60")
+
+	if _, err := repo.CashOutBet(quote(away, "match-1-away", "3.4")); err != nil {
+		t.Fatalf("CashOutBet: %v", err)
+	}
+	if err := settle(t, repo, big, model.StatusLost); err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+	exposure = exposureBySelection(t, repo, "match-1")
+	if _, ok := exposure["match-1-away"]; ok || len(exposure) != 1 {
+		t.Fatalf("exposure = %+v, want only home once the away bet is cashed out", exposure)
+	}
+	assertExposure(t, exposure["match-1-home"], 1, "5.00", "10.50")
+
+	closeEvent(t, repo, "match-1")
+	if _, err := repo.SettleEvent("match-1", func(bet *model.Bet) error {
+		bet.Status = model.StatusWon
+		return nil
+	}); err != nil {
+		t.Fatalf("SettleEvent: %v", err)
+	}
+	if exposure := exposureBySelection(t, repo, "match-1"); len(exposure) != 0 {
+		t.Fatalf("exposure after settlement = %+v, want none", exposure)
+	}
+	if all, err := repo.ListExposure(); err != nil || len(all) != 0 {
+		t.Fatalf("ListExposure = %+v, %v; want none", all, err)
+	}
+}
+
+func testExposureTracksAccumulatorLegs(t *testing.T, repo Repository) {
+	mustCreateUser(t, repo, "alice", money.MustParse("100.00"))
+	mustPlaceAccumulator(t, repo, "alice", "10.00", leg("match-1", "2"), leg("match-2", "3"))
+	mustPlaceBet(t, repo, "alice", "match-3", "2", "1.00")
+
+	// The accumulator pays 60.00 if either event goes its way and the
+	// other does too.
+	assertExposure(t, exposureBySelection(t, repo, "match-1")[""], 1, "10.00", "60.00")
+	assertExposure(t, exposureBySelection(t, repo, "match-2")[""], 1, "10.00", "60.00")
+	all, err := repo.ListExposure()
+	if err != nil {
+		t.Fatalf("ListExposure: %v", err)
+	}
+	if len(all) != 3 || all[0].EventID != "match-1" || all[1].EventID != "match-2" || all[2].EventID != "match-3" {
+		t.Fatalf("ListExposure = %+v, want match-1, match-2 and match-3 in order", all)
+	}
+
+	settleLegs(t, repo, "match-1", model.StatusWon)
+	if exposure := exposureBySelection(t, repo, "match-1"); len(exposure) != 0 {
+		t.Fatalf("exposure of a settled leg = %+v, want none", exposure)
+	}
+	assertExposure(t, exposureBySelection(t, repo, "match-2")[""], 1, "10.00", "60.00")
+
+	settleLegs(t, repo, "match-2", model.StatusLost)
+	if all, err := repo.ListExposure(); err != nil || len(all) != 1 || all[0].EventID != "match-3" {
+		t.Fatalf("ListExposure = %+v, %v; want only match-3", all, err)
+	}
+}
@@ -0,0 +1,687 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/ledger"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+const betColumns = `id, user_id, type, event_id, market_id, selection_id, odds, amount, status, cashed_out_stake, cashed_out, created_at, settled_at, version, currency, free_bet_id, boost_id, original_odds`
+
+// placedOnEvent selects the PLACED bets on an event, whether backed directly
+// or through a leg. It takes the event ID twice, then the status.
+const placedOnEvent = `SELECT ` + betColumns + ` FROM bets
+	WHERE (event_id = ? OR id IN (SELECT bet_id FROM bet_legs WHERE event_id = ?)) AND status = ?
+	ORDER BY created_at, id`
+
+// listingKeys holds the SQL expression each bet listing is ordered by,
+// matching model.BetQuery.SortKey.
+var listingKeys = map[model.BetSort]string{
+	model.SortByCreatedAt: "created_at",
+	model.SortBySettledAt: "COALESCE(settled_at, 0)",
+	model.SortByAmount:    "amount",
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBet(row rowScanner) (*model.Bet, error) {
+	var (
+		bet                  model.Bet
+		odds, amount         int64
+		cashedOutStake       int64
+		cashedOut            int64
+		betType, status      string
+		currency             string
+		originalOdds         int64
+		createdAt, settledAt sql.NullInt64
+	)
+	if err := row.Scan(&bet.ID, &bet.UserID, &betType, &bet.EventID, &bet.MarketID, &bet.SelectionID, &odds, &amount, &status,
+		&cashedOutStake, &cashedOut, &createdAt, &settledAt, &bet.Version, &currency, &bet.FreeBetID, &bet.BoostID, &originalOdds); err != nil {
+		return nil, err
+	}
+	bet.CashedOutStake = money.FromMinor(cashedOutStake)
+	bet.CashedOut = money.FromMinor(cashedOut)
+	bet.Type = model.BetType(betType)
+	bet.Odds = money.Odds(odds)
+	bet.OriginalOdds = money.Odds(originalOdds)
+	bet.Amount = money.FromMinor(amount)
+	bet.Status = model.BetStatus(status)
+	bet.Currency = money.Currency(currency)
+	bet.CreatedAt = fromUnix(createdAt)
+	bet.SettledAt = fromUnix(settledAt)
+	return &bet, nil
+}
+
+// PlaceBet stores a new bet and debits the stake from the user's balance in one transaction.
+func (r *SQLiteRepository) PlaceBet(bet *model.Bet, check model.PlacementCheck) (*model.Bet, error) {
+	err := r.withTx(func(tx *sql.Tx) error {
+		user, err := getUser(tx, bet.UserID)
+		if err != nil {
+			return err
+		}
+		if bet.Currency == "" {
+			bet.Currency = user.Currency
+		}
+		if err := checkBettable(tx, bet); err != nil {
+			return err
+		}
+		now := time.Now()
+		if bet.IsFree() {
+			freeBet, err := getFreeBet(tx, bet.FreeBetID)
+			if err != nil {
+				return err
+			}
+			if err := checkFreeBet(freeBet, bet, now); err != nil {
+				return err
+			}
+		} else if err := checkFunds(user, bet.Currency, bet.Amount); err != nil {
+			return err
+		}
+		if bet.IsBoosted() {
+			if err := checkBoost(tx, bet); err != nil {
+				return err
+			}
+		}
+		if check != nil {
+			liability, err := placementLiability(tx, bet, now)
+			if err != nil {
+				return err
+			}
+			if err := check(bet, liability); err != nil {
+				return err
+			}
+		}
+
+		bet.ID = uuid.New().String()
+		bet.Status = model.StatusPlaced
+		bet.CreatedAt = now
+		bet.Version = 1
+		if bet.Type == "" {
+			bet.Type = model.BetTypeSingle
+		}
+
+		if _, err := tx.Exec(`INSERT INTO bets (`+betColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			bet.ID, bet.UserID, string(bet.Type), bet.EventID, bet.MarketID, bet.SelectionID, bet.Odds.Raw(), bet.Amount.Minor(), string(bet.Status),
+			bet.CashedOutStake.Minor(), bet.CashedOut.Minor(), toUnix(bet.CreatedAt), toUnix(bet.SettledAt), bet.Version, string(bet.Currency), bet.FreeBetID,
+			bet.BoostID, bet.OriginalOdds.Raw()); err != nil {
+			return fmt.Errorf("insert bet: %w", err)
+		}
+		if bet.IsFree() {
+			if _, err := tx.Exec(`UPDATE free_bets SET status = ?, bet_id = ?, closed_at = ? WHERE id = ?`,
+				string(model.FreeBetUsed), bet.ID, toUnix(now), bet.FreeBetID); err != nil {
+				return fmt.Errorf("use free bet %s: %w", bet.FreeBetID, err)
+			}
+		}
+		for i, leg := range bet.Legs {
+			if _, err := tx.Exec(`INSERT INTO bet_legs (bet_id, position, event_id, market_id, selection_id, odds, status, settled_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				bet.ID, i, leg.EventID, leg.MarketID, leg.SelectionID, leg.Odds.Raw(), string(leg.Status), toUnix(leg.SettledAt)); err != nil {
+				return fmt.Errorf("insert leg %d of bet %s: %w", i, bet.ID, err)
+			}
+		}
+		for i, line := range bet.Lines {
+			if _, err := tx.Exec(`INSERT INTO bet_lines (bet_id, position, legs, odds, amount, status, settled_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				bet.ID, i, formatPositions(line.Legs), line.Odds.Raw(), line.Amount.Minor(), string(line.Status), toUnix(line.SettledAt)); err != nil {
+				return fmt.Errorf("insert line %d of bet %s: %w", i, bet.ID, err)
+			}
+		}
+		if err := applyExposure(tx, nil, bet); err != nil {
+			return err
+		}
+		return post(tx, ledger.StakeEntry(bet))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bet, nil
+}
+
+// FindBetsByEvent retrieves all bets for a specific event that are not yet settled.
+func (r *SQLiteRepository) FindBetsByEvent(eventID string) ([]*model.Bet, error) {
+	return queryBets(r.db, placedOnEvent, eventID, eventID, string(model.StatusPlaced))
+}
+
+// GetBet retrieves a bet by ID with its legs and lines.
+func (r *SQLiteRepository) GetBet(betID string) (*model.Bet, error) {
+	return getBet(r.db, betID)
+}
+
+// FindBets lists bets matching a query with a single indexed SELECT, reading
+// one bet past the page to tell whether another page follows.
+func (r *SQLiteRepository) FindBets(query *model.BetQuery) (*model.BetPage, error) {
+	var (
+		where []string
+		args  []any
+	)
+	filter := func(cond string, values ...any) {
+		where = append(where, cond)
+		args = append(args, values...)
+	}
+	if query.UserID != "" {
+		filter(`user_id = ?`, query.UserID)
+	}
+	if query.EventID != "" {
+		filter(`(event_id = ? OR id IN (SELECT bet_id FROM bet_legs WHERE event_id = ?))`, query.EventID, query.EventID)
+	}
+	if len(query.Statuses) > 0 {
+		statuses := make([]any, len(query.Statuses))
+		for i, status := range query.Statuses {
+			statuses[i] = string(status)
+		}
+		filter(`status IN (?`+strings.Repeat(`, ?`, len(statuses)-1)+`)`, statuses...)
+	}
+	if !query.CreatedFrom.IsZero() {
+		filter(`created_at >= ?`, query.CreatedFrom.UnixNano())
+	}
+	if !query.CreatedTo.IsZero() {
+		filter(`created_at <= ?`, query.CreatedTo.UnixNano())
+	}
+	// Unsettled bets have a NULL settled_at and fail both comparisons.
+	if !query.SettledFrom.IsZero() {
+		filter(`settled_at >= ?`, query.SettledFrom.UnixNano())
+	}
+	if !query.SettledTo.IsZero() {
+		filter(`settled_at <= ?`, query.SettledTo.UnixNano())
+	}
+	if !query.MinStake.IsZero() {
+		filter(`amount >= ?`, query.MinStake.Minor())
+	}
+	if !query.MaxStake.IsZero() {
+		filter(`amount <= ?`, query.MaxStake.Minor())
+	}
+
+	key := listingKeys[query.Sort]
+	cmp, order := ">", "ASC"
+	if query.Desc {
+		cmp, order = "<", "DESC"
+	}
+	if query.After != nil {
+		filter(fmt.Sprintf(`(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))`, key, cmp), query.After.Key, query.After.Key, query.After.ID)
+	}
+
+	stmt := `SELECT ` + betColumns + ` FROM bets`
+	if len(where) > 0 {
+		stmt += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	stmt += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT ?`, key, order, order)
+	bets, err := queryBets(r.db, stmt, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	return query.Page(bets), nil
+}
+
+// UserBetStats summarises a user's bets, read through the user_id index.
+func (r *SQLiteRepository) UserBetStats(userID string) (map[money.Currency]*model.BetStats, error) {
+	bets, err := queryBets(r.db, `SELECT `+betColumns+` FROM bets WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return model.SummarizeBetsByCurrency(bets), nil
+}
+
+func getBet(q queryer, betID string) (*model.Bet, error) {
+	bet, err := scanBet(q.QueryRow(`SELECT `+betColumns+` FROM bets WHERE id = ?`, betID))
+	if err == sql.ErrNoRows {
+		return nil, &errors.ErrorNotFound{Entity: "Bet", ID: betID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load bet %s: %w", betID, err)
+	}
+	if err := loadLegs(q, bet); err != nil {
+		return nil, err
+	}
+	if err := loadCashOuts(q, bet); err != nil {
+		return nil, err
+	}
+	return bet, nil
+}
+
+// CashOutBet closes the quoted stake of a PLACED bet for the quoted value.
+// The quote is checked against the stored bet and selection odds in the
+// same transaction that credits the value and records the cash-out.
+func (r *SQLiteRepository) CashOutBet(quote *model.CashOutQuote) (*model.Bet, error) {
+	var cashed *model.Bet
+	err := r.withTx(func(tx *sql.Tx) error {
+		bet, err := getBet(tx, quote.BetID)
+		if err != nil {
+			return err
+		}
+		getEvent := func(eventID string) (*model.Event, error) {
+			event, err := eventState(tx, eventID)
+			if err == nil && event == nil {
+				err = &errors.ErrorNotFound{Entity: "Event", ID: eventID}
+			}
+			return event, err
+		}
+		err = model.CheckQuote(bet, quote, getEvent, func(selectionID string) (money.Odds, error) {
+			sel, err := getSelection(tx, selectionID)
+			if err != nil {
+				return 0, err
+			}
+			return sel.Odds, nil
+		})
+		if err != nil {
+			if _, ok := err.(*errors.ErrorNotFound); ok {
+				return err
+			}
+			return &errors.ErrorConflict{Message: err.Error()}
+		}
+		if _, err := getUser(tx, bet.UserID); err != nil {
+			return fmt.Errorf("internal error: user %s not found for bet %s", bet.UserID, bet.ID)
+		}
+
+		now := time.Now()
+		before := bet.Clone()
+		bet.ApplyCashOut(quote, now)
+		if err := post(tx, ledger.CashOutEntry(bet, quote.Stake, quote.Value)); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO bet_cash_outs (bet_id, stake, value, created_at) VALUES (?, ?, ?, ?)`,
+			bet.ID, quote.Stake.Minor(), quote.Value.Minor(), toUnix(now)); err != nil {
+			return fmt.Errorf("insert cash-out of bet %s: %w", bet.ID, err)
+		}
+		if _, err := tx.Exec(`UPDATE bets SET status = ?, cashed_out_stake = ?, cashed_out = ?, settled_at = ?, version = version + 1 WHERE id = ?`,
+			string(bet.Status), bet.CashedOutStake.Minor(), bet.CashedOut.Minor(), toUnix(bet.SettledAt), bet.ID); err != nil {
+			return fmt.Errorf("update bet %s: %w", bet.ID, err)
+		}
+		if err := applyExposure(tx, before, bet); err != nil {
+			return err
+		}
+		bet.Version++
+		cashed = bet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cashed, nil
+}
+
+// UpdateBet settles a PLACED bet, crediting the payout in the same transaction
+// if it won. A non-zero Version must match the stored bet's.
+func (r *SQLiteRepository) UpdateBet(bet *model.Bet) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		existing, err := getBet(tx, bet.ID)
+		if err != nil {
+			return err
+		}
+		if existing.Status != model.StatusPlaced {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("bet %s already settled with status %s", bet.ID, existing.Status)}
+		}
+		if bet.Version != 0 && bet.Version != existing.Version {
+			return &errors.ErrorPreconditionFailed{Message: fmt.Sprintf("bet %s is at version %d, not %d", bet.ID, existing.Version, bet.Version)}
+		}
+
+		before := existing.Clone()
+		existing.Status = bet.Status
+		existing.SettledAt = time.Now()
+		if _, err := settleBet(tx, existing); err != nil {
+			return err
+		}
+		return applyExposure(tx, before, existing)
+	})
+}
+
+// SettleEvent settles every PLACED bet on an event in a single transaction.
+// settle decides each bet's outcome on a copy; any error rolls back every
+// status change and payout.
+func (r *SQLiteRepository) SettleEvent(eventID string, settle model.BetSettler) (*model.SettlementSummary, error) {
+	var summary *model.SettlementSummary
+	err := r.withTx(func(tx *sql.Tx) error {
+		if err := checkNoSettlementJob(tx, eventID); err != nil {
+			return err
+		}
+		var err error
+		summary, err = settleEvent(tx, eventID, (*model.Event).CheckSettleable, settle, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// VoidEvent voids every PLACED bet on a cancelled event in a single
+// transaction, as SettleEvent settles them.
+func (r *SQLiteRepository) VoidEvent(eventID string, void model.BetSettler) (*model.SettlementSummary, error) {
+	var summary *model.SettlementSummary
+	err := r.withTx(func(tx *sql.Tx) error {
+		if err := checkNoSettlementJob(tx, eventID); err != nil {
+			return err
+		}
+		var err error
+		summary, err = settleEvent(tx, eventID, (*model.Event).CheckVoidable, void, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// PreviewSettlement settles an event as SettleEvent does, in a transaction
+// that is then rolled back.
+func (r *SQLiteRepository) PreviewSettlement(eventID string, settle model.BetSettler) (*model.SettlementPreview, error) {
+	preview := &model.SettlementPreview{}
+	err := r.withRollback(func(tx *sql.Tx) error {
+		summary, err := settleEvent(tx, eventID, (*model.Event).CheckSettleable, settle, preview)
+		preview.Summary = summary
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// settleEvent settles the PLACED bets on an event within tx, once check
+// accepts the event if it is known. With a non-nil preview, the bets it
+// changes are also added to it.
+func settleEvent(tx *sql.Tx, eventID string, check func(*model.Event) error, settle model.BetSettler, preview *model.SettlementPreview) (*model.SettlementSummary, error) {
+	event, err := eventState(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event != nil {
+		if err := check(event); err != nil {
+			return nil, &errors.ErrorConflict{Message: err.Error()}
+		}
+	}
+	bets, err := queryBets(tx, placedOnEvent, eventID, eventID, string(model.StatusPlaced))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &model.SettlementSummary{EventID: eventID}
+	awaiting, err := settleBets(tx, eventID, bets, settle, summary, preview)
+	if err != nil {
+		return nil, err
+	}
+	if err := finishSettlement(tx, event, awaiting, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// settleBets settles PLACED bets on eventID with settle within tx, recording
+// them in summary and, if non-nil, preview. awaiting reports whether any of
+// them still awaits the event's result.
+func settleBets(tx *sql.Tx, eventID string, bets []*model.Bet, settle model.BetSettler, summary *model.SettlementSummary, preview *model.SettlementPreview) (awaiting bool, err error) {
+	now := time.Now()
+	for _, bet := range bets {
+		before := bet.Clone()
+		if err := settle(bet); err != nil {
+			return false, fmt.Errorf("failed to settle bet %s: %w", bet.ID, err)
+		}
+		if bet.AwaitsEvent(eventID) {
+			awaiting = true
+		}
+		if bet.Status == model.StatusPlaced {
+			// A multi-leg bet can have legs settled without being decided.
+			if bet.OpenLegs() < before.OpenLegs() {
+				if err := saveLegs(tx, bet); err != nil {
+					return false, err
+				}
+				if err := applyExposure(tx, before, bet); err != nil {
+					return false, err
+				}
+				if _, err := tx.Exec(`UPDATE bets SET version = version + 1 WHERE id = ?`, bet.ID); err != nil {
+					return false, fmt.Errorf("update bet %s: %w", bet.ID, err)
+				}
+				summary.Pending++
+				if preview != nil {
+					preview.Add(bet, money.Zero)
+				}
+			}
+			continue
+		}
+		bet.SettledAt = now
+		credited, err := settleBet(tx, bet)
+		if err != nil {
+			return false, err
+		}
+		if err := applyExposure(tx, before, bet); err != nil {
+			return false, err
+		}
+		summary.Record(bet, credited)
+		if preview != nil {
+			preview.Add(bet, credited)
+		}
+	}
+	return awaiting, nil
+}
+
+// finishSettlement moves a known event to the status a settlement leaves it
+// in and reports that status in summary.
+func finishSettlement(tx *sql.Tx, event *model.Event, awaiting bool, summary *model.SettlementSummary) error {
+	if event == nil {
+		return nil
+	}
+	if status := event.StatusAfterSettlement(awaiting); status != event.Status {
+		event.Status = status
+		if _, err := tx.Exec(`UPDATE events SET status = ?, status_changed_at = ? WHERE id = ?`,
+			string(event.Status), toUnix(time.Now()), event.ID); err != nil {
+			return fmt.Errorf("update event %s: %w", event.ID, err)
+		}
+	}
+	summary.EventStatus = event.Status
+	return nil
+}
+
+// settleBet stores a bet's final status, bumping its version, and posts the
+// resulting payout, returning the amount credited to the user.
+func settleBet(tx *sql.Tx, bet *model.Bet) (money.Money, error) {
+	var credited money.Money
+	if entry := ledger.SettlementEntry(bet); entry != nil {
+		if _, err := getUser(tx, bet.UserID); err != nil {
+			return 0, fmt.Errorf("internal error: user %s not found for bet %s", bet.UserID, bet.ID)
+		}
+		if err := post(tx, entry); err != nil {
+			return 0, err
+		}
+		credited = ledger.WalletDelta(entry, bet.UserID)
+	}
+
+	if _, err := tx.Exec(`UPDATE bets SET status = ?, settled_at = ?, version = version + 1 WHERE id = ?`,
+		string(bet.Status), toUnix(bet.SettledAt), bet.ID); err != nil {
+		return 0, fmt.Errorf("update bet %s: %w", bet.ID, err)
+	}
+	if err := saveLegs(tx, bet); err != nil {
+		return 0, err
+	}
+	return credited, nil
+}
+
+// saveLegs stores the status of each of a bet's legs and lines.
+func saveLegs(tx *sql.Tx, bet *model.Bet) error {
+	for i, leg := range bet.Legs {
+		if _, err := tx.Exec(`UPDATE bet_legs SET status = ?, settled_at = ? WHERE bet_id = ? AND position = ?`,
+			string(leg.Status), toUnix(leg.SettledAt), bet.ID, i); err != nil {
+			return fmt.Errorf("update leg %d of bet %s: %w", i, bet.ID, err)
+		}
+	}
+	for i, line := range bet.Lines {
+		if _, err := tx.Exec(`UPDATE bet_lines SET status = ?, settled_at = ? WHERE bet_id = ? AND position = ?`,
+			string(line.Status), toUnix(line.SettledAt), bet.ID, i); err != nil {
+			return fmt.Errorf("update line %d of bet %s: %w", i, bet.ID, err)
+		}
+	}
+	return nil
+}
+
+// loadLegs reads the legs, and the lines of system bets, of the given
+// multi-leg bets in order.
+func loadLegs(q queryer, bets ...*model.Bet) error {
+	for _, bet := range bets {
+		if bet.Type == model.BetTypeSingle {
+			continue
+		}
+		rows, err := q.Query(`SELECT event_id, market_id, selection_id, odds, status, settled_at
+			FROM bet_legs WHERE bet_id = ? ORDER BY position`, bet.ID)
+		if err != nil {
+			return fmt.Errorf("query legs of bet %s: %w", bet.ID, err)
+		}
+		for rows.Next() {
+			var (
+				leg       model.BetLeg
+				odds      int64
+				status    string
+				settledAt sql.NullInt64
+			)
+			if err := rows.Scan(&leg.EventID, &leg.MarketID, &leg.SelectionID, &odds, &status, &settledAt); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan leg of bet %s: %w", bet.ID, err)
+			}
+			leg.Odds = money.Odds(odds)
+			leg.Status = model.BetStatus(status)
+			leg.SettledAt = fromUnix(settledAt)
+			bet.Legs = append(bet.Legs, leg)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("query legs of bet %s: %w", bet.ID, err)
+		}
+		if bet.Type == model.BetTypeSystem {
+			if err := loadLines(q, bet); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func loadLines(q queryer, bet *model.Bet) error {
+	rows, err := q.Query(`SELECT legs, odds, amount, status, settled_at
+		FROM bet_lines WHERE bet_id = ? ORDER BY position`, bet.ID)
+	if err != nil {
+		return fmt.Errorf("query lines of bet %s: %w", bet.ID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			line         model.BetLine
+			legs, status string
+			odds, amount int64
+			settledAt    sql.NullInt64
+		)
+		if err := rows.Scan(&legs, &odds, &amount, &status, &settledAt); err != nil {
+			return fmt.Errorf("scan line of bet %s: %w", bet.ID, err)
+		}
+		if line.Legs, err = parsePositions(legs); err != nil {
+			return fmt.Errorf("line of bet %s: %w", bet.ID, err)
+		}
+		line.Odds = money.Odds(odds)
+		line.Amount = money.FromMinor(amount)
+		line.Status = model.BetStatus(status)
+		line.SettledAt = fromUnix(settledAt)
+		bet.Lines = append(bet.Lines, line)
+	}
+	return rows.Err()
+}
+
+// loadCashOuts reads the cash-out history of the given bets, oldest first.
+func loadCashOuts(q queryer, bets ...*model.Bet) error {
+	for _, bet := range bets {
+		if bet.CashedOutStake.IsZero() {
+			continue
+		}
+		rows, err := q.Query(`SELECT stake, value, created_at FROM bet_cash_outs WHERE bet_id = ? ORDER BY seq`, bet.ID)
+		if err != nil {
+			return fmt.Errorf("query cash-outs of bet %s: %w", bet.ID, err)
+		}
+		for rows.Next() {
+			var (
+				stake, value int64
+				createdAt    sql.NullInt64
+			)
+			if err := rows.Scan(&stake, &value, &createdAt); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan cash-out of bet %s: %w", bet.ID, err)
+			}
+			bet.CashOuts = append(bet.CashOuts, model.CashOut{
+				Stake:     money.FromMinor(stake),
+				Value:     money.FromMinor(value),
+				CreatedAt: fromUnix(createdAt),
+			})
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("query cash-outs of bet %s: %w", bet.ID, err)
+		}
+	}
+	return nil
+}
+
+// formatPositions stores a line's leg positions as a comma-separated list.
+func formatPositions(positions []int) string {
+	parts := make([]string, len(positions))
+	for i, p := range positions {
+		parts[i] = strconv.Itoa(p)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parsePositions(s string) ([]int, error) {
+	parts := strings.Split(s, ",")
+	positions := make([]int, len(parts))
+	for i, part := range parts {
+		p, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leg position %q", part)
+		}
+		positions[i] = p
+	}
+	return positions, nil
+}
+
+// queryBets runs a query selecting betColumns, scans every row and loads
+// the legs of multi-leg bets.
+func queryBets(q queryer, query string, args ...any) ([]*model.Bet, error) {
+	bets, err := scanBets(q, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	// Legs are read once the bet rows are closed: the pool holds a single
+	// connection.
+	if err := loadLegs(q, bets...); err != nil {
+		return nil, err
+	}
+	if err := loadCashOuts(q, bets...); err != nil {
+		return nil, err
+	}
+	return bets, nil
+}
+
+func scanBets(q queryer, query string, args ...any) ([]*model.Bet, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query bets: %w", err)
+	}
+	defer rows.Close()
+
+	bets := []*model.Bet{}
+	for rows.Next() {
+		bet, err := scanBet(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan bet: %w", err)
+		}
+		bets = append(bets, bet)
+	}
+	return bets, rows.Err()
+}
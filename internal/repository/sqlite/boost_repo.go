@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+const boostColumns = `id, name, event_id, selection_id, increase_bps, max_stake, currency, max_uses_per_user, status, starts_at, ends_at, created_at, cancelled_at`
+
+func scanBoost(row rowScanner) (*model.Boost, error) {
+	var (
+		b                      model.Boost
+		maxStake               int64
+		currency, status       string
+		startsAt, endsAt       int64
+		createdAt, cancelledAt sql.NullInt64
+	)
+	if err := row.Scan(&b.ID, &b.Name, &b.EventID, &b.SelectionID, &b.IncreaseBps, &maxStake, &currency, &b.MaxUsesPerUser, &status,
+		&startsAt, &endsAt, &createdAt, &cancelledAt); err != nil {
+		return nil, err
+	}
+	b.MaxStake = money.FromMinor(maxStake)
+	b.Currency = money.Currency(currency)
+	b.Status = model.BoostStatus(status)
+	b.StartsAt = time.Unix(0, startsAt)
+	b.EndsAt = time.Unix(0, endsAt)
+	b.CreatedAt = fromUnix(createdAt)
+	b.CancelledAt = fromUnix(cancelledAt)
+	return &b, nil
+}
+
+func getBoost(q queryer, boostID string) (*model.Boost, error) {
+	b, err := scanBoost(q.QueryRow(`SELECT `+boostColumns+` FROM boosts WHERE id = ?`, boostID))
+	if err == sql.ErrNoRows {
+		return nil, &errors.ErrorNotFound{Entity: "Boost", ID: boostID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load boost %s: %w", boostID, err)
+	}
+	return b, nil
+}
+
+// CreateBoost stores a new boost.
+func (r *SQLiteRepository) CreateBoost(boost *model.Boost) (*model.Boost, error) {
+	stored := boost.Clone()
+	stored.ID = uuid.New().String()
+	stored.Status = model.BoostActive
+	stored.CreatedAt = time.Now()
+	if _, err := r.db.Exec(`INSERT INTO boosts (`+boostColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		stored.ID, stored.Name, stored.EventID, stored.SelectionID, stored.IncreaseBps, stored.MaxStake.Minor(), string(stored.Currency),
+		stored.MaxUsesPerUser, string(stored.Status), stored.StartsAt.UnixNano(), stored.EndsAt.UnixNano(),
+		toUnix(stored.CreatedAt), toUnix(stored.CancelledAt)); err != nil {
+		return nil, fmt.Errorf("insert boost: %w", err)
+	}
+	return stored, nil
+}
+
+// GetBoost retrieves a boost by its ID.
+func (r *SQLiteRepository) GetBoost(boostID string) (*model.Boost, error) {
+	return getBoost(r.db, boostID)
+}
+
+// ListBoosts returns every boost, oldest first.
+func (r *SQLiteRepository) ListBoosts() ([]*model.Boost, error) {
+	rows, err := r.db.Query(`SELECT ` + boostColumns + ` FROM boosts ORDER BY created_at, rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("query boosts: %w", err)
+	}
+	defer rows.Close()
+
+	boosts := []*model.Boost{}
+	for rows.Next() {
+		b, err := scanBoost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan boost: %w", err)
+		}
+		boosts = append(boosts, b)
+	}
+	return boosts, rows.Err()
+}
+
+// CancelBoost cancels an active boost.
+func (r *SQLiteRepository) CancelBoost(boostID string) (*model.Boost, error) {
+	var cancelled *model.Boost
+	err := r.withTx(func(tx *sql.Tx) error {
+		boost, err := getBoost(tx, boostID)
+		if err != nil {
+			return err
+		}
+		if boost.Status != model.BoostActive {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("boost %s is already %s", boostID, boost.Status)}
+		}
+		boost.Status = model.BoostCancelled
+		boost.CancelledAt = time.Now()
+		if _, err := tx.Exec(`UPDATE boosts SET status = ?, cancelled_at = ? WHERE id = ?`,
+			string(boost.Status), toUnix(boost.CancelledAt), boost.ID); err != nil {
+			return fmt.Errorf("cancel boost %s: %w", boost.ID, err)
+		}
+		cancelled = boost
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// FindBetsByBoost returns the bets placed with a boost, oldest first.
+func (r *SQLiteRepository) FindBetsByBoost(boostID string) ([]*model.Bet, error) {
+	if _, err := getBoost(r.db, boostID); err != nil {
+		return nil, err
+	}
+	return queryBets(r.db, `SELECT `+betColumns+` FROM bets WHERE boost_id = ? ORDER BY created_at, id`, boostID)
+}
+
+// checkBoost reports why the user cannot place bet with its boost: the
+// boost does not exist, was cancelled, or they have used it up.
+func checkBoost(tx *sql.Tx, bet *model.Bet) error {
+	boost, err := getBoost(tx, bet.BoostID)
+	if err != nil {
+		return err
+	}
+	var used int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM bets WHERE boost_id = ? AND user_id = ?`, boost.ID, bet.UserID).Scan(&used); err != nil {
+		return fmt.Errorf("count uses of boost %s: %w", boost.ID, err)
+	}
+	return checkBoostUsage(boost, used)
+}
+
+// checkBoostUsage reports a cancelled boost, or one a user has already
+// used the given number of times and may not use again.
+func checkBoostUsage(boost *model.Boost, used int) error {
+	if boost.Status != model.BoostActive {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("boost %s is %s", boost.ID, boost.Status)}
+	}
+	if used >= boost.MaxUsesPerUser {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("boost %s can be used %d time(s) per user and has been used %d", boost.ID, boost.MaxUsesPerUser, used)}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/repotest"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/sqlite"
+)
+
+// open returns a repository on path, closed when the test ends.
+func open(t *testing.T, path string) repotest.Repository {
+	t.Helper()
+	repo, err := sqlite.NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository(%s): %v", path, err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		return open(t, filepath.Join(t.TempDir(), "bets.db"))
+	})
+}
+
+func TestConformanceInMemory(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		return open(t, ":memory:")
+	})
+}
@@ -0,0 +1,243 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/ledger"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+const correctionColumns = `id, event_id, action, operator, reason, result, bets_reopened, clawed_back, settlement, event_status, created_at`
+
+// betsOnEvent selects every bet on an event, whatever its status, whether
+// backed directly or through a leg. It takes the event ID twice.
+const betsOnEvent = `SELECT ` + betColumns + ` FROM bets
+	WHERE event_id = ? OR id IN (SELECT bet_id FROM bet_legs WHERE event_id = ?)
+	ORDER BY created_at, id`
+
+// CorrectSettlement reopens the bets an event's result decided and, for a
+// resettlement, settles them again, in a single transaction together with
+// the correction's audit record.
+func (r *SQLiteRepository) CorrectSettlement(correction *model.SettlementCorrection, settle model.BetSettler) (*model.SettlementCorrection, error) {
+	stored := correction.Clone()
+	eventID := stored.EventID
+	resettle := stored.Action == model.CorrectionResettle
+	err := r.withTx(func(tx *sql.Tx) error {
+		if err := checkNoSettlementJob(tx, eventID); err != nil {
+			return err
+		}
+		event, err := eventState(tx, eventID)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			if err := event.CheckCorrectable(); err != nil {
+				return &errors.ErrorConflict{Message: err.Error()}
+			}
+		}
+		stored.ID = uuid.New().String()
+		stored.CreatedAt = time.Now()
+		if resettle {
+			stored.Settlement = &model.SettlementSummary{EventID: eventID}
+		}
+
+		bets, err := queryBets(tx, betsOnEvent, eventID, eventID)
+		if err != nil {
+			return err
+		}
+		awaiting := false
+		for _, bet := range bets {
+			before := bet.Clone()
+			reopened := bet.Unsettle(eventID)
+			if !reopened && !(resettle && before.Status == model.StatusPlaced) {
+				awaiting = awaiting || bet.AwaitsEvent(eventID)
+				continue
+			}
+
+			if reopened {
+				var clawedBack money.Money
+				if bet.Status == model.StatusPlaced {
+					if entry := ledger.ClawbackEntry(before, stored.Reason); entry != nil {
+						if _, err := getUser(tx, bet.UserID); err != nil {
+							return fmt.Errorf("internal error: user %s not found for bet %s", bet.UserID, bet.ID)
+						}
+						if err := post(tx, entry); err != nil {
+							return err
+						}
+						clawedBack = ledger.WalletDelta(entry, bet.UserID).Neg()
+					}
+				}
+				stored.Reopen(bet, clawedBack)
+			}
+
+			if resettle && bet.Status == model.StatusPlaced {
+				openLegs := bet.OpenLegs()
+				if err := settle(bet); err != nil {
+					return fmt.Errorf("failed to settle bet %s: %w", bet.ID, err)
+				}
+				if bet.Status != model.StatusPlaced {
+					bet.SettledAt = stored.CreatedAt
+					credited, err := settleBet(tx, bet)
+					if err != nil {
+						return err
+					}
+					if err := applyExposure(tx, before, bet); err != nil {
+						return err
+					}
+					stored.Settlement.Record(bet, credited)
+					continue
+				}
+				if bet.OpenLegs() < openLegs {
+					stored.Settlement.Pending++
+				} else if !reopened {
+					// Nothing changed for it.
+					awaiting = awaiting || bet.AwaitsEvent(eventID)
+					continue
+				}
+			}
+			if reopened {
+				// Events that are already SETTLED take no more results.
+				for _, id := range bet.AwaitedEvents() {
+					if id == eventID {
+						continue
+					}
+					other, err := eventState(tx, id)
+					if err != nil {
+						return err
+					}
+					if other != nil && other.Status == model.EventSettled {
+						return &errors.ErrorConflict{Message: fmt.Sprintf("bet %s would be reopened waiting for event %s, which is already SETTLED", bet.ID, id)}
+					}
+				}
+			}
+			awaiting = awaiting || bet.AwaitsEvent(eventID)
+			if _, err := tx.Exec(`UPDATE bets SET status = ?, settled_at = ?, version = version + 1 WHERE id = ?`,
+				string(bet.Status), toUnix(bet.SettledAt), bet.ID); err != nil {
+				return fmt.Errorf("update bet %s: %w", bet.ID, err)
+			}
+			if err := saveLegs(tx, bet); err != nil {
+				return err
+			}
+			if err := applyExposure(tx, before, bet); err != nil {
+				return err
+			}
+		}
+
+		if event != nil {
+			status := model.EventClosed
+			if resettle && !awaiting {
+				status = model.EventSettled
+			}
+			if event.Status != status {
+				event.Status = status
+				if _, err := tx.Exec(`UPDATE events SET status = ?, status_changed_at = ? WHERE id = ?`,
+					string(event.Status), toUnix(stored.CreatedAt), eventID); err != nil {
+					return fmt.Errorf("update event %s: %w", eventID, err)
+				}
+			}
+			stored.EventStatus = event.Status
+			if stored.Settlement != nil {
+				stored.Settlement.EventStatus = event.Status
+			}
+		}
+		return insertCorrection(tx, stored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func insertCorrection(tx *sql.Tx, c *model.SettlementCorrection) error {
+	var result, settlement sql.NullString
+	if c.Result != nil {
+		encoded, err := json.Marshal(c.Result)
+		if err != nil {
+			return fmt.Errorf("encode result of correction: %w", err)
+		}
+		result = sql.NullString{String: string(encoded), Valid: true}
+	}
+	if c.Settlement != nil {
+		encoded, err := json.Marshal(c.Settlement)
+		if err != nil {
+			return fmt.Errorf("encode settlement of correction: %w", err)
+		}
+		settlement = sql.NullString{String: string(encoded), Valid: true}
+	}
+	clawedBack := c.ClawedBack
+	if clawedBack == nil {
+		clawedBack = map[money.Currency]money.Money{}
+	}
+	encoded, err := json.Marshal(clawedBack)
+	if err != nil {
+		return fmt.Errorf("encode clawbacks of correction: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO settlement_corrections (`+correctionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.EventID, string(c.Action), c.Operator, c.Reason, result, c.BetsReopened, string(encoded), settlement,
+		string(c.EventStatus), toUnix(c.CreatedAt)); err != nil {
+		return fmt.Errorf("insert settlement correction: %w", err)
+	}
+	return nil
+}
+
+// ListSettlementCorrections returns the corrections of an event, oldest
+// first.
+func (r *SQLiteRepository) ListSettlementCorrections(eventID string) ([]*model.SettlementCorrection, error) {
+	rows, err := r.db.Query(`SELECT `+correctionColumns+` FROM settlement_corrections WHERE event_id = ?
+		ORDER BY created_at, rowid`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query settlement corrections: %w", err)
+	}
+	defer rows.Close()
+
+	corrections := []*model.SettlementCorrection{}
+	for rows.Next() {
+		c, err := scanCorrection(rows)
+		if err != nil {
+			return nil, err
+		}
+		corrections = append(corrections, c)
+	}
+	return corrections, rows.Err()
+}
+
+func scanCorrection(row rowScanner) (*model.SettlementCorrection, error) {
+	var (
+		c                   model.SettlementCorrection
+		action, eventStatus string
+		clawedBack          string
+		result, settlement  sql.NullString
+		createdAt           sql.NullInt64
+	)
+	if err := row.Scan(&c.ID, &c.EventID, &action, &c.Operator, &c.Reason, &result, &c.BetsReopened, &clawedBack,
+		&settlement, &eventStatus, &createdAt); err != nil {
+		return nil, fmt.Errorf("scan settlement correction: %w", err)
+	}
+	if result.Valid {
+		if err := json.Unmarshal([]byte(result.String), &c.Result); err != nil {
+			return nil, fmt.Errorf("decode result of correction %s: %w", c.ID, err)
+		}
+	}
+	if settlement.Valid {
+		if err := json.Unmarshal([]byte(settlement.String), &c.Settlement); err != nil {
+			return nil, fmt.Errorf("decode settlement of correction %s: %w", c.ID, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(clawedBack), &c.ClawedBack); err != nil {
+		return nil, fmt.Errorf("decode clawbacks of correction %s: %w", c.ID, err)
+	}
+	if len(c.ClawedBack) == 0 {
+		c.ClawedBack = nil
+	}
+	c.Action = model.CorrectionAction(action)
+	c.EventStatus = model.EventStatus(eventStatus)
+	c.CreatedAt = fromUnix(createdAt)
+	return &c, nil
+}
@@ -0,0 +1,298 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// CreateEvent stores a new event together with its markets and selections.
+// Missing market and selection IDs are generated.
+func (r *SQLiteRepository) CreateEvent(event *model.Event) (*model.Event, error) {
+	var created *model.Event
+	err := r.withTx(func(tx *sql.Tx) error {
+		var exists int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM events WHERE id = ?`, event.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("check event %s: %w", event.ID, err)
+		}
+		if exists > 0 {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("event with ID '%s' already exists", event.ID)}
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec(`INSERT INTO events (id, name, status, created_at, status_changed_at) VALUES (?, ?, ?, ?, ?)`,
+			event.ID, event.Name, string(model.EventOpen), toUnix(now), toUnix(now)); err != nil {
+			return fmt.Errorf("insert event %s: %w", event.ID, err)
+		}
+		for _, m := range event.Markets {
+			if err := insertMarket(tx, event.ID, m.Clone()); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		created, err = getEvent(tx, event.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// AddMarket adds a market with its selections to an existing event.
+func (r *SQLiteRepository) AddMarket(eventID string, market *model.Market) (*model.Market, error) {
+	stored := market.Clone()
+	err := r.withTx(func(tx *sql.Tx) error {
+		var exists int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM events WHERE id = ?`, eventID).Scan(&exists); err != nil {
+			return fmt.Errorf("check event %s: %w", eventID, err)
+		}
+		if exists == 0 {
+			return &errors.ErrorNotFound{Entity: "Event", ID: eventID}
+		}
+		return insertMarket(tx, eventID, stored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// GetEvent retrieves an event with its markets and selections.
+func (r *SQLiteRepository) GetEvent(eventID string) (*model.Event, error) {
+	return getEvent(r.db, eventID)
+}
+
+// ListEvents retrieves all events ordered by ID.
+func (r *SQLiteRepository) ListEvents() ([]*model.Event, error) {
+	rows, err := r.db.Query(`SELECT id FROM events ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	events := make([]*model.Event, 0, len(ids))
+	for _, id := range ids {
+		event, err := getEvent(r.db, id)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// SetEventStatus moves an event to status.
+func (r *SQLiteRepository) SetEventStatus(eventID string, status model.EventStatus) (*model.Event, error) {
+	var updated *model.Event
+	err := r.withTx(func(tx *sql.Tx) error {
+		event, err := getEvent(tx, eventID)
+		if err != nil {
+			return err
+		}
+		if err := event.CheckTransition(status); err != nil {
+			return &errors.ErrorConflict{Message: err.Error()}
+		}
+		if status == model.EventCancelled {
+			if err := checkNoSettlementJob(tx, eventID); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`UPDATE events SET status = ?, status_changed_at = ? WHERE id = ?`,
+			string(status), toUnix(time.Now()), eventID); err != nil {
+			return fmt.Errorf("update event %s: %w", eventID, err)
+		}
+		updated, err = getEvent(tx, eventID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// eventState loads an event without its markets, or nil when it is unknown.
+func eventState(q queryer, eventID string) (*model.Event, error) {
+	var (
+		event                      model.Event
+		status                     string
+		createdAt, statusChangedAt sql.NullInt64
+	)
+	err := q.QueryRow(`SELECT id, name, status, created_at, status_changed_at FROM events WHERE id = ?`, eventID).
+		Scan(&event.ID, &event.Name, &status, &createdAt, &statusChangedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load event %s: %w", eventID, err)
+	}
+	event.Status = model.EventStatus(status)
+	event.CreatedAt = fromUnix(createdAt)
+	event.StatusChangedAt = fromUnix(statusChangedAt)
+	return &event, nil
+}
+
+// checkBettable reports a conflict if a known event of the bet does not
+// take bets.
+func checkBettable(tx *sql.Tx, bet *model.Bet) error {
+	for _, eventID := range bet.EventIDs() {
+		event, err := eventState(tx, eventID)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			if err := event.CheckBettable(); err != nil {
+				return &errors.ErrorConflict{Message: err.Error()}
+			}
+		}
+	}
+	return nil
+}
+
+// GetSelection retrieves a selection by its ID.
+func (r *SQLiteRepository) GetSelection(selectionID string) (*model.Selection, error) {
+	return getSelection(r.db, selectionID)
+}
+
+// UpdateSelectionOdds reprices a selection.
+func (r *SQLiteRepository) UpdateSelectionOdds(selectionID string, odds money.Odds) (*model.Selection, error) {
+	res, err := r.db.Exec(`UPDATE selections SET odds = ? WHERE id = ?`, odds.Raw(), selectionID)
+	if err != nil {
+		return nil, fmt.Errorf("update selection %s: %w", selectionID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, &errors.ErrorNotFound{Entity: "Selection", ID: selectionID}
+	}
+	return getSelection(r.db, selectionID)
+}
+
+const selectionColumns = `id, market_id, event_id, name, odds`
+
+func scanSelection(row rowScanner) (*model.Selection, error) {
+	var (
+		sel  model.Selection
+		odds int64
+	)
+	if err := row.Scan(&sel.ID, &sel.MarketID, &sel.EventID, &sel.Name, &odds); err != nil {
+		return nil, err
+	}
+	sel.Odds = money.Odds(odds)
+	return &sel, nil
+}
+
+func getSelection(q queryer, selectionID string) (*model.Selection, error) {
+	sel, err := scanSelection(q.QueryRow(`SELECT `+selectionColumns+` FROM selections WHERE id = ?`, selectionID))
+	if err == sql.ErrNoRows {
+		return nil, &errors.ErrorNotFound{Entity: "Selection", ID: selectionID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load selection %s: %w", selectionID, err)
+	}
+	return sel, nil
+}
+
+func getEvent(q queryer, eventID string) (*model.Event, error) {
+	state, err := eventState(q, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, &errors.ErrorNotFound{Entity: "Event", ID: eventID}
+	}
+	event := *state
+	event.Markets = []*model.Market{}
+
+	rows, err := q.Query(`SELECT id, event_id, name FROM markets WHERE event_id = ? ORDER BY rowid`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query markets for event %s: %w", eventID, err)
+	}
+	markets := make(map[string]*model.Market)
+	for rows.Next() {
+		m := &model.Market{Selections: []*model.Selection{}}
+		if err := rows.Scan(&m.ID, &m.EventID, &m.Name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan market: %w", err)
+		}
+		event.Markets = append(event.Markets, m)
+		markets[m.ID] = m
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(`SELECT `+selectionColumns+` FROM selections WHERE event_id = ? ORDER BY rowid`, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query selections for event %s: %w", eventID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		sel, err := scanSelection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan selection: %w", err)
+		}
+		if m, ok := markets[sel.MarketID]; ok {
+			m.Selections = append(m.Selections, sel)
+		}
+	}
+	return &event, rows.Err()
+}
+
+// insertMarket assigns missing IDs, links the market and its selections to
+// the event and stores them, rejecting IDs that are already in use.
+func insertMarket(tx *sql.Tx, eventID string, m *model.Market) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	m.EventID = eventID
+
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM markets WHERE id = ?`, m.ID).Scan(&exists); err != nil {
+		return fmt.Errorf("check market %s: %w", m.ID, err)
+	}
+	if exists > 0 {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("market with ID '%s' already exists", m.ID)}
+	}
+	if _, err := tx.Exec(`INSERT INTO markets (id, event_id, name) VALUES (?, ?, ?)`, m.ID, eventID, m.Name); err != nil {
+		return fmt.Errorf("insert market %s: %w", m.ID, err)
+	}
+
+	for _, sel := range m.Selections {
+		if sel.ID == "" {
+			sel.ID = uuid.New().String()
+		}
+		sel.MarketID = m.ID
+		sel.EventID = eventID
+
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM selections WHERE id = ?`, sel.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("check selection %s: %w", sel.ID, err)
+		}
+		if exists > 0 {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("selection with ID '%s' already exists", sel.ID)}
+		}
+		if _, err := tx.Exec(`INSERT INTO selections (`+selectionColumns+`) VALUES (?, ?, ?, ?, ?)`,
+			sel.ID, sel.MarketID, sel.EventID, sel.Name, sel.Odds.Raw()); err != nil {
+			return fmt.Errorf("insert selection %s: %w", sel.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+const exposureColumns = `event_id, market_id, selection_id, currency, bets, stake, payout`
+
+// applyExposure moves the exposure rows a bet backs from its state before a
+// change to its state after it, in the transaction making the change;
+// before is nil for a new bet. Rows no open bet backs any more are deleted.
+func applyExposure(tx *sql.Tx, before, after *model.Bet) error {
+	for _, change := range model.ExposureChange(before, after) {
+		if _, err := tx.Exec(`INSERT INTO event_exposure (`+exposureColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (event_id, market_id, selection_id, currency) DO UPDATE SET
+				bets = bets + excluded.bets, stake = stake + excluded.stake, payout = payout + excluded.payout`,
+			change.EventID, change.MarketID, change.SelectionID, string(change.Currency), change.Bets,
+			change.Stake.Minor(), change.Payout.Minor()); err != nil {
+			return fmt.Errorf("update exposure of event %s: %w", change.EventID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM event_exposure
+			WHERE event_id = ? AND market_id = ? AND selection_id = ? AND currency = ? AND bets <= 0`,
+			change.EventID, change.MarketID, change.SelectionID, string(change.Currency)); err != nil {
+			return fmt.Errorf("update exposure of event %s: %w", change.EventID, err)
+		}
+	}
+	return nil
+}
+
+// backfillExposure builds the exposure rows of the bets PLACED before the
+// table existed. It reads them with its own queries, on the columns they had
+// at this migration, so later changes to the schema cannot break it.
+func backfillExposure(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, type, event_id, market_id, selection_id, odds, amount, cashed_out_stake, currency, free_bet_id
+		FROM bets WHERE status = ?`, string(model.StatusPlaced))
+	if err != nil {
+		return fmt.Errorf("query open bets: %w", err)
+	}
+	var bets []*model.Bet
+	for rows.Next() {
+		var (
+			bet                     model.Bet
+			betType, currency       string
+			odds, amount, cashedOut int64
+		)
+		if err := rows.Scan(&bet.ID, &betType, &bet.EventID, &bet.MarketID, &bet.SelectionID, &odds, &amount, &cashedOut, &currency, &bet.FreeBetID); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan open bet: %w", err)
+		}
+		bet.Type = model.BetType(betType)
+		bet.Odds = money.Odds(odds)
+		bet.Amount = money.FromMinor(amount)
+		bet.CashedOutStake = money.FromMinor(cashedOut)
+		bet.Currency = money.Currency(currency)
+		bet.Status = model.StatusPlaced
+		bets = append(bets, &bet)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("query open bets: %w", err)
+	}
+
+	// Legs are read once the bet rows are closed: the pool holds a single
+	// connection.
+	for _, bet := range bets {
+		if bet.Type != model.BetTypeSingle {
+			if err := backfillLegs(tx, bet); err != nil {
+				return err
+			}
+		}
+		if err := applyExposure(tx, nil, bet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillLegs reads the legs, and a system bet's lines, of a bet for
+// backfillExposure.
+func backfillLegs(tx *sql.Tx, bet *model.Bet) error {
+	rows, err := tx.Query(`SELECT event_id, market_id, selection_id, odds, status
+		FROM bet_legs WHERE bet_id = ? ORDER BY position`, bet.ID)
+	if err != nil {
+		return fmt.Errorf("query legs of bet %s: %w", bet.ID, err)
+	}
+	for rows.Next() {
+		var (
+			leg    model.BetLeg
+			odds   int64
+			status string
+		)
+		if err := rows.Scan(&leg.EventID, &leg.MarketID, &leg.SelectionID, &odds, &status); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan leg of bet %s: %w", bet.ID, err)
+		}
+		leg.Odds = money.Odds(odds)
+		leg.Status = model.BetStatus(status)
+		bet.Legs = append(bet.Legs, leg)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("query legs of bet %s: %w", bet.ID, err)
+	}
+	if bet.Type != model.BetTypeSystem {
+		return nil
+	}
+
+	rows, err = tx.Query(`SELECT legs, odds, amount, status
+		FROM bet_lines WHERE bet_id = ? ORDER BY position`, bet.ID)
+	if err != nil {
+		return fmt.Errorf("query lines of bet %s: %w", bet.ID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			line         model.BetLine
+			legs, status string
+			odds, amount int64
+		)
+		if err := rows.Scan(&legs, &odds, &amount, &status); err != nil {
+			return fmt.Errorf("scan line of bet %s: %w", bet.ID, err)
+		}
+		if line.Legs, err = parsePositions(legs); err != nil {
+			return fmt.Errorf("line of bet %s: %w", bet.ID, err)
+		}
+		line.Odds = money.Odds(odds)
+		line.Amount = money.FromMinor(amount)
+		line.Status = model.BetStatus(status)
+		bet.Lines = append(bet.Lines, line)
+	}
+	return rows.Err()
+}
+
+// EventExposure returns the exposure rows of an event.
+func (r *SQLiteRepository) EventExposure(eventID string) ([]*model.ExposureEntry, error) {
+	return queryExposure(r.db, `SELECT `+exposureColumns+` FROM event_exposure WHERE event_id = ?
+		ORDER BY market_id, selection_id, currency`, eventID)
+}
+
+// ListExposure returns the exposure rows of every event.
+func (r *SQLiteRepository) ListExposure() ([]*model.ExposureEntry, error) {
+	return queryExposure(r.db, `SELECT `+exposureColumns+` FROM event_exposure
+		ORDER BY event_id, market_id, selection_id, currency`)
+}
+
+func queryExposure(q queryer, query string, args ...any) ([]*model.ExposureEntry, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query exposure: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*model.ExposureEntry{}
+	for rows.Next() {
+		var (
+			entry         model.ExposureEntry
+			currency      string
+			stake, payout int64
+		)
+		if err := rows.Scan(&entry.EventID, &entry.MarketID, &entry.SelectionID, &currency, &entry.Bets, &stake, &payout); err != nil {
+			return nil, fmt.Errorf("scan exposure: %w", err)
+		}
+		entry.Currency = money.Currency(currency)
+		entry.Stake = money.FromMinor(stake)
+		entry.Payout = money.FromMinor(payout)
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,205 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+const freeBetColumns = `id, user_id, value, currency, event_ids, min_odds, status, expires_at, bet_id, reason, created_at, closed_at`
+
+func scanFreeBet(row rowScanner) (*model.FreeBet, error) {
+	var (
+		f                   model.FreeBet
+		value, minOdds      int64
+		currency, status    string
+		eventIDs            string
+		expiresAt           int64
+		createdAt, closedAt sql.NullInt64
+	)
+	if err := row.Scan(&f.ID, &f.UserID, &value, &currency, &eventIDs, &minOdds, &status, &expiresAt, &f.BetID, &f.Reason, &createdAt, &closedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(eventIDs), &f.EventIDs); err != nil {
+		return nil, fmt.Errorf("decode events of free bet %s: %w", f.ID, err)
+	}
+	if len(f.EventIDs) == 0 {
+		f.EventIDs = nil
+	}
+	f.Value = money.FromMinor(value)
+	f.Currency = money.Currency(currency)
+	f.MinOdds = money.Odds(minOdds)
+	f.Status = model.FreeBetStatus(status)
+	f.ExpiresAt = time.Unix(0, expiresAt)
+	f.CreatedAt = fromUnix(createdAt)
+	f.ClosedAt = fromUnix(closedAt)
+	return &f, nil
+}
+
+func getFreeBet(q queryer, freeBetID string) (*model.FreeBet, error) {
+	f, err := scanFreeBet(q.QueryRow(`SELECT `+freeBetColumns+` FROM free_bets WHERE id = ?`, freeBetID))
+	if err == sql.ErrNoRows {
+		return nil, &errors.ErrorNotFound{Entity: "Free bet", ID: freeBetID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load free bet %s: %w", freeBetID, err)
+	}
+	return f, nil
+}
+
+// CreateFreeBet grants a free-bet token to an existing user.
+func (r *SQLiteRepository) CreateFreeBet(freeBet *model.FreeBet) (*model.FreeBet, error) {
+	stored := freeBet.Clone()
+	err := r.withTx(func(tx *sql.Tx) error {
+		user, err := getUser(tx, freeBet.UserID)
+		if err != nil {
+			return err
+		}
+		if stored.Currency == "" {
+			stored.Currency = user.Currency
+		}
+		stored.ID = uuid.New().String()
+		stored.Status = model.FreeBetActive
+		stored.CreatedAt = time.Now()
+
+		eventIDs := stored.EventIDs
+		if eventIDs == nil {
+			eventIDs = []string{}
+		}
+		encoded, err := json.Marshal(eventIDs)
+		if err != nil {
+			return fmt.Errorf("encode events of free bet: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO free_bets (`+freeBetColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			stored.ID, stored.UserID, stored.Value.Minor(), string(stored.Currency), string(encoded), stored.MinOdds.Raw(), string(stored.Status),
+			stored.ExpiresAt.UnixNano(), stored.BetID, stored.Reason, toUnix(stored.CreatedAt), toUnix(stored.ClosedAt)); err != nil {
+			return fmt.Errorf("insert free bet: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// GetFreeBet retrieves a free-bet token by its ID.
+func (r *SQLiteRepository) GetFreeBet(freeBetID string) (*model.FreeBet, error) {
+	return getFreeBet(r.db, freeBetID)
+}
+
+// ListFreeBets returns a user's free-bet tokens, oldest first.
+func (r *SQLiteRepository) ListFreeBets(userID string) ([]*model.FreeBet, error) {
+	if _, err := r.GetUser(userID); err != nil {
+		return nil, err
+	}
+	return queryFreeBets(r.db, `SELECT `+freeBetColumns+` FROM free_bets WHERE user_id = ? ORDER BY created_at, rowid`, userID)
+}
+
+// RevokeFreeBet revokes one of a user's active tokens.
+func (r *SQLiteRepository) RevokeFreeBet(userID, freeBetID, reason string) (*model.FreeBet, error) {
+	var revoked *model.FreeBet
+	err := r.withTx(func(tx *sql.Tx) error {
+		freeBet, err := getFreeBet(tx, freeBetID)
+		if err != nil {
+			return err
+		}
+		if freeBet.UserID != userID {
+			return &errors.ErrorNotFound{Entity: "Free bet", ID: freeBetID}
+		}
+		now := time.Now()
+		if err := checkFreeBetActive(freeBet, now); err != nil {
+			return err
+		}
+		freeBet.Status = model.FreeBetRevoked
+		freeBet.Reason = reason
+		freeBet.ClosedAt = now
+		if _, err := tx.Exec(`UPDATE free_bets SET status = ?, reason = ?, closed_at = ? WHERE id = ?`,
+			string(freeBet.Status), freeBet.Reason, toUnix(freeBet.ClosedAt), freeBet.ID); err != nil {
+			return fmt.Errorf("revoke free bet %s: %w", freeBet.ID, err)
+		}
+		revoked = freeBet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// ExpireFreeBets marks every active token that lapsed by now as expired and
+// returns them in the order they expired.
+func (r *SQLiteRepository) ExpireFreeBets(now time.Time) ([]*model.FreeBet, error) {
+	var expired []*model.FreeBet
+	err := r.withTx(func(tx *sql.Tx) error {
+		lapsed, err := queryFreeBets(tx, `SELECT `+freeBetColumns+` FROM free_bets
+			WHERE status = ? AND expires_at <= ? ORDER BY expires_at, id`, string(model.FreeBetActive), now.UnixNano())
+		if err != nil {
+			return err
+		}
+		for _, f := range lapsed {
+			f.Refresh(now)
+			if _, err := tx.Exec(`UPDATE free_bets SET status = ?, closed_at = ? WHERE id = ?`,
+				string(f.Status), toUnix(f.ClosedAt), f.ID); err != nil {
+				return fmt.Errorf("expire free bet %s: %w", f.ID, err)
+			}
+		}
+		expired = lapsed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+func queryFreeBets(q queryer, query string, args ...any) ([]*model.FreeBet, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query free bets: %w", err)
+	}
+	defer rows.Close()
+
+	freeBets := []*model.FreeBet{}
+	for rows.Next() {
+		f, err := scanFreeBet(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan free bet: %w", err)
+		}
+		freeBets = append(freeBets, f)
+	}
+	return freeBets, rows.Err()
+}
+
+// checkFreeBetActive reports why a token can no longer be used or revoked.
+func checkFreeBetActive(freeBet *model.FreeBet, now time.Time) error {
+	if freeBet.Lapsed(now) {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("free bet %s expired at %s", freeBet.ID, freeBet.ExpiresAt.Format(time.RFC3339))}
+	}
+	if freeBet.Status != model.FreeBetActive {
+		return &errors.ErrorConflict{Message: fmt.Sprintf("free bet %s is already %s", freeBet.ID, freeBet.Status)}
+	}
+	return nil
+}
+
+// checkFreeBet reports why a token, nil if it does not exist, cannot stake
+// bet.
+func checkFreeBet(freeBet *model.FreeBet, bet *model.Bet, now time.Time) error {
+	if freeBet == nil || freeBet.UserID != bet.UserID {
+		return &errors.ErrorNotFound{Entity: "Free bet", ID: bet.FreeBetID}
+	}
+	if err := checkFreeBetActive(freeBet, now); err != nil {
+		return err
+	}
+	if err := freeBet.CheckEligible(bet); err != nil {
+		return &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+)
+
+// ClaimIdempotencyKey stores a new record unless a live one holds its key,
+// which is returned instead. Expired records are purged in the same
+// transaction.
+func (r *SQLiteRepository) ClaimIdempotencyKey(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	var existing *model.IdempotencyRecord
+	err := r.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, toUnix(time.Now())); err != nil {
+			return fmt.Errorf("purge expired idempotency keys: %w", err)
+		}
+
+		var (
+			stored               model.IdempotencyRecord
+			headers              sql.NullString
+			createdAt, expiresAt sql.NullInt64
+		)
+		err := tx.QueryRow(`SELECT key, fingerprint, status_code, headers, body, created_at, expires_at FROM idempotency_keys WHERE key = ?`, record.Key).
+			Scan(&stored.Key, &stored.Fingerprint, &stored.StatusCode, &headers, &stored.Body, &createdAt, &expiresAt)
+		switch {
+		case err == nil:
+			if headers.Valid {
+				if err := json.Unmarshal([]byte(headers.String), &stored.Headers); err != nil {
+					return fmt.Errorf("decode headers of idempotency key %s: %w", record.Key, err)
+				}
+			}
+			stored.CreatedAt = fromUnix(createdAt)
+			stored.ExpiresAt = fromUnix(expiresAt)
+			existing = &stored
+			return nil
+		case err != sql.ErrNoRows:
+			return fmt.Errorf("load idempotency key: %w", err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO idempotency_keys (key, fingerprint, status_code, body, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			record.Key, record.Fingerprint, record.StatusCode, record.Body, toUnix(record.CreatedAt), toUnix(record.ExpiresAt)); err != nil {
+			return fmt.Errorf("insert idempotency key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// CompleteIdempotencyKey stores the response and expiry of record over the
+// claim it was made with.
+func (r *SQLiteRepository) CompleteIdempotencyKey(record *model.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return fmt.Errorf("encode headers of idempotency key %s: %w", record.Key, err)
+	}
+	res, err := r.db.Exec(`UPDATE idempotency_keys SET status_code = ?, headers = ?, body = ?, expires_at = ? WHERE key = ? AND created_at = ?`,
+		record.StatusCode, string(headers), record.Body, toUnix(record.ExpiresAt), record.Key, toUnix(record.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return &errors.ErrorNotFound{Entity: "Idempotency key", ID: record.Key}
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the claim record was made with, if it still
+// holds its key.
+func (r *SQLiteRepository) ReleaseIdempotencyKey(record *model.IdempotencyRecord) error {
+	if _, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE key = ? AND created_at = ?`, record.Key, toUnix(record.CreatedAt)); err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+const limitColumns = `scope, scope_id, min_stake, max_stake, max_payout, max_event_liability, max_daily_liability, currency, updated_at`
+
+func scanLimits(row rowScanner) (*model.Limits, error) {
+	var (
+		l                                    model.Limits
+		scope, currency                      string
+		minStake, maxStake, maxPayout        int64
+		maxEventLiability, maxDailyLiability int64
+		updatedAt                            sql.NullInt64
+	)
+	if err := row.Scan(&scope, &l.ScopeID, &minStake, &maxStake, &maxPayout, &maxEventLiability, &maxDailyLiability, &currency, &updatedAt); err != nil {
+		return nil, err
+	}
+	l.Scope = model.LimitScope(scope)
+	l.MinStake = money.FromMinor(minStake)
+	l.MaxStake = money.FromMinor(maxStake)
+	l.MaxPayout = money.FromMinor(maxPayout)
+	l.MaxEventLiability = money.FromMinor(maxEventLiability)
+	l.MaxDailyLiability = money.FromMinor(maxDailyLiability)
+	l.Currency = money.Currency(currency)
+	l.UpdatedAt = fromUnix(updatedAt)
+	return &l, nil
+}
+
+// limitsName names the limits of a scope when they are missing.
+func limitsName(scope model.LimitScope, scopeID string) string {
+	if scopeID == "" {
+		return string(scope)
+	}
+	return string(scope) + ":" + scopeID
+}
+
+// SaveLimits stores the limits of a scope, replacing any it had.
+func (r *SQLiteRepository) SaveLimits(limits *model.Limits) (*model.Limits, error) {
+	stored := limits.Clone()
+	stored.UpdatedAt = time.Now()
+	if _, err := r.db.Exec(`INSERT OR REPLACE INTO limits (`+limitColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		string(stored.Scope), stored.ScopeID, stored.MinStake.Minor(), stored.MaxStake.Minor(), stored.MaxPayout.Minor(),
+		stored.MaxEventLiability.Minor(), stored.MaxDailyLiability.Minor(), string(stored.Currency), toUnix(stored.UpdatedAt)); err != nil {
+		return nil, fmt.Errorf("save limits %s: %w", limitsName(stored.Scope, stored.ScopeID), err)
+	}
+	return stored, nil
+}
+
+// GetLimits retrieves the limits of a scope.
+func (r *SQLiteRepository) GetLimits(scope model.LimitScope, scopeID string) (*model.Limits, error) {
+	l, err := scanLimits(r.db.QueryRow(`SELECT `+limitColumns+` FROM limits WHERE scope = ? AND scope_id = ?`, string(scope), scopeID))
+	if err == sql.ErrNoRows {
+		return nil, &errors.ErrorNotFound{Entity: "Limits", ID: limitsName(scope, scopeID)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load limits %s: %w", limitsName(scope, scopeID), err)
+	}
+	return l, nil
+}
+
+// ListLimits returns every set of limits, global first, then by scope and ID.
+func (r *SQLiteRepository) ListLimits() ([]*model.Limits, error) {
+	rows, err := r.db.Query(`SELECT `+limitColumns+` FROM limits ORDER BY scope != ?, scope, scope_id`, string(model.LimitScopeGlobal))
+	if err != nil {
+		return nil, fmt.Errorf("query limits: %w", err)
+	}
+	defer rows.Close()
+
+	all := []*model.Limits{}
+	for rows.Next() {
+		l, err := scanLimits(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan limits: %w", err)
+		}
+		all = append(all, l)
+	}
+	return all, rows.Err()
+}
+
+// DeleteLimits removes the limits of a scope.
+func (r *SQLiteRepository) DeleteLimits(scope model.LimitScope, scopeID string) error {
+	res, err := r.db.Exec(`DELETE FROM limits WHERE scope = ? AND scope_id = ?`, string(scope), scopeID)
+	if err != nil {
+		return fmt.Errorf("delete limits %s: %w", limitsName(scope, scopeID), err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return &errors.ErrorNotFound{Entity: "Limits", ID: limitsName(scope, scopeID)}
+	}
+	return nil
+}
+
+// placementLiability measures the liability a new bet adds to: the open
+// bets on each of its events and the user's bets placed today, read
+// through the event and user indexes.
+func placementLiability(tx *sql.Tx, bet *model.Bet, now time.Time) (*model.PlacementLiability, error) {
+	eventBets := make(map[string][]*model.Bet)
+	for _, eventID := range bet.EventIDs() {
+		placed, err := queryBets(tx, placedOnEvent, eventID, eventID, string(model.StatusPlaced))
+		if err != nil {
+			return nil, err
+		}
+		eventBets[eventID] = placed
+	}
+	today, err := queryBets(tx, `SELECT `+betColumns+` FROM bets WHERE user_id = ? AND created_at >= ?`,
+		bet.UserID, toUnix(model.LimitDay(now)))
+	if err != nil {
+		return nil, err
+	}
+	return model.MeasureLiability(eventBets, today), nil
+}
@@ -0,0 +1,429 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// migration is one versioned step of the schema. Versions are applied in
+// order and each one exactly once; never edit a migration that has shipped,
+// append a new one instead.
+type migration struct {
+	version int
+	name    string
+	stmts   []string
+	// backfill, when set, fills in what the statements added from existing
+	// rows, in the same transaction.
+	backfill func(tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		stmts: []string{
+			`CREATE TABLE users (
+				id         TEXT PRIMARY KEY,
+				balance    INTEGER NOT NULL,
+				created_at INTEGER NOT NULL,
+				updated_at INTEGER NOT NULL
+			)`,
+			`CREATE TABLE bets (
+				id         TEXT PRIMARY KEY,
+				user_id    TEXT NOT NULL,
+				event_id   TEXT NOT NULL,
+				odds       INTEGER NOT NULL,
+				amount     INTEGER NOT NULL,
+				status     TEXT NOT NULL,
+				created_at INTEGER NOT NULL,
+				settled_at INTEGER
+			)`,
+			`CREATE INDEX idx_bets_event_status ON bets (event_id, status)`,
+			`CREATE TABLE journal_entries (
+				seq         INTEGER PRIMARY KEY AUTOINCREMENT,
+				id          TEXT NOT NULL UNIQUE,
+				type        TEXT NOT NULL,
+				user_id     TEXT NOT NULL,
+				bet_id      TEXT NOT NULL DEFAULT '',
+				description TEXT NOT NULL DEFAULT '',
+				created_at  INTEGER NOT NULL
+			)`,
+			`CREATE TABLE postings (
+				entry_seq INTEGER NOT NULL REFERENCES journal_entries (seq),
+				account   TEXT NOT NULL,
+				amount    INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idx_postings_account ON postings (account, entry_seq)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "events, markets and selections",
+		stmts: []string{
+			`CREATE TABLE events (
+				id         TEXT PRIMARY KEY,
+				name       TEXT NOT NULL,
+				created_at INTEGER NOT NULL
+			)`,
+			`CREATE TABLE markets (
+				id       TEXT PRIMARY KEY,
+				event_id TEXT NOT NULL REFERENCES events (id),
+				name     TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_markets_event ON markets (event_id)`,
+			`CREATE TABLE selections (
+				id        TEXT PRIMARY KEY,
+				market_id TEXT NOT NULL REFERENCES markets (id),
+				event_id  TEXT NOT NULL REFERENCES events (id),
+				name      TEXT NOT NULL,
+				odds      INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idx_selections_market ON selections (market_id)`,
+			`ALTER TABLE bets ADD COLUMN market_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE bets ADD COLUMN selection_id TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 3,
+		name:    "multi-leg bets",
+		stmts: []string{
+			`ALTER TABLE bets ADD COLUMN type TEXT NOT NULL DEFAULT 'SINGLE'`,
+			`CREATE TABLE bet_legs (
+				bet_id       TEXT NOT NULL REFERENCES bets (id),
+				position     INTEGER NOT NULL,
+				event_id     TEXT NOT NULL,
+				market_id    TEXT NOT NULL DEFAULT '',
+				selection_id TEXT NOT NULL DEFAULT '',
+				odds         INTEGER NOT NULL,
+				status       TEXT NOT NULL,
+				settled_at   INTEGER,
+				PRIMARY KEY (bet_id, position)
+			)`,
+			`CREATE INDEX idx_bet_legs_event ON bet_legs (event_id, status)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "system bet lines",
+		stmts: []string{
+			`CREATE TABLE bet_lines (
+				bet_id     TEXT NOT NULL REFERENCES bets (id),
+				position   INTEGER NOT NULL,
+				legs       TEXT NOT NULL,
+				odds       INTEGER NOT NULL,
+				amount     INTEGER NOT NULL,
+				status     TEXT NOT NULL,
+				settled_at INTEGER,
+				PRIMARY KEY (bet_id, position)
+			)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "cash-out",
+		stmts: []string{
+			`ALTER TABLE bets ADD COLUMN cashed_out INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 6,
+		name:    "partial cash-out",
+		stmts: []string{
+			`ALTER TABLE bets ADD COLUMN cashed_out_stake INTEGER NOT NULL DEFAULT 0`,
+			// Bets cashed out in full before this migration closed their whole stake.
+			`UPDATE bets SET cashed_out_stake = amount WHERE status = 'CASHED_OUT'`,
+			`CREATE TABLE bet_cash_outs (
+				seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+				bet_id     TEXT NOT NULL REFERENCES bets (id),
+				stake      INTEGER NOT NULL,
+				value      INTEGER NOT NULL,
+				created_at INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idx_bet_cash_outs_bet ON bet_cash_outs (bet_id, seq)`,
+			`INSERT INTO bet_cash_outs (bet_id, stake, value, created_at)
+				SELECT id, amount, cashed_out, settled_at FROM bets WHERE status = 'CASHED_OUT'`,
+		},
+	},
+	{
+		version: 7,
+		name:    "bet listing indexes",
+		stmts: []string{
+			`CREATE INDEX idx_bets_user_created ON bets (user_id, created_at)`,
+			`CREATE INDEX idx_bets_status_created ON bets (status, created_at)`,
+			`CREATE INDEX idx_bets_created ON bets (created_at, id)`,
+			`CREATE INDEX idx_bets_settled ON bets (settled_at, id)`,
+			`CREATE INDEX idx_bets_amount ON bets (amount, id)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "idempotency keys",
+		stmts: []string{
+			`CREATE TABLE idempotency_keys (
+				key         TEXT PRIMARY KEY,
+				fingerprint TEXT NOT NULL,
+				status_code INTEGER NOT NULL DEFAULT 0,
+				body        BLOB,
+				created_at  INTEGER NOT NULL,
+				expires_at  INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idx_idempotency_keys_expiry ON idempotency_keys (expires_at)`,
+		},
+	},
+	{
+		version: 9,
+		name:    "row versions",
+		stmts: []string{
+			`ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE bets ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+		},
+	},
+	{
+		version: 10,
+		name:    "deposits and withdrawals",
+		stmts: []string{
+			`CREATE TABLE wallet_operations (
+				id          TEXT PRIMARY KEY,
+				user_id     TEXT NOT NULL,
+				type        TEXT NOT NULL,
+				reference   TEXT NOT NULL UNIQUE,
+				amount      INTEGER NOT NULL,
+				status      TEXT NOT NULL,
+				reason      TEXT NOT NULL DEFAULT '',
+				created_at  INTEGER NOT NULL,
+				resolved_at INTEGER
+			)`,
+			`CREATE INDEX idx_wallet_operations_user ON wallet_operations (user_id, type, created_at)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "multi-currency wallets",
+		stmts: []string{
+			// Everything so far was in EUR: balances move to per-currency
+			// rows and every ledger account gains its currency suffix.
+			`ALTER TABLE users ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR'`,
+			`CREATE TABLE user_balances (
+				user_id  TEXT NOT NULL,
+				currency TEXT NOT NULL,
+				balance  INTEGER NOT NULL,
+				PRIMARY KEY (user_id, currency)
+			)`,
+			`INSERT INTO user_balances (user_id, currency, balance) SELECT id, 'EUR', balance FROM users`,
+			`ALTER TABLE users DROP COLUMN balance`,
+			`ALTER TABLE bets ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR'`,
+			`ALTER TABLE wallet_operations ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR'`,
+			`ALTER TABLE journal_entries ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR'`,
+			`UPDATE postings SET account = account || ':EUR'`,
+		},
+	},
+	{
+		version: 12,
+		name:    "free bets",
+		stmts: []string{
+			`CREATE TABLE free_bets (
+				id         TEXT PRIMARY KEY,
+				user_id    TEXT NOT NULL,
+				value      INTEGER NOT NULL,
+				currency   TEXT NOT NULL,
+				event_ids  TEXT NOT NULL DEFAULT '[]',
+				min_odds   INTEGER NOT NULL DEFAULT 0,
+				status     TEXT NOT NULL,
+				expires_at INTEGER NOT NULL,
+				bet_id     TEXT NOT NULL DEFAULT '',
+				reason     TEXT NOT NULL DEFAULT '',
+				created_at INTEGER NOT NULL,
+				closed_at  INTEGER
+			)`,
+			`CREATE INDEX idx_free_bets_user ON free_bets (user_id, created_at)`,
+			`CREATE INDEX idx_free_bets_status ON free_bets (status, expires_at)`,
+			`ALTER TABLE bets ADD COLUMN free_bet_id TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 13,
+		name:    "odds boosts",
+		stmts: []string{
+			`CREATE TABLE boosts (
+				id                TEXT PRIMARY KEY,
+				name              TEXT NOT NULL,
+				event_id          TEXT NOT NULL,
+				selection_id      TEXT NOT NULL DEFAULT '',
+				increase_bps      INTEGER NOT NULL,
+				max_stake         INTEGER NOT NULL,
+				currency          TEXT NOT NULL,
+				max_uses_per_user INTEGER NOT NULL,
+				status            TEXT NOT NULL,
+				starts_at         INTEGER NOT NULL,
+				ends_at           INTEGER NOT NULL,
+				created_at        INTEGER NOT NULL,
+				cancelled_at      INTEGER
+			)`,
+			`ALTER TABLE bets ADD COLUMN boost_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE bets ADD COLUMN original_odds INTEGER NOT NULL DEFAULT 0`,
+			`CREATE INDEX idx_bets_boost ON bets (boost_id, user_id) WHERE boost_id != ''`,
+		},
+	},
+	{
+		version: 14,
+		name:    "betting limits",
+		stmts: []string{
+			`CREATE TABLE limits (
+				scope               TEXT NOT NULL,
+				scope_id            TEXT NOT NULL DEFAULT '',
+				min_stake           INTEGER NOT NULL DEFAULT 0,
+				max_stake           INTEGER NOT NULL DEFAULT 0,
+				max_payout          INTEGER NOT NULL DEFAULT 0,
+				max_event_liability INTEGER NOT NULL DEFAULT 0,
+				max_daily_liability INTEGER NOT NULL DEFAULT 0,
+				currency            TEXT NOT NULL,
+				updated_at          INTEGER NOT NULL,
+				PRIMARY KEY (scope, scope_id)
+			)`,
+		},
+	},
+	{
+		version: 15,
+		name:    "event exposure",
+		stmts: []string{
+			`CREATE TABLE event_exposure (
+				event_id     TEXT NOT NULL,
+				market_id    TEXT NOT NULL DEFAULT '',
+				selection_id TEXT NOT NULL DEFAULT '',
+				currency     TEXT NOT NULL,
+				bets         INTEGER NOT NULL DEFAULT 0,
+				stake        INTEGER NOT NULL DEFAULT 0,
+				payout       INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY (event_id, market_id, selection_id, currency)
+			)`,
+		},
+		backfill: backfillExposure,
+	},
+	{
+		version: 16,
+		name:    "event status",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN status TEXT NOT NULL DEFAULT 'OPEN'`,
+			`ALTER TABLE events ADD COLUMN status_changed_at INTEGER`,
+			`UPDATE events SET status_changed_at = created_at`,
+			// Bets used to be taken on any event ID. Give those events a
+			// record so their open bets can be settled, and take no more
+			// bets on them: SUSPENDED while bets await them, else SETTLED.
+			`INSERT INTO events (id, name, status, created_at, status_changed_at)
+				SELECT event_id, event_id, CASE WHEN SUM(status = 'PLACED') > 0 THEN 'SUSPENDED' ELSE 'SETTLED' END,
+					MIN(created_at), MIN(created_at)
+				FROM (
+					SELECT event_id, status, created_at FROM bets WHERE event_id != ''
+					UNION ALL
+					SELECT l.event_id, l.status, b.created_at FROM bet_legs l JOIN bets b ON b.id = l.bet_id
+				)
+				WHERE event_id NOT IN (SELECT id FROM events)
+				GROUP BY event_id`,
+		},
+	},
+	{
+		version: 17,
+		name:    "settlement corrections",
+		stmts: []string{
+			// result and settlement hold JSON, and are NULL for an
+			// unsettlement; clawed_back holds a JSON object by currency.
+			`CREATE TABLE settlement_corrections (
+				id            TEXT PRIMARY KEY,
+				event_id      TEXT NOT NULL,
+				action        TEXT NOT NULL,
+				operator      TEXT NOT NULL,
+				reason        TEXT NOT NULL,
+				result        TEXT,
+				bets_reopened INTEGER NOT NULL,
+				clawed_back   TEXT NOT NULL,
+				settlement    TEXT,
+				event_status  TEXT NOT NULL DEFAULT '',
+				created_at    INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idx_settlement_corrections_event ON settlement_corrections (event_id, created_at)`,
+		},
+	},
+	{
+		version: 18,
+		name:    "settlement jobs",
+		stmts: []string{
+			// request and summary hold JSON, errors a JSON array; cursor is
+			// the rowid of the last bet the job went through.
+			`CREATE TABLE settlement_jobs (
+				id          TEXT PRIMARY KEY,
+				event_id    TEXT NOT NULL,
+				request     TEXT NOT NULL,
+				state       TEXT NOT NULL,
+				total       INTEGER NOT NULL DEFAULT 0,
+				processed   INTEGER NOT NULL DEFAULT 0,
+				errors      TEXT NOT NULL DEFAULT '[]',
+				summary     TEXT,
+				cursor      INTEGER NOT NULL DEFAULT 0,
+				created_at  INTEGER NOT NULL,
+				started_at  INTEGER,
+				finished_at INTEGER,
+				updated_at  INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idx_settlement_jobs_state ON settlement_jobs (state, created_at)`,
+			`CREATE INDEX idx_settlement_jobs_event ON settlement_jobs (event_id, state)`,
+		},
+	},
+	{
+		version: 19,
+		name:    "idempotency response headers",
+		stmts: []string{
+			// headers holds a JSON object of the response headers to replay.
+			`ALTER TABLE idempotency_keys ADD COLUMN headers TEXT`,
+		},
+	},
+}
+
+// migrate brings the database schema up to the latest version.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("migration %d: %w", m.version, err)
+		}
+		for _, stmt := range m.stmts {
+			if _, err := tx.Exec(stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+			}
+		}
+		if m.backfill != nil {
+			if err := m.backfill(tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.version, m.name, time.Now().UnixNano()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", m.version, err)
+		}
+		log.Printf("Applied schema migration %d: %s", m.version, m.name)
+	}
+	return nil
+}
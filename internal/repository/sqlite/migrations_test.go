@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+// TestBackfillExposure migrates a database holding open bets from the
+// version before the exposure table to the latest one.
+func TestBackfillExposure(t *testing.T) {
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	all := migrations
+	migrations = all[:14]
+	err = migrate(db)
+	migrations = all
+	if err != nil {
+		t.Fatalf("migrate to version 14: %v", err)
+	}
+
+	for _, stmt := range []string{
+		`INSERT INTO bets (id, user_id, type, event_id, market_id, selection_id, odds, amount, status, created_at)
+			VALUES ('single', 'alice', 'SINGLE', 'e1', 'm1', 's1', 25000, 1000, 'PLACED', 1)`,
+		`INSERT INTO bets (id, user_id, type, event_id, market_id, selection_id, odds, amount, status, created_at, settled_at)
+			VALUES ('lost', 'alice', 'SINGLE', 'e1', 'm1', 's1', 25000, 1000, 'LOST', 1, 2)`,
+		`INSERT INTO bets (id, user_id, type, event_id, odds, amount, status, created_at)
+			VALUES ('acca', 'alice', 'ACCUMULATOR', '', 60000, 500, 'PLACED', 1)`,
+		`INSERT INTO bet_legs (bet_id, position, event_id, market_id, selection_id, odds, status)
+			VALUES ('acca', 0, 'e1', 'm1', 's2', 20000, 'PLACED'), ('acca', 1, 'e2', 'm2', 's3', 30000, 'WON')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seed version 14: %v", err)
+		}
+	}
+
+	if err := migrate(db); err != nil {
+		t.Fatalf("migrate to the latest version: %v", err)
+	}
+	entries, err := (&SQLiteRepository{db: db}).ListExposure()
+	if err != nil {
+		t.Fatalf("ListExposure: %v", err)
+	}
+	want := map[string][2]money.Money{
+		"s1": {money.MustParse("10.00"), money.MustParse("25.00")},
+		"s2": {money.MustParse("5.00"), money.MustParse("30.00")},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("exposure = %+v, want rows for %v", entries, want)
+	}
+	for _, e := range entries {
+		w, ok := want[e.SelectionID]
+		if !ok || e.EventID != "e1" || e.Currency != "EUR" || e.Bets != 1 || e.Stake != w[0] || e.Payout != w[1] {
+			t.Fatalf("exposure row %+v, want stake and payout %v on e1", e, w)
+		}
+	}
+}
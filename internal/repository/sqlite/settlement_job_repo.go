@@ -0,0 +1,343 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+const settlementJobColumns = `id, event_id, request, state, total, processed, errors, summary, cursor, created_at, started_at, finished_at, updated_at`
+
+// placedOnEventAfter selects the rowids of the PLACED bets on an event
+// placed after a rowid, in the order they were placed. It takes the event ID
+// twice, the status, the rowid and a limit.
+const placedOnEventAfter = `SELECT rowid FROM bets
+	WHERE (event_id = ? OR id IN (SELECT bet_id FROM bet_legs WHERE event_id = ?)) AND status = ? AND rowid > ?
+	ORDER BY rowid LIMIT ?`
+
+// CreateSettlementJob stores a new QUEUED job, unless its event has an
+// unfinished one.
+func (r *SQLiteRepository) CreateSettlementJob(job *model.SettlementJob) (*model.SettlementJob, error) {
+	stored := job.Clone()
+	err := r.withTx(func(tx *sql.Tx) error {
+		if err := checkNoSettlementJob(tx, stored.EventID); err != nil {
+			return err
+		}
+
+		stored.ID = uuid.New().String()
+		stored.State = model.JobQueued
+		stored.CreatedAt = time.Now()
+		stored.UpdatedAt = stored.CreatedAt
+		request, err := json.Marshal(stored.Request)
+		if err != nil {
+			return fmt.Errorf("encode request of settlement job: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO settlement_jobs (id, event_id, request, state, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			stored.ID, stored.EventID, string(request), string(stored.State), toUnix(stored.CreatedAt), toUnix(stored.UpdatedAt)); err != nil {
+			return fmt.Errorf("insert settlement job: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// checkNoSettlementJob reports a conflict if the event has an unfinished
+// settlement job, which settles its bets alone until it finishes.
+func checkNoSettlementJob(tx *sql.Tx, eventID string) error {
+	var jobID, state string
+	err := tx.QueryRow(`SELECT id, state FROM settlement_jobs WHERE event_id = ? AND state IN (?, ?) LIMIT 1`,
+		eventID, string(model.JobQueued), string(model.JobRunning)).Scan(&jobID, &state)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check settlement jobs of event %s: %w", eventID, err)
+	}
+	return &errors.ErrorConflict{Message: fmt.Sprintf("event %s has settlement job %s %s", eventID, jobID, state)}
+}
+
+// GetSettlementJob retrieves a settlement job by its ID.
+func (r *SQLiteRepository) GetSettlementJob(jobID string) (*model.SettlementJob, error) {
+	return getSettlementJob(r.db, jobID)
+}
+
+// ListSettlementJobs returns the jobs in any of states, oldest first.
+func (r *SQLiteRepository) ListSettlementJobs(states ...model.SettlementJobState) ([]*model.SettlementJob, error) {
+	query := `SELECT ` + settlementJobColumns + ` FROM settlement_jobs`
+	args := make([]any, len(states))
+	if len(states) > 0 {
+		query += ` WHERE state IN (?` + strings.Repeat(`, ?`, len(states)-1) + `)`
+		for i, state := range states {
+			args[i] = string(state)
+		}
+	}
+	rows, err := r.db.Query(query+` ORDER BY created_at, rowid`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query settlement jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*model.SettlementJob{}
+	for rows.Next() {
+		job, err := scanSettlementJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimSettlementJob starts the oldest QUEUED job in a transaction, so no
+// two callers claim the same one.
+func (r *SQLiteRepository) ClaimSettlementJob() (*model.SettlementJob, error) {
+	var claimed *model.SettlementJob
+	err := r.withTx(func(tx *sql.Tx) error {
+		job, err := scanSettlementJob(tx.QueryRow(`SELECT `+settlementJobColumns+` FROM settlement_jobs WHERE state = ?
+			ORDER BY created_at, rowid LIMIT 1`, string(model.JobQueued)))
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM bets
+			WHERE (event_id = ? OR id IN (SELECT bet_id FROM bet_legs WHERE event_id = ?)) AND status = ?`,
+			job.EventID, job.EventID, string(model.StatusPlaced)).Scan(&job.Total); err != nil {
+			return fmt.Errorf("count bets of event %s: %w", job.EventID, err)
+		}
+		job.State = model.JobRunning
+		job.Summary = &model.SettlementSummary{EventID: job.EventID}
+		job.StartedAt = time.Now()
+		job.UpdatedAt = job.StartedAt
+		if err := saveSettlementJob(tx, job); err != nil {
+			return err
+		}
+		claimed = job
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// SettleJobBatch settles the next batch of a running job's bets in a single
+// transaction together with the job's progress. The job's Cursor is the
+// rowid of the last bet it went through.
+func (r *SQLiteRepository) SettleJobBatch(jobID string, settle model.BetSettler, limit int) (*model.SettlementJob, error) {
+	var job *model.SettlementJob
+	err := r.withTx(func(tx *sql.Tx) error {
+		var err error
+		job, err = unfinishedSettlementJob(tx, jobID)
+		if err != nil {
+			return err
+		}
+		if job.State != model.JobRunning {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("settlement job %s is %s, not RUNNING", jobID, job.State)}
+		}
+		event, err := eventState(tx, job.EventID)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			if err := event.CheckSettleable(); err != nil {
+				// The event moved on under the job; it settles nothing more.
+				job.Errors = append(job.Errors, err.Error())
+				job.State = model.JobFailed
+				job.FinishedAt = time.Now()
+				job.UpdatedAt = job.FinishedAt
+				return saveSettlementJob(tx, job)
+			}
+		}
+
+		// The batch ends at the last of the next limit bets.
+		var last sql.NullInt64
+		if err := tx.QueryRow(`SELECT MAX(rowid) FROM (`+placedOnEventAfter+`)`,
+			job.EventID, job.EventID, string(model.StatusPlaced), job.Cursor, limit).Scan(&last); err != nil {
+			return fmt.Errorf("query bets of event %s: %w", job.EventID, err)
+		}
+		job.UpdatedAt = time.Now()
+		if !last.Valid {
+			// Every bet has been through; finish as SettleEvent would.
+			left, err := queryBets(tx, placedOnEvent, job.EventID, job.EventID, string(model.StatusPlaced))
+			if err != nil {
+				return err
+			}
+			awaiting := false
+			for _, bet := range left {
+				awaiting = awaiting || bet.AwaitsEvent(job.EventID)
+			}
+			if err := finishSettlement(tx, event, awaiting, job.Summary); err != nil {
+				return err
+			}
+			job.State = model.JobCompleted
+			job.FinishedAt = job.UpdatedAt
+			return saveSettlementJob(tx, job)
+		}
+
+		batch, err := queryBets(tx, `SELECT `+betColumns+` FROM bets
+			WHERE (event_id = ? OR id IN (SELECT bet_id FROM bet_legs WHERE event_id = ?)) AND status = ? AND rowid > ? AND rowid <= ?
+			ORDER BY rowid`, job.EventID, job.EventID, string(model.StatusPlaced), job.Cursor, last.Int64)
+		if err != nil {
+			return err
+		}
+		if _, err := settleBets(tx, job.EventID, batch, settle, job.Summary, nil); err != nil {
+			return err
+		}
+		job.Processed += len(batch)
+		job.Cursor = last.Int64
+		return saveSettlementJob(tx, job)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// AddSettlementJobError records an error on an unfinished job.
+func (r *SQLiteRepository) AddSettlementJobError(jobID, message string) (*model.SettlementJob, error) {
+	var job *model.SettlementJob
+	err := r.withTx(func(tx *sql.Tx) error {
+		var err error
+		job, err = unfinishedSettlementJob(tx, jobID)
+		if err != nil {
+			return err
+		}
+		job.Errors = append(job.Errors, message)
+		job.UpdatedAt = time.Now()
+		return saveSettlementJob(tx, job)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// FailSettlementJob records an error on an unfinished job and fails it.
+func (r *SQLiteRepository) FailSettlementJob(jobID, message string) (*model.SettlementJob, error) {
+	return r.finishSettlementJob(jobID, model.JobFailed, message)
+}
+
+// CancelSettlementJob cancels an unfinished job.
+func (r *SQLiteRepository) CancelSettlementJob(jobID string) (*model.SettlementJob, error) {
+	return r.finishSettlementJob(jobID, model.JobCancelled, "")
+}
+
+// finishSettlementJob stops an unfinished job in state, recording message
+// as an error unless it is empty.
+func (r *SQLiteRepository) finishSettlementJob(jobID string, state model.SettlementJobState, message string) (*model.SettlementJob, error) {
+	var job *model.SettlementJob
+	err := r.withTx(func(tx *sql.Tx) error {
+		var err error
+		job, err = unfinishedSettlementJob(tx, jobID)
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			job.Errors = append(job.Errors, message)
+		}
+		job.State = state
+		job.FinishedAt = time.Now()
+		job.UpdatedAt = job.FinishedAt
+		return saveSettlementJob(tx, job)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// unfinishedSettlementJob loads a job that must not have finished.
+func unfinishedSettlementJob(q queryer, jobID string) (*model.SettlementJob, error) {
+	job, err := getSettlementJob(q, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.State.Finished() {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("settlement job %s has already finished as %s", jobID, job.State)}
+	}
+	return job, nil
+}
+
+func getSettlementJob(q queryer, jobID string) (*model.SettlementJob, error) {
+	job, err := scanSettlementJob(q.QueryRow(`SELECT `+settlementJobColumns+` FROM settlement_jobs WHERE id = ?`, jobID))
+	if err == sql.ErrNoRows {
+		return nil, &errors.ErrorNotFound{Entity: "Settlement job", ID: jobID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// saveSettlementJob stores the state and progress of a job.
+func saveSettlementJob(tx *sql.Tx, job *model.SettlementJob) error {
+	errs, err := json.Marshal(job.Errors)
+	if err != nil {
+		return fmt.Errorf("encode errors of settlement job %s: %w", job.ID, err)
+	}
+	var summary any
+	if job.Summary != nil {
+		encoded, err := json.Marshal(job.Summary)
+		if err != nil {
+			return fmt.Errorf("encode summary of settlement job %s: %w", job.ID, err)
+		}
+		summary = string(encoded)
+	}
+	if _, err := tx.Exec(`UPDATE settlement_jobs SET state = ?, total = ?, processed = ?, errors = ?, summary = ?, cursor = ?,
+		started_at = ?, finished_at = ?, updated_at = ? WHERE id = ?`,
+		string(job.State), job.Total, job.Processed, string(errs), summary, job.Cursor,
+		toUnix(job.StartedAt), toUnix(job.FinishedAt), toUnix(job.UpdatedAt), job.ID); err != nil {
+		return fmt.Errorf("update settlement job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// scanSettlementJob reads a job, returning sql.ErrNoRows as is.
+func scanSettlementJob(row rowScanner) (*model.SettlementJob, error) {
+	var (
+		job                                      model.SettlementJob
+		request, state, errs                     string
+		summary                                  sql.NullString
+		createdAt, startedAt, finishedAt, update sql.NullInt64
+	)
+	err := row.Scan(&job.ID, &job.EventID, &request, &state, &job.Total, &job.Processed, &errs, &summary, &job.Cursor,
+		&createdAt, &startedAt, &finishedAt, &update)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan settlement job: %w", err)
+	}
+	if err := json.Unmarshal([]byte(request), &job.Request); err != nil {
+		return nil, fmt.Errorf("decode request of settlement job %s: %w", job.ID, err)
+	}
+	if err := json.Unmarshal([]byte(errs), &job.Errors); err != nil {
+		return nil, fmt.Errorf("decode errors of settlement job %s: %w", job.ID, err)
+	}
+	if len(job.Errors) == 0 {
+		job.Errors = nil
+	}
+	if summary.Valid {
+		if err := json.Unmarshal([]byte(summary.String), &job.Summary); err != nil {
+			return nil, fmt.Errorf("decode summary of settlement job %s: %w", job.ID, err)
+		}
+	}
+	job.State = model.SettlementJobState(state)
+	job.CreatedAt = fromUnix(createdAt)
+	job.StartedAt = fromUnix(startedAt)
+	job.FinishedAt = fromUnix(finishedAt)
+	job.UpdatedAt = fromUnix(update)
+	return &job, nil
+}
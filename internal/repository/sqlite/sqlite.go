@@ -0,0 +1,139 @@
+// Package sqlite is a persistent repository backed by a SQLite database file.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/ledger"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteRepository stores bets, users and the ledger in SQLite.
+// Every operation that moves money runs in a single transaction together
+// with the journal entry that explains it.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository opens (or creates) the database at path and applies
+// any pending schema migrations. Use ":memory:" for a throwaway database.
+func NewSQLiteRepository(path string) (*SQLiteRepository, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database %s: %w", path, err)
+	}
+	// SQLite allows a single writer; one connection serialises access and
+	// keeps ":memory:" databases shared across calls.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteRepository{db: db}, nil
+}
+
+// Close releases the underlying database.
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// withTx runs fn inside a transaction, committing if it returns nil and
+// rolling back otherwise.
+func (r *SQLiteRepository) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// withRollback runs fn in a transaction that is always rolled back, so fn
+// can work out changes without keeping them.
+func (r *SQLiteRepository) withRollback(fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	return fn(tx)
+}
+
+// post records a journal entry and applies its effect on the user's cached
+// balance in the entry's currency within the caller's transaction.
+func post(tx *sql.Tx, entry *model.JournalEntry) error {
+	if err := ledger.Validate(entry); err != nil {
+		return fmt.Errorf("internal error: %w", err)
+	}
+	res, err := tx.Exec(`INSERT INTO journal_entries (id, type, user_id, bet_id, description, currency, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, string(entry.Type), entry.UserID, entry.BetID, entry.Description, string(entry.Currency), toUnix(entry.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("insert journal entry: %w", err)
+	}
+	seq, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("insert journal entry: %w", err)
+	}
+	for _, p := range entry.Postings {
+		if _, err := tx.Exec(`INSERT INTO postings (entry_seq, account, amount) VALUES (?, ?, ?)`,
+			seq, p.Account, p.Amount.Minor()); err != nil {
+			return fmt.Errorf("insert posting: %w", err)
+		}
+	}
+
+	delta := ledger.WalletDelta(entry, entry.UserID)
+	if !delta.IsZero() {
+		if _, err := tx.Exec(`INSERT INTO user_balances (user_id, currency, balance) VALUES (?, ?, ?)
+			ON CONFLICT (user_id, currency) DO UPDATE SET balance = balance + excluded.balance`,
+			entry.UserID, string(entry.Currency), delta.Minor()); err != nil {
+			return fmt.Errorf("update balance: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE users SET updated_at = ?, version = version + 1 WHERE id = ?`,
+			toUnix(time.Now()), entry.UserID); err != nil {
+			return fmt.Errorf("update balance: %w", err)
+		}
+	}
+	return nil
+}
+
+// toUnix stores timestamps as Unix nanoseconds; the zero time is NULL.
+func toUnix(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UnixNano()
+}
+
+func fromUnix(v sql.NullInt64) time.Time {
+	if !v.Valid {
+		return time.Time{}
+	}
+	return time.Unix(0, v.Int64)
+}
+
+func toUnixPtr(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return toUnix(*t)
+}
+
+func fromUnixPtr(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := fromUnix(v)
+	return &t
+}
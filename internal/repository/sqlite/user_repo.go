@@ -0,0 +1,377 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/ledger"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+// defaultBalance is credited to users created without an explicit balance.
+var defaultBalance = money.FromUnits(1000)
+
+const userColumns = `id, currency, created_at, updated_at, version`
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func scanUser(row rowScanner) (*model.User, error) {
+	var (
+		user                 model.User
+		currency             string
+		createdAt, updatedAt sql.NullInt64
+	)
+	if err := row.Scan(&user.ID, &currency, &createdAt, &updatedAt, &user.Version); err != nil {
+		return nil, err
+	}
+	user.Currency = money.Currency(currency)
+	user.CreatedAt = fromUnix(createdAt)
+	user.UpdatedAt = fromUnix(updatedAt)
+	return &user, nil
+}
+
+func getUser(q queryer, userID string) (*model.User, error) {
+	user, err := scanUser(q.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = ?`, userID))
+	if err == sql.ErrNoRows {
+		return nil, &errors.ErrorNotFound{Entity: "User", ID: userID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load user %s: %w", userID, err)
+	}
+	if err := loadBalances(q, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// loadBalances fills in the wallets of users, which must be distinct.
+func loadBalances(q queryer, users ...*model.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	byID := make(map[string]*model.User, len(users))
+	var query string
+	var args []any
+	if len(users) == 1 {
+		query = `SELECT user_id, currency, balance FROM user_balances WHERE user_id = ?`
+		args = []any{users[0].ID}
+	} else {
+		query = `SELECT user_id, currency, balance FROM user_balances`
+	}
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("query balances: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			userID, currency string
+			balance          int64
+		)
+		if err := rows.Scan(&userID, &currency, &balance); err != nil {
+			return fmt.Errorf("scan balance: %w", err)
+		}
+		if user, ok := byID[userID]; ok {
+			user.SetBalance(money.Currency(currency), money.FromMinor(balance))
+		}
+	}
+	return rows.Err()
+}
+
+// CreateUser adds a new user and records their opening balance in the ledger.
+func (r *SQLiteRepository) CreateUser(user *model.User) (*model.User, error) {
+	var created *model.User
+	err := r.withTx(func(tx *sql.Tx) error {
+		if _, err := getUser(tx, user.ID); err == nil {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("user with ID '%s' already exists", user.ID)}
+		} else if _, ok := err.(*errors.ErrorNotFound); !ok {
+			return err
+		}
+
+		opening := user.Balance
+		if opening.IsZero() {
+			opening = defaultBalance
+		}
+		currency := user.Currency
+		if currency == "" {
+			currency = money.DefaultCurrency
+		}
+		// Posting the opening balance brings the version to 1.
+		now := time.Now()
+		if _, err := tx.Exec(`INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?)`,
+			user.ID, string(currency), toUnix(now), toUnix(now), int64(0)); err != nil {
+			return fmt.Errorf("insert user %s: %w", user.ID, err)
+		}
+		if err := post(tx, ledger.AdjustmentEntry(user.ID, opening, currency, "opening balance")); err != nil {
+			return err
+		}
+
+		var err error
+		created, err = getUser(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetUser retrieves a specific user by ID.
+func (r *SQLiteRepository) GetUser(userID string) (*model.User, error) {
+	return getUser(r.db, userID)
+}
+
+// ListUsers retrieves all users.
+func (r *SQLiteRepository) ListUsers() ([]*model.User, error) {
+	rows, err := r.db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := loadBalances(r.db, users...); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UpdateUser updates details of an existing user. A non-zero Version must
+// match the stored user's.
+func (r *SQLiteRepository) UpdateUser(user *model.User) (*model.User, error) {
+	var updated *model.User
+	err := r.withTx(func(tx *sql.Tx) error {
+		existing, err := getUser(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkUserVersion(existing, user.Version); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE users SET updated_at = ?, version = version + 1 WHERE id = ?`, toUnix(time.Now()), user.ID); err != nil {
+			return fmt.Errorf("update user %s: %w", user.ID, err)
+		}
+		updated, err = getUser(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// checkFunds reports why a user cannot pay amount out of their wallet in
+// currency: they have no such wallet, or too little in it.
+func checkFunds(user *model.User, currency money.Currency, amount money.Money) error {
+	balance, ok := user.Wallet(currency)
+	if !ok {
+		return &errors.ErrorBadRequest{Message: fmt.Sprintf("user %s has no %s wallet; amounts are not converted between currencies", user.ID, currency)}
+	}
+	if balance < amount {
+		return &errors.ErrorBadRequest{Message: fmt.Sprintf("insufficient balance: current %s %s, required %s", balance, currency, amount)}
+	}
+	return nil
+}
+
+// checkUserVersion reports a stale version; version 0 matches any.
+func checkUserVersion(user *model.User, version int64) error {
+	if version != 0 && version != user.Version {
+		return &errors.ErrorPreconditionFailed{Message: fmt.Sprintf("user %s is at version %d, not %d", user.ID, user.Version, version)}
+	}
+	return nil
+}
+
+// DeleteUser removes a user, closing their wallets in the ledger first. A
+// non-zero version must match the stored user's.
+func (r *SQLiteRepository) DeleteUser(userID string, version int64) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		user, err := getUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if err := checkUserVersion(user, version); err != nil {
+			return err
+		}
+		var pending int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM wallet_operations WHERE user_id = ? AND type = ? AND status = ?`,
+			userID, string(model.OperationWithdrawal), string(model.OperationPending)).Scan(&pending); err != nil {
+			return fmt.Errorf("check pending withdrawals of %s: %w", userID, err)
+		}
+		if pending > 0 {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("user %s has pending withdrawals", userID)}
+		}
+		// Their open bets could not be settled without them.
+		var open int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM bets WHERE user_id = ? AND status = ?`,
+			userID, string(model.StatusPlaced)).Scan(&open); err != nil {
+			return fmt.Errorf("check open bets of %s: %w", userID, err)
+		}
+		if open > 0 {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("user %s has open bets", userID)}
+		}
+		for currency, balance := range user.Balances {
+			if balance.IsZero() {
+				continue
+			}
+			if err := post(tx, ledger.AdjustmentEntry(userID, balance.Neg(), currency, "account closed")); err != nil {
+				return err
+			}
+		}
+		// Revoke their tokens too, so a user later created with the same ID
+		// does not inherit them.
+		if _, err := tx.Exec(`UPDATE free_bets SET status = ?, reason = ?, closed_at = ? WHERE user_id = ? AND status = ?`,
+			string(model.FreeBetRevoked), "account closed", toUnix(time.Now()), userID, string(model.FreeBetActive)); err != nil {
+			return fmt.Errorf("revoke free bets of %s: %w", userID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM user_balances WHERE user_id = ?`, userID); err != nil {
+			return fmt.Errorf("delete balances of %s: %w", userID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM users WHERE id = ?`, userID); err != nil {
+			return fmt.Errorf("delete user %s: %w", userID, err)
+		}
+		return nil
+	})
+}
+
+// FindOrCreateUser returns the user with the given ID, creating them with
+// the default balance if they do not exist yet.
+func (r *SQLiteRepository) FindOrCreateUser(userID string) (*model.User, error) {
+	user, err := r.GetUser(userID)
+	if err == nil {
+		return user, nil
+	}
+	if _, ok := err.(*errors.ErrorNotFound); !ok {
+		return nil, err
+	}
+
+	user, err = r.CreateUser(&model.User{ID: userID})
+	if err != nil {
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			// User was created by another request, try getting it again
+			return r.GetUser(userID)
+		}
+		return nil, fmt.Errorf("failed to create user '%s': %w", userID, err)
+	}
+	return user, nil
+}
+
+// GetUserBalance returns the current balance of a user in their currency.
+func (r *SQLiteRepository) GetUserBalance(userID string) (money.Money, error) {
+	user, err := r.GetUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	return user.Balance, nil
+}
+
+// AdjustBalance applies a manual credit (positive amount) or debit (negative
+// amount) to a user's wallet in currency.
+func (r *SQLiteRepository) AdjustBalance(userID string, amount money.Money, currency money.Currency, reason string) (*model.User, error) {
+	var updated *model.User
+	err := r.withTx(func(tx *sql.Tx) error {
+		user, err := getUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		if currency == "" {
+			currency = user.Currency
+		}
+		if balance, _ := user.Wallet(currency); (balance + amount).IsNegative() {
+			return &errors.ErrorBadRequest{Message: fmt.Sprintf("insufficient balance: current %s %s, adjustment %s", balance, currency, amount)}
+		}
+		if err := post(tx, ledger.AdjustmentEntry(userID, amount, currency, reason)); err != nil {
+			return err
+		}
+		updated, err = getUser(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ListTransactions returns the ledger history of a user's wallets, oldest first.
+func (r *SQLiteRepository) ListTransactions(userID string) ([]*model.Transaction, error) {
+	user, err := r.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := loadEntries(r.db, ledger.WalletAccounts(user)...)
+	if err != nil {
+		return nil, err
+	}
+	return ledger.Transactions(userID, entries), nil
+}
+
+// loadEntries returns the journal entries touching any of the accounts,
+// oldest first.
+func loadEntries(q queryer, accounts ...string) ([]*model.JournalEntry, error) {
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(accounts))
+	for i, account := range accounts {
+		args[i] = account
+	}
+	rows, err := q.Query(`SELECT e.seq, e.id, e.type, e.user_id, e.bet_id, e.description, e.currency, e.created_at, p.account, p.amount
+		FROM journal_entries e
+		JOIN postings p ON p.entry_seq = e.seq
+		WHERE e.seq IN (SELECT entry_seq FROM postings WHERE account IN (?`+strings.Repeat(`, ?`, len(accounts)-1)+`))
+		ORDER BY e.seq, p.rowid`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query journal entries for %v: %w", accounts, err)
+	}
+	defer rows.Close()
+
+	var (
+		entries []*model.JournalEntry
+		lastSeq int64 = -1
+	)
+	for rows.Next() {
+		var (
+			e         model.JournalEntry
+			seq       int64
+			entryType string
+			currency  string
+			createdAt sql.NullInt64
+			p         model.Posting
+			amount    int64
+		)
+		if err := rows.Scan(&seq, &e.ID, &entryType, &e.UserID, &e.BetID, &e.Description, &currency, &createdAt, &p.Account, &amount); err != nil {
+			return nil, fmt.Errorf("scan journal entry: %w", err)
+		}
+		p.Amount = money.FromMinor(amount)
+		if seq != lastSeq {
+			e.Type = model.EntryType(entryType)
+			e.Currency = money.Currency(currency)
+			e.CreatedAt = fromUnix(createdAt)
+			entries = append(entries, &e)
+			lastSeq = seq
+		}
+		last := entries[len(entries)-1]
+		last.Postings = append(last.Postings, p)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/ledger"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+const walletOperationColumns = `id, user_id, type, reference, amount, currency, status, reason, created_at, resolved_at`
+
+func scanWalletOperation(row rowScanner) (*model.WalletOperation, error) {
+	var (
+		op                    model.WalletOperation
+		opType, status        string
+		currency              string
+		amount                int64
+		createdAt, resolvedAt sql.NullInt64
+	)
+	if err := row.Scan(&op.ID, &op.UserID, &opType, &op.Reference, &amount, &currency, &status, &op.Reason, &createdAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+	op.Type = model.WalletOperationType(opType)
+	op.Amount = money.FromMinor(amount)
+	op.Currency = money.Currency(currency)
+	op.Status = model.WalletOperationStatus(status)
+	op.CreatedAt = fromUnix(createdAt)
+	op.ResolvedAt = fromUnixPtr(resolvedAt)
+	return &op, nil
+}
+
+// CreateWalletOperation records a deposit or withdrawal and posts its
+// journal entry in one transaction.
+func (r *SQLiteRepository) CreateWalletOperation(op *model.WalletOperation) (*model.WalletOperation, error) {
+	stored := op.Clone()
+	err := r.withTx(func(tx *sql.Tx) error {
+		user, err := getUser(tx, op.UserID)
+		if err != nil {
+			return err
+		}
+		var used int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM wallet_operations WHERE reference = ?`, op.Reference).Scan(&used); err != nil {
+			return fmt.Errorf("check reference %s: %w", op.Reference, err)
+		}
+		if used > 0 {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("reference '%s' has already been used", op.Reference)}
+		}
+
+		if stored.Currency == "" {
+			stored.Currency = user.Currency
+		}
+		stored.ID = uuid.New().String()
+		stored.CreatedAt = time.Now()
+		var entry *model.JournalEntry
+		switch stored.Type {
+		case model.OperationDeposit:
+			stored.Status = model.OperationCompleted
+			stored.ResolvedAt = &stored.CreatedAt
+			entry = ledger.DepositEntry(stored)
+		case model.OperationWithdrawal:
+			if err := checkFunds(user, stored.Currency, stored.Amount); err != nil {
+				return err
+			}
+			stored.Status = model.OperationPending
+			entry = ledger.WithdrawalEntry(stored)
+		default:
+			return fmt.Errorf("unknown wallet operation type %q", stored.Type)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO wallet_operations (`+walletOperationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			stored.ID, stored.UserID, string(stored.Type), stored.Reference, stored.Amount.Minor(), string(stored.Currency), string(stored.Status),
+			stored.Reason, toUnix(stored.CreatedAt), toUnixPtr(stored.ResolvedAt)); err != nil {
+			return fmt.Errorf("insert wallet operation: %w", err)
+		}
+		return post(tx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// ResolveWithdrawal approves or rejects a pending withdrawal, moving its
+// reserved amount in the same transaction.
+func (r *SQLiteRepository) ResolveWithdrawal(userID, operationID string, status model.WalletOperationStatus, reason string) (*model.WalletOperation, error) {
+	var resolved *model.WalletOperation
+	err := r.withTx(func(tx *sql.Tx) error {
+		op, err := scanWalletOperation(tx.QueryRow(`SELECT `+walletOperationColumns+` FROM wallet_operations WHERE id = ?`, operationID))
+		if err == sql.ErrNoRows || (err == nil && (op.UserID != userID || op.Type != model.OperationWithdrawal)) {
+			return &errors.ErrorNotFound{Entity: "Withdrawal", ID: operationID}
+		}
+		if err != nil {
+			return fmt.Errorf("load withdrawal %s: %w", operationID, err)
+		}
+		if op.Status != model.OperationPending {
+			return &errors.ErrorConflict{Message: fmt.Sprintf("withdrawal %s is already %s", operationID, op.Status)}
+		}
+
+		op.Status = status
+		op.Reason = reason
+		resolvedAt := time.Now()
+		op.ResolvedAt = &resolvedAt
+		var entry *model.JournalEntry
+		switch status {
+		case model.OperationApproved:
+			entry = ledger.WithdrawalPaidEntry(op)
+		case model.OperationRejected:
+			entry = ledger.WithdrawalReversalEntry(op)
+		default:
+			return fmt.Errorf("cannot resolve a withdrawal as %s", status)
+		}
+		if err := post(tx, entry); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE wallet_operations SET status = ?, reason = ?, resolved_at = ? WHERE id = ?`,
+			string(op.Status), op.Reason, toUnixPtr(op.ResolvedAt), op.ID); err != nil {
+			return fmt.Errorf("update withdrawal %s: %w", op.ID, err)
+		}
+		resolved = op
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// ListWalletOperations returns a user's operations of one type, oldest first.
+func (r *SQLiteRepository) ListWalletOperations(userID string, opType model.WalletOperationType) ([]*model.WalletOperation, error) {
+	if _, err := r.GetUser(userID); err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(`SELECT `+walletOperationColumns+` FROM wallet_operations
+		WHERE user_id = ? AND type = ? ORDER BY created_at, rowid`, userID, string(opType))
+	if err != nil {
+		return nil, fmt.Errorf("query wallet operations of %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ops := []*model.WalletOperation{}
+	for rows.Next() {
+		op, err := scanWalletOperation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan wallet operation: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
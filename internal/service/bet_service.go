@@ -2,20 +2,135 @@ package service
 
 import (
 	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
-	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/memory"
 	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
 	"fmt"
 	"log" // Added for logging [cite: 3]
+	"sync"
+	"time"
 )
 
+// DefaultCashOutMarginBps is the cash-out margin used unless configured.
+const DefaultCashOutMarginBps = 500
+
+// DefaultFXRates is the FX rate table used unless configured; see
+// money.ParseFXTable.
+const DefaultFXRates = "EUR=1,GBP=1.17,USD=0.92"
+
 // BetService handles the business logic for bets.
 type BetService struct {
-	repo *memory.InMemoryBetRepository
+	bets     BetRepository
+	users    UserRepository
+	events   EventRepository
+	keys     IdempotencyRepository
+	wallets  WalletRepository
+	freeBets FreeBetRepository
+	boosts   BoostRepository
+	limits   LimitRepository
+	jobs     SettlementJobRepository
+
+	cashOutMarginBps int64
+	idempotencyTTL   time.Duration
+	idempotencyLease time.Duration
+	// fx converts between the currencies bets may be placed in, which are
+	// exactly those it has rates for; reports are in reportingCurrency.
+	fx                *money.FXTable
+	reportingCurrency money.Currency
+
+	settlementWorkers   int
+	settlementBatchSize int
+	// jobWake wakes an idle settlement worker; resumedJobs are the jobs
+	// interrupted by the last shutdown, run before any queued job.
+	jobWake     chan struct{}
+	jobsMu      sync.Mutex
+	resumedJobs []string
+	// workers tracks the running settlement workers.
+	workers sync.WaitGroup
+}
+
+// Option configures optional BetService behaviour.
+type Option func(*BetService)
+
+// WithCashOutMargin sets the share of a bet's fair cash-out value kept by
+// the house, in basis points (500 = 5%).
+func WithCashOutMargin(bps int64) Option {
+	return func(s *BetService) {
+		s.cashOutMarginBps = bps
+	}
+}
+
+// WithIdempotencyTTL sets how long Idempotency-Keys are remembered.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(s *BetService) {
+		s.idempotencyTTL = ttl
+	}
+}
+
+// WithIdempotencyLease sets how long a request may hold its Idempotency-Key
+// before its response is stored.
+func WithIdempotencyLease(lease time.Duration) Option {
+	return func(s *BetService) {
+		s.idempotencyLease = lease
+	}
+}
+
+// WithFXRates sets the FX rate table. Only currencies in it are accepted,
+// and it must include the reporting currency.
+func WithFXRates(fx *money.FXTable) Option {
+	return func(s *BetService) {
+		s.fx = fx
+	}
+}
+
+// WithReportingCurrency sets the currency reports and totals are converted to.
+func WithReportingCurrency(currency money.Currency) Option {
+	return func(s *BetService) {
+		s.reportingCurrency = currency
+	}
+}
+
+// WithSettlementWorkers sets how many settlement jobs run at once.
+func WithSettlementWorkers(n int) Option {
+	return func(s *BetService) {
+		s.settlementWorkers = n
+	}
+}
+
+// WithSettlementBatchSize sets how many bets a settlement job settles in
+// one step.
+func WithSettlementBatchSize(n int) Option {
+	return func(s *BetService) {
+		s.settlementBatchSize = n
+	}
 }
 
 // NewBetService creates a new BetService.
-func NewBetService(repo *memory.InMemoryBetRepository) *BetService {
-	return &BetService{repo: repo}
+func NewBetService(bets BetRepository, users UserRepository, events EventRepository, keys IdempotencyRepository, wallets WalletRepository, freeBets FreeBetRepository, boosts BoostRepository, limits LimitRepository, jobs SettlementJobRepository, opts ...Option) *BetService {
+	s := &BetService{bets: bets, users: users, events: events, keys: keys, wallets: wallets, freeBets: freeBets, boosts: boosts, limits: limits, jobs: jobs,
+		cashOutMarginBps: DefaultCashOutMarginBps, idempotencyTTL: DefaultIdempotencyTTL, idempotencyLease: DefaultIdempotencyLease,
+		fx: money.MustParseFXTable(DefaultFXRates), reportingCurrency: money.DefaultCurrency,
+		settlementWorkers: DefaultSettlementWorkers, settlementBatchSize: DefaultSettlementBatchSize,
+		jobWake: make(chan struct{}, 1)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// currency reads a requested currency, which must be one the FX table
+// supports. An empty one is left for the repository to default.
+func (s *BetService) currency(requested string) (money.Currency, error) {
+	if requested == "" {
+		return "", nil
+	}
+	currency, err := money.ParseCurrency(requested)
+	if err != nil {
+		return "", &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	if !s.fx.Supports(currency) {
+		return "", &errors.ErrorBadRequest{Message: fmt.Sprintf("unsupported currency %s (supported: %v)", currency, s.fx.Currencies())}
+	}
+	return currency, nil
 }
 
 func (s *BetService) PlaceBet(req *model.PlaceBetRequest) (*model.Bet, error) {
@@ -24,23 +139,70 @@ func (s *BetService) PlaceBet(req *model.PlaceBetRequest) (*model.Bet, error) {
 		log.Printf("Validation error placing bet for user %s: %v", req.UserID, err) // Logging [cite: 3]
 		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
 	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	amount := req.Amount
+	if req.FreeBetID != "" {
+		// The token's value and currency stand in for missing ones; the
+		// repository checks the rest when it redeems it.
+		freeBet, err := s.freeBets.GetFreeBet(req.FreeBetID)
+		if err != nil {
+			return nil, err
+		}
+		if freeBet.UserID != req.UserID {
+			return nil, &errors.ErrorNotFound{Entity: "Free bet", ID: req.FreeBetID}
+		}
+		if amount.IsZero() {
+			amount = freeBet.Value
+		}
+		if currency == "" {
+			currency = freeBet.Currency
+		}
+	}
 
 	// Ensure user exists (or create)
-	_, err := s.repo.FindOrCreateUser(req.UserID)
+	user, err := s.users.FindOrCreateUser(req.UserID)
 	if err != nil {
         log.Printf("Error finding/creating user %s: %v", req.UserID, err)
 		return nil, fmt.Errorf("could not ensure user exists: %w", err)
 	}
 
 
+	leg, err := s.resolveLeg(req.UserID, req.Leg())
+	if err != nil {
+		return nil, err
+	}
 	bet := &model.Bet{
-		UserID:  req.UserID,
-		EventID: req.EventID,
-		Odds:    req.Odds,
-		Amount:  req.Amount,
+		UserID:      req.UserID,
+		Type:        model.BetTypeSingle,
+		EventID:     leg.EventID,
+		MarketID:    leg.MarketID,
+		SelectionID: leg.SelectionID,
+		Odds:        leg.Odds,
+		Amount:      amount,
+		Currency:    currency,
+		FreeBetID:   req.FreeBetID,
+	}
+	if req.BoostID != "" {
+		if bet.Currency == "" {
+			bet.Currency = user.Currency
+		}
+		if err := s.applyBoost(bet, req.BoostID); err != nil {
+			log.Printf("Boost %s refused for user %s: %v", req.BoostID, req.UserID, err)
+			return nil, err
+		}
+	}
+	if err := bet.CheckPayout(); err != nil {
+		return nil, &errors.ErrorBadRequest{Message: err.Error()}
 	}
 
-	createdBet, err := s.repo.PlaceBet(bet)
+	check, err := s.limitCheck(bet)
+	if err != nil {
+		return nil, fmt.Errorf("could not load limits: %w", err)
+	}
+	createdBet, err := s.bets.PlaceBet(bet, check)
 	if err != nil {
 		log.Printf("Error placing bet in repository for user %s: %v", req.UserID, err) 
 		if _, ok := err.(*errors.ErrorBadRequest); ok {
@@ -49,6 +211,12 @@ func (s *BetService) PlaceBet(req *model.PlaceBetRequest) (*model.Bet, error) {
 		if _, ok := err.(*errors.ErrorNotFound); ok {
             return nil, err
         }
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
+		}
+		if _, ok := err.(*errors.ErrorLimitExceeded); ok {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to place bet: %w", err)
 	}
 	log.Printf("Bet placed successfully: ID=%s, UserID=%s, EventID=%s", createdBet.ID, createdBet.UserID, createdBet.EventID) 
@@ -56,56 +224,392 @@ func (s *BetService) PlaceBet(req *model.PlaceBetRequest) (*model.Bet, error) {
 }
 
 
-func (s *BetService) SettleBetsForEvent(eventID string, result string) error {
-	
-	if result != "win" && result != "lose" {
-        errMsg := fmt.Sprintf("invalid settlement result '%s', must be 'win' or 'lose'", result)
-        log.Printf("Error settling event %s: %s", eventID, errMsg) 
-		return &errors.ErrorBadRequest{Message: errMsg}
+// PlaceAccumulator places one stake on several legs, each on a different
+// event. The bet wins only if every leg wins; it is priced at the product of
+// the legs' odds.
+func (s *BetService) PlaceAccumulator(req *model.PlaceAccumulatorRequest) (*model.Bet, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error placing accumulator for user %s: %v", req.UserID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.users.FindOrCreateUser(req.UserID); err != nil {
+		log.Printf("Error finding/creating user %s: %v", req.UserID, err)
+		return nil, fmt.Errorf("could not ensure user exists: %w", err)
+	}
+
+	legs, err := s.resolveLegs(req.UserID, req.Legs)
+	if err != nil {
+		return nil, err
+	}
+	bet := &model.Bet{
+		UserID:   req.UserID,
+		Type:     model.BetTypeAccumulator,
+		Legs:     legs,
+		Amount:   req.Amount,
+		Currency: currency,
+	}
+	if err := bet.CheckPayout(); err != nil {
+		return nil, &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	bet.Odds = bet.EffectiveOdds()
+	return s.placeMultiple(bet)
+}
+
+// PlaceSystem places a system bet: the stake is split evenly across every
+// combination the request describes, and each combination (line) settles
+// on its own. The whole stake is debited at once.
+func (s *BetService) PlaceSystem(req *model.PlaceSystemRequest) (*model.Bet, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error placing system bet for user %s: %v", req.UserID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	if req.System != "" && req.Fold != 0 {
+		return nil, &errors.ErrorBadRequest{Message: "provide either 'system' or 'fold', not both"}
+	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.users.FindOrCreateUser(req.UserID); err != nil {
+		log.Printf("Error finding/creating user %s: %v", req.UserID, err)
+		return nil, fmt.Errorf("could not ensure user exists: %w", err)
+	}
+
+	legs, err := s.resolveLegs(req.UserID, req.Legs)
+	if err != nil {
+		return nil, err
 	}
+	lines, err := model.SystemLines(req.System, req.Fold, len(legs), req.Amount)
+	if err != nil {
+		return nil, &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	bet := &model.Bet{
+		UserID: req.UserID,
+		Type:     model.BetTypeSystem,
+		Legs:     legs,
+		Lines:    lines,
+		Amount:   req.Amount,
+		Currency: currency,
+	}
+	if err := bet.CheckPayout(); err != nil {
+		return nil, &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	bet.PriceLines()
+	return s.placeMultiple(bet)
+}
 
-	betsToSettle, err := s.repo.FindBetsByEvent(eventID)
+// placeMultiple stores a resolved multi-leg bet, within the limits that
+// apply to it, and debits its stake.
+func (s *BetService) placeMultiple(bet *model.Bet) (*model.Bet, error) {
+	check, err := s.limitCheck(bet)
 	if err != nil {
+		return nil, fmt.Errorf("could not load limits: %w", err)
+	}
+	createdBet, err := s.bets.PlaceBet(bet, check)
+	if err != nil {
+		log.Printf("Error placing %s in repository for user %s: %v", bet.Subject(), bet.UserID, err)
+		if _, ok := err.(*errors.ErrorBadRequest); ok {
+			return nil, err
+		}
 		if _, ok := err.(*errors.ErrorNotFound); ok {
-            log.Printf("No placed bets found to settle for event %s", eventID)
-			return err 
+			return nil, err
+		}
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
 		}
-        log.Printf("Error finding bets for event %s: %v", eventID, err) 
-		return fmt.Errorf("failed to find bets for event %s: %w", eventID, err)
+		if _, ok := err.(*errors.ErrorLimitExceeded); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to place bet: %w", err)
 	}
+	log.Printf("Bet placed successfully: ID=%s, UserID=%s, Type=%s, Legs=%d, Lines=%d", createdBet.ID, createdBet.UserID, createdBet.Type, len(createdBet.Legs), len(createdBet.Lines))
+	return createdBet, nil
+}
 
-    if len(betsToSettle) == 0 {
-        log.Printf("No placed bets found for event %s to settle.", eventID) 
-        return &errors.ErrorNotFound{Entity:"Placed Bets for Event", ID: eventID} 
-    }
+// resolveLegs resolves every leg of a multi-leg bet. Legs must be on
+// different events, since outcomes of one event are not independent.
+func (s *BetService) resolveLegs(userID string, reqs []model.BetLegRequest) ([]model.BetLeg, error) {
+	legs := make([]model.BetLeg, 0, len(reqs))
+	seen := make(map[string]bool, len(reqs))
+	for _, req := range reqs {
+		leg, err := s.resolveLeg(userID, req)
+		if err != nil {
+			return nil, err
+		}
+		if seen[leg.EventID] {
+			return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("more than one leg on event '%s'", leg.EventID)}
+		}
+		seen[leg.EventID] = true
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
 
+// resolveLeg turns a requested selection, or event, into a PLACED leg. A
+// leg naming a selection is priced at the selection's current odds; odds
+// sent by the client must match the current price. The event must exist;
+// the repository checks that it is OPEN as the bet is placed.
+func (s *BetService) resolveLeg(userID string, req model.BetLegRequest) (model.BetLeg, error) {
+	if req.SelectionID == "" {
+		if _, err := s.events.GetEvent(req.EventID); err != nil {
+			log.Printf("Error finding event %s for user %s: %v", req.EventID, userID, err)
+			return model.BetLeg{}, err
+		}
+		return model.BetLeg{EventID: req.EventID, Odds: req.Odds, Status: model.StatusPlaced}, nil
+	}
 
-	settleStatus := model.StatusLost
-	if result == "win" {
-		settleStatus = model.StatusWon
+	sel, err := s.events.GetSelection(req.SelectionID)
+	if err != nil {
+		log.Printf("Error finding selection %s for user %s: %v", req.SelectionID, userID, err)
+		return model.BetLeg{}, err
+	}
+	if req.EventID != "" && req.EventID != sel.EventID {
+		return model.BetLeg{}, &errors.ErrorBadRequest{Message: fmt.Sprintf("selection '%s' does not belong to event '%s'", sel.ID, req.EventID)}
+	}
+	if req.Odds != 0 && req.Odds != sel.Odds {
+		return model.BetLeg{}, &errors.ErrorConflict{Message: fmt.Sprintf("odds for selection '%s' have changed: requested %s, current %s", sel.ID, req.Odds, sel.Odds)}
 	}
 
-	var firstError error 
+	return model.BetLeg{
+		EventID:     sel.EventID,
+		MarketID:    sel.MarketID,
+		SelectionID: sel.ID,
+		Odds:        sel.Odds,
+		Status:      model.StatusPlaced,
+	}, nil
+}
 
-	for _, bet := range betsToSettle {
-		bet.Status = settleStatus
-		err := s.repo.UpdateBet(bet) 
-		if err != nil {
-			log.Printf("Error settling bet ID %s for event %s: %v", bet.ID, eventID, err) 
-			if firstError == nil {
-				firstError = fmt.Errorf("failed to settle bet %s: %w", bet.ID, err)
+// SettleBetsForEvent settles the PLACED bets on an event. The request either
+// gives one result for every bet, or the winning selections per market so
+// that backers of different selections are settled differently in one call.
+// Accumulators with a leg on the event have that leg settled, and are
+// themselves settled once the result decides them.
+// Either every affected bet is settled or, on any failure, none are. Only
+// CLOSED events are settled; the event is SETTLED once every bet on it is.
+func (s *BetService) SettleBetsForEvent(eventID string, req *model.SettleBetRequest) (*model.SettlementSummary, error) {
+	settler, err := s.settlementSettler(eventID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.settleEvent(eventID, settler)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Settled %d bets for event %s: %d won, %d lost, %d voided, %d pending, total payout %s, total refunded %s, event %s",
+		summary.BetsSettled, eventID, summary.Won, summary.Lost, summary.Voided, summary.Pending, summary.TotalPayout, summary.TotalRefunded, summary.EventStatus)
+	return summary, nil
+}
+
+// PreviewSettlement reports what SettleBetsForEvent would do with the same
+// request: the bets it would change, what it would credit each user and the
+// house's profit. The settlement is worked out by the same code and then
+// discarded, so nothing changes.
+func (s *BetService) PreviewSettlement(eventID string, req *model.SettleBetRequest) (*model.SettlementPreview, error) {
+	settler, err := s.settlementSettler(eventID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	preview, err := s.bets.PreviewSettlement(eventID, settler)
+	if err != nil {
+		log.Printf("Error previewing settlement of event %s: %v", eventID, err)
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to preview settlement of event %s: %w", eventID, err)
+	}
+	if err := preview.Report(s.fx, s.reportingCurrency); err != nil {
+		log.Printf("Error converting settlement preview of event %s to %s: %v", eventID, s.reportingCurrency, err)
+	}
+	return preview, nil
+}
+
+// settlementSettler checks a settlement request for an event and builds its
+// settler. Only CLOSED events can be settled.
+func (s *BetService) settlementSettler(eventID string, req *model.SettleBetRequest) (model.BetSettler, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error settling event %s: %v", eventID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	if req.Result != "" && len(req.Markets) > 0 {
+		return nil, &errors.ErrorBadRequest{Message: "provide either 'result' or 'markets', not both"}
+	}
+
+	event, err := s.events.GetEvent(eventID)
+	if err != nil {
+		log.Printf("Error settling event %s: %v", eventID, err)
+		return nil, err
+	}
+	if event.Status != model.EventClosed {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("event %s is %s; only CLOSED events can be settled", eventID, event.Status)}
+	}
+	settler, err := s.eventSettler(event, req)
+	if err != nil {
+		log.Printf("Error settling event %s: %v", eventID, err)
+		return nil, err
+	}
+	return settler, nil
+}
+
+// settleEvent settles the bets on an event with settler and reports the
+// totals in the reporting currency.
+func (s *BetService) settleEvent(eventID string, settler model.BetSettler) (*model.SettlementSummary, error) {
+	summary, err := s.bets.SettleEvent(eventID, settler)
+	return s.reportSettlement(eventID, summary, err)
+}
+
+// reportSettlement logs a failed settlement of an event, or converts the
+// totals of a successful one to the reporting currency.
+func (s *BetService) reportSettlement(eventID string, summary *model.SettlementSummary, err error) (*model.SettlementSummary, error) {
+	if err != nil {
+		log.Printf("Error settling event %s, no bets were settled: %v", eventID, err)
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to settle event %s: %w", eventID, err)
+	}
+
+	// The bets are settled whatever happens here; Totals stay exact.
+	if err := summary.Report(s.fx, s.reportingCurrency); err != nil {
+		log.Printf("Error converting settlement totals of event %s to %s: %v", eventID, s.reportingCurrency, err)
+	}
+	return summary, nil
+}
+
+// eventSettler builds the settler for a settlement request.
+func (s *BetService) eventSettler(event *model.Event, req *model.SettleBetRequest) (model.BetSettler, error) {
+	if len(req.Markets) > 0 {
+		if err := checkMarketResults(event, req.Markets); err != nil {
+			return nil, err
+		}
+		return legSettler(event.ID, marketOutcome(req.Markets)), nil
+	}
+	decide, err := resultOutcome(req.Result)
+	if err != nil {
+		return nil, err
+	}
+	return legSettler(event.ID, decide), nil
+}
+
+// outcome decides how a selection on the event being settled finished. ok is
+// false when the result does not cover the selection's market.
+type outcome func(marketID, selectionID string) (status model.BetStatus, ok bool)
+
+// legSettler returns a settler applying an outcome to a single bet, or to
+// the legs of a multi-leg bet that are on eventID. A multi-leg bet is then
+// resolved from all its legs and stays PLACED while any decisive leg is open.
+func legSettler(eventID string, decide outcome) model.BetSettler {
+	now := time.Now()
+	return func(bet *model.Bet) error {
+		if !bet.IsMultiple() {
+			if status, ok := decide(bet.MarketID, bet.SelectionID); ok {
+				bet.Status = status
 			}
-		} else {
-             log.Printf("Bet ID %s settled successfully with status %s for event %s", bet.ID, settleStatus, eventID) 
-        }
+			return nil
+		}
+		for i := range bet.Legs {
+			leg := &bet.Legs[i]
+			if leg.EventID != eventID || leg.Status != model.StatusPlaced {
+				continue
+			}
+			if status, ok := decide(leg.MarketID, leg.SelectionID); ok {
+				leg.Status = status
+				leg.SettledAt = now
+			}
+		}
+		bet.Resolve(now)
+		return nil
+	}
+}
+
+// resultOutcome returns an outcome applying one result to every selection.
+func resultOutcome(result string) (outcome, error) {
+	var settleStatus model.BetStatus
+	switch result {
+	case "win":
+		settleStatus = model.StatusWon
+	case "lose":
+		settleStatus = model.StatusLost
+	case "void":
+		settleStatus = model.StatusVoid
+	default:
+        errMsg := fmt.Sprintf("invalid settlement result '%s', must be 'win', 'lose' or 'void'", result)
+		return nil, &errors.ErrorBadRequest{Message: errMsg}
+	}
+
+	return func(string, string) (model.BetStatus, bool) {
+		return settleStatus, true
+	}, nil
+}
+
+// GetBet retrieves a bet by its ID.
+func (s *BetService) GetBet(betID string) (*model.Bet, error) {
+	if betID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "bet ID cannot be empty"}
+	}
+	bet, err := s.bets.GetBet(betID)
+	if err != nil {
+		log.Printf("Error getting bet %s: %v", betID, err)
+		return nil, err
+	}
+	return bet, nil
+}
+
+// ListBets returns one page of the bets matching the request's filters.
+func (s *BetService) ListBets(req *model.ListBetsRequest) (*model.BetPage, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error listing bets: %v", err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	query, err := req.Query()
+	if err != nil {
+		log.Printf("Invalid bet listing query: %v", err)
+		return nil, &errors.ErrorBadRequest{Message: err.Error()}
+	}
+
+	page, err := s.bets.FindBets(query)
+	if err != nil {
+		log.Printf("Repository error listing bets: %v", err)
+		return nil, fmt.Errorf("failed to list bets: %w", err)
+	}
+	log.Printf("Retrieved %d bets", len(page.Bets))
+	return page, nil
+}
+
+// ListUserBets returns one page of a user's bets matching the request's
+// filters, with a summary of every bet the user has placed.
+func (s *BetService) ListUserBets(userID string, req *model.ListBetsRequest) (*model.UserBetHistory, error) {
+	if userID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "user ID cannot be empty"}
+	}
+	if _, err := s.users.GetUser(userID); err != nil {
+		log.Printf("Error getting user %s for bet history: %v", userID, err)
+		return nil, err
 	}
-    if firstError != nil {
-        log.Printf("Finished settling event %s with errors.", eventID)
-    } else {
-        log.Printf("Successfully settled all placed bets for event %s.", eventID) 
-    }
 
-	return firstError 
+	req.UserID = userID
+	page, err := s.ListBets(req)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.bets.UserBetStats(userID)
+	if err != nil {
+		log.Printf("Repository error summarising bets of user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to summarise bets: %w", err)
+	}
+	summary, err := model.MergeBetStats(stats, s.fx, s.reportingCurrency)
+	if err != nil {
+		log.Printf("Error converting bet stats of user %s to %s: %v", userID, s.reportingCurrency, err)
+		return nil, fmt.Errorf("failed to summarise bets: %w", err)
+	}
+	return &model.UserBetHistory{UserID: userID, Summary: summary, ByCurrency: stats, BetPage: page}, nil
 }
 
 // CreateUser handles the logic for creating a new user.
@@ -114,12 +618,17 @@ func (s *BetService) CreateUser(req *model.CreateUserRequest) (*model.User, erro
 		log.Printf("Validation error creating user %s: %v", req.UserID, err)
 		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
 	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
 
 	user := &model.User{
-		ID: req.UserID,
+		ID:       req.UserID,
+		Currency: currency,
 	}
 
-	createdUser, err := s.repo.CreateUser(user)
+	createdUser, err := s.users.CreateUser(user)
 	if err != nil {
 		log.Printf("Repository error creating user %s: %v", req.UserID, err)
         if _, ok := err.(*errors.ErrorConflict); ok {
@@ -136,7 +645,7 @@ func (s *BetService) GetUser(userID string) (*model.User, error) {
 	if userID == "" {
 		return nil, &errors.ErrorBadRequest{Message: "user ID cannot be empty"}
 	}
-	user, err := s.repo.GetUser(userID)
+	user, err := s.users.GetUser(userID)
 	if err != nil {
 		log.Printf("Error getting user %s: %v", userID, err)
 		return nil, err
@@ -147,7 +656,7 @@ func (s *BetService) GetUser(userID string) (*model.User, error) {
 
 // ListUsers retrieves all users.
 func (s *BetService) ListUsers() ([]*model.User, error) {
-	users, err := s.repo.ListUsers()
+	users, err := s.users.ListUsers()
 	if err != nil {
 		log.Printf("Error listing users: %v", err)
 		return nil, fmt.Errorf("failed to list users: %w", err)
@@ -156,8 +665,9 @@ func (s *BetService) ListUsers() ([]*model.User, error) {
 	return users, nil
 }
 
-// UpdateUser handles updating user information.
-func (s *BetService) UpdateUser(userID string, req *model.UpdateUserRequest) (*model.User, error) {
+// UpdateUser handles updating user information. A non-zero version must
+// match the user's current one.
+func (s *BetService) UpdateUser(userID string, version int64, req *model.UpdateUserRequest) (*model.User, error) {
 	if userID == "" {
 		return nil, &errors.ErrorBadRequest{Message: "user ID cannot be empty"}
 	}
@@ -167,16 +677,19 @@ func (s *BetService) UpdateUser(userID string, req *model.UpdateUserRequest) (*m
 	}
 
 	// First, check if user exists
-	userToUpdate, err := s.repo.GetUser(userID)
+	userToUpdate, err := s.users.GetUser(userID)
 	if err != nil {
 		log.Printf("Error finding user %s for update: %v", userID, err)
 		return nil, err 
 	}
+	userToUpdate.Version = version
 
-
-	updatedUser, err := s.repo.UpdateUser(userToUpdate) 
+	updatedUser, err := s.users.UpdateUser(userToUpdate) 
 	if err != nil {
 		log.Printf("Repository error updating user %s: %v", userID, err)
+		if _, ok := err.(*errors.ErrorPreconditionFailed); ok {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
@@ -184,14 +697,15 @@ func (s *BetService) UpdateUser(userID string, req *model.UpdateUserRequest) (*m
 	return updatedUser, nil
 }
 
-// DeleteUser handles deleting a user.
-func (s *BetService) DeleteUser(userID string) error {
+// DeleteUser handles deleting a user. A non-zero version must match the
+// user's current one.
+func (s *BetService) DeleteUser(userID string, version int64) error {
 	if userID == "" {
 		return &errors.ErrorBadRequest{Message: "user ID cannot be empty"}
 	}
 
 
-	err := s.repo.DeleteUser(userID)
+	err := s.users.DeleteUser(userID, version)
 	if err != nil {
 		log.Printf("Repository error deleting user %s: %v", userID, err)
 		return err 
@@ -201,14 +715,73 @@ func (s *BetService) DeleteUser(userID string) error {
 }
 
 
-// Modify GetUserBalance service method slightly
-// It should rely on GetUser service method for consistency
-func (s *BetService) GetUserBalance(userID string) (float64, error) {
+// GetUserBalance returns a user's balances, with the total of every wallet
+// converted to the reporting currency.
+func (s *BetService) GetUserBalance(userID string) (*model.UserBalance, error) {
 	user, err := s.GetUser(userID) // Use the service GetUser method
 	if err != nil {
         log.Printf("Error getting balance for user %s (via GetUser): %v", userID, err)
-		return 0, err 
+		return nil, err 
+	}
+	balance := &model.UserBalance{
+		UserID:            user.ID,
+		Currency:          user.Currency,
+		Balance:           user.Balance,
+		Balances:          user.Balances,
+		ReportingCurrency: s.reportingCurrency,
+	}
+	for currency, amount := range user.Balances {
+		converted, err := s.fx.Convert(amount, currency, s.reportingCurrency)
+		if err != nil {
+			log.Printf("Error converting %s balance of user %s: %v", currency, userID, err)
+			return nil, fmt.Errorf("failed to total balances: %w", err)
+		}
+		balance.Total += converted
+	}
+	log.Printf("Retrieved balance for user %s: %s %s (total %s %s)", userID, user.Balance, user.Currency, balance.Total, s.reportingCurrency)
+	return balance, nil
+}
+
+// AdjustUserBalance applies a manual credit or debit to a user's balance.
+// The adjustment is recorded in the ledger with the given reason.
+func (s *BetService) AdjustUserBalance(userID string, req *model.AdjustBalanceRequest) (*model.User, error) {
+	if userID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "user ID cannot be empty"}
+	}
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error adjusting balance for user %s: %v", userID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.AdjustBalance(userID, req.Amount, currency, req.Reason)
+	if err != nil {
+		log.Printf("Repository error adjusting balance for user %s: %v", userID, err)
+		if _, ok := err.(*errors.ErrorNotFound); ok {
+			return nil, err
+		}
+		if _, ok := err.(*errors.ErrorBadRequest); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to adjust balance: %w", err)
+	}
+	log.Printf("Balance adjusted for user %s by %s: %s", userID, req.Amount, req.Reason)
+	return user, nil
+}
+
+// ListUserTransactions returns the ledger entries that explain a user's balance.
+func (s *BetService) ListUserTransactions(userID string) ([]*model.Transaction, error) {
+	if userID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "user ID cannot be empty"}
+	}
+	txs, err := s.users.ListTransactions(userID)
+	if err != nil {
+		log.Printf("Error listing transactions for user %s: %v", userID, err)
+		return nil, err
 	}
-	log.Printf("Retrieved balance for user %s: %.2f", userID, user.Balance)
-	return user.Balance, nil
+	log.Printf("Retrieved %d transactions for user %s", len(txs), userID)
+	return txs, nil
 }
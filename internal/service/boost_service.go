@@ -0,0 +1,141 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// CreateBoost creates an odds boost on an event or one of its selections.
+// It starts at once unless StartsAt says otherwise, and its stake limit is
+// in the reporting currency unless another is given.
+func (s *BetService) CreateBoost(req *model.CreateBoostRequest) (*model.Boost, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error creating boost: %v", err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if currency == "" {
+		currency = s.reportingCurrency
+	}
+	startsAt := req.StartsAt
+	if startsAt.IsZero() {
+		startsAt = time.Now()
+	}
+	if !req.EndsAt.After(startsAt) || !req.EndsAt.After(time.Now()) {
+		return nil, &errors.ErrorBadRequest{Message: "ends_at must be in the future and after starts_at"}
+	}
+	if req.SelectionID != "" {
+		sel, err := s.events.GetSelection(req.SelectionID)
+		if err != nil {
+			return nil, err
+		}
+		if sel.EventID != req.EventID {
+			return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("selection '%s' does not belong to event '%s'", sel.ID, req.EventID)}
+		}
+	}
+	maxUses := req.MaxUsesPerUser
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	boost, err := s.boosts.CreateBoost(&model.Boost{
+		Name:           req.Name,
+		EventID:        req.EventID,
+		SelectionID:    req.SelectionID,
+		IncreaseBps:    req.IncreaseBps,
+		MaxStake:       req.MaxStake,
+		Currency:       currency,
+		MaxUsesPerUser: maxUses,
+		StartsAt:       startsAt,
+		EndsAt:         req.EndsAt,
+	})
+	if err != nil {
+		log.Printf("Repository error creating boost on event %s: %v", req.EventID, err)
+		return nil, err
+	}
+	log.Printf("Boost %s created on event %s: +%d bps up to %s %s", boost.ID, boost.EventID, boost.IncreaseBps, boost.MaxStake, boost.Currency)
+	return boost, nil
+}
+
+// GetBoost retrieves a boost by its ID.
+func (s *BetService) GetBoost(boostID string) (*model.Boost, error) {
+	return s.boosts.GetBoost(boostID)
+}
+
+// ListBoosts returns the boosts on an event, or every boost when eventID
+// is empty, oldest first.
+func (s *BetService) ListBoosts(eventID string) ([]*model.Boost, error) {
+	boosts, err := s.boosts.ListBoosts()
+	if err != nil || eventID == "" {
+		return boosts, err
+	}
+	onEvent := []*model.Boost{}
+	for _, b := range boosts {
+		if b.EventID == eventID {
+			onEvent = append(onEvent, b)
+		}
+	}
+	return onEvent, nil
+}
+
+// CancelBoost stops a boost from pricing new bets.
+func (s *BetService) CancelBoost(boostID string) (*model.Boost, error) {
+	boost, err := s.boosts.CancelBoost(boostID)
+	if err != nil {
+		log.Printf("Repository error cancelling boost %s: %v", boostID, err)
+		return nil, err
+	}
+	log.Printf("Boost %s cancelled", boost.ID)
+	return boost, nil
+}
+
+// BoostReport summarises the bets placed with a boost and what it cost in
+// extra winnings, converted to the reporting currency.
+func (s *BetService) BoostReport(boostID string) (*model.BoostReport, error) {
+	boost, err := s.boosts.GetBoost(boostID)
+	if err != nil {
+		return nil, err
+	}
+	bets, err := s.boosts.FindBetsByBoost(boostID)
+	if err != nil {
+		return nil, err
+	}
+	return model.SummarizeBoost(boost, bets, s.fx, s.reportingCurrency)
+}
+
+// applyBoost prices a single bet with a boost, checking that the boost is
+// running, covers the bet and allows its stake. The per-user limit is
+// checked by the repository as the bet is placed.
+func (s *BetService) applyBoost(bet *model.Bet, boostID string) error {
+	if bet.IsFree() {
+		return &errors.ErrorBadRequest{Message: "free bets cannot be boosted"}
+	}
+	boost, err := s.boosts.GetBoost(boostID)
+	if err != nil {
+		return err
+	}
+	if err := boost.CheckApplies(bet, time.Now()); err != nil {
+		return &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	stake, err := s.fx.Convert(bet.Amount, bet.Currency, boost.Currency)
+	if err != nil {
+		return &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	if stake > boost.MaxStake {
+		return &errors.ErrorBadRequest{Message: fmt.Sprintf("stake %s %s is over the boost's maximum of %s %s", bet.Amount, bet.Currency, boost.MaxStake, boost.Currency)}
+	}
+	boosted, err := boost.Price(bet.Odds)
+	if err != nil {
+		return &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	bet.BoostID = boost.ID
+	bet.OriginalOdds = bet.Odds
+	bet.Odds = boosted
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"fmt"
+	"log"
+	"time"
+)
+
+// QuoteCashOut prices stake of a PLACED bet for cash-out at the current odds
+// of the selections it still depends on. A zero stake quotes all of the
+// bet's open stake.
+func (s *BetService) QuoteCashOut(betID string, stake money.Money) (*model.CashOutQuote, error) {
+	if betID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "bet ID cannot be empty"}
+	}
+	bet, err := s.bets.GetBet(betID)
+	if err != nil {
+		log.Printf("Error getting bet %s for cash-out: %v", betID, err)
+		return nil, err
+	}
+
+	open, err := bet.OpenSelections()
+	if err != nil {
+		return nil, &errors.ErrorConflict{Message: err.Error()}
+	}
+	if err := bet.CheckCashOutEvents(s.events.GetEvent); err != nil {
+		log.Printf("Error checking events of bet %s for cash-out: %v", betID, err)
+		if _, ok := err.(*errors.ErrorNotFound); ok {
+			return nil, err
+		}
+		return nil, &errors.ErrorConflict{Message: err.Error()}
+	}
+	if stake.IsZero() {
+		stake = bet.OpenStake()
+	}
+	if err := bet.CheckCashOutStake(stake); err != nil {
+		return nil, &errors.ErrorBadRequest{Message: err.Error()}
+	}
+	quote := &model.CashOutQuote{BetID: bet.ID, Stake: stake, QuotedAt: time.Now()}
+	for _, selectionID := range open {
+		sel, err := s.events.GetSelection(selectionID)
+		if err != nil {
+			log.Printf("Error pricing selection %s for cash-out of bet %s: %v", selectionID, betID, err)
+			return nil, err
+		}
+		quote.Prices = append(quote.Prices, model.QuotedPrice{SelectionID: sel.ID, Odds: sel.Odds})
+	}
+	quote.Value = bet.CashOutValue(stake, quote.Prices, s.cashOutMarginBps)
+	if !quote.Value.IsPositive() {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("bet %s has no cash-out value", betID)}
+	}
+	return quote, nil
+}
+
+// CashOut closes some or all of a PLACED bet's open stake early for the
+// value the player accepted. The bet is requoted at current odds and the
+// accepted value must match; the repository then re-checks the quote while
+// crediting it, so a price that moves at any point before execution is
+// rejected as a conflict. The rest of the stake stays on the bet.
+func (s *BetService) CashOut(betID string, req *model.CashOutRequest) (*model.Bet, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error cashing out bet %s: %v", betID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+
+	quote, err := s.QuoteCashOut(betID, req.Stake)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Value != req.Value {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("cash-out value has changed: accepted %s, current %s", req.Value, quote.Value)}
+	}
+
+	bet, err := s.bets.CashOutBet(quote)
+	if err != nil {
+		log.Printf("Repository error cashing out bet %s: %v", betID, err)
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
+		}
+		if _, ok := err.(*errors.ErrorNotFound); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to cash out bet: %w", err)
+	}
+	log.Printf("Bet cashed out: ID=%s, UserID=%s, Stake=%s, Value=%s, OpenStake=%s", bet.ID, bet.UserID, quote.Stake, quote.Value, bet.OpenStake())
+	return bet, nil
+}
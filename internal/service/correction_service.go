@@ -0,0 +1,113 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+)
+
+// ResettleEvent corrects the result of a settled event. Every bet the old
+// result decided is reopened, what its settlement credited is clawed back,
+// even into a negative balance, and the corrected result is applied as by
+// SettleBetsForEvent, all in one atomic step recorded with the operator and
+// reason.
+func (s *BetService) ResettleEvent(eventID string, req *model.ResettleRequest) (*model.SettlementCorrection, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error resettling event %s: %v", eventID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	if req.Result != "" && len(req.Markets) > 0 {
+		return nil, &errors.ErrorBadRequest{Message: "provide either 'result' or 'markets', not both"}
+	}
+	event, err := s.correctableEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	settler, err := s.eventSettler(event, &req.SettleBetRequest)
+	if err != nil {
+		log.Printf("Error resettling event %s: %v", eventID, err)
+		return nil, err
+	}
+
+	result := req.SettleBetRequest
+	return s.correctSettlement(&model.SettlementCorrection{
+		EventID:  eventID,
+		Action:   model.CorrectionResettle,
+		Operator: req.Operator,
+		Reason:   req.Reason,
+		Result:   &result,
+	}, settler)
+}
+
+// UnsettleEvent takes back the result of a settled event: its bets return
+// to PLACED, what their settlement credited is clawed back and the event is
+// CLOSED again, ready to be settled, all in one atomic step recorded with
+// the operator and reason.
+func (s *BetService) UnsettleEvent(eventID string, req *model.CorrectionRequest) (*model.SettlementCorrection, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error unsettling event %s: %v", eventID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	if _, err := s.correctableEvent(eventID); err != nil {
+		return nil, err
+	}
+	return s.correctSettlement(&model.SettlementCorrection{
+		EventID:  eventID,
+		Action:   model.CorrectionUnsettle,
+		Operator: req.Operator,
+		Reason:   req.Reason,
+	}, nil)
+}
+
+// ListSettlementCorrections returns the corrections made to an event's
+// settlement, oldest first, with totals in the reporting currency.
+func (s *BetService) ListSettlementCorrections(eventID string) ([]*model.SettlementCorrection, error) {
+	if _, err := s.GetEvent(eventID); err != nil {
+		return nil, err
+	}
+	corrections, err := s.bets.ListSettlementCorrections(eventID)
+	if err != nil {
+		log.Printf("Repository error listing corrections of event %s: %v", eventID, err)
+		return nil, fmt.Errorf("failed to list settlement corrections: %w", err)
+	}
+	for _, correction := range corrections {
+		if err := correction.Report(s.fx, s.reportingCurrency); err != nil {
+			log.Printf("Error converting totals of correction %s to %s: %v", correction.ID, s.reportingCurrency, err)
+		}
+	}
+	return corrections, nil
+}
+
+// correctableEvent returns an event whose settlement can be corrected.
+func (s *BetService) correctableEvent(eventID string) (*model.Event, error) {
+	event, err := s.GetEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := event.CheckCorrectable(); err != nil {
+		return nil, &errors.ErrorConflict{Message: err.Error()}
+	}
+	return event, nil
+}
+
+// correctSettlement applies a correction and reports its totals in the
+// reporting currency.
+func (s *BetService) correctSettlement(correction *model.SettlementCorrection, settler model.BetSettler) (*model.SettlementCorrection, error) {
+	corrected, err := s.bets.CorrectSettlement(correction, settler)
+	if err != nil {
+		log.Printf("Error correcting settlement of event %s, nothing was changed: %v", correction.EventID, err)
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to correct settlement of event %s: %w", correction.EventID, err)
+	}
+
+	// The correction is made whatever happens here; ClawedBack stays exact.
+	if err := corrected.Report(s.fx, s.reportingCurrency); err != nil {
+		log.Printf("Error converting totals of correction %s to %s: %v", corrected.ID, s.reportingCurrency, err)
+	}
+	log.Printf("Settlement of event %s corrected (%s by %s: %s): %d bets reopened, total clawed back %s, event %s",
+		corrected.EventID, corrected.Action, corrected.Operator, corrected.Reason, corrected.BetsReopened, corrected.TotalClawedBack, corrected.EventStatus)
+	return corrected, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+)
+
+// CreateEvent creates an event together with its markets and selections.
+func (s *BetService) CreateEvent(req *model.CreateEventRequest) (*model.Event, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error creating event %s: %v", req.EventID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+
+	event := &model.Event{ID: req.EventID, Name: req.Name}
+	for i := range req.Markets {
+		event.Markets = append(event.Markets, newMarket(&req.Markets[i]))
+	}
+
+	created, err := s.events.CreateEvent(event)
+	if err != nil {
+		log.Printf("Repository error creating event %s: %v", req.EventID, err)
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create event: %w", err)
+	}
+	log.Printf("Event created successfully: ID=%s, Markets=%d", created.ID, len(created.Markets))
+	return created, nil
+}
+
+// AddMarket adds a market with its selections to an existing event.
+func (s *BetService) AddMarket(eventID string, req *model.CreateMarketRequest) (*model.Market, error) {
+	if eventID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "event ID cannot be empty"}
+	}
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error adding market to event %s: %v", eventID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+
+	market, err := s.events.AddMarket(eventID, newMarket(req))
+	if err != nil {
+		log.Printf("Repository error adding market to event %s: %v", eventID, err)
+		if _, ok := err.(*errors.ErrorNotFound); ok {
+			return nil, err
+		}
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to add market: %w", err)
+	}
+	log.Printf("Market added successfully: ID=%s, EventID=%s", market.ID, eventID)
+	return market, nil
+}
+
+// GetEvent retrieves an event with its markets and selections.
+func (s *BetService) GetEvent(eventID string) (*model.Event, error) {
+	if eventID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "event ID cannot be empty"}
+	}
+	event, err := s.events.GetEvent(eventID)
+	if err != nil {
+		log.Printf("Error getting event %s: %v", eventID, err)
+		return nil, err
+	}
+	return event, nil
+}
+
+// ListEvents retrieves all events.
+func (s *BetService) ListEvents() ([]*model.Event, error) {
+	events, err := s.events.ListEvents()
+	if err != nil {
+		log.Printf("Error listing events: %v", err)
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	log.Printf("Retrieved %d events", len(events))
+	return events, nil
+}
+
+// SetEventStatus moves an event to SUSPENDED, back to OPEN or to CLOSED.
+// Events become SETTLED through settlement and CANCELLED through
+// CancelEvent, which also voids their bets.
+func (s *BetService) SetEventStatus(eventID string, status model.EventStatus) (*model.Event, error) {
+	switch status {
+	case model.EventOpen, model.EventSuspended, model.EventClosed:
+	default:
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("events cannot be set to %s", status)}
+	}
+	event, err := s.events.SetEventStatus(eventID, status)
+	if err != nil {
+		log.Printf("Error setting event %s to %s: %v", eventID, status, err)
+		return nil, err
+	}
+	log.Printf("Event %s is now %s", eventID, event.Status)
+	return event, nil
+}
+
+// CancelEvent cancels an event that will not finish and voids its open
+// bets, refunding their stakes; multi-leg bets have their leg on it voided.
+// The event stops taking bets before they are voided, so none can be
+// placed in between. If voiding fails the event stays CANCELLED with its
+// bets open, and cancelling it again retries.
+func (s *BetService) CancelEvent(eventID string) (*model.SettlementSummary, error) {
+	event, err := s.events.GetEvent(eventID)
+	if err != nil {
+		log.Printf("Error cancelling event %s: %v", eventID, err)
+		return nil, err
+	}
+	if event.Status != model.EventCancelled {
+		if _, err := s.events.SetEventStatus(eventID, model.EventCancelled); err != nil {
+			log.Printf("Error cancelling event %s: %v", eventID, err)
+			return nil, err
+		}
+	}
+
+	void, err := resultOutcome("void")
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.bets.VoidEvent(eventID, legSettler(eventID, void))
+	summary, err = s.reportSettlement(eventID, summary, err)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Event %s cancelled: %d bets voided, %d pending, total refunded %s",
+		eventID, summary.Voided, summary.Pending, summary.TotalRefunded)
+	return summary, nil
+}
+
+// UpdateSelectionOdds reprices a selection of an event. Open bets keep the
+// odds they were placed at.
+func (s *BetService) UpdateSelectionOdds(eventID, selectionID string, req *model.UpdateOddsRequest) (*model.Selection, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error repricing selection %s: %v", selectionID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	sel, err := s.events.GetSelection(selectionID)
+	if err != nil {
+		log.Printf("Error getting selection %s: %v", selectionID, err)
+		return nil, err
+	}
+	if sel.EventID != eventID {
+		return nil, &errors.ErrorNotFound{Entity: "Selection", ID: selectionID}
+	}
+
+	updated, err := s.events.UpdateSelectionOdds(selectionID, req.Odds)
+	if err != nil {
+		log.Printf("Repository error repricing selection %s: %v", selectionID, err)
+		if _, ok := err.(*errors.ErrorNotFound); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update odds: %w", err)
+	}
+	log.Printf("Selection repriced: ID=%s, Odds=%s -> %s", selectionID, sel.Odds, updated.Odds)
+	return updated, nil
+}
+
+func newMarket(req *model.CreateMarketRequest) *model.Market {
+	market := &model.Market{ID: req.MarketID, Name: req.Name}
+	for _, sel := range req.Selections {
+		market.Selections = append(market.Selections, &model.Selection{
+			ID:   sel.SelectionID,
+			Name: sel.Name,
+			Odds: sel.Odds,
+		})
+	}
+	return market
+}
+
+// marketOutcome returns an outcome deciding selections by the results of
+// their market. Selections in markets without a result are left undecided.
+func marketOutcome(results []model.MarketResult) outcome {
+	byMarket := make(map[string]*model.MarketResult, len(results))
+	for i := range results {
+		byMarket[results[i].MarketID] = &results[i]
+	}
+	return func(marketID, selectionID string) (model.BetStatus, bool) {
+		result, ok := byMarket[marketID]
+		if marketID == "" || !ok {
+			return "", false
+		}
+		switch {
+		case result.Void:
+			return model.StatusVoid, true
+		case contains(result.WinningSelectionIDs, selectionID):
+			return model.StatusWon, true
+		default:
+			return model.StatusLost, true
+		}
+	}
+}
+
+// checkMarketResults verifies that every result names a market of the event
+// at most once and that winners are selections of that market.
+func checkMarketResults(event *model.Event, results []model.MarketResult) error {
+	seen := make(map[string]bool, len(results))
+	for _, result := range results {
+		market := event.Market(result.MarketID)
+		if market == nil {
+			return &errors.ErrorBadRequest{Message: fmt.Sprintf("market '%s' does not belong to event '%s'", result.MarketID, event.ID)}
+		}
+		if seen[result.MarketID] {
+			return &errors.ErrorBadRequest{Message: fmt.Sprintf("market '%s' has more than one result", result.MarketID)}
+		}
+		seen[result.MarketID] = true
+		if result.Void && len(result.WinningSelectionIDs) > 0 {
+			return &errors.ErrorBadRequest{Message: fmt.Sprintf("void market '%s' cannot have winning selections", result.MarketID)}
+		}
+		for _, selID := range result.WinningSelectionIDs {
+			if market.Selection(selID) == nil {
+				return &errors.ErrorBadRequest{Message: fmt.Sprintf("selection '%s' does not belong to market '%s'", selID, result.MarketID)}
+			}
+		}
+	}
+	return nil
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
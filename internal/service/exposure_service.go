@@ -0,0 +1,45 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+)
+
+// EventExposure reports what the house stands to lose on an event from its
+// open bets, per market and outcome, in the reporting currency. An event
+// without open bets has no exposure; one that is not known and has none is
+// not found.
+func (s *BetService) EventExposure(eventID string) (*model.EventExposure, error) {
+	entries, err := s.bets.EventExposure(eventID)
+	if err != nil {
+		log.Printf("Repository error reading exposure of event %s: %v", eventID, err)
+		return nil, err
+	}
+	if len(entries) == 0 {
+		if _, err := s.events.GetEvent(eventID); err != nil {
+			return nil, err
+		}
+	}
+	return model.SummarizeExposure(eventID, entries, s.fx, s.reportingCurrency)
+}
+
+// TopExposure lists the events with the highest worst-case liability, in
+// the reporting currency.
+func (s *BetService) TopExposure(req *model.TopExposureRequest) (*model.ExposureRanking, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error listing exposure: %v", err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = model.DefaultTopExposure
+	}
+	entries, err := s.bets.ListExposure()
+	if err != nil {
+		log.Printf("Repository error listing exposure: %v", err)
+		return nil, err
+	}
+	return model.RankExposure(entries, limit, s.fx, s.reportingCurrency)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// GrantFreeBet grants a user a free-bet token, in their home currency
+// unless another is requested. The token expires at req.ExpiresAt, which
+// must be in the future.
+func (s *BetService) GrantFreeBet(userID string, req *model.GrantFreeBetRequest) (*model.FreeBet, error) {
+	if userID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "user ID cannot be empty"}
+	}
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error granting free bet to user %s: %v", userID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	if !req.ExpiresAt.After(time.Now()) {
+		return nil, &errors.ErrorBadRequest{Message: "expires_at must be in the future"}
+	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	freeBet, err := s.freeBets.CreateFreeBet(&model.FreeBet{
+		UserID:    userID,
+		Value:     req.Value,
+		Currency:  currency,
+		EventIDs:  req.EventIDs,
+		MinOdds:   req.MinOdds,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		log.Printf("Repository error granting free bet to user %s: %v", userID, err)
+		return nil, err
+	}
+	log.Printf("Free bet %s of %s %s granted to user %s, expiring %s", freeBet.ID, freeBet.Value, freeBet.Currency, userID, freeBet.ExpiresAt.Format(time.RFC3339))
+	return freeBet, nil
+}
+
+// ListFreeBets returns a user's free-bet tokens, oldest first. Tokens that
+// have lapsed show as EXPIRED even before they are swept.
+func (s *BetService) ListFreeBets(userID string) ([]*model.FreeBet, error) {
+	freeBets, err := s.freeBets.ListFreeBets(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, f := range freeBets {
+		f.Refresh(now)
+	}
+	return freeBets, nil
+}
+
+// RevokeFreeBet withdraws a user's unused token.
+func (s *BetService) RevokeFreeBet(userID, freeBetID string, req *model.RevokeFreeBetRequest) (*model.FreeBet, error) {
+	if err := req.Validate(); err != nil {
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	freeBet, err := s.freeBets.RevokeFreeBet(userID, freeBetID, req.Reason)
+	if err != nil {
+		log.Printf("Repository error revoking free bet %s of user %s: %v", freeBetID, userID, err)
+		return nil, err
+	}
+	log.Printf("Free bet %s of user %s revoked", freeBet.ID, userID)
+	return freeBet, nil
+}
+
+// ExpireFreeBets marks every token past its expiry as EXPIRED and returns
+// them. Lapsed tokens cannot be used either way; sweeping records it.
+func (s *BetService) ExpireFreeBets() ([]*model.FreeBet, error) {
+	expired, err := s.freeBets.ExpireFreeBets(time.Now())
+	if err != nil {
+		log.Printf("Repository error expiring free bets: %v", err)
+		return nil, err
+	}
+	log.Printf("Expired %d free bets", len(expired))
+	return expired, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long an Idempotency-Key is remembered unless
+// configured.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// DefaultIdempotencyLease is how long a request may hold its
+// Idempotency-Key before its response is stored, unless configured. A claim
+// left behind by a crash lapses after it, so retries are not refused for
+// the whole TTL.
+const DefaultIdempotencyLease = time.Minute
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// BeginIdempotentRequest claims key for the request identified by
+// fingerprint. It returns the stored response, which is Completed, when the
+// key was already used for the same request. Otherwise it returns the new
+// claim: the caller should handle the request and then complete or release
+// it before its lease runs out. Reusing a key for a different request is
+// unprocessable; retrying while the first request is still being handled is
+// a conflict.
+func (s *BetService) BeginIdempotentRequest(key, fingerprint string) (*model.IdempotencyRecord, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength)}
+	}
+
+	now := time.Now()
+	claim := &model.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.idempotencyLease),
+	}
+	existing, err := s.keys.ClaimIdempotencyKey(claim)
+	if err != nil {
+		log.Printf("Repository error claiming idempotency key %s: %v", key, err)
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if existing == nil {
+		return claim, nil
+	}
+	if existing.Fingerprint != fingerprint {
+		return nil, &errors.ErrorUnprocessable{Message: fmt.Sprintf("Idempotency-Key '%s' was already used with a different request", key)}
+	}
+	if !existing.Completed() {
+		return nil, &errors.ErrorConflict{Message: fmt.Sprintf("a request with Idempotency-Key '%s' is still being processed", key)}
+	}
+	log.Printf("Replaying response for idempotency key %s", key)
+	return existing, nil
+}
+
+// CompleteIdempotentRequest stores the response to replay for retries of
+// the request that made claim, for the rest of the TTL.
+func (s *BetService) CompleteIdempotentRequest(claim *model.IdempotencyRecord, statusCode int, headers map[string]string, body []byte) error {
+	completed := claim.Clone()
+	completed.StatusCode = statusCode
+	completed.Headers = headers
+	completed.Body = body
+	completed.ExpiresAt = claim.CreatedAt.Add(s.idempotencyTTL)
+	if err := s.keys.CompleteIdempotencyKey(completed); err != nil {
+		log.Printf("Repository error completing idempotency key %s: %v", claim.Key, err)
+		return err
+	}
+	return nil
+}
+
+// ReleaseIdempotentRequest gives up claim after a request failed without a
+// response worth replaying, so the client can retry it.
+func (s *BetService) ReleaseIdempotentRequest(claim *model.IdempotencyRecord) error {
+	if err := s.keys.ReleaseIdempotencyKey(claim); err != nil {
+		log.Printf("Repository error releasing idempotency key %s: %v", claim.Key, err)
+		return err
+	}
+	return nil
+}
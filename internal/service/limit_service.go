@@ -0,0 +1,140 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+)
+
+// SetLimits replaces the limits of a scope. Event and user limits need the
+// event or user to exist. Amounts are in the reporting currency unless
+// another is given.
+func (s *BetService) SetLimits(scope model.LimitScope, scopeID string, req *model.SetLimitsRequest) (*model.Limits, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error setting %s limits %s: %v", scope, scopeID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if currency == "" {
+		currency = s.reportingCurrency
+	}
+	if req.MinStake.IsPositive() && req.MaxStake.IsPositive() && req.MinStake > req.MaxStake {
+		return nil, &errors.ErrorBadRequest{Message: "min_stake cannot be above max_stake"}
+	}
+	switch scope {
+	case model.LimitScopeEvent:
+		if req.MaxDailyLiability.IsPositive() {
+			return nil, &errors.ErrorBadRequest{Message: "max_daily_liability cannot be set per event"}
+		}
+		if _, err := s.events.GetEvent(scopeID); err != nil {
+			return nil, err
+		}
+	case model.LimitScopeUser:
+		if req.MaxEventLiability.IsPositive() {
+			return nil, &errors.ErrorBadRequest{Message: "max_event_liability cannot be set per user"}
+		}
+		if _, err := s.users.GetUser(scopeID); err != nil {
+			return nil, err
+		}
+	}
+
+	limits, err := s.limits.SaveLimits(&model.Limits{
+		Scope:             scope,
+		ScopeID:           scopeID,
+		MinStake:          req.MinStake,
+		MaxStake:          req.MaxStake,
+		MaxPayout:         req.MaxPayout,
+		MaxEventLiability: req.MaxEventLiability,
+		MaxDailyLiability: req.MaxDailyLiability,
+		Currency:          currency,
+	})
+	if err != nil {
+		log.Printf("Repository error setting %s limits %s: %v", scope, scopeID, err)
+		return nil, err
+	}
+	log.Printf("Limits set for %s %s: stake %s-%s, payout %s, event liability %s, daily liability %s %s", scope, scopeID,
+		limits.MinStake, limits.MaxStake, limits.MaxPayout, limits.MaxEventLiability, limits.MaxDailyLiability, limits.Currency)
+	return limits, nil
+}
+
+// GetLimits returns the limits of a scope, or an empty set, in the
+// reporting currency, when none are stored.
+func (s *BetService) GetLimits(scope model.LimitScope, scopeID string) (*model.Limits, error) {
+	limits, err := s.limits.GetLimits(scope, scopeID)
+	if _, ok := err.(*errors.ErrorNotFound); ok {
+		return &model.Limits{Scope: scope, ScopeID: scopeID, Currency: s.reportingCurrency}, nil
+	}
+	return limits, err
+}
+
+// ListLimits returns every stored set of limits, global first.
+func (s *BetService) ListLimits() ([]*model.Limits, error) {
+	return s.limits.ListLimits()
+}
+
+// DeleteLimits removes the limits of a scope, so the global ones apply
+// again; removing the global limits leaves only event and user ones.
+func (s *BetService) DeleteLimits(scope model.LimitScope, scopeID string) error {
+	if err := s.limits.DeleteLimits(scope, scopeID); err != nil {
+		return err
+	}
+	log.Printf("Limits removed for %s %s", scope, scopeID)
+	return nil
+}
+
+// findLimits returns the limits of a scope, or nil when none are stored.
+func (s *BetService) findLimits(scope model.LimitScope, scopeID string) (*model.Limits, error) {
+	limits, err := s.limits.GetLimits(scope, scopeID)
+	if _, ok := err.(*errors.ErrorNotFound); ok {
+		return nil, nil
+	}
+	return limits, err
+}
+
+// limitCheck returns the check that holds bet to the limits set globally,
+// for its events and for its user, or nil when none are set. The check
+// runs as the bet is placed, once its currency is known, so that the
+// liability it measures cannot change before the bet is stored.
+func (s *BetService) limitCheck(bet *model.Bet) (model.PlacementCheck, error) {
+	global, err := s.findLimits(model.LimitScopeGlobal, "")
+	if err != nil {
+		return nil, err
+	}
+	var specific []*model.Limits
+	for _, eventID := range bet.EventIDs() {
+		limits, err := s.findLimits(model.LimitScopeEvent, eventID)
+		if err != nil {
+			return nil, err
+		}
+		if limits != nil {
+			specific = append(specific, limits)
+		}
+	}
+	user, err := s.findLimits(model.LimitScopeUser, bet.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		specific = append(specific, user)
+	}
+	if global == nil && len(specific) == 0 {
+		return nil, nil
+	}
+
+	return func(bet *model.Bet, liability *model.PlacementLiability) error {
+		limits, err := model.ResolveLimits(global, specific, s.fx, bet.Currency)
+		if err != nil {
+			return err
+		}
+		err = model.CheckLimits(limits, bet, liability, s.fx)
+		if breach, ok := err.(*model.LimitBreach); ok {
+			return &errors.ErrorLimitExceeded{Code: string(breach.Code), Message: breach.Message,
+				MinStake: breach.MinStake, MaxStake: breach.MaxStake, Currency: bet.Currency}
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,236 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/repository/memory"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/service"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
+
+// scopedLimits are the limits to set on one scope.
+type scopedLimits struct {
+	scope   model.LimitScope
+	scopeID string
+	req     model.SetLimitsRequest
+}
+
+// stake is a single bet by a user on a selection.
+type stake struct {
+	userID, selectionID, amount string
+}
+
+// newLimitService returns a service with users alice and bob and two open
+// events: e1, whose selections are at 3, and e2, whose selections are at 2.
+func newLimitService(t *testing.T) *service.BetService {
+	t.Helper()
+	repo := memory.NewInMemoryBetRepository()
+	s := service.NewBetService(repo, repo, repo, repo, repo, repo, repo, repo, repo)
+	for _, id := range []string{"alice", "bob"} {
+		if _, err := s.CreateUser(&model.CreateUserRequest{UserID: id, Name: id}); err != nil {
+			t.Fatalf("CreateUser(%s): %v", id, err)
+		}
+	}
+	for id, odds := range map[string]string{"e1": "3", "e2": "2"} {
+		_, err := s.CreateEvent(&model.CreateEventRequest{EventID: id, Name: id, Markets: []model.CreateMarketRequest{{
+			MarketID: id + "-result",
+			Name:     "Result",
+			Selections: []model.CreateSelectionRequest{
+				{SelectionID: id + "-home", Name: "Home", Odds: money.MustParseOdds(odds)},
+				{SelectionID: id + "-away", Name: "Away", Odds: money.MustParseOdds(odds)},
+			},
+		}}})
+		if err != nil {
+			t.Fatalf("CreateEvent(%s): %v", id, err)
+		}
+	}
+	return s
+}
+
+func placeStake(s *service.BetService, bet stake) error {
+	_, err := s.PlaceBet(&model.PlaceBetRequest{UserID: bet.userID, SelectionID: bet.selectionID, Amount: money.MustParse(bet.amount)})
+	return err
+}
+
+func TestPlaceBetLimits(t *testing.T) {
+	global := func(req model.SetLimitsRequest) scopedLimits { return scopedLimits{model.LimitScopeGlobal, "", req} }
+	event := func(id string, req model.SetLimitsRequest) scopedLimits { return scopedLimits{model.LimitScopeEvent, id, req} }
+	user := func(id string, req model.SetLimitsRequest) scopedLimits { return scopedLimits{model.LimitScopeUser, id, req} }
+	m := money.MustParse
+
+	tests := []struct {
+		name   string
+		limits []scopedLimits
+		prior  []stake
+		bet    stake
+		// wantCode is the breach the bet causes, if any, and wantMax the
+		// largest stake the breach offers instead.
+		wantCode string
+		wantMax  string
+	}{
+		{name: "no limits", bet: stake{"alice", "e1-home", "500.00"}},
+		{
+			name:     "global max stake",
+			limits:   []scopedLimits{global(model.SetLimitsRequest{MaxStake: m("50.00")})},
+			bet:      stake{"alice", "e1-home", "60.00"},
+			wantCode: string(model.LimitMaxStake), wantMax: "50.00",
+		},
+		{
+			name:   "at the max stake",
+			limits: []scopedLimits{global(model.SetLimitsRequest{MaxStake: m("50.00")})},
+			bet:    stake{"alice", "e1-home", "50.00"},
+		},
+		{
+			name: "user limits replace looser global ones",
+			limits: []scopedLimits{
+				global(model.SetLimitsRequest{MaxStake: m("50.00")}),
+				user("alice", model.SetLimitsRequest{MaxStake: m("100.00")}),
+			},
+			bet: stake{"alice", "e1-home", "60.00"},
+		},
+		{
+			name: "event limits replace global ones",
+			limits: []scopedLimits{
+				global(model.SetLimitsRequest{MaxStake: m("100.00")}),
+				event("e1", model.SetLimitsRequest{MaxStake: m("30.00")}),
+			},
+			bet:      stake{"alice", "e1-home", "40.00"},
+			wantCode: string(model.LimitMaxStake), wantMax: "30.00",
+		},
+		{
+			name: "limits of another user or event do not apply",
+			limits: []scopedLimits{
+				global(model.SetLimitsRequest{MaxStake: m("100.00")}),
+				event("e2", model.SetLimitsRequest{MaxStake: m("30.00")}),
+				user("bob", model.SetLimitsRequest{MaxStake: m("30.00")}),
+			},
+			bet: stake{"alice", "e1-home", "40.00"},
+		},
+		{
+			name: "unset specific limits leave the global one",
+			limits: []scopedLimits{
+				global(model.SetLimitsRequest{MaxStake: m("50.00")}),
+				user("alice", model.SetLimitsRequest{MinStake: m("1.00")}),
+			},
+			bet:      stake{"alice", "e1-home", "60.00"},
+			wantCode: string(model.LimitMaxStake), wantMax: "50.00",
+		},
+		{
+			name: "tightest maximum of event and user",
+			limits: []scopedLimits{
+				global(model.SetLimitsRequest{MaxStake: m("20.00")}),
+				event("e1", model.SetLimitsRequest{MaxStake: m("100.00")}),
+				user("alice", model.SetLimitsRequest{MaxStake: m("40.00")}),
+			},
+			bet:      stake{"alice", "e1-home", "50.00"},
+			wantCode: string(model.LimitMaxStake), wantMax: "40.00",
+		},
+		{
+			name: "tightest maximum above the replaced global one",
+			limits: []scopedLimits{
+				global(model.SetLimitsRequest{MaxStake: m("20.00")}),
+				event("e1", model.SetLimitsRequest{MaxStake: m("100.00")}),
+				user("alice", model.SetLimitsRequest{MaxStake: m("40.00")}),
+			},
+			bet: stake{"alice", "e1-home", "30.00"},
+		},
+		{
+			name: "highest minimum of event and user",
+			limits: []scopedLimits{
+				event("e1", model.SetLimitsRequest{MinStake: m("5.00")}),
+				user("alice", model.SetLimitsRequest{MinStake: m("10.00")}),
+			},
+			bet:      stake{"alice", "e1-home", "7.00"},
+			wantCode: string(model.LimitMinStake),
+		},
+		{
+			name: "user minimum replaces a higher global one",
+			limits: []scopedLimits{
+				global(model.SetLimitsRequest{MinStake: m("10.00")}),
+				user("alice", model.SetLimitsRequest{MinStake: m("2.00")}),
+			},
+			bet: stake{"alice", "e1-home", "5.00"},
+		},
+		{
+			name:     "max payout",
+			limits:   []scopedLimits{global(model.SetLimitsRequest{MaxPayout: m("100.00")})},
+			bet:      stake{"alice", "e1-home", "40.00"},
+			wantCode: string(model.LimitMaxPayout), wantMax: "33.33",
+		},
+		{
+			name:     "limits in another currency",
+			limits:   []scopedLimits{global(model.SetLimitsRequest{MaxStake: m("10.00"), Currency: "GBP"})},
+			bet:      stake{"alice", "e1-home", "12.00"},
+			wantCode: string(model.LimitMaxStake), wantMax: "11.70",
+		},
+		{
+			name:     "event liability counts other users' bets",
+			limits:   []scopedLimits{event("e1", model.SetLimitsRequest{MaxEventLiability: m("50.00")})},
+			prior:    []stake{{"bob", "e1-home", "20.00"}},
+			bet:      stake{"alice", "e1-away", "10.00"},
+			wantCode: string(model.LimitEventLiability), wantMax: "5.00",
+		},
+		{
+			name:   "event liability of other events",
+			limits: []scopedLimits{event("e1", model.SetLimitsRequest{MaxEventLiability: m("50.00")})},
+			prior:  []stake{{"bob", "e2-home", "40.00"}},
+			bet:    stake{"alice", "e1-away", "10.00"},
+		},
+		{
+			name:     "daily liability counts today's bets on every event",
+			limits:   []scopedLimits{user("alice", model.SetLimitsRequest{MaxDailyLiability: m("30.00")})},
+			prior:    []stake{{"alice", "e2-home", "10.00"}},
+			bet:      stake{"alice", "e1-home", "15.00"},
+			wantCode: string(model.LimitDailyLiability), wantMax: "10.00",
+		},
+		{
+			name:   "daily liability of other users",
+			limits: []scopedLimits{user("alice", model.SetLimitsRequest{MaxDailyLiability: m("30.00")})},
+			prior:  []stake{{"bob", "e1-home", "100.00"}},
+			bet:    stake{"alice", "e1-home", "15.00"},
+		},
+		{
+			name: "the first breach is reported",
+			limits: []scopedLimits{global(model.SetLimitsRequest{
+				MinStake: m("100.00"), MaxStake: m("200.00"), MaxPayout: m("30.00"),
+			})},
+			bet:      stake{"alice", "e1-home", "50.00"},
+			wantCode: string(model.LimitMinStake),
+		},
+	}
+	for _, tc := range tests {
+		s := newLimitService(t)
+		for _, l := range tc.limits {
+			if _, err := s.SetLimits(l.scope, l.scopeID, &l.req); err != nil {
+				t.Fatalf("%s: SetLimits(%s %s): %v", tc.name, l.scope, l.scopeID, err)
+			}
+		}
+		for _, bet := range tc.prior {
+			if err := placeStake(s, bet); err != nil {
+				t.Fatalf("%s: placing %+v: %v", tc.name, bet, err)
+			}
+		}
+
+		err := placeStake(s, tc.bet)
+		if tc.wantCode == "" {
+			if err != nil {
+				t.Errorf("%s: PlaceBet = %v, want success", tc.name, err)
+			}
+			continue
+		}
+		breach, ok := err.(*errors.ErrorLimitExceeded)
+		if !ok {
+			t.Errorf("%s: PlaceBet = %v, want %s", tc.name, err, tc.wantCode)
+			continue
+		}
+		wantMax := money.Zero
+		if tc.wantMax != "" {
+			wantMax = m(tc.wantMax)
+		}
+		if breach.Code != tc.wantCode || breach.MaxStake != wantMax || breach.Currency != money.EUR {
+			t.Errorf("%s: breach %s with max stake %s %s, want %s with %s EUR", tc.name, breach.Code, breach.MaxStake, breach.Currency, tc.wantCode, wantMax)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+	"time"
+)
+
+// BetRepository is the storage the service needs for bets.
+// Implementations must be safe for concurrent use, and the users and bets
+// they return are copies the caller may change freely.
+type BetRepository interface {
+	// PlaceBet stores a new bet, with its legs, and debits its stake from the
+	// user's wallet in the bet's currency in one step. It assigns the bet's
+	// ID, status, creation time and version 1, and defaults its type to
+	// SINGLE and its currency to the user's. A user without a wallet in the
+	// bet's currency cannot place it. A bet with a FreeBetID redeems that
+	// token instead, in the same step: the token must be the user's, ACTIVE,
+	// unexpired and eligible for the bet (see model.FreeBet.CheckEligible),
+	// and is marked USED. A bet with a BoostID counts against that boost's
+	// per-user usage limit in the same step; a boost that is cancelled or
+	// that the user has used up is a conflict. The boost's price, window
+	// and stake limit are checked by the caller. A bet on a known event
+	// that is not OPEN is a conflict (see model.Event.CheckBettable); bets
+	// on events the repository does not know are not checked. A non-nil
+	// check is called in the same step with the liability the bet adds to
+	// (see model.PlacementCheck); if it fails nothing is placed.
+	PlaceBet(bet *model.Bet, check model.PlacementCheck) (*model.Bet, error)
+	// FindBetsByEvent returns the bets on an event that are still PLACED,
+	// including multi-leg bets with a leg on the event.
+	FindBetsByEvent(eventID string) ([]*model.Bet, error)
+	// UpdateBet settles a PLACED bet with the given status, crediting the
+	// payout when it won. Settling an already settled bet is a conflict; a
+	// non-zero Version that is not the stored one fails the precondition.
+	UpdateBet(bet *model.Bet) error
+	// GetBet retrieves a bet with its legs and lines.
+	GetBet(betID string) (*model.Bet, error)
+	// FindBets returns the page of bets matching a query, in its order, and
+	// the cursor of the next page (see model.BetQuery.Page). Implementations
+	// serve the filters from indexes rather than scanning every bet.
+	FindBets(query *model.BetQuery) (*model.BetPage, error)
+	// UserBetStats summarises every bet a user has placed, per currency (see
+	// model.SummarizeBetsByCurrency), read through a per-user index. A user
+	// without bets has no stats.
+	UserBetStats(userID string) (map[money.Currency]*model.BetStats, error)
+	// CashOutBet closes the quoted stake of a PLACED bet for the quoted
+	// value, crediting it and recording the cash-out on the bet in the same
+	// step (see model.Bet.ApplyCashOut). The quote is checked with model.CheckQuote against the
+	// bet and selection odds under the repository's lock or transaction; a
+	// quote that no longer holds is a conflict and changes nothing.
+	CashOutBet(quote *model.CashOutQuote) (*model.Bet, error)
+	// SettleEvent settles every PLACED bet on an event atomically. settle is
+	// called for each bet under the repository's lock or transaction; if it
+	// or any payout fails, no bet or balance is changed. Multi-leg bets that
+	// settle return PLACED keep their updated legs and count as pending.
+	// A known event must be CLOSED, or settling it is a conflict (see
+	// model.Event.CheckSettleable), as it is while the event has an
+	// unfinished settlement job. A CLOSED event becomes SETTLED in the same
+	// step once no bet awaits its result (see model.Bet.AwaitsEvent); the
+	// summary reports its status.
+	SettleEvent(eventID string, settle model.BetSettler) (*model.SettlementSummary, error)
+	// PreviewSettlement reports what SettleEvent would do with settle,
+	// running the same settlement and then discarding it, so nothing is
+	// changed. The summary reports the status the event would have.
+	PreviewSettlement(eventID string, settle model.BetSettler) (*model.SettlementPreview, error)
+	// VoidEvent voids every PLACED bet on a cancelled event as SettleEvent
+	// settles them, with void deciding each bet. A known event must be
+	// CANCELLED, or voiding its bets is a conflict (see
+	// model.Event.CheckVoidable), as it is while the event has an
+	// unfinished settlement job.
+	VoidEvent(eventID string, void model.BetSettler) (*model.SettlementSummary, error)
+	// CorrectSettlement corrects the settlement of correction.EventID
+	// atomically and stores correction as its audit record, assigning its ID
+	// and creation time and filling in what changed. Every bet the event's
+	// result decided, or decided a leg of, is reopened (see
+	// model.Bet.Unsettle), and whatever the settlement of a bet that is
+	// PLACED again credited is clawed back, even into a negative balance. A
+	// RESETTLE then applies settle to every PLACED bet on the event as
+	// SettleEvent does; an UNSETTLE ignores it. A known event must be CLOSED
+	// or SETTLED, or correcting it is a conflict (see
+	// model.Event.CheckCorrectable). So is correcting it while it has an
+	// unfinished settlement job, or leaving a reopened bet waiting for
+	// another event that is already SETTLED (see model.Bet.AwaitedEvents).
+	// Afterwards an unsettled event is CLOSED and a resettled one is SETTLED
+	// unless bets still await its result.
+	CorrectSettlement(correction *model.SettlementCorrection, settle model.BetSettler) (*model.SettlementCorrection, error)
+	// ListSettlementCorrections returns the corrections of an event, oldest
+	// first.
+	ListSettlementCorrections(eventID string) ([]*model.SettlementCorrection, error)
+	// EventExposure returns the exposure entries of an event's outcomes, one
+	// per outcome and currency backed by open bets (see model.ExposureEntry).
+	// Implementations keep them up to date as bets are placed, cashed out
+	// and settled, applying model.ExposureChange in the same step, rather
+	// than summing the bets when asked.
+	EventExposure(eventID string) ([]*model.ExposureEntry, error)
+	// ListExposure returns the exposure entries of every event, ordered by
+	// event ID.
+	ListExposure() ([]*model.ExposureEntry, error)
+}
+
+// UserRepository is the storage the service needs for users and balances.
+// Implementations must be safe for concurrent use, and the users and bets
+// they return are copies the caller may change freely.
+type UserRepository interface {
+	// CreateUser stores a new user, defaulting their currency to
+	// money.DefaultCurrency, and credits their opening balance in it.
+	CreateUser(user *model.User) (*model.User, error)
+	GetUser(userID string) (*model.User, error)
+	ListUsers() ([]*model.User, error)
+	// UpdateUser stores changes to a user. A non-zero Version must match the
+	// stored one or ErrorPreconditionFailed is returned.
+	UpdateUser(user *model.User) (*model.User, error)
+	// DeleteUser removes a user; a non-zero version must match theirs. It
+	// returns ErrorConflict while they have pending withdrawals or PLACED
+	// bets.
+	DeleteUser(userID string, version int64) error
+	FindOrCreateUser(userID string) (*model.User, error)
+	// GetUserBalance returns a user's balance in their own currency.
+	GetUserBalance(userID string) (money.Money, error)
+	// AdjustBalance credits (positive) or debits (negative) a user's wallet
+	// in currency, the user's own when empty, and records the adjustment in
+	// the ledger. A credit may open a wallet.
+	AdjustBalance(userID string, amount money.Money, currency money.Currency, reason string) (*model.User, error)
+	// ListTransactions returns the ledger history of all of a user's
+	// wallets, oldest first.
+	ListTransactions(userID string) ([]*model.Transaction, error)
+}
+
+// EventRepository is the storage the service needs for events, their
+// markets and selections. Implementations must be safe for concurrent use.
+type EventRepository interface {
+	// CreateEvent stores an OPEN event with its markets and selections,
+	// assigning any missing market and selection IDs. IDs must be unique.
+	CreateEvent(event *model.Event) (*model.Event, error)
+	// AddMarket adds a market with its selections to an existing event.
+	AddMarket(eventID string, market *model.Market) (*model.Market, error)
+	GetEvent(eventID string) (*model.Event, error)
+	ListEvents() ([]*model.Event, error)
+	// SetEventStatus moves an event to status, recording when. A move its
+	// current status does not allow is a conflict (see
+	// model.Event.CheckTransition), as is cancelling an event with an
+	// unfinished settlement job.
+	SetEventStatus(eventID string, status model.EventStatus) (*model.Event, error)
+	GetSelection(selectionID string) (*model.Selection, error)
+	// UpdateSelectionOdds reprices a selection.
+	UpdateSelectionOdds(selectionID string, odds money.Odds) (*model.Selection, error)
+}
+
+// IdempotencyRepository stores Idempotency-Key records until they expire.
+// Implementations must be safe for concurrent use.
+type IdempotencyRepository interface {
+	// ClaimIdempotencyKey stores a new record for its key in one atomic
+	// step, unless a live record already holds the key, which it returns
+	// instead. Expired records count as absent and may be purged.
+	ClaimIdempotencyKey(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	// CompleteIdempotencyKey stores the response and expiry of record over
+	// the claim it was made with, matched by key and CreatedAt. It returns
+	// ErrorNotFound if that claim has lapsed or been released.
+	CompleteIdempotencyKey(record *model.IdempotencyRecord) error
+	// ReleaseIdempotencyKey deletes the claim record was made with, if it
+	// still holds its key, so the request can be retried.
+	ReleaseIdempotencyKey(record *model.IdempotencyRecord) error
+}
+
+// WalletRepository stores deposits and withdrawals. Each one changes the
+// user's balance in the same step that records it. Implementations must be
+// safe for concurrent use.
+type WalletRepository interface {
+	// CreateWalletOperation records a deposit, crediting it, or a withdrawal,
+	// reserving its amount out of the balance until it is resolved. Both use
+	// the wallet in the operation's currency, the user's own when empty; a
+	// deposit opens it if needed. It assigns the operation's ID, status and
+	// creation time. A reference that was already used is a conflict and a
+	// withdrawal larger than the wallet's balance is a bad request.
+	CreateWalletOperation(op *model.WalletOperation) (*model.WalletOperation, error)
+	// ResolveWithdrawal approves or rejects a user's PENDING withdrawal.
+	// Approving pays out the reserved amount; rejecting returns it to the
+	// balance. A withdrawal that is no longer pending is a conflict.
+	ResolveWithdrawal(userID, operationID string, status model.WalletOperationStatus, reason string) (*model.WalletOperation, error)
+	// ListWalletOperations returns a user's operations of one type, oldest first.
+	ListWalletOperations(userID string, opType model.WalletOperationType) ([]*model.WalletOperation, error)
+}
+
+// FreeBetRepository stores free-bet tokens. Tokens are redeemed by
+// BetRepository.PlaceBet. Implementations must be safe for concurrent use.
+type FreeBetRepository interface {
+	// CreateFreeBet grants a token to an existing user, defaulting its
+	// currency to theirs. It assigns the token's ID, ACTIVE status and
+	// creation time.
+	CreateFreeBet(freeBet *model.FreeBet) (*model.FreeBet, error)
+	GetFreeBet(freeBetID string) (*model.FreeBet, error)
+	// ListFreeBets returns a user's tokens, oldest first, as stored.
+	ListFreeBets(userID string) ([]*model.FreeBet, error)
+	// RevokeFreeBet revokes a user's ACTIVE token. A token that was already
+	// used, revoked or has expired is a conflict.
+	RevokeFreeBet(userID, freeBetID, reason string) (*model.FreeBet, error)
+	// ExpireFreeBets stores every ACTIVE token that lapsed by now as
+	// EXPIRED and returns them.
+	ExpireFreeBets(now time.Time) ([]*model.FreeBet, error)
+}
+
+// BoostRepository stores odds boosts. Their usage is counted by
+// BetRepository.PlaceBet. Implementations must be safe for concurrent use.
+type BoostRepository interface {
+	// CreateBoost stores a new boost, assigning its ID, ACTIVE status and
+	// creation time.
+	CreateBoost(boost *model.Boost) (*model.Boost, error)
+	GetBoost(boostID string) (*model.Boost, error)
+	// ListBoosts returns every boost, oldest first.
+	ListBoosts() ([]*model.Boost, error)
+	// CancelBoost cancels an ACTIVE boost; cancelling it again is a
+	// conflict. Bets already placed with it keep their odds.
+	CancelBoost(boostID string) (*model.Boost, error)
+	// FindBetsByBoost returns every bet placed with a boost, oldest first.
+	FindBetsByBoost(boostID string) ([]*model.Bet, error)
+}
+
+// LimitRepository stores betting limits, one set per scope. They are
+// enforced through the check given to BetRepository.PlaceBet.
+// Implementations must be safe for concurrent use.
+type LimitRepository interface {
+	// SaveLimits stores the limits of a scope, replacing any it had, and
+	// sets their update time.
+	SaveLimits(limits *model.Limits) (*model.Limits, error)
+	// GetLimits returns the limits of a scope; a scope without limits is
+	// not found.
+	GetLimits(scope model.LimitScope, scopeID string) (*model.Limits, error)
+	// ListLimits returns every stored set of limits: the global ones first,
+	// then by scope and ID.
+	ListLimits() ([]*model.Limits, error)
+	// DeleteLimits removes the limits of a scope; a scope without limits is
+	// not found.
+	DeleteLimits(scope model.LimitScope, scopeID string) error
+}
+
+// SettlementJobRepository stores settlement jobs and settles their events a
+// batch at a time. Implementations must be safe for concurrent use, and the
+// jobs they return are copies.
+type SettlementJobRepository interface {
+	// CreateSettlementJob stores a new QUEUED job, assigning its ID and
+	// creation time. An event whose last job has not finished cannot get
+	// another; that is a conflict.
+	CreateSettlementJob(job *model.SettlementJob) (*model.SettlementJob, error)
+	GetSettlementJob(jobID string) (*model.SettlementJob, error)
+	// ListSettlementJobs returns the jobs in any of states, every job when
+	// none are given, oldest first.
+	ListSettlementJobs(states ...model.SettlementJobState) ([]*model.SettlementJob, error)
+	// ClaimSettlementJob moves the oldest QUEUED job to RUNNING, counting
+	// the PLACED bets on its event as its Total, and returns it. It returns
+	// nil when no job is queued. A job is only ever claimed once.
+	ClaimSettlementJob() (*model.SettlementJob, error)
+	// SettleJobBatch settles the next limit PLACED bets on a RUNNING job's
+	// event, in the order they were placed, as SettleEvent settles every
+	// bet, and records them on the job, all in one atomic step. A job
+	// resumed after a crash thus carries on where it stopped. Once no bet is
+	// left the job is COMPLETED, and the event SETTLED as SettleEvent would
+	// leave it. If the event is no longer CLOSED, the job is FAILED with the
+	// reason instead and settles nothing more. A job that is not RUNNING is
+	// a conflict and nothing is changed.
+	SettleJobBatch(jobID string, settle model.BetSettler, limit int) (*model.SettlementJob, error)
+	// AddSettlementJobError records an error on a job that has not finished.
+	AddSettlementJobError(jobID, message string) (*model.SettlementJob, error)
+	// FailSettlementJob records an error on a job that has not finished and
+	// stops it as FAILED.
+	FailSettlementJob(jobID, message string) (*model.SettlementJob, error)
+	// CancelSettlementJob cancels a job that has not finished; its next batch
+	// is not settled. Cancelling a finished job is a conflict.
+	CancelSettlementJob(jobID string) (*model.SettlementJob, error)
+}
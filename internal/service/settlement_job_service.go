@@ -0,0 +1,254 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultSettlementWorkers is how many settlement jobs run at once unless
+// configured.
+const DefaultSettlementWorkers = 2
+
+// DefaultSettlementBatchSize is how many bets a settlement job settles in
+// one step unless configured.
+const DefaultSettlementBatchSize = 500
+
+// settlementBatchAttempts is how many times a failing batch is tried before
+// its job fails.
+const settlementBatchAttempts = 3
+
+// settlementPollInterval is how often idle workers look for queued jobs
+// without being woken.
+const settlementPollInterval = 5 * time.Second
+
+// SubmitSettlementJob queues the settlement of an event as a job, checked
+// as SettleBetsForEvent checks it. A worker settles its bets in batches,
+// each one atomic, so a large event does not hold the repository, or the
+// request, for long.
+func (s *BetService) SubmitSettlementJob(req *model.SettlementJobRequest) (*model.SettlementJob, error) {
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error submitting settlement job: %v", err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	if _, err := s.settlementSettler(req.EventID, &req.SettleBetRequest); err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobs.CreateSettlementJob(&model.SettlementJob{EventID: req.EventID, Request: req.SettleBetRequest})
+	if err != nil {
+		log.Printf("Error submitting settlement job for event %s: %v", req.EventID, err)
+		if _, ok := err.(*errors.ErrorConflict); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to submit settlement job: %w", err)
+	}
+	log.Printf("Settlement job %s queued for event %s", job.ID, job.EventID)
+	s.wakeSettlementWorkers()
+	return job, nil
+}
+
+// GetSettlementJob returns a settlement job with its summary in the
+// reporting currency.
+func (s *BetService) GetSettlementJob(jobID string) (*model.SettlementJob, error) {
+	job, err := s.jobs.GetSettlementJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	s.reportSettlementJob(job)
+	return job, nil
+}
+
+// ListSettlementJobs returns the settlement jobs in any of states, every
+// job when none are given, oldest first.
+func (s *BetService) ListSettlementJobs(states ...model.SettlementJobState) ([]*model.SettlementJob, error) {
+	jobs, err := s.jobs.ListSettlementJobs(states...)
+	if err != nil {
+		log.Printf("Repository error listing settlement jobs: %v", err)
+		return nil, fmt.Errorf("failed to list settlement jobs: %w", err)
+	}
+	for _, job := range jobs {
+		s.reportSettlementJob(job)
+	}
+	return jobs, nil
+}
+
+// CancelSettlementJob cancels a job that has not finished. A running job
+// stops before its next batch; the batches it settled stay settled.
+func (s *BetService) CancelSettlementJob(jobID string) (*model.SettlementJob, error) {
+	job, err := s.jobs.CancelSettlementJob(jobID)
+	if err != nil {
+		log.Printf("Error cancelling settlement job %s: %v", jobID, err)
+		return nil, err
+	}
+	log.Printf("Settlement job %s for event %s cancelled after %d of %d bets", job.ID, job.EventID, job.Processed, job.Total)
+	s.reportSettlementJob(job)
+	return job, nil
+}
+
+// StartSettlementWorkers starts the workers that run settlement jobs until
+// ctx is done. Jobs left RUNNING by a previous process, which only a
+// persistent repository keeps, are resumed first, from the last batch they
+// settled.
+func (s *BetService) StartSettlementWorkers(ctx context.Context) error {
+	interrupted, err := s.jobs.ListSettlementJobs(model.JobRunning)
+	if err != nil {
+		return fmt.Errorf("failed to list interrupted settlement jobs: %w", err)
+	}
+	s.jobsMu.Lock()
+	for _, job := range interrupted {
+		log.Printf("Resuming settlement job %s for event %s at %d of %d bets", job.ID, job.EventID, job.Processed, job.Total)
+		s.resumedJobs = append(s.resumedJobs, job.ID)
+	}
+	s.jobsMu.Unlock()
+
+	for i := 0; i < s.settlementWorkers; i++ {
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			s.settlementWorker(ctx)
+		}()
+	}
+	s.wakeSettlementWorkers()
+	return nil
+}
+
+// WaitSettlementWorkers waits for the workers to stop once the context they
+// were started with is done. A worker finishes the batch it is settling
+// first; its job stays RUNNING and is resumed when the workers next start.
+func (s *BetService) WaitSettlementWorkers() {
+	s.workers.Wait()
+}
+
+// wakeSettlementWorkers tells an idle worker to look for a job. It never
+// blocks; a worker already told is enough.
+func (s *BetService) wakeSettlementWorkers() {
+	select {
+	case s.jobWake <- struct{}{}:
+	default:
+	}
+}
+
+// settlementWorker runs jobs one at a time until ctx is done.
+func (s *BetService) settlementWorker(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.nextSettlementJob()
+		if err != nil {
+			log.Printf("Error fetching the next settlement job: %v", err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-s.jobWake:
+			case <-time.After(settlementPollInterval):
+			}
+			continue
+		}
+		// Another job may be waiting for an idle worker.
+		s.wakeSettlementWorkers()
+		s.runSettlementJob(ctx, job)
+	}
+}
+
+// nextSettlementJob returns the next resumed job that is still RUNNING, or
+// else claims the oldest queued one. It returns nil when there is none.
+func (s *BetService) nextSettlementJob() (*model.SettlementJob, error) {
+	for {
+		s.jobsMu.Lock()
+		if len(s.resumedJobs) == 0 {
+			s.jobsMu.Unlock()
+			return s.jobs.ClaimSettlementJob()
+		}
+		jobID := s.resumedJobs[0]
+		s.resumedJobs = s.resumedJobs[1:]
+		s.jobsMu.Unlock()
+
+		job, err := s.jobs.GetSettlementJob(jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job.State == model.JobRunning {
+			return job, nil
+		}
+	}
+}
+
+// runSettlementJob settles a RUNNING job's bets batch by batch until it
+// completes, fails, is cancelled or ctx is done. A job stopped by ctx stays
+// RUNNING and is resumed when the workers next start.
+func (s *BetService) runSettlementJob(ctx context.Context, job *model.SettlementJob) {
+	// The settler is rebuilt from the stored request, so a resumed job
+	// settles as it started.
+	event, err := s.events.GetEvent(job.EventID)
+	if err != nil {
+		s.failSettlementJob(job, err.Error())
+		return
+	}
+	settler, err := s.eventSettler(event, &job.Request)
+	if err != nil {
+		s.failSettlementJob(job, err.Error())
+		return
+	}
+
+	attempts := 0
+	for ctx.Err() == nil {
+		next, err := s.jobs.SettleJobBatch(job.ID, settler, s.settlementBatchSize)
+		if err != nil {
+			if _, ok := err.(*errors.ErrorConflict); ok {
+				// The job was cancelled.
+				s.failSettlementJob(job, err.Error())
+				return
+			}
+			attempts++
+			message := fmt.Sprintf("batch after %d bets failed (attempt %d of %d): %v", job.Processed, attempts, settlementBatchAttempts, err)
+			if attempts == settlementBatchAttempts {
+				s.failSettlementJob(job, message)
+				return
+			}
+			log.Printf("Settlement job %s: %s", job.ID, message)
+			if _, err := s.jobs.AddSettlementJobError(job.ID, message); err != nil {
+				log.Printf("Error recording the error of settlement job %s: %v", job.ID, err)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(attempts) * time.Second):
+			}
+			continue
+		}
+		attempts = 0
+		job = next
+		switch job.State {
+		case model.JobCompleted:
+			log.Printf("Settlement job %s completed for event %s: %d bets processed, %d settled, %d pending, event %s",
+				job.ID, job.EventID, job.Processed, job.Summary.BetsSettled, job.Summary.Pending, job.Summary.EventStatus)
+			return
+		case model.JobFailed:
+			log.Printf("Settlement job %s for event %s failed at %d of %d bets: %s", job.ID, job.EventID, job.Processed, job.Total, job.Errors[len(job.Errors)-1])
+			return
+		}
+	}
+	log.Printf("Settlement job %s stopped at %d of %d bets; it resumes when the workers next start", job.ID, job.Processed, job.Total)
+}
+
+// failSettlementJob stops a job as FAILED with message, unless it has
+// already finished, for instance because it was cancelled.
+func (s *BetService) failSettlementJob(job *model.SettlementJob, message string) {
+	if _, err := s.jobs.FailSettlementJob(job.ID, message); err != nil {
+		log.Printf("Settlement job %s stopped at %d of %d bets: %v", job.ID, job.Processed, job.Total, err)
+		return
+	}
+	log.Printf("Settlement job %s for event %s failed at %d of %d bets: %s", job.ID, job.EventID, job.Processed, job.Total, message)
+}
+
+// reportSettlementJob converts a job's summary to the reporting currency.
+func (s *BetService) reportSettlementJob(job *model.SettlementJob) {
+	if job.Summary == nil {
+		return
+	}
+	if err := job.Summary.Report(s.fx, s.reportingCurrency); err != nil {
+		log.Printf("Error converting the summary of settlement job %s to %s: %v", job.ID, s.reportingCurrency, err)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/internal/model"
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Deposit credits money paid in by a user to their balance.
+func (s *BetService) Deposit(userID string, req *model.WalletOperationRequest) (*model.WalletOperation, error) {
+	return s.createWalletOperation(userID, model.OperationDeposit, req)
+}
+
+// RequestWithdrawal reserves money a user asked to withdraw. The amount
+// leaves their balance at once and stays reserved until the withdrawal is
+// approved or rejected.
+func (s *BetService) RequestWithdrawal(userID string, req *model.WalletOperationRequest) (*model.WalletOperation, error) {
+	return s.createWalletOperation(userID, model.OperationWithdrawal, req)
+}
+
+func (s *BetService) createWalletOperation(userID string, opType model.WalletOperationType, req *model.WalletOperationRequest) (*model.WalletOperation, error) {
+	if userID == "" {
+		return nil, &errors.ErrorBadRequest{Message: "user ID cannot be empty"}
+	}
+	req.Reference = strings.TrimSpace(req.Reference)
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error in %s for user %s: %v", opType, userID, err)
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	currency, err := s.currency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := s.wallets.CreateWalletOperation(&model.WalletOperation{
+		UserID:    userID,
+		Type:      opType,
+		Reference: req.Reference,
+		Amount:    req.Amount,
+		Currency:  currency,
+	})
+	if err != nil {
+		log.Printf("Repository error in %s for user %s (reference %s): %v", opType, userID, req.Reference, err)
+		return nil, err
+	}
+	log.Printf("%s %s of %s %s for user %s: %s", opType, op.ID, op.Amount, op.Currency, userID, op.Status)
+	return op, nil
+}
+
+// ApproveWithdrawal pays out a pending withdrawal.
+func (s *BetService) ApproveWithdrawal(userID, withdrawalID string) (*model.WalletOperation, error) {
+	return s.resolveWithdrawal(userID, withdrawalID, model.OperationApproved, "")
+}
+
+// RejectWithdrawal returns a pending withdrawal's amount to the balance.
+func (s *BetService) RejectWithdrawal(userID, withdrawalID string, req *model.RejectWithdrawalRequest) (*model.WalletOperation, error) {
+	if err := req.Validate(); err != nil {
+		return nil, &errors.ErrorBadRequest{Message: fmt.Sprintf("validation failed: %s", err.Error())}
+	}
+	return s.resolveWithdrawal(userID, withdrawalID, model.OperationRejected, req.Reason)
+}
+
+func (s *BetService) resolveWithdrawal(userID, withdrawalID string, status model.WalletOperationStatus, reason string) (*model.WalletOperation, error) {
+	op, err := s.wallets.ResolveWithdrawal(userID, withdrawalID, status, reason)
+	if err != nil {
+		log.Printf("Repository error resolving withdrawal %s of user %s as %s: %v", withdrawalID, userID, status, err)
+		return nil, err
+	}
+	log.Printf("Withdrawal %s of %s for user %s %s", op.ID, op.Amount, userID, op.Status)
+	return op, nil
+}
+
+// ListDeposits returns a user's deposits, oldest first.
+func (s *BetService) ListDeposits(userID string) ([]*model.WalletOperation, error) {
+	return s.wallets.ListWalletOperations(userID, model.OperationDeposit)
+}
+
+// ListWithdrawals returns a user's withdrawals, oldest first.
+func (s *BetService) ListWithdrawals(userID string) ([]*model.WalletOperation, error) {
+	return s.wallets.ListWalletOperations(userID, model.OperationWithdrawal)
+}
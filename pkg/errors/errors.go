@@ -1,6 +1,10 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/navindunimsara2001/bet-settlement-engine-vortex/pkg/money"
+)
 
 type ErrorNotFound struct {
 	Entity string
@@ -30,3 +34,35 @@ type ErrorConflict struct {
 func (e *ErrorConflict) Error() string {
 	return fmt.Sprintf("conflict: %s", e.Message)
 }
+
+type ErrorUnprocessable struct {
+	Message string
+}
+
+func (e *ErrorUnprocessable) Error() string {
+	return fmt.Sprintf("unprocessable: %s", e.Message)
+}
+
+type ErrorPreconditionFailed struct {
+	Message string
+}
+
+func (e *ErrorPreconditionFailed) Error() string {
+	return fmt.Sprintf("precondition failed: %s", e.Message)
+}
+
+// ErrorLimitExceeded is a bet refused by a betting limit. Code names the
+// limit it breaks. MinStake and MaxStake are the smallest and largest
+// stakes the limits would accept for the same bet, in Currency; MaxStake is
+// zero when they accept none or set no maximum.
+type ErrorLimitExceeded struct {
+	Code     string
+	Message  string
+	MinStake money.Money
+	MaxStake money.Money
+	Currency money.Currency
+}
+
+func (e *ErrorLimitExceeded) Error() string {
+	return fmt.Sprintf("limit exceeded: %s", e.Message)
+}
@@ -0,0 +1,36 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code such as "EUR". Every amount belongs
+// to exactly one currency; amounts in different currencies are only ever
+// combined through an FXTable.
+type Currency string
+
+// Currencies customers bet in.
+const (
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	USD Currency = "USD"
+)
+
+// DefaultCurrency is used wherever no currency is given, including for
+// balances and bets stored before currencies were introduced.
+const DefaultCurrency = EUR
+
+// ParseCurrency reads a three-letter currency code, in either case.
+func ParseCurrency(s string) (Currency, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency %q (want a three-letter code such as EUR)", s)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("invalid currency %q (want a three-letter code such as EUR)", s)
+		}
+	}
+	return Currency(code), nil
+}
@@ -0,0 +1,110 @@
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseFixed parses a plain decimal string ("12", "-0.5", "3.25") into an
+// integer scaled by 10^scale. More fractional digits than scale is an error,
+// so no value is ever silently rounded while being read.
+func parseFixed(s string, scale int) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty decimal value")
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, fmt.Errorf("invalid decimal value")
+	}
+	if hasDot && fracPart == "" {
+		return 0, fmt.Errorf("invalid decimal value %q", s)
+	}
+	if len(fracPart) > scale {
+		return 0, fmt.Errorf("decimal value %q has more than %d fractional digits", s, scale)
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, fmt.Errorf("invalid decimal value %q", s)
+			}
+		}
+	}
+
+	digits := intPart + fracPart + strings.Repeat("0", scale-len(fracPart))
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("decimal value %q out of range", s)
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// formatFixed renders an integer scaled by 10^scale as a decimal string with
+// at least minFrac fractional digits. Trailing zeros beyond minFrac are dropped.
+func formatFixed(v int64, scale, minFrac int) string {
+	neg := v < 0
+	u := uint64(v)
+	if neg {
+		u = uint64(-v)
+	}
+
+	digits := strconv.FormatUint(u, 10)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	intPart := digits[:len(digits)-scale]
+	fracPart := digits[len(digits)-scale:]
+	for len(fracPart) > minFrac && fracPart[len(fracPart)-1] == '0' {
+		fracPart = fracPart[:len(fracPart)-1]
+	}
+
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	b.WriteString(intPart)
+	if fracPart != "" {
+		b.WriteByte('.')
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+// unquoteJSON accepts either a JSON number or a JSON string holding a number
+// and returns the raw decimal text.
+func unquoteJSON(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return "", fmt.Errorf("invalid decimal string %s", data)
+		}
+		return s, nil
+	}
+	s := string(data)
+	// JSON numbers may use exponent notation, which the fixed-point parser
+	// does not accept; reject explicitly rather than guessing.
+	if strings.ContainsAny(s, "eE") {
+		return "", fmt.Errorf("exponent notation is not supported for decimal value %s", s)
+	}
+	return s, nil
+}
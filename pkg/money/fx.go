@@ -0,0 +1,100 @@
+package money
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FXScale is the number of decimal places held by FX rates.
+const FXScale = 6
+
+// FXTable holds the value of one unit of each supported currency in a
+// common unit, so that any two of them can be converted. Only the ratios
+// between rates matter: "EUR=1,GBP=1.17" and "EUR=100,GBP=117" convert the
+// same way.
+type FXTable struct {
+	rates map[Currency]int64
+}
+
+// ParseFXTable reads a table written as comma-separated CODE=RATE pairs,
+// for example "EUR=1,GBP=1.17,USD=0.92". Rates must be positive and have at
+// most FXScale decimal places.
+func ParseFXTable(spec string) (*FXTable, error) {
+	t := &FXTable{rates: make(map[Currency]int64)}
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		code, rate, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid FX rate %q (want CODE=RATE)", pair)
+		}
+		c, err := ParseCurrency(code)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := t.rates[c]; dup {
+			return nil, fmt.Errorf("FX rate for %s given twice", c)
+		}
+		v, err := parseFixed(rate, FXScale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FX rate for %s: %w", c, err)
+		}
+		if v <= 0 {
+			return nil, fmt.Errorf("FX rate for %s must be positive", c)
+		}
+		t.rates[c] = v
+	}
+	if len(t.rates) == 0 {
+		return nil, fmt.Errorf("FX table has no rates")
+	}
+	return t, nil
+}
+
+// MustParseFXTable is like ParseFXTable but panics on invalid input.
+// Intended for constants.
+func MustParseFXTable(spec string) *FXTable {
+	t, err := ParseFXTable(spec)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Supports reports whether the table has a rate for c.
+func (t *FXTable) Supports(c Currency) bool {
+	_, ok := t.rates[c]
+	return ok
+}
+
+// Currencies returns the currencies in the table, sorted.
+func (t *FXTable) Currencies() []Currency {
+	out := make([]Currency, 0, len(t.rates))
+	for c := range t.rates {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Convert converts an amount from one currency to another, rounding half
+// to even to whole minor units.
+func (t *FXTable) Convert(m Money, from, to Currency) (Money, error) {
+	if from == to {
+		return m, nil
+	}
+	fromRate, ok := t.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("no FX rate for %s", from)
+	}
+	toRate, ok := t.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("no FX rate for %s", to)
+	}
+	converted, err := m.CheckedMulRatio(fromRate, toRate, RoundHalfEven)
+	if err != nil {
+		return 0, fmt.Errorf("cannot convert %s %s to %s: %w", m, from, to, err)
+	}
+	return converted, nil
+}
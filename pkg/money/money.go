@@ -0,0 +1,129 @@
+// Package money provides fixed-point types for monetary amounts and decimal
+// odds so balances and payouts never pass through binary floating point.
+package money
+
+import (
+	"math/big"
+)
+
+// Scale is the number of decimal places held by Money (minor units per unit = 10^Scale).
+const Scale = 2
+
+const minorPerUnit = 100
+
+// Money is an amount expressed as an integer number of minor units (e.g. cents).
+// The zero value is zero. Addition, subtraction and comparison use the
+// ordinary integer operators.
+type Money int64
+
+// Zero is the zero amount.
+const Zero Money = 0
+
+// FromMinor returns the amount for the given number of minor units.
+func FromMinor(minor int64) Money {
+	return Money(minor)
+}
+
+// FromUnits returns the amount for a whole number of major units.
+func FromUnits(units int64) Money {
+	return Money(units * minorPerUnit)
+}
+
+// Parse reads a decimal string such as "12.50". At most Scale fractional
+// digits are accepted.
+func Parse(s string) (Money, error) {
+	v, err := parseFixed(s, Scale)
+	if err != nil {
+		return 0, err
+	}
+	return Money(v), nil
+}
+
+// MustParse is like Parse but panics on invalid input. Intended for constants.
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Minor returns the amount as an integer number of minor units.
+func (m Money) Minor() int64 {
+	return int64(m)
+}
+
+// String formats the amount with exactly Scale fractional digits, e.g. "12.50".
+func (m Money) String() string {
+	return formatFixed(int64(m), Scale, Scale)
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+// IsPositive reports whether the amount is above zero.
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+// Neg returns the amount with its sign flipped.
+func (m Money) Neg() Money {
+	return -m
+}
+
+// MulOdds multiplies the amount by decimal odds, rounding the result to
+// whole minor units with the given mode. It panics with ErrOverflow if the
+// result is out of range, which bets within the payout limits never are.
+func (m Money) MulOdds(o Odds, mode RoundingMode) Money {
+	num := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(o)))
+	return Money(mustFit(divRound(num, big.NewInt(oddsPerUnit), mode)))
+}
+
+// MulRatio multiplies the amount by num/den, rounding the result to whole
+// minor units with the given mode. den must not be zero. It panics with
+// ErrOverflow if the result is out of range; see CheckedMulRatio.
+func (m Money) MulRatio(num, den int64, mode RoundingMode) Money {
+	return Money(mustFit(m.mulRatio(num, den, mode)))
+}
+
+// CheckedMulRatio is like MulRatio but returns ErrOverflow instead of
+// panicking.
+func (m Money) CheckedMulRatio(num, den int64, mode RoundingMode) (Money, error) {
+	v, err := m.mulRatio(num, den, mode)
+	return Money(v), err
+}
+
+func (m Money) mulRatio(num, den int64, mode RoundingMode) (int64, error) {
+	n := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(num))
+	return divRound(n, big.NewInt(den), mode)
+}
+
+// MarshalJSON encodes the amount as a JSON number with Scale decimals (e.g. 12.50),
+// which existing clients reading a float continue to accept.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number (12.5) or a string ("12.50").
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s, err := unquoteJSON(data)
+	if err != nil {
+		return err
+	}
+	if s == "null" {
+		return nil
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
@@ -0,0 +1,222 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: "12.50", want: 1250},
+		{in: "12.5", want: 1250},
+		{in: "12", want: 1200},
+		{in: "0", want: 0},
+		{in: "0.01", want: 1},
+		{in: ".5", want: 50},
+		{in: "007.10", want: 710},
+		{in: "+3", want: 300},
+		{in: "-3.25", want: -325},
+		{in: "-0", want: 0},
+		{in: "-.01", want: -1},
+		{in: "  4.20 ", want: 420},
+		{in: "92233720368547758.07", want: math.MaxInt64},
+		{in: "-92233720368547758.07", want: -math.MaxInt64},
+		{in: "92233720368547758.08", wantErr: true},
+		{in: "1.234", wantErr: true},
+		{in: "0.001", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "   ", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "+", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "12.", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "+-1", wantErr: true},
+		{in: "1-", wantErr: true},
+		{in: "1,50", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: "0x10", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := Parse(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %d, want an error", tc.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("Parse(%q) = %d, %v; want %d", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want string
+	}{
+		{0, "0.00"},
+		{1, "0.01"},
+		{10, "0.10"},
+		{100, "1.00"},
+		{1250, "12.50"},
+		{-1, "-0.01"},
+		{-1250, "-12.50"},
+		{math.MaxInt64, "92233720368547758.07"},
+		{math.MinInt64, "-92233720368547758.08"},
+	}
+	for _, tc := range tests {
+		if got := tc.in.String(); got != tc.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(tc.in), got, tc.want)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, m := range []Money{0, 1, -1, 99, -99, 100, 1250, -1250, 123456789, math.MaxInt64, -math.MaxInt64} {
+		got, err := Parse(m.String())
+		if err != nil || got != m {
+			t.Errorf("Parse(%q) = %d, %v; want %d", m.String(), got, err, int64(m))
+		}
+	}
+	for _, o := range []Odds{EvenOdds, 15000, 21000, 41250, 10001, math.MaxInt64} {
+		got, err := ParseOdds(o.String())
+		if err != nil || got != o {
+			t.Errorf("ParseOdds(%q) = %d, %v; want %d", o.String(), got, err, int64(o))
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type payload struct {
+		Amount Money `json:"amount"`
+	}
+	for _, m := range []Money{0, 1, -1250, 1250, math.MaxInt64} {
+		data, err := json.Marshal(payload{m})
+		if err != nil {
+			t.Fatalf("Marshal(%d): %v", int64(m), err)
+		}
+		if want := `{"amount":` + m.String() + `}`; string(data) != want {
+			t.Errorf("Marshal(%d) = %s, want %s", int64(m), data, want)
+		}
+		var back payload
+		if err := json.Unmarshal(data, &back); err != nil || back.Amount != m {
+			t.Errorf("Unmarshal(%s) = %d, %v; want %d", data, int64(back.Amount), err, int64(m))
+		}
+	}
+
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: `12.5`, want: 1250},
+		{in: `"12.50"`, want: 1250},
+		{in: `"-0.01"`, want: -1},
+		{in: ` 7 `, want: 700},
+		{in: `1e2`, wantErr: true},
+		{in: `1.5E1`, wantErr: true},
+		{in: `12.345`, wantErr: true},
+		{in: `"abc"`, wantErr: true},
+		{in: `""`, wantErr: true},
+		{in: `true`, wantErr: true},
+	}
+	for _, tc := range tests {
+		var m Money
+		err := m.UnmarshalJSON([]byte(tc.in))
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("UnmarshalJSON(%s) = %d, want an error", tc.in, int64(m))
+			}
+			continue
+		}
+		if err != nil || m != tc.want {
+			t.Errorf("UnmarshalJSON(%s) = %d, %v; want %d", tc.in, int64(m), err, int64(tc.want))
+		}
+	}
+
+	// null leaves the amount as it was.
+	m := Money(42)
+	if err := json.Unmarshal([]byte(`null`), &m); err != nil || m != 42 {
+		t.Errorf("Unmarshal(null) = %d, %v; want 42 unchanged", int64(m), err)
+	}
+}
+
+func TestRoundingAtHalf(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		mode     RoundingMode
+		want     int64
+	}{
+		// 2.5 and -2.5: the even neighbour is 2.
+		{5, 2, RoundHalfUp, 3},
+		{5, 2, RoundHalfEven, 2},
+		{5, 2, RoundDown, 2},
+		{5, 2, RoundUp, 3},
+		{-5, 2, RoundHalfUp, -3},
+		{-5, 2, RoundHalfEven, -2},
+		{-5, 2, RoundDown, -2},
+		{-5, 2, RoundUp, -3},
+		// 3.5 and -3.5: the even neighbour is 4.
+		{7, 2, RoundHalfUp, 4},
+		{7, 2, RoundHalfEven, 4},
+		{7, 2, RoundDown, 3},
+		{7, 2, RoundUp, 4},
+		{-7, 2, RoundHalfUp, -4},
+		{-7, 2, RoundHalfEven, -4},
+		{-7, 2, RoundDown, -3},
+		{-7, 2, RoundUp, -4},
+		// Just either side of a half.
+		{249, 100, RoundHalfUp, 2},
+		{251, 100, RoundHalfEven, 3},
+		{-249, 100, RoundHalfUp, -2},
+		{-251, 100, RoundHalfEven, -3},
+		// A negative denominator gives the same results.
+		{5, -2, RoundHalfUp, -3},
+		{-5, -2, RoundHalfEven, 2},
+		// Exact quotients are never moved.
+		{6, 2, RoundUp, 3},
+		{-6, 2, RoundUp, -3},
+	}
+	for _, tc := range tests {
+		got, err := divRound(big.NewInt(tc.num), big.NewInt(tc.den), tc.mode)
+		if err != nil || got != tc.want {
+			t.Errorf("%d/%d rounded %s = %d, %v; want %d", tc.num, tc.den, tc.mode, got, err, tc.want)
+		}
+	}
+}
+
+func TestMulRatioRounding(t *testing.T) {
+	tests := []struct {
+		amount string
+		mode   RoundingMode
+		want   string
+	}{
+		// Half of 0.05 is 2.5 cents.
+		{"0.05", RoundHalfUp, "0.03"},
+		{"0.05", RoundHalfEven, "0.02"},
+		{"0.05", RoundDown, "0.02"},
+		{"0.05", RoundUp, "0.03"},
+		{"-0.05", RoundHalfUp, "-0.03"},
+		{"-0.05", RoundHalfEven, "-0.02"},
+		{"-0.05", RoundDown, "-0.02"},
+		{"-0.05", RoundUp, "-0.03"},
+		{"0.07", RoundHalfEven, "0.04"},
+		{"-0.07", RoundHalfEven, "-0.04"},
+	}
+	for _, tc := range tests {
+		if got := MustParse(tc.amount).MulRatio(1, 2, tc.mode); got != MustParse(tc.want) {
+			t.Errorf("%s / 2 rounded %s = %s, want %s", tc.amount, tc.mode, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package money
+
+import (
+	"math"
+	"math/big"
+)
+
+// OddsScale is the number of decimal places held by Odds.
+const OddsScale = 4
+
+const oddsPerUnit = 10000
+
+// Odds are decimal (European) odds held as an integer number of
+// ten-thousandths, so 2.5 is stored as 25000.
+type Odds int64
+
+// EvenOdds is 1.0, the point at which a winning bet only returns its stake.
+const EvenOdds Odds = oddsPerUnit
+
+// ParseOdds reads a decimal string such as "2.75". At most OddsScale
+// fractional digits are accepted.
+func ParseOdds(s string) (Odds, error) {
+	v, err := parseFixed(s, OddsScale)
+	if err != nil {
+		return 0, err
+	}
+	return Odds(v), nil
+}
+
+// MustParseOdds is like ParseOdds but panics on invalid input. Intended for constants.
+func MustParseOdds(s string) Odds {
+	o, err := ParseOdds(s)
+	if err != nil {
+		panic(err)
+	}
+	return o
+}
+
+// Raw returns the odds as an integer number of ten-thousandths.
+func (o Odds) Raw() int64 {
+	return int64(o)
+}
+
+// String formats the odds with trailing zeros removed, e.g. "2.5".
+func (o Odds) String() string {
+	return formatFixed(int64(o), OddsScale, 0)
+}
+
+// MarshalJSON encodes the odds as a JSON number.
+func (o Odds) MarshalJSON() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number (2.5) or a string ("2.50").
+func (o *Odds) UnmarshalJSON(data []byte) error {
+	s, err := unquoteJSON(data)
+	if err != nil {
+		return err
+	}
+	if s == "null" {
+		return nil
+	}
+	v, err := ParseOdds(s)
+	if err != nil {
+		return err
+	}
+	*o = v
+	return nil
+}
+
+// CombineOdds multiplies decimal odds together, as for an accumulator,
+// rounding the product to OddsScale places with the given mode. The product
+// of no odds is EvenOdds. ErrOverflow is returned if the product is out of
+// range.
+func CombineOdds(mode RoundingMode, odds ...Odds) (Odds, error) {
+	if len(odds) == 0 {
+		return EvenOdds, nil
+	}
+	// Each factor carries one oddsPerUnit scale; the exact product carries
+	// len(odds) of them and is divided back down to one.
+	product := big.NewInt(1)
+	for _, o := range odds {
+		product.Mul(product, big.NewInt(int64(o)))
+	}
+	den := new(big.Int).Exp(big.NewInt(oddsPerUnit), big.NewInt(int64(len(odds)-1)), nil)
+	combined, err := divRound(product, den, mode)
+	return Odds(combined), err
+}
+
+// BoostOdds raises the winnings part of odds, odds − 1, by bps basis points
+// (2500 = 25%), as for a profit boost, rounding to OddsScale places with the
+// given mode. Odds of EvenOdds stay as they are. ErrOverflow is returned if
+// the boosted odds are out of range.
+func BoostOdds(o Odds, bps int64, mode RoundingMode) (Odds, error) {
+	winnings := new(big.Int).Mul(big.NewInt(int64(o-EvenOdds)), big.NewInt(10000+bps))
+	boosted, err := divRound(winnings, big.NewInt(10000), mode)
+	if err != nil || boosted > int64(math.MaxInt64-EvenOdds) {
+		return 0, ErrOverflow
+	}
+	return EvenOdds + Odds(boosted), nil
+}
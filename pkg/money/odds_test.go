@@ -0,0 +1,63 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCombineOdds(t *testing.T) {
+	combined, err := CombineOdds(RoundDown, MustParseOdds("2.5"), MustParseOdds("1.5"), MustParseOdds("1.1"))
+	if err != nil {
+		t.Fatalf("CombineOdds: %v", err)
+	}
+	if want := MustParseOdds("4.125"); combined != want {
+		t.Fatalf("CombineOdds = %s, want %s", combined, want)
+	}
+	if none, err := CombineOdds(RoundDown); err != nil || none != EvenOdds {
+		t.Fatalf("CombineOdds() = %s, %v; want 1, nil", none, err)
+	}
+}
+
+func TestCombineOddsOverflow(t *testing.T) {
+	legs := make([]Odds, 12)
+	for i := range legs {
+		legs[i] = MustParseOdds("50")
+	}
+	if combined, err := CombineOdds(RoundDown, legs...); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("CombineOdds of 12 legs at 50 = %s, %v; want ErrOverflow", combined, err)
+	}
+}
+
+func TestBoostOddsOverflow(t *testing.T) {
+	if boosted, err := BoostOdds(Odds(math.MaxInt64/2), 100000, RoundDown); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("BoostOdds = %s, %v; want ErrOverflow", boosted, err)
+	}
+}
+
+func TestDivRoundModes(t *testing.T) {
+	tests := []struct {
+		amount string
+		mode   RoundingMode
+		want   string
+	}{
+		{"0.05", RoundDown, "0.07"},
+		{"0.05", RoundUp, "0.08"},
+		{"0.05", RoundHalfUp, "0.08"},
+		{"0.05", RoundHalfEven, "0.08"},
+		{"0.03", RoundHalfEven, "0.04"},
+		{"-0.05", RoundHalfUp, "-0.08"},
+	}
+	for _, tc := range tests {
+		// Odds of 1.5 leave a half cent on odd amounts.
+		if got := MustParse(tc.amount).MulOdds(MustParseOdds("1.5"), tc.mode); got != MustParse(tc.want) {
+			t.Errorf("%s × 1.5 rounded %s = %s, want %s", tc.amount, tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestCheckedMulRatioOverflow(t *testing.T) {
+	if _, err := Money(math.MaxInt64/2).CheckedMulRatio(3, 1, RoundDown); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("CheckedMulRatio error = %v, want ErrOverflow", err)
+	}
+}
@@ -0,0 +1,89 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrOverflow is returned when a result does not fit in 64 bits.
+var ErrOverflow = errors.New("value out of range")
+
+// RoundingMode controls how a fractional number of minor units is resolved.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest value, ties away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest value, ties to the even neighbour (banker's rounding).
+	RoundHalfEven
+	// RoundDown truncates towards zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+// String returns the name of the rounding mode.
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfUp:
+		return "HALF_UP"
+	case RoundHalfEven:
+		return "HALF_EVEN"
+	case RoundDown:
+		return "DOWN"
+	case RoundUp:
+		return "UP"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// divRound computes num/den rounded with the given mode, or ErrOverflow if
+// the result does not fit in an int64.
+// Arbitrary precision is used for the intermediate values so products of
+// large stakes and odds cannot overflow before they are scaled back down.
+func divRound(num, den *big.Int, mode RoundingMode) (int64, error) {
+	if den.Sign() < 0 {
+		num = new(big.Int).Neg(num)
+		den = new(big.Int).Neg(den)
+	}
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		// away is +1 or -1: the direction a non-truncating rounding moves in.
+		away := big.NewInt(int64(num.Sign()))
+
+		// cmpHalf compares 2*|rem| with den to tell below/at/above the midpoint.
+		twice := new(big.Int).Abs(rem)
+		twice.Lsh(twice, 1)
+		cmpHalf := twice.Cmp(den)
+
+		switch mode {
+		case RoundDown:
+			// Truncation already happened in QuoRem.
+		case RoundUp:
+			quo.Add(quo, away)
+		case RoundHalfUp:
+			if cmpHalf >= 0 {
+				quo.Add(quo, away)
+			}
+		case RoundHalfEven:
+			if cmpHalf > 0 || (cmpHalf == 0 && quo.Bit(0) != 0) {
+				quo.Add(quo, away)
+			}
+		}
+	}
+	if !quo.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quo.Int64(), nil
+}
+
+// mustFit returns v, panicking if err reports that it overflowed. It is
+// used where callers have bounded the inputs, so overflow is a bug.
+func mustFit(v int64, err error) int64 {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}